@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS set_updated_at_anomaly ON "anomaly";
+DROP TABLE IF EXISTS "anomaly";
+
+COMMIT;
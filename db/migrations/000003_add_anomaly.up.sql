@@ -0,0 +1,33 @@
+BEGIN;
+
+-- Create the "anomaly" table holding detected unusual expenses
+CREATE TABLE "anomaly" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "user_id" UUID NOT NULL,
+    "expense_id" INTEGER NOT NULL,
+    "kind" TEXT NOT NULL,
+    "score" DECIMAL(10,2) NOT NULL,
+    "reason" TEXT NOT NULL,
+    "dismissed_at" TIMESTAMP(3) NULL,
+
+    CONSTRAINT "anomaly_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "anomaly_kind_check" CHECK ("kind" IN ('large_amount', 'new_merchant', 'frequency_spike'))
+);
+
+-- An expense is flagged at most once per kind
+CREATE UNIQUE INDEX "anomaly_expense_id_kind_key" ON "anomaly"("expense_id", "kind");
+CREATE INDEX "anomaly_user_id_idx" ON "anomaly"("user_id");
+
+-- Add foreign key constraints
+ALTER TABLE "anomaly" ADD CONSTRAINT "anomaly_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+ALTER TABLE "anomaly" ADD CONSTRAINT "anomaly_expense_id_fkey" FOREIGN KEY ("expense_id") REFERENCES "expense"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- Add trigger to automatically update "updated_at" on row updates
+CREATE TRIGGER set_updated_at_anomaly
+BEFORE UPDATE ON "anomaly"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+COMMIT;
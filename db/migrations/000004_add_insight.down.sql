@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS set_updated_at_insight ON "insight";
+DROP TABLE IF EXISTS "insight";
+
+ALTER TABLE "user" DROP COLUMN IF EXISTS "locale";
+
+COMMIT;
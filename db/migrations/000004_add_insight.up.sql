@@ -0,0 +1,38 @@
+BEGIN;
+
+-- Locale used to render user facing texts such as insights
+ALTER TABLE "user" ADD COLUMN "locale" TEXT NOT NULL DEFAULT 'en-US';
+
+-- Create the "insight" table holding generated insight cards
+CREATE TABLE "insight" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "user_id" UUID NOT NULL,
+    "type" TEXT NOT NULL,
+    "key" TEXT NOT NULL,
+    "severity" TEXT NOT NULL,
+    "title" TEXT NOT NULL,
+    "message" TEXT NOT NULL,
+    "data" JSONB NOT NULL DEFAULT '{}',
+    "filter" JSONB NOT NULL DEFAULT '{}',
+    "read_at" TIMESTAMP(3) NULL,
+    "dismissed_at" TIMESTAMP(3) NULL,
+
+    CONSTRAINT "insight_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "insight_severity_check" CHECK ("severity" IN ('positive', 'info', 'warning'))
+);
+
+-- The key identifies a card across recomputations so read/dismiss state is kept
+CREATE UNIQUE INDEX "insight_user_id_key_key" ON "insight"("user_id", "key");
+
+-- Add foreign key constraints
+ALTER TABLE "insight" ADD CONSTRAINT "insight_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- Add trigger to automatically update "updated_at" on row updates
+CREATE TRIGGER set_updated_at_insight
+BEFORE UPDATE ON "insight"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+-- Income cannot be represented without a kind
+DELETE FROM "expense" WHERE "kind" <> 'expense';
+DELETE FROM "category" WHERE "kind" <> 'expense';
+
+DROP INDEX IF EXISTS "expense_user_id_kind_purchase_date_idx";
+ALTER TABLE "expense" DROP COLUMN IF EXISTS "kind";
+ALTER TABLE "category" DROP COLUMN IF EXISTS "kind";
+
+COMMIT;
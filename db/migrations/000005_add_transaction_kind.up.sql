@@ -0,0 +1,12 @@
+BEGIN;
+
+-- Expenses and income share the "expense" table, told apart by their kind
+ALTER TABLE "expense" ADD COLUMN "kind" TEXT NOT NULL DEFAULT 'expense';
+ALTER TABLE "expense" ADD CONSTRAINT "expense_kind_check" CHECK ("kind" IN ('expense', 'income'));
+CREATE INDEX "expense_user_id_kind_purchase_date_idx" ON "expense"("user_id", "kind", "purchase_date");
+
+-- Categories are either for expenses or for income
+ALTER TABLE "category" ADD COLUMN "kind" TEXT NOT NULL DEFAULT 'expense';
+ALTER TABLE "category" ADD CONSTRAINT "category_kind_check" CHECK ("kind" IN ('expense', 'income'));
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+DROP VIEW IF EXISTS "report_line";
+
+DELETE FROM "expense" WHERE "kind" = 'refund';
+
+DROP INDEX IF EXISTS "expense_refund_of_id_idx";
+ALTER TABLE "expense" DROP CONSTRAINT IF EXISTS "expense_refund_of_id_check";
+ALTER TABLE "expense" DROP CONSTRAINT IF EXISTS "expense_refund_of_id_fkey";
+ALTER TABLE "expense" DROP COLUMN IF EXISTS "refund_of_id";
+
+ALTER TABLE "expense" DROP CONSTRAINT "expense_kind_check";
+ALTER TABLE "expense" ADD CONSTRAINT "expense_kind_check" CHECK ("kind" IN ('expense', 'income'));
+
+COMMIT;
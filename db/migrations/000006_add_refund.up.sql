@@ -0,0 +1,36 @@
+BEGIN;
+
+-- Refunds are transactions of their own kind referencing the refunded expense
+ALTER TABLE "expense" DROP CONSTRAINT "expense_kind_check";
+ALTER TABLE "expense" ADD CONSTRAINT "expense_kind_check" CHECK ("kind" IN ('expense', 'income', 'refund'));
+
+ALTER TABLE "expense" ADD COLUMN "refund_of_id" INTEGER NULL;
+ALTER TABLE "expense" ADD CONSTRAINT "expense_refund_of_id_fkey" FOREIGN KEY ("refund_of_id") REFERENCES "expense"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "expense" ADD CONSTRAINT "expense_refund_of_id_check" CHECK (("kind" = 'refund') = ("refund_of_id" IS NOT NULL));
+CREATE INDEX "expense_refund_of_id_idx" ON "expense"("refund_of_id");
+
+-- Every report aggregates this view. Refunds are netted against the expense
+-- they refund: same category and purchase date, negative amount.
+CREATE VIEW "report_line" AS
+SELECT
+    e."id" AS "expense_id",
+    e."user_id",
+    e."kind",
+    e."category_id",
+    e."purchase_date",
+    e."amount"
+FROM "expense" e
+WHERE e."kind" IN ('expense', 'income')
+UNION ALL
+SELECT
+    r."id" AS "expense_id",
+    r."user_id",
+    o."kind",
+    o."category_id",
+    o."purchase_date",
+    -r."amount" AS "amount"
+FROM "expense" r
+JOIN "expense" o ON o."id" = r."refund_of_id"
+WHERE r."kind" = 'refund';
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+DELETE FROM "expense" WHERE "kind" = 'transfer';
+
+DROP INDEX IF EXISTS "expense_transfer_account_id_idx";
+DROP INDEX IF EXISTS "expense_account_id_idx";
+ALTER TABLE "expense" DROP CONSTRAINT IF EXISTS "expense_transfer_account_id_check";
+ALTER TABLE "expense" DROP CONSTRAINT IF EXISTS "expense_transfer_account_id_fkey";
+ALTER TABLE "expense" DROP CONSTRAINT IF EXISTS "expense_account_id_fkey";
+ALTER TABLE "expense" DROP COLUMN IF EXISTS "transfer_account_id";
+ALTER TABLE "expense" DROP COLUMN IF EXISTS "account_id";
+
+ALTER TABLE "expense" DROP CONSTRAINT "expense_kind_check";
+ALTER TABLE "expense" ADD CONSTRAINT "expense_kind_check" CHECK ("kind" IN ('expense', 'income', 'refund'));
+
+DROP TABLE IF EXISTS "account";
+
+COMMIT;
@@ -0,0 +1,40 @@
+BEGIN;
+
+-- Create the "account" table
+CREATE TABLE "account" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "user_id" UUID NOT NULL,
+    "name" TEXT NOT NULL,
+    "type" TEXT NOT NULL,
+
+    CONSTRAINT "account_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "account_type_check" CHECK ("type" IN ('checking', 'savings', 'credit_card', 'cash'))
+);
+
+ALTER TABLE "account" ADD CONSTRAINT "account_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+CREATE INDEX "account_user_id_idx" ON "account"("user_id");
+
+CREATE TRIGGER set_updated_at_account
+BEFORE UPDATE ON "account"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Transactions optionally belong to an account. Transfers move money from their
+-- account to the transfer account and never count as spending or income.
+ALTER TABLE "expense" DROP CONSTRAINT "expense_kind_check";
+ALTER TABLE "expense" ADD CONSTRAINT "expense_kind_check" CHECK ("kind" IN ('expense', 'income', 'refund', 'transfer'));
+
+ALTER TABLE "expense" ADD COLUMN "account_id" INTEGER NULL;
+ALTER TABLE "expense" ADD COLUMN "transfer_account_id" INTEGER NULL;
+ALTER TABLE "expense" ADD CONSTRAINT "expense_account_id_fkey" FOREIGN KEY ("account_id") REFERENCES "account"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+ALTER TABLE "expense" ADD CONSTRAINT "expense_transfer_account_id_fkey" FOREIGN KEY ("transfer_account_id") REFERENCES "account"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+ALTER TABLE "expense" ADD CONSTRAINT "expense_transfer_account_id_check" CHECK (
+    ("kind" = 'transfer') = ("transfer_account_id" IS NOT NULL)
+    AND ("kind" <> 'transfer' OR ("account_id" IS NOT NULL AND "account_id" <> "transfer_account_id" AND "category_id" IS NULL))
+);
+CREATE INDEX "expense_account_id_idx" ON "expense"("account_id");
+CREATE INDEX "expense_transfer_account_id_idx" ON "expense"("transfer_account_id");
+
+COMMIT;
@@ -0,0 +1,60 @@
+BEGIN;
+
+-- Create the "tag" table
+CREATE TABLE "tag" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "user_id" UUID NOT NULL,
+    "name" TEXT NOT NULL,
+
+    CONSTRAINT "tag_pkey" PRIMARY KEY ("id")
+);
+
+ALTER TABLE "tag" ADD CONSTRAINT "tag_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+CREATE UNIQUE INDEX "tag_user_id_name_key" ON "tag"("user_id", "name");
+
+CREATE TRIGGER set_updated_at_tag
+BEFORE UPDATE ON "tag"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Create the "expense_tag" table
+CREATE TABLE "expense_tag" (
+    "expense_id" INTEGER NOT NULL,
+    "tag_id" INTEGER NOT NULL,
+
+    CONSTRAINT "expense_tag_pkey" PRIMARY KEY ("expense_id", "tag_id")
+);
+
+ALTER TABLE "expense_tag" ADD CONSTRAINT "expense_tag_expense_id_fkey" FOREIGN KEY ("expense_id") REFERENCES "expense"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "expense_tag" ADD CONSTRAINT "expense_tag_tag_id_fkey" FOREIGN KEY ("tag_id") REFERENCES "tag"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+CREATE INDEX "expense_tag_tag_id_idx" ON "expense_tag"("tag_id");
+
+-- Refund lines carry the id of the expense they refund so they can be joined
+-- with its tags
+CREATE OR REPLACE VIEW "report_line" AS
+SELECT
+    e."id" AS "expense_id",
+    e."user_id",
+    e."kind",
+    e."category_id",
+    e."purchase_date",
+    e."amount",
+    e."id" AS "source_expense_id"
+FROM "expense" e
+WHERE e."kind" IN ('expense', 'income')
+UNION ALL
+SELECT
+    r."id" AS "expense_id",
+    r."user_id",
+    o."kind",
+    o."category_id",
+    o."purchase_date",
+    -r."amount" AS "amount",
+    o."id" AS "source_expense_id"
+FROM "expense" r
+JOIN "expense" o ON o."id" = r."refund_of_id"
+WHERE r."kind" = 'refund';
+
+COMMIT;
@@ -0,0 +1,29 @@
+BEGIN;
+
+CREATE OR REPLACE VIEW "report_line" AS
+SELECT
+    e."id" AS "expense_id",
+    e."user_id",
+    e."kind",
+    e."category_id",
+    e."purchase_date",
+    e."amount",
+    e."id" AS "source_expense_id"
+FROM "expense" e
+WHERE e."kind" IN ('expense', 'income')
+UNION ALL
+SELECT
+    r."id" AS "expense_id",
+    r."user_id",
+    o."kind",
+    o."category_id",
+    o."purchase_date",
+    -r."amount" AS "amount",
+    o."id" AS "source_expense_id"
+FROM "expense" r
+JOIN "expense" o ON o."id" = r."refund_of_id"
+WHERE r."kind" = 'refund';
+
+DROP TABLE IF EXISTS "expense_split";
+
+COMMIT;
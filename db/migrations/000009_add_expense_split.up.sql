@@ -0,0 +1,79 @@
+BEGIN;
+
+-- Create the "expense_split" table
+CREATE TABLE "expense_split" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "expense_id" INTEGER NOT NULL,
+    "category_id" INTEGER NOT NULL,
+    "amount" DECIMAL(10,2) NOT NULL,
+    "note" TEXT NULL,
+
+    CONSTRAINT "expense_split_pkey" PRIMARY KEY ("id")
+);
+
+ALTER TABLE "expense_split" ADD CONSTRAINT "expense_split_expense_id_fkey" FOREIGN KEY ("expense_id") REFERENCES "expense"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "expense_split" ADD CONSTRAINT "expense_split_category_id_fkey" FOREIGN KEY ("category_id") REFERENCES "category"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+CREATE INDEX "expense_split_expense_id_idx" ON "expense_split"("expense_id");
+CREATE INDEX "expense_split_category_id_idx" ON "expense_split"("category_id");
+
+CREATE TRIGGER set_updated_at_expense_split
+BEFORE UPDATE ON "expense_split"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Split expenses contribute one line per split. Refunds of split expenses are
+-- spread over the splits in proportion to their amounts.
+CREATE OR REPLACE VIEW "report_line" AS
+SELECT
+    e."id" AS "expense_id",
+    e."user_id",
+    e."kind",
+    e."category_id",
+    e."purchase_date",
+    e."amount",
+    e."id" AS "source_expense_id"
+FROM "expense" e
+WHERE e."kind" IN ('expense', 'income')
+AND NOT EXISTS (SELECT 1 FROM "expense_split" s WHERE s."expense_id" = e."id")
+UNION ALL
+SELECT
+    e."id" AS "expense_id",
+    e."user_id",
+    e."kind",
+    s."category_id",
+    e."purchase_date",
+    s."amount",
+    e."id" AS "source_expense_id"
+FROM "expense" e
+JOIN "expense_split" s ON s."expense_id" = e."id"
+WHERE e."kind" IN ('expense', 'income')
+UNION ALL
+SELECT
+    r."id" AS "expense_id",
+    r."user_id",
+    o."kind",
+    o."category_id",
+    o."purchase_date",
+    -r."amount" AS "amount",
+    o."id" AS "source_expense_id"
+FROM "expense" r
+JOIN "expense" o ON o."id" = r."refund_of_id"
+WHERE r."kind" = 'refund'
+AND NOT EXISTS (SELECT 1 FROM "expense_split" s WHERE s."expense_id" = o."id")
+UNION ALL
+SELECT
+    r."id" AS "expense_id",
+    r."user_id",
+    o."kind",
+    s."category_id",
+    o."purchase_date",
+    -r."amount" * s."amount" / o."amount" AS "amount",
+    o."id" AS "source_expense_id"
+FROM "expense" r
+JOIN "expense" o ON o."id" = r."refund_of_id"
+JOIN "expense_split" s ON s."expense_id" = o."id"
+WHERE r."kind" = 'refund';
+
+COMMIT;
@@ -0,0 +1,67 @@
+BEGIN;
+
+DROP VIEW IF EXISTS "report_line";
+CREATE VIEW "report_line" AS
+SELECT
+    e."id" AS "expense_id",
+    e."user_id",
+    e."kind",
+    e."category_id",
+    e."purchase_date",
+    e."amount",
+    e."id" AS "source_expense_id"
+FROM "expense" e
+WHERE e."kind" IN ('expense', 'income')
+AND NOT EXISTS (SELECT 1 FROM "expense_split" s WHERE s."expense_id" = e."id")
+UNION ALL
+SELECT
+    e."id" AS "expense_id",
+    e."user_id",
+    e."kind",
+    s."category_id",
+    e."purchase_date",
+    s."amount",
+    e."id" AS "source_expense_id"
+FROM "expense" e
+JOIN "expense_split" s ON s."expense_id" = e."id"
+WHERE e."kind" IN ('expense', 'income')
+UNION ALL
+SELECT
+    r."id" AS "expense_id",
+    r."user_id",
+    o."kind",
+    o."category_id",
+    o."purchase_date",
+    -r."amount" AS "amount",
+    o."id" AS "source_expense_id"
+FROM "expense" r
+JOIN "expense" o ON o."id" = r."refund_of_id"
+WHERE r."kind" = 'refund'
+AND NOT EXISTS (SELECT 1 FROM "expense_split" s WHERE s."expense_id" = o."id")
+UNION ALL
+SELECT
+    r."id" AS "expense_id",
+    r."user_id",
+    o."kind",
+    s."category_id",
+    o."purchase_date",
+    -r."amount" * s."amount" / o."amount" AS "amount",
+    o."id" AS "source_expense_id"
+FROM "expense" r
+JOIN "expense" o ON o."id" = r."refund_of_id"
+JOIN "expense_split" s ON s."expense_id" = o."id"
+WHERE r."kind" = 'refund';
+
+DROP INDEX IF EXISTS "category_workspace_id_idx";
+ALTER TABLE "category" DROP CONSTRAINT IF EXISTS "category_workspace_id_fkey";
+ALTER TABLE "category" DROP COLUMN IF EXISTS "workspace_id";
+
+DROP INDEX IF EXISTS "expense_workspace_id_kind_purchase_date_idx";
+ALTER TABLE "expense" DROP CONSTRAINT IF EXISTS "expense_workspace_id_fkey";
+ALTER TABLE "expense" DROP COLUMN IF EXISTS "workspace_id";
+
+DROP TABLE IF EXISTS "workspace_invitation";
+DROP TABLE IF EXISTS "workspace_member";
+DROP TABLE IF EXISTS "workspace";
+
+COMMIT;
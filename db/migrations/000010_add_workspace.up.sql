@@ -0,0 +1,130 @@
+BEGIN;
+
+-- Create the "workspace" table
+CREATE TABLE "workspace" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "name" TEXT NOT NULL,
+
+    CONSTRAINT "workspace_pkey" PRIMARY KEY ("id")
+);
+
+CREATE TRIGGER set_updated_at_workspace
+BEFORE UPDATE ON "workspace"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Create the "workspace_member" table
+CREATE TABLE "workspace_member" (
+    "workspace_id" INTEGER NOT NULL,
+    "user_id" UUID NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "role" TEXT NOT NULL,
+
+    CONSTRAINT "workspace_member_pkey" PRIMARY KEY ("workspace_id", "user_id"),
+    CONSTRAINT "workspace_member_role_check" CHECK ("role" IN ('owner', 'editor', 'viewer'))
+);
+
+ALTER TABLE "workspace_member" ADD CONSTRAINT "workspace_member_workspace_id_fkey" FOREIGN KEY ("workspace_id") REFERENCES "workspace"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "workspace_member" ADD CONSTRAINT "workspace_member_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+CREATE INDEX "workspace_member_user_id_idx" ON "workspace_member"("user_id");
+
+CREATE TRIGGER set_updated_at_workspace_member
+BEFORE UPDATE ON "workspace_member"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Create the "workspace_invitation" table
+CREATE TABLE "workspace_invitation" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "workspace_id" INTEGER NOT NULL,
+    "invited_by" UUID NOT NULL,
+    "email" TEXT NOT NULL,
+    "role" TEXT NOT NULL,
+    "accepted_at" TIMESTAMP(3) NULL,
+
+    CONSTRAINT "workspace_invitation_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "workspace_invitation_role_check" CHECK ("role" IN ('editor', 'viewer'))
+);
+
+ALTER TABLE "workspace_invitation" ADD CONSTRAINT "workspace_invitation_workspace_id_fkey" FOREIGN KEY ("workspace_id") REFERENCES "workspace"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "workspace_invitation" ADD CONSTRAINT "workspace_invitation_invited_by_fkey" FOREIGN KEY ("invited_by") REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+CREATE UNIQUE INDEX "workspace_invitation_workspace_id_email_key" ON "workspace_invitation"("workspace_id", "email") WHERE "accepted_at" IS NULL;
+CREATE INDEX "workspace_invitation_email_idx" ON "workspace_invitation"("email");
+
+CREATE TRIGGER set_updated_at_workspace_invitation
+BEFORE UPDATE ON "workspace_invitation"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Transactions and categories either belong to their user's personal ledger,
+-- when "workspace_id" is NULL, or to a shared workspace. "user_id" remains the
+-- member who created the row.
+ALTER TABLE "expense" ADD COLUMN "workspace_id" INTEGER NULL;
+ALTER TABLE "expense" ADD CONSTRAINT "expense_workspace_id_fkey" FOREIGN KEY ("workspace_id") REFERENCES "workspace"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+CREATE INDEX "expense_workspace_id_kind_purchase_date_idx" ON "expense"("workspace_id", "kind", "purchase_date");
+
+ALTER TABLE "category" ADD COLUMN "workspace_id" INTEGER NULL;
+ALTER TABLE "category" ADD CONSTRAINT "category_workspace_id_fkey" FOREIGN KEY ("workspace_id") REFERENCES "workspace"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+CREATE INDEX "category_workspace_id_idx" ON "category"("workspace_id");
+
+CREATE OR REPLACE VIEW "report_line" AS
+SELECT
+    e."id" AS "expense_id",
+    e."user_id",
+    e."kind",
+    e."category_id",
+    e."purchase_date",
+    e."amount",
+    e."id" AS "source_expense_id",
+    e."workspace_id"
+FROM "expense" e
+WHERE e."kind" IN ('expense', 'income')
+AND NOT EXISTS (SELECT 1 FROM "expense_split" s WHERE s."expense_id" = e."id")
+UNION ALL
+SELECT
+    e."id" AS "expense_id",
+    e."user_id",
+    e."kind",
+    s."category_id",
+    e."purchase_date",
+    s."amount",
+    e."id" AS "source_expense_id",
+    e."workspace_id"
+FROM "expense" e
+JOIN "expense_split" s ON s."expense_id" = e."id"
+WHERE e."kind" IN ('expense', 'income')
+UNION ALL
+SELECT
+    r."id" AS "expense_id",
+    o."user_id",
+    o."kind",
+    o."category_id",
+    o."purchase_date",
+    -r."amount" AS "amount",
+    o."id" AS "source_expense_id",
+    o."workspace_id"
+FROM "expense" r
+JOIN "expense" o ON o."id" = r."refund_of_id"
+WHERE r."kind" = 'refund'
+AND NOT EXISTS (SELECT 1 FROM "expense_split" s WHERE s."expense_id" = o."id")
+UNION ALL
+SELECT
+    r."id" AS "expense_id",
+    o."user_id",
+    o."kind",
+    s."category_id",
+    o."purchase_date",
+    -r."amount" * s."amount" / o."amount" AS "amount",
+    o."id" AS "source_expense_id",
+    o."workspace_id"
+FROM "expense" r
+JOIN "expense" o ON o."id" = r."refund_of_id"
+JOIN "expense_split" s ON s."expense_id" = o."id"
+WHERE r."kind" = 'refund';
+
+COMMIT;
@@ -0,0 +1,157 @@
+BEGIN;
+
+-- Create the "contact" table
+CREATE TABLE "contact" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "user_id" UUID NOT NULL,
+    "name" TEXT NOT NULL,
+    "email" TEXT NULL,
+
+    CONSTRAINT "contact_pkey" PRIMARY KEY ("id")
+);
+
+ALTER TABLE "contact" ADD CONSTRAINT "contact_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+CREATE INDEX "contact_user_id_idx" ON "contact"("user_id");
+
+CREATE TRIGGER set_updated_at_contact
+BEFORE UPDATE ON "contact"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Create the "expense_share" table. A NULL "contact_id" is the user's own share.
+CREATE TABLE "expense_share" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "expense_id" INTEGER NOT NULL,
+    "contact_id" INTEGER NULL,
+    "amount" DECIMAL(10,2) NOT NULL,
+
+    CONSTRAINT "expense_share_pkey" PRIMARY KEY ("id")
+);
+
+ALTER TABLE "expense_share" ADD CONSTRAINT "expense_share_expense_id_fkey" FOREIGN KEY ("expense_id") REFERENCES "expense"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "expense_share" ADD CONSTRAINT "expense_share_contact_id_fkey" FOREIGN KEY ("contact_id") REFERENCES "contact"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+CREATE UNIQUE INDEX "expense_share_expense_id_contact_id_key" ON "expense_share"("expense_id", COALESCE("contact_id", 0));
+CREATE INDEX "expense_share_contact_id_idx" ON "expense_share"("contact_id");
+
+CREATE TRIGGER set_updated_at_expense_share
+BEFORE UPDATE ON "expense_share"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- The contact who paid a shared expense, NULL when the user paid it
+ALTER TABLE "expense" ADD COLUMN "paid_by_contact_id" INTEGER NULL;
+ALTER TABLE "expense" ADD CONSTRAINT "expense_paid_by_contact_id_fkey" FOREIGN KEY ("paid_by_contact_id") REFERENCES "contact"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- Create the "settlement" table. A NULL contact on either side is the user.
+CREATE TABLE "settlement" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "user_id" UUID NOT NULL,
+    "from_contact_id" INTEGER NULL,
+    "to_contact_id" INTEGER NULL,
+    "amount" DECIMAL(10,2) NOT NULL,
+    "date" TIMESTAMP(3) NOT NULL,
+    "note" TEXT NULL,
+
+    CONSTRAINT "settlement_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "settlement_amount_check" CHECK ("amount" > 0),
+    CONSTRAINT "settlement_parties_check" CHECK ("from_contact_id" IS DISTINCT FROM "to_contact_id")
+);
+
+ALTER TABLE "settlement" ADD CONSTRAINT "settlement_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+ALTER TABLE "settlement" ADD CONSTRAINT "settlement_from_contact_id_fkey" FOREIGN KEY ("from_contact_id") REFERENCES "contact"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+ALTER TABLE "settlement" ADD CONSTRAINT "settlement_to_contact_id_fkey" FOREIGN KEY ("to_contact_id") REFERENCES "contact"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+CREATE INDEX "settlement_user_id_date_idx" ON "settlement"("user_id", "date");
+
+CREATE TRIGGER set_updated_at_settlement
+BEFORE UPDATE ON "settlement"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Shared expenses only count the user's own share, scaling every line of the
+-- expense, including those of its refunds.
+DROP VIEW "report_line";
+
+CREATE VIEW "report_line" AS
+SELECT
+    l."expense_id",
+    l."user_id",
+    l."kind",
+    l."category_id",
+    l."purchase_date",
+    CASE
+        WHEN sh."total" IS NULL OR sh."total" = 0 THEN l."amount"
+        ELSE l."amount" * sh."own" / sh."total"
+    END AS "amount",
+    l."source_expense_id",
+    l."workspace_id"
+FROM (
+    SELECT
+        e."id" AS "expense_id",
+        e."user_id",
+        e."kind",
+        e."category_id",
+        e."purchase_date",
+        e."amount",
+        e."id" AS "source_expense_id",
+        e."workspace_id"
+    FROM "expense" e
+    WHERE e."kind" IN ('expense', 'income')
+    AND NOT EXISTS (SELECT 1 FROM "expense_split" s WHERE s."expense_id" = e."id")
+    UNION ALL
+    SELECT
+        e."id" AS "expense_id",
+        e."user_id",
+        e."kind",
+        s."category_id",
+        e."purchase_date",
+        s."amount",
+        e."id" AS "source_expense_id",
+        e."workspace_id"
+    FROM "expense" e
+    JOIN "expense_split" s ON s."expense_id" = e."id"
+    WHERE e."kind" IN ('expense', 'income')
+    UNION ALL
+    SELECT
+        r."id" AS "expense_id",
+        o."user_id",
+        o."kind",
+        o."category_id",
+        o."purchase_date",
+        -r."amount" AS "amount",
+        o."id" AS "source_expense_id",
+        o."workspace_id"
+    FROM "expense" r
+    JOIN "expense" o ON o."id" = r."refund_of_id"
+    WHERE r."kind" = 'refund'
+    AND NOT EXISTS (SELECT 1 FROM "expense_split" s WHERE s."expense_id" = o."id")
+    UNION ALL
+    SELECT
+        r."id" AS "expense_id",
+        o."user_id",
+        o."kind",
+        s."category_id",
+        o."purchase_date",
+        -r."amount" * s."amount" / o."amount" AS "amount",
+        o."id" AS "source_expense_id",
+        o."workspace_id"
+    FROM "expense" r
+    JOIN "expense" o ON o."id" = r."refund_of_id"
+    JOIN "expense_split" s ON s."expense_id" = o."id"
+    WHERE r."kind" = 'refund'
+) l
+LEFT JOIN (
+    SELECT
+        "expense_id",
+        SUM("amount") AS "total",
+        SUM(CASE WHEN "contact_id" IS NULL THEN "amount" ELSE 0 END) AS "own"
+    FROM "expense_share"
+    GROUP BY "expense_id"
+) sh ON sh."expense_id" = l."source_expense_id";
+
+COMMIT;
@@ -0,0 +1,29 @@
+BEGIN;
+
+-- Create the "attachment" table. File contents live in the blob store under
+-- "storage_key".
+CREATE TABLE "attachment" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "expense_id" INTEGER NOT NULL,
+    "user_id" UUID NOT NULL,
+    "storage_key" TEXT NOT NULL,
+    "file_name" TEXT NOT NULL,
+    "content_type" TEXT NOT NULL,
+    "size_bytes" BIGINT NOT NULL,
+
+    CONSTRAINT "attachment_pkey" PRIMARY KEY ("id")
+);
+
+ALTER TABLE "attachment" ADD CONSTRAINT "attachment_expense_id_fkey" FOREIGN KEY ("expense_id") REFERENCES "expense"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "attachment" ADD CONSTRAINT "attachment_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+CREATE UNIQUE INDEX "attachment_storage_key_key" ON "attachment"("storage_key");
+CREATE INDEX "attachment_expense_id_idx" ON "attachment"("expense_id");
+
+CREATE TRIGGER set_updated_at_attachment
+BEFORE UPDATE ON "attachment"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE "expense" DROP COLUMN IF EXISTS "merchant_id";
+DROP TABLE IF EXISTS "merchant";
+
+COMMIT;
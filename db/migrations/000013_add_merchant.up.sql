@@ -0,0 +1,29 @@
+BEGIN;
+
+-- Create the "merchant" table. "key" is the normalized description shared by
+-- the expenses of the merchant and "name" the display name the user can edit.
+CREATE TABLE "merchant" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "user_id" UUID NOT NULL,
+    "key" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+
+    CONSTRAINT "merchant_pkey" PRIMARY KEY ("id")
+);
+
+ALTER TABLE "merchant" ADD CONSTRAINT "merchant_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+CREATE UNIQUE INDEX "merchant_user_id_key_key" ON "merchant"("user_id", "key");
+
+CREATE TRIGGER set_updated_at_merchant
+BEFORE UPDATE ON "merchant"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Existing expenses are assigned a merchant by a background job
+ALTER TABLE "expense" ADD COLUMN "merchant_id" INTEGER NULL;
+ALTER TABLE "expense" ADD CONSTRAINT "expense_merchant_id_fkey" FOREIGN KEY ("merchant_id") REFERENCES "merchant"("id") ON DELETE SET NULL ON UPDATE CASCADE;
+CREATE INDEX "expense_merchant_id_idx" ON "expense"("merchant_id");
+
+COMMIT;
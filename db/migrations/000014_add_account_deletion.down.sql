@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS "account_deletion";
+ALTER TABLE "user" DROP COLUMN IF EXISTS "deletion_scheduled_at";
+
+COMMIT;
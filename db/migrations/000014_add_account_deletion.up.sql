@@ -0,0 +1,33 @@
+BEGIN;
+
+-- Accounts are deleted after a grace period, during which "deletion_scheduled_at"
+-- holds the time the data of the user gets purged
+ALTER TABLE "user" ADD COLUMN "deletion_scheduled_at" TIMESTAMP(3) NULL;
+CREATE INDEX "user_deletion_scheduled_at_idx" ON "user"("deletion_scheduled_at") WHERE "deletion_scheduled_at" IS NOT NULL;
+
+-- Create the "account_deletion" table, the audit trail of account deletions.
+-- It outlives the user, so "user_id" and "clerk_id" reference nothing.
+CREATE TABLE "account_deletion" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "user_id" UUID NOT NULL,
+    "clerk_id" TEXT NOT NULL,
+    "requested_by" TEXT NOT NULL,
+    "scheduled_at" TIMESTAMP(3) NOT NULL,
+    "canceled_at" TIMESTAMP(3) NULL,
+    "purged_at" TIMESTAMP(3) NULL,
+
+    CONSTRAINT "account_deletion_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "account_deletion_requested_by_check" CHECK ("requested_by" IN ('user', 'clerk'))
+);
+
+CREATE INDEX "account_deletion_user_id_idx" ON "account_deletion"("user_id");
+CREATE UNIQUE INDEX "account_deletion_user_id_pending_key" ON "account_deletion"("user_id") WHERE "canceled_at" IS NULL AND "purged_at" IS NULL;
+
+CREATE TRIGGER set_updated_at_account_deletion
+BEFORE UPDATE ON "account_deletion"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+COMMIT;
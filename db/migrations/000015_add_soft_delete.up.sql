@@ -0,0 +1,97 @@
+BEGIN;
+
+-- Deleted transactions and categories go to the trash, where "deleted_at"
+-- holds the time they were deleted, until they are restored or purged.
+ALTER TABLE "expense" ADD COLUMN "deleted_at" TIMESTAMP(3) NULL;
+CREATE INDEX "expense_deleted_at_idx" ON "expense"("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+ALTER TABLE "category" ADD COLUMN "deleted_at" TIMESTAMP(3) NULL;
+CREATE INDEX "category_deleted_at_idx" ON "category"("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+-- Reports leave out the transactions in the trash
+DROP VIEW "report_line";
+
+CREATE VIEW "report_line" AS
+SELECT
+    l."expense_id",
+    l."user_id",
+    l."kind",
+    l."category_id",
+    l."purchase_date",
+    CASE
+        WHEN sh."total" IS NULL OR sh."total" = 0 THEN l."amount"
+        ELSE l."amount" * sh."own" / sh."total"
+    END AS "amount",
+    l."source_expense_id",
+    l."workspace_id"
+FROM (
+    SELECT
+        e."id" AS "expense_id",
+        e."user_id",
+        e."kind",
+        e."category_id",
+        e."purchase_date",
+        e."amount",
+        e."id" AS "source_expense_id",
+        e."workspace_id"
+    FROM "expense" e
+    WHERE e."kind" IN ('expense', 'income')
+    AND e."deleted_at" IS NULL
+    AND NOT EXISTS (SELECT 1 FROM "expense_split" s WHERE s."expense_id" = e."id")
+    UNION ALL
+    SELECT
+        e."id" AS "expense_id",
+        e."user_id",
+        e."kind",
+        s."category_id",
+        e."purchase_date",
+        s."amount",
+        e."id" AS "source_expense_id",
+        e."workspace_id"
+    FROM "expense" e
+    JOIN "expense_split" s ON s."expense_id" = e."id"
+    WHERE e."kind" IN ('expense', 'income')
+    AND e."deleted_at" IS NULL
+    UNION ALL
+    SELECT
+        r."id" AS "expense_id",
+        o."user_id",
+        o."kind",
+        o."category_id",
+        o."purchase_date",
+        -r."amount" AS "amount",
+        o."id" AS "source_expense_id",
+        o."workspace_id"
+    FROM "expense" r
+    JOIN "expense" o ON o."id" = r."refund_of_id"
+    WHERE r."kind" = 'refund'
+    AND r."deleted_at" IS NULL
+    AND o."deleted_at" IS NULL
+    AND NOT EXISTS (SELECT 1 FROM "expense_split" s WHERE s."expense_id" = o."id")
+    UNION ALL
+    SELECT
+        r."id" AS "expense_id",
+        o."user_id",
+        o."kind",
+        s."category_id",
+        o."purchase_date",
+        -r."amount" * s."amount" / o."amount" AS "amount",
+        o."id" AS "source_expense_id",
+        o."workspace_id"
+    FROM "expense" r
+    JOIN "expense" o ON o."id" = r."refund_of_id"
+    JOIN "expense_split" s ON s."expense_id" = o."id"
+    WHERE r."kind" = 'refund'
+    AND r."deleted_at" IS NULL
+    AND o."deleted_at" IS NULL
+) l
+LEFT JOIN (
+    SELECT
+        "expense_id",
+        SUM("amount") AS "total",
+        SUM(CASE WHEN "contact_id" IS NULL THEN "amount" ELSE 0 END) AS "own"
+    FROM "expense_share"
+    GROUP BY "expense_id"
+) sh ON sh."expense_id" = l."source_expense_id";
+
+COMMIT;
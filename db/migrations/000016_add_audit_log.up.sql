@@ -0,0 +1,40 @@
+BEGIN;
+
+-- Create the "audit_log" table, the append-only trail of sensitive operations.
+-- It outlives the users it mentions, so "user_id" references nothing.
+-- "actor_id" holds the Clerk ID of a user or the source of a webhook.
+CREATE TABLE "audit_log" (
+    "id" BIGSERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "actor_type" TEXT NOT NULL,
+    "actor_id" TEXT NULL,
+    "user_id" UUID NULL,
+    "action" TEXT NOT NULL,
+    "entity_type" TEXT NOT NULL,
+    "entity_id" TEXT NULL,
+    "before" JSONB NULL,
+    "after" JSONB NULL,
+    "request_id" TEXT NULL,
+    "ip" TEXT NULL,
+
+    CONSTRAINT "audit_log_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "audit_log_actor_type_check" CHECK ("actor_type" IN ('user', 'webhook', 'system'))
+);
+
+CREATE INDEX "audit_log_user_id_created_at_idx" ON "audit_log"("user_id", "created_at" DESC);
+CREATE INDEX "audit_log_created_at_idx" ON "audit_log"("created_at" DESC);
+
+-- Entries can only be added
+CREATE OR REPLACE FUNCTION reject_audit_log_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reject_audit_log_change
+BEFORE UPDATE OR DELETE ON "audit_log"
+FOR EACH ROW
+EXECUTE FUNCTION reject_audit_log_change();
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+-- Create the "expense_revision" table, the field-level history of expense updates.
+-- "changes" maps each changed field to its old and new values, and "revision"
+-- numbers the updates of an expense from 1.
+CREATE TABLE "expense_revision" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "expense_id" INTEGER NOT NULL,
+    "revision" INTEGER NOT NULL,
+    "user_id" UUID NULL,
+    "changes" JSONB NOT NULL,
+
+    CONSTRAINT "expense_revision_pkey" PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "expense_revision_expense_id_revision_key" ON "expense_revision"("expense_id", "revision");
+
+ALTER TABLE "expense_revision" ADD CONSTRAINT "expense_revision_expense_id_fkey" FOREIGN KEY ("expense_id") REFERENCES "expense"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+-- Revisions by a member who left keep no author
+ALTER TABLE "expense_revision" ADD CONSTRAINT "expense_revision_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE SET NULL ON UPDATE CASCADE;
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+-- Create the "idempotency_key" table, the responses stored for the
+-- Idempotency-Key header of mutating requests. "fingerprint" hashes the request
+-- that claimed the key, and "status_code" stays NULL while it is being served.
+CREATE TABLE "idempotency_key" (
+    "user_id" UUID NOT NULL,
+    "key" TEXT NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "expires_at" TIMESTAMP(3) NOT NULL,
+    "fingerprint" TEXT NOT NULL,
+    "status_code" INTEGER NULL,
+    "content_type" TEXT NULL,
+    "response_body" BYTEA NOT NULL DEFAULT '',
+
+    CONSTRAINT "idempotency_key_pkey" PRIMARY KEY ("user_id", "key")
+);
+
+CREATE INDEX "idempotency_key_expires_at_idx" ON "idempotency_key"("expires_at");
+
+ALTER TABLE "idempotency_key" ADD CONSTRAINT "idempotency_key_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+COMMIT;
@@ -0,0 +1,32 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS record_sync_tombstone_contact ON "contact";
+DROP TRIGGER IF EXISTS set_change_id_contact ON "contact";
+ALTER TABLE "contact" DROP COLUMN IF EXISTS "change_id";
+
+DROP TRIGGER IF EXISTS record_sync_tombstone_merchant ON "merchant";
+DROP TRIGGER IF EXISTS set_change_id_merchant ON "merchant";
+ALTER TABLE "merchant" DROP COLUMN IF EXISTS "change_id";
+
+DROP TRIGGER IF EXISTS record_sync_tombstone_account ON "account";
+DROP TRIGGER IF EXISTS set_change_id_account ON "account";
+ALTER TABLE "account" DROP COLUMN IF EXISTS "change_id";
+
+DROP TRIGGER IF EXISTS record_sync_tombstone_tag ON "tag";
+DROP TRIGGER IF EXISTS set_change_id_tag ON "tag";
+ALTER TABLE "tag" DROP COLUMN IF EXISTS "change_id";
+
+DROP TRIGGER IF EXISTS record_sync_tombstone_category ON "category";
+DROP TRIGGER IF EXISTS set_change_id_category ON "category";
+ALTER TABLE "category" DROP COLUMN IF EXISTS "change_id";
+
+DROP TRIGGER IF EXISTS record_sync_tombstone_expense ON "expense";
+DROP TRIGGER IF EXISTS set_change_id_expense ON "expense";
+ALTER TABLE "expense" DROP COLUMN IF EXISTS "change_id";
+
+DROP TABLE IF EXISTS "sync_tombstone";
+DROP FUNCTION IF EXISTS record_sync_tombstone();
+DROP FUNCTION IF EXISTS update_change_id_column();
+DROP FUNCTION IF EXISTS current_change_id();
+
+COMMIT;
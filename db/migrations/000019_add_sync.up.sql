@@ -0,0 +1,125 @@
+BEGIN;
+
+-- Every synced row carries in "change_id" the ID of the transaction that last
+-- wrote it. Transaction IDs only ever grow, so clients resume syncing from the
+-- lowest ID still in progress when they last synced.
+CREATE OR REPLACE FUNCTION current_change_id()
+RETURNS BIGINT AS $$
+    SELECT pg_current_xact_id()::TEXT::BIGINT;
+$$ LANGUAGE sql VOLATILE;
+
+CREATE OR REPLACE FUNCTION update_change_id_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.change_id = current_change_id();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create the "sync_tombstone" table, which remembers the synced rows deleted
+-- for good until clients had the time to sync. Trashed rows are synced as
+-- updates of "deleted_at" instead.
+CREATE TABLE "sync_tombstone" (
+    "id" BIGSERIAL NOT NULL,
+    "change_id" BIGINT NOT NULL DEFAULT current_change_id(),
+    "deleted_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "entity_type" TEXT NOT NULL,
+    "entity_id" INTEGER NOT NULL,
+    "user_id" UUID NOT NULL,
+    "workspace_id" INTEGER NULL,
+
+    CONSTRAINT "sync_tombstone_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "sync_tombstone_user_id_change_id_idx" ON "sync_tombstone"("user_id", "change_id");
+CREATE INDEX "sync_tombstone_workspace_id_change_id_idx" ON "sync_tombstone"("workspace_id", "change_id") WHERE "workspace_id" IS NOT NULL;
+CREATE INDEX "sync_tombstone_deleted_at_idx" ON "sync_tombstone"("deleted_at");
+
+CREATE OR REPLACE FUNCTION record_sync_tombstone()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO "sync_tombstone" ("entity_type", "entity_id", "user_id", "workspace_id")
+    VALUES (TG_TABLE_NAME, OLD."id", OLD."user_id", (to_jsonb(OLD) ->> 'workspace_id')::INTEGER);
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE "expense" ADD COLUMN "change_id" BIGINT NOT NULL DEFAULT current_change_id();
+CREATE INDEX "expense_change_id_idx" ON "expense"("change_id");
+
+CREATE TRIGGER set_change_id_expense
+BEFORE UPDATE ON "expense"
+FOR EACH ROW
+EXECUTE FUNCTION update_change_id_column();
+
+CREATE TRIGGER record_sync_tombstone_expense
+AFTER DELETE ON "expense"
+FOR EACH ROW
+EXECUTE FUNCTION record_sync_tombstone();
+
+ALTER TABLE "category" ADD COLUMN "change_id" BIGINT NOT NULL DEFAULT current_change_id();
+CREATE INDEX "category_change_id_idx" ON "category"("change_id");
+
+CREATE TRIGGER set_change_id_category
+BEFORE UPDATE ON "category"
+FOR EACH ROW
+EXECUTE FUNCTION update_change_id_column();
+
+CREATE TRIGGER record_sync_tombstone_category
+AFTER DELETE ON "category"
+FOR EACH ROW
+EXECUTE FUNCTION record_sync_tombstone();
+
+ALTER TABLE "tag" ADD COLUMN "change_id" BIGINT NOT NULL DEFAULT current_change_id();
+CREATE INDEX "tag_change_id_idx" ON "tag"("change_id");
+
+CREATE TRIGGER set_change_id_tag
+BEFORE UPDATE ON "tag"
+FOR EACH ROW
+EXECUTE FUNCTION update_change_id_column();
+
+CREATE TRIGGER record_sync_tombstone_tag
+AFTER DELETE ON "tag"
+FOR EACH ROW
+EXECUTE FUNCTION record_sync_tombstone();
+
+ALTER TABLE "account" ADD COLUMN "change_id" BIGINT NOT NULL DEFAULT current_change_id();
+CREATE INDEX "account_change_id_idx" ON "account"("change_id");
+
+CREATE TRIGGER set_change_id_account
+BEFORE UPDATE ON "account"
+FOR EACH ROW
+EXECUTE FUNCTION update_change_id_column();
+
+CREATE TRIGGER record_sync_tombstone_account
+AFTER DELETE ON "account"
+FOR EACH ROW
+EXECUTE FUNCTION record_sync_tombstone();
+
+ALTER TABLE "merchant" ADD COLUMN "change_id" BIGINT NOT NULL DEFAULT current_change_id();
+CREATE INDEX "merchant_change_id_idx" ON "merchant"("change_id");
+
+CREATE TRIGGER set_change_id_merchant
+BEFORE UPDATE ON "merchant"
+FOR EACH ROW
+EXECUTE FUNCTION update_change_id_column();
+
+CREATE TRIGGER record_sync_tombstone_merchant
+AFTER DELETE ON "merchant"
+FOR EACH ROW
+EXECUTE FUNCTION record_sync_tombstone();
+
+ALTER TABLE "contact" ADD COLUMN "change_id" BIGINT NOT NULL DEFAULT current_change_id();
+CREATE INDEX "contact_change_id_idx" ON "contact"("change_id");
+
+CREATE TRIGGER set_change_id_contact
+BEFORE UPDATE ON "contact"
+FOR EACH ROW
+EXECUTE FUNCTION update_change_id_column();
+
+CREATE TRIGGER record_sync_tombstone_contact
+AFTER DELETE ON "contact"
+FOR EACH ROW
+EXECUTE FUNCTION record_sync_tombstone();
+
+COMMIT;
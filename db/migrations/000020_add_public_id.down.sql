@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE OR REPLACE FUNCTION record_sync_tombstone()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO "sync_tombstone" ("entity_type", "entity_id", "user_id", "workspace_id")
+    VALUES (TG_TABLE_NAME, OLD."id", OLD."user_id", (to_jsonb(OLD) ->> 'workspace_id')::INTEGER);
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE "sync_tombstone" DROP COLUMN "entity_public_id";
+
+ALTER TABLE "category" DROP COLUMN "public_id";
+
+ALTER TABLE "expense" DROP COLUMN "public_id";
+
+COMMIT;
@@ -0,0 +1,34 @@
+BEGIN;
+
+-- Transactions and categories get a public ID, the only ID clients see, and
+-- which they may pick themselves to create rows offline. The columns are added
+-- without a default, then given one: a volatile default on ADD COLUMN would
+-- rewrite the tables under an exclusive lock. Rows inserted from now on get a
+-- public ID from the default, existing rows are backfilled by the next
+-- migration, and NOT NULL and the unique indexes come last.
+ALTER TABLE "expense" ADD COLUMN "public_id" UUID NULL;
+ALTER TABLE "expense" ALTER COLUMN "public_id" SET DEFAULT gen_random_uuid();
+
+ALTER TABLE "category" ADD COLUMN "public_id" UUID NULL;
+ALTER TABLE "category" ALTER COLUMN "public_id" SET DEFAULT gen_random_uuid();
+
+-- Tombstones remember the public ID of the rows that have one, which is what
+-- clients know them by
+ALTER TABLE "sync_tombstone" ADD COLUMN "entity_public_id" UUID NULL;
+
+CREATE OR REPLACE FUNCTION record_sync_tombstone()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO "sync_tombstone" ("entity_type", "entity_id", "entity_public_id", "user_id", "workspace_id")
+    VALUES (
+        TG_TABLE_NAME,
+        OLD."id",
+        (to_jsonb(OLD) ->> 'public_id')::UUID,
+        OLD."user_id",
+        (to_jsonb(OLD) ->> 'workspace_id')::INTEGER
+    );
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
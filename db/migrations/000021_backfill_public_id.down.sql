@@ -0,0 +1 @@
+-- Backfilled public IDs are dropped along with their columns.
@@ -0,0 +1,28 @@
+-- Backfill the public IDs of the rows created before they existed, a range of
+-- serial keys at a time. The block commits after every batch so that rows are
+-- only locked briefly, which is why this file is not wrapped in a transaction
+-- and holds a single statement. Rows created meanwhile get one by default.
+DO $$
+DECLARE
+    batch_size CONSTANT INTEGER := 5000;
+    last_id INTEGER;
+    batch_start INTEGER;
+BEGIN
+    SELECT COALESCE(MAX("id"), 0) INTO last_id FROM "category";
+    batch_start := 0;
+    WHILE batch_start < last_id LOOP
+        UPDATE "category" SET "public_id" = gen_random_uuid()
+        WHERE "id" > batch_start AND "id" <= batch_start + batch_size AND "public_id" IS NULL;
+        COMMIT;
+        batch_start := batch_start + batch_size;
+    END LOOP;
+
+    SELECT COALESCE(MAX("id"), 0) INTO last_id FROM "expense";
+    batch_start := 0;
+    WHILE batch_start < last_id LOOP
+        UPDATE "expense" SET "public_id" = gen_random_uuid()
+        WHERE "id" > batch_start AND "id" <= batch_start + batch_size AND "public_id" IS NULL;
+        COMMIT;
+        batch_start := batch_start + batch_size;
+    END LOOP;
+END $$;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE "category" ALTER COLUMN "public_id" DROP NOT NULL;
+
+ALTER TABLE "expense" ALTER COLUMN "public_id" DROP NOT NULL;
+
+COMMIT;
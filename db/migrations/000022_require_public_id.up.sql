@@ -0,0 +1,23 @@
+-- Make the public IDs NOT NULL without scanning the tables under an exclusive
+-- lock: SET NOT NULL skips the scan when a validated check constraint already
+-- proves it, and validating a constraint lets reads and writes through. Each
+-- step commits on its own, so that no lock outlives it.
+BEGIN;
+ALTER TABLE "expense" ADD CONSTRAINT "expense_public_id_not_null" CHECK ("public_id" IS NOT NULL) NOT VALID;
+ALTER TABLE "category" ADD CONSTRAINT "category_public_id_not_null" CHECK ("public_id" IS NOT NULL) NOT VALID;
+COMMIT;
+
+BEGIN;
+ALTER TABLE "expense" VALIDATE CONSTRAINT "expense_public_id_not_null";
+COMMIT;
+
+BEGIN;
+ALTER TABLE "category" VALIDATE CONSTRAINT "category_public_id_not_null";
+COMMIT;
+
+BEGIN;
+ALTER TABLE "expense" ALTER COLUMN "public_id" SET NOT NULL;
+ALTER TABLE "expense" DROP CONSTRAINT "expense_public_id_not_null";
+ALTER TABLE "category" ALTER COLUMN "public_id" SET NOT NULL;
+ALTER TABLE "category" DROP CONSTRAINT "category_public_id_not_null";
+COMMIT;
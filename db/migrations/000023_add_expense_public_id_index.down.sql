@@ -0,0 +1 @@
+DROP INDEX CONCURRENTLY IF EXISTS "expense_public_id_key";
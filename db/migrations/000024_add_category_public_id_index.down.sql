@@ -0,0 +1 @@
+DROP INDEX CONCURRENTLY IF EXISTS "category_public_id_key";
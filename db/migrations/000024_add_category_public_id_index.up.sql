@@ -0,0 +1,3 @@
+-- Built concurrently so that writes go on meanwhile, which cannot run in a
+-- transaction: this file holds a single statement.
+CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS "category_public_id_key" ON "category"("public_id");
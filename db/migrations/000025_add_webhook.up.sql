@@ -0,0 +1,81 @@
+BEGIN;
+
+-- Create the "webhook_endpoint" table, the URLs users register to be notified
+-- of changes. "event_types" lists the event types delivered, every one when
+-- empty, and "secret" signs the deliveries in the Standard Webhooks format.
+CREATE TABLE "webhook_endpoint" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "user_id" UUID NOT NULL,
+    "url" TEXT NOT NULL,
+    "description" TEXT NOT NULL DEFAULT '',
+    "secret" TEXT NOT NULL,
+    "event_types" JSONB NOT NULL DEFAULT '[]',
+    "enabled" BOOLEAN NOT NULL DEFAULT TRUE,
+
+    CONSTRAINT "webhook_endpoint_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "webhook_endpoint_user_id_idx" ON "webhook_endpoint"("user_id");
+
+ALTER TABLE "webhook_endpoint" ADD CONSTRAINT "webhook_endpoint_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+CREATE TRIGGER set_updated_at_webhook_endpoint
+BEFORE UPDATE ON "webhook_endpoint"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Create the "webhook_delivery" table, an event to deliver to an endpoint.
+-- "message_id" is sent as the webhook-id header, the same across the retries
+-- and the endpoints of an event. Pending deliveries are attempted again from
+-- "next_attempt_at".
+CREATE TABLE "webhook_delivery" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "endpoint_id" INTEGER NOT NULL,
+    "message_id" UUID NOT NULL,
+    "event_type" TEXT NOT NULL,
+    "payload" JSONB NOT NULL,
+    "status" TEXT NOT NULL DEFAULT 'pending',
+    "attempt_count" INTEGER NOT NULL DEFAULT 0,
+    "next_attempt_at" TIMESTAMP(3) NULL,
+    "last_attempt_at" TIMESTAMP(3) NULL,
+    "last_response_code" INTEGER NULL,
+
+    CONSTRAINT "webhook_delivery_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "webhook_delivery_status_check" CHECK ("status" IN ('pending', 'succeeded', 'failed'))
+);
+
+CREATE INDEX "webhook_delivery_endpoint_id_created_at_idx" ON "webhook_delivery"("endpoint_id", "created_at");
+CREATE INDEX "webhook_delivery_next_attempt_at_idx" ON "webhook_delivery"("next_attempt_at") WHERE "status" = 'pending';
+CREATE INDEX "webhook_delivery_created_at_idx" ON "webhook_delivery"("created_at");
+
+ALTER TABLE "webhook_delivery" ADD CONSTRAINT "webhook_delivery_endpoint_id_fkey" FOREIGN KEY ("endpoint_id") REFERENCES "webhook_endpoint"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+CREATE TRIGGER set_updated_at_webhook_delivery
+BEFORE UPDATE ON "webhook_delivery"
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Create the "webhook_attempt" table, the log of the requests made for a
+-- delivery. "response_code" stays NULL when no response came back, and
+-- "error" says why.
+CREATE TABLE "webhook_attempt" (
+    "id" SERIAL NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "delivery_id" INTEGER NOT NULL,
+    "response_code" INTEGER NULL,
+    "response_body" TEXT NULL,
+    "error" TEXT NULL,
+    "duration_ms" INTEGER NOT NULL,
+
+    CONSTRAINT "webhook_attempt_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "webhook_attempt_delivery_id_idx" ON "webhook_attempt"("delivery_id");
+
+ALTER TABLE "webhook_attempt" ADD CONSTRAINT "webhook_attempt_delivery_id_fkey" FOREIGN KEY ("delivery_id") REFERENCES "webhook_delivery"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE "idempotency_key" DROP COLUMN "location";
+ALTER TABLE "idempotency_key" DROP COLUMN "etag";
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+-- Replays also return the "ETag" and "Location" headers of the stored
+-- response, which clients need to update or fetch what a retried request
+-- created
+ALTER TABLE "idempotency_key" ADD COLUMN "etag" TEXT NULL;
+ALTER TABLE "idempotency_key" ADD COLUMN "location" TEXT NULL;
+
+COMMIT;
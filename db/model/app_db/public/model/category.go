@@ -20,4 +20,9 @@ type Category struct {
 	Name        string
 	Description string
 	ColorHex    string
+	Kind        string
+	WorkspaceID *int32
+	DeletedAt   *time.Time
+	ChangeID    int64
+	PublicID    uuid.UUID
 }
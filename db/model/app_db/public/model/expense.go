@@ -13,13 +13,23 @@ import (
 )
 
 type Expense struct {
-	ID           int32 `sql:"primary_key"`
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	UserID       uuid.UUID
-	Amount       float64
-	PurchaseDate time.Time
-	BillDate     time.Time
-	Description  string
-	CategoryID   *int32
+	ID                int32 `sql:"primary_key"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	UserID            uuid.UUID
+	Amount            float64
+	PurchaseDate      time.Time
+	BillDate          time.Time
+	Description       string
+	CategoryID        *int32
+	Kind              string
+	RefundOfID        *int32
+	AccountID         *int32
+	TransferAccountID *int32
+	WorkspaceID       *int32
+	PaidByContactID   *int32
+	MerchantID        *int32
+	DeletedAt         *time.Time
+	ChangeID          int64
+	PublicID          uuid.UUID
 }
@@ -24,6 +24,11 @@ type categoryTable struct {
 	Name        postgres.ColumnString
 	Description postgres.ColumnString
 	ColorHex    postgres.ColumnString
+	Kind        postgres.ColumnString
+	WorkspaceID postgres.ColumnInteger
+	DeletedAt   postgres.ColumnTimestamp
+	ChangeID    postgres.ColumnInteger
+	PublicID    postgres.ColumnString
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -72,9 +77,14 @@ func newCategoryTableImpl(schemaName, tableName, alias string) categoryTable {
 		NameColumn        = postgres.StringColumn("name")
 		DescriptionColumn = postgres.StringColumn("description")
 		ColorHexColumn    = postgres.StringColumn("color_hex")
-		allColumns        = postgres.ColumnList{IDColumn, CreatedAtColumn, UpdatedAtColumn, UserIDColumn, NameColumn, DescriptionColumn, ColorHexColumn}
-		mutableColumns    = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, UserIDColumn, NameColumn, DescriptionColumn, ColorHexColumn}
-		defaultColumns    = postgres.ColumnList{IDColumn, CreatedAtColumn, UpdatedAtColumn}
+		KindColumn        = postgres.StringColumn("kind")
+		WorkspaceIDColumn = postgres.IntegerColumn("workspace_id")
+		DeletedAtColumn   = postgres.TimestampColumn("deleted_at")
+		ChangeIDColumn    = postgres.IntegerColumn("change_id")
+		PublicIDColumn    = postgres.StringColumn("public_id")
+		allColumns        = postgres.ColumnList{IDColumn, CreatedAtColumn, UpdatedAtColumn, UserIDColumn, NameColumn, DescriptionColumn, ColorHexColumn, KindColumn, WorkspaceIDColumn, DeletedAtColumn, ChangeIDColumn, PublicIDColumn}
+		mutableColumns    = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, UserIDColumn, NameColumn, DescriptionColumn, ColorHexColumn, KindColumn, WorkspaceIDColumn, DeletedAtColumn, ChangeIDColumn, PublicIDColumn}
+		defaultColumns    = postgres.ColumnList{IDColumn, CreatedAtColumn, UpdatedAtColumn, KindColumn, ChangeIDColumn, PublicIDColumn}
 	)
 
 	return categoryTable{
@@ -88,6 +98,11 @@ func newCategoryTableImpl(schemaName, tableName, alias string) categoryTable {
 		Name:        NameColumn,
 		Description: DescriptionColumn,
 		ColorHex:    ColorHexColumn,
+		Kind:        KindColumn,
+		WorkspaceID: WorkspaceIDColumn,
+		DeletedAt:   DeletedAtColumn,
+		ChangeID:    ChangeIDColumn,
+		PublicID:    PublicIDColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -17,15 +17,25 @@ type expenseTable struct {
 	postgres.Table
 
 	// Columns
-	ID           postgres.ColumnInteger
-	CreatedAt    postgres.ColumnTimestamp
-	UpdatedAt    postgres.ColumnTimestamp
-	UserID       postgres.ColumnString
-	Amount       postgres.ColumnFloat
-	PurchaseDate postgres.ColumnTimestamp
-	BillDate     postgres.ColumnTimestamp
-	Description  postgres.ColumnString
-	CategoryID   postgres.ColumnInteger
+	ID                postgres.ColumnInteger
+	CreatedAt         postgres.ColumnTimestamp
+	UpdatedAt         postgres.ColumnTimestamp
+	UserID            postgres.ColumnString
+	Amount            postgres.ColumnFloat
+	PurchaseDate      postgres.ColumnTimestamp
+	BillDate          postgres.ColumnTimestamp
+	Description       postgres.ColumnString
+	CategoryID        postgres.ColumnInteger
+	Kind              postgres.ColumnString
+	RefundOfID        postgres.ColumnInteger
+	AccountID         postgres.ColumnInteger
+	TransferAccountID postgres.ColumnInteger
+	WorkspaceID       postgres.ColumnInteger
+	PaidByContactID   postgres.ColumnInteger
+	MerchantID        postgres.ColumnInteger
+	DeletedAt         postgres.ColumnTimestamp
+	ChangeID          postgres.ColumnInteger
+	PublicID          postgres.ColumnString
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -67,33 +77,53 @@ func newExpenseTable(schemaName, tableName, alias string) *ExpenseTable {
 
 func newExpenseTableImpl(schemaName, tableName, alias string) expenseTable {
 	var (
-		IDColumn           = postgres.IntegerColumn("id")
-		CreatedAtColumn    = postgres.TimestampColumn("created_at")
-		UpdatedAtColumn    = postgres.TimestampColumn("updated_at")
-		UserIDColumn       = postgres.StringColumn("user_id")
-		AmountColumn       = postgres.FloatColumn("amount")
-		PurchaseDateColumn = postgres.TimestampColumn("purchase_date")
-		BillDateColumn     = postgres.TimestampColumn("bill_date")
-		DescriptionColumn  = postgres.StringColumn("description")
-		CategoryIDColumn   = postgres.IntegerColumn("category_id")
-		allColumns         = postgres.ColumnList{IDColumn, CreatedAtColumn, UpdatedAtColumn, UserIDColumn, AmountColumn, PurchaseDateColumn, BillDateColumn, DescriptionColumn, CategoryIDColumn}
-		mutableColumns     = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, UserIDColumn, AmountColumn, PurchaseDateColumn, BillDateColumn, DescriptionColumn, CategoryIDColumn}
-		defaultColumns     = postgres.ColumnList{IDColumn, CreatedAtColumn, UpdatedAtColumn}
+		IDColumn                = postgres.IntegerColumn("id")
+		CreatedAtColumn         = postgres.TimestampColumn("created_at")
+		UpdatedAtColumn         = postgres.TimestampColumn("updated_at")
+		UserIDColumn            = postgres.StringColumn("user_id")
+		AmountColumn            = postgres.FloatColumn("amount")
+		PurchaseDateColumn      = postgres.TimestampColumn("purchase_date")
+		BillDateColumn          = postgres.TimestampColumn("bill_date")
+		DescriptionColumn       = postgres.StringColumn("description")
+		CategoryIDColumn        = postgres.IntegerColumn("category_id")
+		KindColumn              = postgres.StringColumn("kind")
+		RefundOfIDColumn        = postgres.IntegerColumn("refund_of_id")
+		AccountIDColumn         = postgres.IntegerColumn("account_id")
+		TransferAccountIDColumn = postgres.IntegerColumn("transfer_account_id")
+		WorkspaceIDColumn       = postgres.IntegerColumn("workspace_id")
+		PaidByContactIDColumn   = postgres.IntegerColumn("paid_by_contact_id")
+		MerchantIDColumn        = postgres.IntegerColumn("merchant_id")
+		DeletedAtColumn         = postgres.TimestampColumn("deleted_at")
+		ChangeIDColumn          = postgres.IntegerColumn("change_id")
+		PublicIDColumn          = postgres.StringColumn("public_id")
+		allColumns              = postgres.ColumnList{IDColumn, CreatedAtColumn, UpdatedAtColumn, UserIDColumn, AmountColumn, PurchaseDateColumn, BillDateColumn, DescriptionColumn, CategoryIDColumn, KindColumn, RefundOfIDColumn, AccountIDColumn, TransferAccountIDColumn, WorkspaceIDColumn, PaidByContactIDColumn, MerchantIDColumn, DeletedAtColumn, ChangeIDColumn, PublicIDColumn}
+		mutableColumns          = postgres.ColumnList{CreatedAtColumn, UpdatedAtColumn, UserIDColumn, AmountColumn, PurchaseDateColumn, BillDateColumn, DescriptionColumn, CategoryIDColumn, KindColumn, RefundOfIDColumn, AccountIDColumn, TransferAccountIDColumn, WorkspaceIDColumn, PaidByContactIDColumn, MerchantIDColumn, DeletedAtColumn, ChangeIDColumn, PublicIDColumn}
+		defaultColumns          = postgres.ColumnList{IDColumn, CreatedAtColumn, UpdatedAtColumn, KindColumn, ChangeIDColumn, PublicIDColumn}
 	)
 
 	return expenseTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		ID:           IDColumn,
-		CreatedAt:    CreatedAtColumn,
-		UpdatedAt:    UpdatedAtColumn,
-		UserID:       UserIDColumn,
-		Amount:       AmountColumn,
-		PurchaseDate: PurchaseDateColumn,
-		BillDate:     BillDateColumn,
-		Description:  DescriptionColumn,
-		CategoryID:   CategoryIDColumn,
+		ID:                IDColumn,
+		CreatedAt:         CreatedAtColumn,
+		UpdatedAt:         UpdatedAtColumn,
+		UserID:            UserIDColumn,
+		Amount:            AmountColumn,
+		PurchaseDate:      PurchaseDateColumn,
+		BillDate:          BillDateColumn,
+		Description:       DescriptionColumn,
+		CategoryID:        CategoryIDColumn,
+		Kind:              KindColumn,
+		RefundOfID:        RefundOfIDColumn,
+		AccountID:         AccountIDColumn,
+		TransferAccountID: TransferAccountIDColumn,
+		WorkspaceID:       WorkspaceIDColumn,
+		PaidByContactID:   PaidByContactIDColumn,
+		MerchantID:        MerchantIDColumn,
+		DeletedAt:         DeletedAtColumn,
+		ChangeID:          ChangeIDColumn,
+		PublicID:          PublicIDColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -10,8 +10,30 @@ package table
 // UseSchema sets a new schema name for all generated table SQL builder types. It is recommended to invoke
 // this method only once at the beginning of the program.
 func UseSchema(schema string) {
+	Account = Account.FromSchema(schema)
+	AccountDeletion = AccountDeletion.FromSchema(schema)
+	Anomaly = Anomaly.FromSchema(schema)
+	Attachment = Attachment.FromSchema(schema)
+	AuditLog = AuditLog.FromSchema(schema)
 	Category = Category.FromSchema(schema)
+	Contact = Contact.FromSchema(schema)
 	Expense = Expense.FromSchema(schema)
+	ExpenseRevision = ExpenseRevision.FromSchema(schema)
+	ExpenseShare = ExpenseShare.FromSchema(schema)
+	ExpenseSplit = ExpenseSplit.FromSchema(schema)
+	ExpenseTag = ExpenseTag.FromSchema(schema)
+	IdempotencyKey = IdempotencyKey.FromSchema(schema)
+	Insight = Insight.FromSchema(schema)
+	Merchant = Merchant.FromSchema(schema)
 	SchemaMigrations = SchemaMigrations.FromSchema(schema)
+	Settlement = Settlement.FromSchema(schema)
+	SyncTombstone = SyncTombstone.FromSchema(schema)
+	Tag = Tag.FromSchema(schema)
 	User = User.FromSchema(schema)
+	WebhookAttempt = WebhookAttempt.FromSchema(schema)
+	WebhookDelivery = WebhookDelivery.FromSchema(schema)
+	WebhookEndpoint = WebhookEndpoint.FromSchema(schema)
+	Workspace = Workspace.FromSchema(schema)
+	WorkspaceInvitation = WorkspaceInvitation.FromSchema(schema)
+	WorkspaceMember = WorkspaceMember.FromSchema(schema)
 }
@@ -17,14 +17,16 @@ type userTable struct {
 	postgres.Table
 
 	// Columns
-	ID        postgres.ColumnString
-	CreatedAt postgres.ColumnTimestamp
-	ClerkID   postgres.ColumnString
-	Email     postgres.ColumnString
-	FirstName postgres.ColumnString
-	LastName  postgres.ColumnString
-	ImageURL  postgres.ColumnString
-	UpdatedAt postgres.ColumnTimestamp
+	ID                  postgres.ColumnString
+	CreatedAt           postgres.ColumnTimestamp
+	ClerkID             postgres.ColumnString
+	Email               postgres.ColumnString
+	FirstName           postgres.ColumnString
+	LastName            postgres.ColumnString
+	ImageURL            postgres.ColumnString
+	UpdatedAt           postgres.ColumnTimestamp
+	Locale              postgres.ColumnString
+	DeletionScheduledAt postgres.ColumnTimestamp
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -66,31 +68,35 @@ func newUserTable(schemaName, tableName, alias string) *UserTable {
 
 func newUserTableImpl(schemaName, tableName, alias string) userTable {
 	var (
-		IDColumn        = postgres.StringColumn("id")
-		CreatedAtColumn = postgres.TimestampColumn("created_at")
-		ClerkIDColumn   = postgres.StringColumn("clerk_id")
-		EmailColumn     = postgres.StringColumn("email")
-		FirstNameColumn = postgres.StringColumn("first_name")
-		LastNameColumn  = postgres.StringColumn("last_name")
-		ImageURLColumn  = postgres.StringColumn("image_url")
-		UpdatedAtColumn = postgres.TimestampColumn("updated_at")
-		allColumns      = postgres.ColumnList{IDColumn, CreatedAtColumn, ClerkIDColumn, EmailColumn, FirstNameColumn, LastNameColumn, ImageURLColumn, UpdatedAtColumn}
-		mutableColumns  = postgres.ColumnList{CreatedAtColumn, ClerkIDColumn, EmailColumn, FirstNameColumn, LastNameColumn, ImageURLColumn, UpdatedAtColumn}
-		defaultColumns  = postgres.ColumnList{IDColumn, CreatedAtColumn, UpdatedAtColumn}
+		IDColumn                  = postgres.StringColumn("id")
+		CreatedAtColumn           = postgres.TimestampColumn("created_at")
+		ClerkIDColumn             = postgres.StringColumn("clerk_id")
+		EmailColumn               = postgres.StringColumn("email")
+		FirstNameColumn           = postgres.StringColumn("first_name")
+		LastNameColumn            = postgres.StringColumn("last_name")
+		ImageURLColumn            = postgres.StringColumn("image_url")
+		UpdatedAtColumn           = postgres.TimestampColumn("updated_at")
+		LocaleColumn              = postgres.StringColumn("locale")
+		DeletionScheduledAtColumn = postgres.TimestampColumn("deletion_scheduled_at")
+		allColumns                = postgres.ColumnList{IDColumn, CreatedAtColumn, ClerkIDColumn, EmailColumn, FirstNameColumn, LastNameColumn, ImageURLColumn, UpdatedAtColumn, LocaleColumn, DeletionScheduledAtColumn}
+		mutableColumns            = postgres.ColumnList{CreatedAtColumn, ClerkIDColumn, EmailColumn, FirstNameColumn, LastNameColumn, ImageURLColumn, UpdatedAtColumn, LocaleColumn, DeletionScheduledAtColumn}
+		defaultColumns            = postgres.ColumnList{IDColumn, CreatedAtColumn, UpdatedAtColumn, LocaleColumn}
 	)
 
 	return userTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		ID:        IDColumn,
-		CreatedAt: CreatedAtColumn,
-		ClerkID:   ClerkIDColumn,
-		Email:     EmailColumn,
-		FirstName: FirstNameColumn,
-		LastName:  LastNameColumn,
-		ImageURL:  ImageURLColumn,
-		UpdatedAt: UpdatedAtColumn,
+		ID:                  IDColumn,
+		CreatedAt:           CreatedAtColumn,
+		ClerkID:             ClerkIDColumn,
+		Email:               EmailColumn,
+		FirstName:           FirstNameColumn,
+		LastName:            LastNameColumn,
+		ImageURL:            ImageURLColumn,
+		UpdatedAt:           UpdatedAtColumn,
+		Locale:              LocaleColumn,
+		DeletionScheduledAt: DeletionScheduledAtColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -0,0 +1,211 @@
+// Package anomaly flags expenses that are unusual for a user given their history.
+// It is pure computation: callers load the history and persist the findings.
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/igorschechtel/clearflow-backend/internal/merchant"
+)
+
+type Kind string
+
+const (
+	KindLargeAmount    Kind = "large_amount"
+	KindNewMerchant    Kind = "new_merchant"
+	KindFrequencySpike Kind = "frequency_spike"
+)
+
+const (
+	// Minimum number of past observations before any rule is evaluated.
+	minSamples = 5
+	// Modified z-score above which an amount is considered an outlier (Iglewicz and Hoaglin).
+	robustZThreshold = 3.5
+	// Percentile of the user's amounts a first-time merchant must exceed.
+	newMerchantPercentile = 0.9
+	// Frequency is compared over weekly windows against this many previous weeks.
+	frequencyWeeks = 12
+	// Minimum number of expenses in the current week to consider a frequency spike.
+	minSpikeCount = 3
+)
+
+// Observation is the subset of an expense the detector looks at.
+type Observation struct {
+	Amount       float64
+	Description  string
+	CategoryID   *int32
+	PurchaseDate time.Time
+}
+
+type Finding struct {
+	Kind   Kind
+	Score  float64
+	Reason string
+}
+
+// Detect evaluates target against the user's history, which must not include target itself.
+func Detect(target Observation, history []Observation) []Finding {
+	if len(history) < minSamples {
+		return nil
+	}
+
+	findings := []Finding{}
+	if f, ok := detectLargeAmount(target, history); ok {
+		findings = append(findings, f)
+	}
+	if f, ok := detectNewMerchant(target, history); ok {
+		findings = append(findings, f)
+	}
+	if f, ok := detectFrequencySpike(target, history); ok {
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+func detectLargeAmount(target Observation, history []Observation) (Finding, bool) {
+	amounts := []float64{}
+	for _, h := range history {
+		if sameCategory(h.CategoryID, target.CategoryID) {
+			amounts = append(amounts, h.Amount)
+		}
+	}
+	if len(amounts) < minSamples {
+		return Finding{}, false
+	}
+
+	z, ok := RobustZScore(target.Amount, amounts)
+	if !ok || z <= robustZThreshold {
+		return Finding{}, false
+	}
+	return Finding{
+		Kind:   KindLargeAmount,
+		Score:  z,
+		Reason: fmt.Sprintf("%.2f is much larger than the typical %.2f for this category", target.Amount, Median(amounts)),
+	}, true
+}
+
+func detectNewMerchant(target Observation, history []Observation) (Finding, bool) {
+	key := MerchantKey(target.Description)
+	amounts := make([]float64, 0, len(history))
+	for _, h := range history {
+		if MerchantKey(h.Description) == key {
+			return Finding{}, false
+		}
+		amounts = append(amounts, h.Amount)
+	}
+
+	threshold := Percentile(amounts, newMerchantPercentile)
+	if threshold <= 0 || target.Amount <= threshold {
+		return Finding{}, false
+	}
+	return Finding{
+		Kind:   KindNewMerchant,
+		Score:  target.Amount / threshold,
+		Reason: fmt.Sprintf("first purchase at this merchant and higher than %.0f%% of your expenses", newMerchantPercentile*100),
+	}, true
+}
+
+func detectFrequencySpike(target Observation, history []Observation) (Finding, bool) {
+	const week = 7 * 24 * time.Hour
+	end := target.PurchaseDate.Add(24 * time.Hour)
+	counts := make([]float64, frequencyWeeks+1)
+
+	for _, h := range history {
+		if !sameCategory(h.CategoryID, target.CategoryID) || !h.PurchaseDate.Before(end) {
+			continue
+		}
+		idx := int(end.Sub(h.PurchaseDate) / week)
+		if idx < len(counts) {
+			counts[idx]++
+		}
+	}
+	counts[0]++ // the target itself
+
+	recent, baseline := counts[0], counts[1:]
+	mean, stddev := meanStdDev(baseline)
+	if recent < minSpikeCount || recent <= mean+3*stddev || recent < 2*mean {
+		return Finding{}, false
+	}
+
+	score := recent
+	if mean > 0 {
+		score = recent / mean
+	}
+	return Finding{
+		Kind:   KindFrequencySpike,
+		Score:  score,
+		Reason: fmt.Sprintf("%.0f expenses in this category over the last week, usually %.1f", recent, mean),
+	}, true
+}
+
+// RobustZScore returns the modified z-score of x relative to values, based on
+// the median absolute deviation. It falls back to the mean absolute deviation
+// when more than half of the values are identical. ok is false when values have
+// no dispersion at all.
+func RobustZScore(x float64, values []float64) (float64, bool) {
+	median := Median(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+
+	if mad := Median(deviations); mad > 0 {
+		return 0.6745 * (x - median) / mad, true
+	}
+
+	var sum float64
+	for _, d := range deviations {
+		sum += d
+	}
+	if meanAD := sum / float64(len(deviations)); meanAD > 0 {
+		return (x - median) / (1.253314 * meanAD), true
+	}
+	return 0, false
+}
+
+func Median(values []float64) float64 {
+	return Percentile(values, 0.5)
+}
+
+// Percentile returns the p-th percentile (0..1) of values using linear interpolation.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := p * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// MerchantKey reduces a description to a comparable merchant key.
+func MerchantKey(description string) string {
+	return merchant.Key(description)
+}
+
+func meanStdDev(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(values)))
+}
+
+func sameCategory(a, b *int32) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,121 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func day(offset int) time.Time {
+	return time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, offset)
+}
+
+func category(id int32) *int32 {
+	return &id
+}
+
+// groceries returns one grocery expense per week for the previous n weeks.
+func groceries(n int) []Observation {
+	history := []Observation{}
+	amounts := []float64{80, 95, 110, 90, 100, 85, 105, 92, 98, 102}
+	for i := 0; i < n; i++ {
+		history = append(history, Observation{
+			Amount:       amounts[i%len(amounts)],
+			Description:  "Supermarket",
+			CategoryID:   category(1),
+			PurchaseDate: day(-7 * (i + 1)),
+		})
+	}
+	return history
+}
+
+func kinds(findings []Finding) []Kind {
+	result := []Kind{}
+	for _, f := range findings {
+		result = append(result, f.Kind)
+	}
+	return result
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  Observation
+		history []Observation
+		want    []Kind
+	}{
+		{
+			name:    "not enough history",
+			target:  Observation{Amount: 5000, Description: "Supermarket", CategoryID: category(1), PurchaseDate: day(0)},
+			history: groceries(3),
+			want:    []Kind{},
+		},
+		{
+			name:    "typical expense",
+			target:  Observation{Amount: 97, Description: "Supermarket", CategoryID: category(1), PurchaseDate: day(0)},
+			history: groceries(10),
+			want:    []Kind{},
+		},
+		{
+			name:    "large amount for category",
+			target:  Observation{Amount: 600, Description: "Supermarket", CategoryID: category(1), PurchaseDate: day(0)},
+			history: groceries(10),
+			want:    []Kind{KindLargeAmount},
+		},
+		{
+			name:    "first time merchant with high amount",
+			target:  Observation{Amount: 400, Description: "Electronics Store", CategoryID: category(2), PurchaseDate: day(0)},
+			history: groceries(10),
+			want:    []Kind{KindNewMerchant},
+		},
+		{
+			name:    "first time merchant with low amount",
+			target:  Observation{Amount: 20, Description: "Bakery", CategoryID: category(1), PurchaseDate: day(0)},
+			history: groceries(10),
+			want:    []Kind{},
+		},
+		{
+			name:   "frequency spike",
+			target: Observation{Amount: 95, Description: "Supermarket", CategoryID: category(1), PurchaseDate: day(0)},
+			history: append(groceries(10),
+				Observation{Amount: 90, Description: "Supermarket", CategoryID: category(1), PurchaseDate: day(-1)},
+				Observation{Amount: 100, Description: "Supermarket", CategoryID: category(1), PurchaseDate: day(-2)},
+				Observation{Amount: 85, Description: "Supermarket", CategoryID: category(1), PurchaseDate: day(-3)},
+			),
+			want: []Kind{KindFrequencySpike},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, kinds(Detect(tt.target, tt.history)))
+		})
+	}
+}
+
+func TestRobustZScore(t *testing.T) {
+	z, ok := RobustZScore(10, []float64{1, 2, 3, 4, 5})
+	assert.True(t, ok)
+	assert.InDelta(t, 4.7215, z, 0.001)
+
+	// More than half identical values: MAD is zero, mean absolute deviation is used.
+	z, ok = RobustZScore(10, []float64{5, 5, 5, 5, 7})
+	assert.True(t, ok)
+	assert.Greater(t, z, robustZThreshold)
+
+	_, ok = RobustZScore(10, []float64{5, 5, 5})
+	assert.False(t, ok)
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{40, 10, 30, 20}
+	assert.Equal(t, 25.0, Median(values))
+	assert.Equal(t, 10.0, Percentile(values, 0))
+	assert.Equal(t, 40.0, Percentile(values, 1))
+	assert.Equal(t, 0.0, Percentile(nil, 0.5))
+}
+
+func TestMerchantKey(t *testing.T) {
+	assert.Equal(t, "uber trip", MerchantKey("  UBER   Trip "))
+}
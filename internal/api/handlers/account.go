@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type AccountHandler struct {
+	accountService services.AccountService
+	validate       *validator.Validate
+}
+
+func NewAccountHandler(accountService services.AccountService, validate *validator.Validate) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+		validate:       validate,
+	}
+}
+
+func (h *AccountHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type ListAccountsRequest struct {
+		Limit  int `json:"limit" validate:"min=1,max=100"`
+		Offset int `json:"offset" validate:"min=0"`
+	}
+	queryParams := ListAccountsRequest{
+		Limit:  100,
+		Offset: 0,
+	}
+
+	if err := u.ParseQueryParamInt(r, &queryParams.Limit, "limit", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := u.ParseQueryParamInt(r, &queryParams.Offset, "offset", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(queryParams); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Fetching
+	accounts, err := h.accountService.ListByUser(r.Context(), clerkID, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSONList(w, r, accounts)
+}
+
+func (h *AccountHandler) Create(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type CreateAccountRequest struct {
+		Name string `json:"name" validate:"required,min=1,max=255"`
+		Type string `json:"type" validate:"required,oneof=checking savings credit_card cash"`
+	}
+
+	reqBody := CreateAccountRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Creating
+	modelAccount := &model.Account{
+		Name: reqBody.Name,
+		Type: reqBody.Type,
+	}
+
+	createdAccount, err := h.accountService.Create(r.Context(), clerkID, modelAccount)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, createdAccount)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type AccountDeletionHandler struct {
+	accountDeletionService services.AccountDeletionService
+}
+
+func NewAccountDeletionHandler(accountDeletionService services.AccountDeletionService) *AccountDeletionHandler {
+	return &AccountDeletionHandler{
+		accountDeletionService: accountDeletionService,
+	}
+}
+
+// Get returns the scheduled deletion of the user's account, 404 when none is.
+func (h *AccountDeletionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	// Fetching
+	deletion, err := h.accountDeletionService.Get(r.Context(), clerkID)
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, deletion)
+}
+
+// Request schedules the deletion of the user's account. The data is purged
+// once the grace period is over, unless the deletion is cancelled.
+func (h *AccountDeletionHandler) Request(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	// Scheduling
+	deletion, err := h.accountDeletionService.Request(r.Context(), clerkID)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSON(w, http.StatusAccepted, deletion)
+}
+
+func (h *AccountDeletionHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	// Cancelling
+	deletion, err := h.accountDeletionService.Cancel(r.Context(), clerkID)
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, deletion)
+}
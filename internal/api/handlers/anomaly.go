@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type AnomalyHandler struct {
+	anomalyService  services.AnomalyService
+	publicIDService services.PublicIDService
+	validate        *validator.Validate
+}
+
+func NewAnomalyHandler(anomalyService services.AnomalyService, publicIDService services.PublicIDService, validate *validator.Validate) *AnomalyHandler {
+	return &AnomalyHandler{
+		anomalyService:  anomalyService,
+		publicIDService: publicIDService,
+		validate:        validate,
+	}
+}
+
+func (h *AnomalyHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type ListAnomaliesRequest struct {
+		Limit            int  `json:"limit" validate:"min=1,max=100"`
+		Offset           int  `json:"offset" validate:"min=0"`
+		IncludeDismissed bool `json:"includeDismissed"`
+	}
+	queryParams := ListAnomaliesRequest{
+		Limit:  100,
+		Offset: 0,
+	}
+
+	if err := u.ParseQueryParamInt(r, &queryParams.Limit, "limit", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := u.ParseQueryParamInt(r, &queryParams.Offset, "offset", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	queryParams.IncludeDismissed = r.URL.Query().Get("includeDismissed") == "true"
+
+	// Validation
+	if err := h.validate.Struct(queryParams); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Fetching
+	anomalies, err := h.anomalyService.ListByUser(r.Context(), clerkID, queryParams.IncludeDismissed, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	refs := services.SerialRefs{}
+	views := make([]anomalyView, len(anomalies))
+	for i := range anomalies {
+		views[i].set(anomalies[i], &refs)
+	}
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	u.WriteJSONList(w, r, views)
+}
+
+func (h *AnomalyHandler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Dismissing
+	anomaly, err := h.anomalyService.Dismiss(r.Context(), clerkID, id)
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	refs := services.SerialRefs{}
+	view := anomalyView{}
+	view.set(*anomaly, &refs)
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, view)
+}
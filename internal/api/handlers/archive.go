@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/igorschechtel/clearflow-backend/internal/archive"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Largest archive accepted for a restore
+const maxArchiveBytes = 512 << 20
+
+var errArchiveTooLarge = fmt.Errorf("Archive exceeds the maximum size of %d MB", maxArchiveBytes>>20)
+
+type ArchiveHandler struct {
+	archiveService services.ArchiveService
+}
+
+func NewArchiveHandler(archiveService services.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{
+		archiveService: archiveService,
+	}
+}
+
+// Export downloads a zip archive of everything the user owns.
+func (h *ArchiveHandler) Export(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	// Exporting
+	fileName := fmt.Sprintf("clearflow-%s.zip", time.Now().UTC().Format("2006-01-02"))
+	out := &exportWriter{w: w, contentType: "application/zip", fileName: fileName}
+	if err := h.archiveService.Export(r.Context(), clerkID, out); err != nil {
+		if out.started {
+			// The status is already sent, the client sees a truncated file
+			logrus.WithError(err).Error("failed to export archive")
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+// Restore imports an archive sent as the raw request body into an account
+// without any data yet.
+func (h *ArchiveHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	// Zip files are read from their end, so the body is spooled to disk first
+	r.Body = http.MaxBytesReader(w, r.Body, maxArchiveBytes)
+	file, err := os.CreateTemp("", "archive-*.zip")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	size, err := io.Copy(file, r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			u.WriteJSONError(w, http.StatusRequestEntityTooLarge, errArchiveTooLarge)
+			return
+		}
+		u.WriteJSONError(w, http.StatusBadRequest, errors.New("Failed to read request body"))
+		return
+	}
+
+	// Restoring
+	if err := h.archiveService.Restore(r.Context(), clerkID, file, size); err != nil {
+		switch {
+		case errors.Is(err, archive.ErrInvalidArchive), errors.Is(err, archive.ErrUnsupportedVersion):
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+		case err == u.ErrAccountNotEmpty:
+			u.WriteJSONError(w, http.StatusConflict, err)
+		default:
+			u.WriteJSONError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
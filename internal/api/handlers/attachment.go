@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Room left in upload bodies for the multipart boundaries and headers
+const multipartOverhead = 64 << 10
+
+type AttachmentHandler struct {
+	attachmentService services.AttachmentService
+	publicIDService   services.PublicIDService
+	maxBytes          int64
+}
+
+func NewAttachmentHandler(attachmentService services.AttachmentService, publicIDService services.PublicIDService, maxBytes int64) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		publicIDService:   publicIDService,
+		maxBytes:          maxBytes,
+	}
+}
+
+func (h *AttachmentHandler) ListByExpense(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	expenseID, ok := resolveExpensePath(w, r, h.publicIDService)
+	if !ok {
+		return
+	}
+
+	// Fetching
+	attachments, err := h.attachmentService.ListByExpense(r.Context(), clerkID, expenseID)
+	if err != nil {
+		writeAttachmentError(w, err)
+		return
+	}
+
+	refs := services.SerialRefs{}
+	views := make([]attachmentView, len(attachments))
+	for i := range attachments {
+		views[i].set(attachments[i], &refs)
+	}
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, views)
+}
+
+// Create uploads a receipt as the "file" field of a multipart form.
+func (h *AttachmentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	expenseID, ok := resolveExpensePath(w, r, h.publicIDService)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBytes+multipartOverhead)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, fmt.Errorf("expected a multipart/form-data body"))
+		return
+	}
+
+	// Validation
+	var part io.Reader
+	var fileName string
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeUploadError(w, err)
+			return
+		}
+		if p.FormName() == "file" {
+			part, fileName = p, p.FileName()
+			break
+		}
+	}
+	if part == nil {
+		u.WriteJSONError(w, http.StatusBadRequest, fmt.Errorf("form field file is required"))
+		return
+	}
+
+	// Creating
+	attachment, err := h.attachmentService.Create(r.Context(), clerkID, expenseID, fileName, part)
+	if err != nil {
+		writeUploadError(w, err)
+		return
+	}
+
+	refs := services.SerialRefs{}
+	view := attachmentView{}
+	view.set(*attachment, &refs)
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, view)
+}
+
+func (h *AttachmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Deleting
+	if err := h.attachmentService.Delete(r.Context(), clerkID, id); err != nil {
+		writeAttachmentError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Content serves the file of an attachment. It is authorized by the signed,
+// expiring link handed out with the attachment instead of a session.
+func (h *AttachmentHandler) Content(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Fetching
+	attachment, content, err := h.attachmentService.Open(r.Context(), id, r.URL.Path, r.URL.Query())
+	if err != nil {
+		writeAttachmentError(w, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, content); err != nil {
+		logrus.WithError(err).WithField("attachment_id", id).Warn("failed to stream attachment")
+	}
+}
+
+func writeUploadError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		err = u.ErrAttachmentTooLarge
+	}
+	writeAttachmentError(w, err)
+}
+
+func writeAttachmentError(w http.ResponseWriter, err error) {
+	switch err {
+	case u.ErrNotFound:
+		u.WriteJSONError(w, http.StatusNotFound, err)
+	case u.ErrForbidden, u.ErrInvalidDownloadLink:
+		u.WriteJSONError(w, http.StatusForbidden, err)
+	case u.ErrPreconditionFailed:
+		u.WriteJSONError(w, http.StatusPreconditionFailed, err)
+	case u.ErrAttachmentTooLarge:
+		u.WriteJSONError(w, http.StatusRequestEntityTooLarge, err)
+	case u.ErrUnsupportedAttachment:
+		u.WriteJSONError(w, http.StatusUnsupportedMediaType, err)
+	default:
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type AuditHandler struct {
+	auditService services.AuditService
+	validate     *validator.Validate
+}
+
+func NewAuditHandler(auditService services.AuditService, validate *validator.Validate) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		validate:     validate,
+	}
+}
+
+// ListByUser returns the audit trail of the user's own account, latest first.
+func (h *AuditHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type ListAuditRequest struct {
+		Limit  int `json:"limit" validate:"min=1,max=100"`
+		Offset int `json:"offset" validate:"min=0"`
+	}
+	queryParams := ListAuditRequest{
+		Limit:  100,
+		Offset: 0,
+	}
+
+	if err := u.ParseQueryParamInt(r, &queryParams.Limit, "limit", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := u.ParseQueryParamInt(r, &queryParams.Offset, "offset", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(queryParams); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Fetching
+	entries, err := h.auditService.ListByUser(r.Context(), clerkID, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSONList(w, r, entries)
+}
+
+// List returns the audit trail across users, latest first. It is only routed
+// for administrators.
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	type ListAllAuditRequest struct {
+		Limit   int    `json:"limit" validate:"min=1,max=100"`
+		Offset  int    `json:"offset" validate:"min=0"`
+		UserID  string `json:"userId" validate:"omitempty,uuid"`
+		ActorID string `json:"actorId"`
+		Action  string `json:"action"`
+		From    string `json:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+		To      string `json:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	}
+	queryParams := ListAllAuditRequest{
+		Limit:  100,
+		Offset: 0,
+	}
+
+	if err := u.ParseQueryParamInt(r, &queryParams.Limit, "limit", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := u.ParseQueryParamInt(r, &queryParams.Offset, "offset", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	query := r.URL.Query()
+	queryParams.UserID = query.Get("userId")
+	queryParams.ActorID = query.Get("actorId")
+	queryParams.Action = query.Get("action")
+	queryParams.From = query.Get("from")
+	queryParams.To = query.Get("to")
+
+	// Validation
+	if err := h.validate.Struct(queryParams); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	filter := services.AuditFilter{
+		ActorID: queryParams.ActorID,
+		Action:  queryParams.Action,
+	}
+	if queryParams.UserID != "" {
+		userID, err := u.ParseUUID(queryParams.UserID, "userId")
+		if err != nil {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		filter.UserID = &userID
+	}
+	if queryParams.From != "" {
+		from, _ := time.Parse(time.RFC3339, queryParams.From)
+		from = from.UTC()
+		filter.From = &from
+	}
+	if queryParams.To != "" {
+		to, _ := time.Parse(time.RFC3339, queryParams.To)
+		to = to.UTC()
+		filter.To = &to
+	}
+
+	// Fetching
+	entries, err := h.auditService.List(r.Context(), filter, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSONList(w, r, entries)
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
 	"github.com/igorschechtel/clearflow-backend/internal/auth"
 	"github.com/igorschechtel/clearflow-backend/internal/services"
@@ -12,12 +13,14 @@ import (
 
 type CategoryHandler struct {
 	categoryService services.CategoryService
+	publicIDService services.PublicIDService
 	validate        *validator.Validate
 }
 
-func NewCategoryHandler(categoryService services.CategoryService, validate *validator.Validate) *CategoryHandler {
+func NewCategoryHandler(categoryService services.CategoryService, publicIDService services.PublicIDService, validate *validator.Validate) *CategoryHandler {
 	return &CategoryHandler{
 		categoryService: categoryService,
+		publicIDService: publicIDService,
 		validate:        validate,
 	}
 }
@@ -33,8 +36,9 @@ func (h *CategoryHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	type ListCategoriesRequest struct {
-		Limit  int `json:"limit" validate:"min=1,max=100"`
-		Offset int `json:"offset" validate:"min=0"`
+		Limit  int    `json:"limit" validate:"min=1,max=100"`
+		Offset int    `json:"offset" validate:"min=0"`
+		Kind   string `json:"kind" validate:"omitempty,oneof=expense income"`
 	}
 	queryParams := ListCategoriesRequest{
 		Limit:  100,
@@ -49,6 +53,12 @@ func (h *CategoryHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
 		u.WriteJSONError(w, http.StatusBadRequest, err)
 		return
 	}
+	queryParams.Kind = r.URL.Query().Get("kind")
+	workspaceID, err := parseWorkspaceID(r)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
 
 	// Validation
 	if err := h.validate.Struct(queryParams); err != nil {
@@ -57,13 +67,50 @@ func (h *CategoryHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Fetching
-	categories, err := h.categoryService.ListByUser(r.Context(), clerkID, queryParams.Limit, queryParams.Offset)
+	categories, err := h.categoryService.ListByUser(r.Context(), clerkID, workspaceID, queryParams.Kind, queryParams.Limit, queryParams.Offset)
 	if err != nil {
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
 		u.WriteJSONError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	u.WriteJSON(w, http.StatusOK, categories)
+	u.WriteJSONList(w, r, newCategoryViews(categories))
+}
+
+func (h *CategoryHandler) Get(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, ok := resolveCategoryPath(w, r, h.publicIDService)
+	if !ok {
+		return
+	}
+
+	// Fetching
+	category, err := h.categoryService.Get(r.Context(), clerkID, id)
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSONWithETag(w, r, http.StatusOK, u.VersionETag(category.UpdatedAt), newCategoryView(*category))
 }
 
 func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -77,9 +124,12 @@ func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	type CreateCategoryRequest struct {
-		Name        string `json:"name" validate:"required,min=1,max=255"`
-		Description string `json:"description" validate:"max=255"`
-		ColorHex    string `json:"colorHex" validate:"required,min=7,max=7"`
+		ID          *uuid.UUID `json:"id"`
+		Name        string     `json:"name" validate:"required,min=1,max=255"`
+		Description string     `json:"description" validate:"max=255"`
+		ColorHex    string     `json:"colorHex" validate:"required,min=7,max=7"`
+		Kind        string     `json:"kind" validate:"omitempty,oneof=expense income"`
+		WorkspaceID *int32     `json:"workspaceId"`
 	}
 
 	reqBody := CreateCategoryRequest{}
@@ -99,13 +149,63 @@ func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Name:        reqBody.Name,
 		Description: reqBody.Description,
 		ColorHex:    reqBody.ColorHex,
+		Kind:        reqBody.Kind,
+		WorkspaceID: reqBody.WorkspaceID,
+	}
+	if reqBody.ID != nil {
+		modelCategory.PublicID = *reqBody.ID
 	}
 
 	createdCategory, err := h.categoryService.Create(r.Context(), clerkID, modelCategory)
 	if err != nil {
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		if err == u.ErrPublicIDTaken {
+			u.WriteJSONError(w, http.StatusConflict, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, newCategoryView(*createdCategory))
+}
+
+// Delete moves a category to the trash, from which it can be restored.
+func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, ok := resolveCategoryPath(w, r, h.publicIDService)
+	if !ok {
+		return
+	}
+
+	// Deleting
+	if err := h.categoryService.Delete(r.Context(), clerkID, id); err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		if err == u.ErrPreconditionFailed {
+			u.WriteJSONError(w, http.StatusPreconditionFailed, err)
+			return
+		}
 		u.WriteJSONError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	u.WriteJSON(w, http.StatusOK, createdCategory)
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -10,21 +10,24 @@ import (
 	svix "github.com/svix/svix-webhooks/go"
 
 	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/audit"
 	"github.com/igorschechtel/clearflow-backend/internal/services"
 	u "github.com/igorschechtel/clearflow-backend/internal/utils"
 )
 
 type ClerkWebhookHandler struct {
-	userService   services.UserService
-	webhookSecret string
-	logger        *logrus.Logger
+	userService            services.UserService
+	accountDeletionService services.AccountDeletionService
+	webhookSecret          string
+	logger                 *logrus.Logger
 }
 
-func NewClerkWebhookHandler(userService services.UserService, webhookSecret string, logger *logrus.Logger) *ClerkWebhookHandler {
+func NewClerkWebhookHandler(userService services.UserService, accountDeletionService services.AccountDeletionService, webhookSecret string, logger *logrus.Logger) *ClerkWebhookHandler {
 	return &ClerkWebhookHandler{
-		userService:   userService,
-		webhookSecret: webhookSecret,
-		logger:        logger,
+		userService:            userService,
+		accountDeletionService: accountDeletionService,
+		webhookSecret:          webhookSecret,
+		logger:                 logger,
 	}
 }
 
@@ -81,6 +84,7 @@ func (h *ClerkWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		u.WriteJSONError(w, http.StatusBadRequest, errors.New("Failed to parse webhook payload"))
 		return
 	}
+	ctx := audit.WithActor(r.Context(), audit.Webhook("clerk"))
 
 	switch event.Type {
 	case "user.created", "user.updated":
@@ -111,7 +115,7 @@ func (h *ClerkWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 			ImageURL:  event.Data.ImageURL,
 		}
 
-		_, _, err := h.userService.Upsert(r.Context(), &user)
+		_, _, err := h.userService.Upsert(ctx, &user)
 		if err != nil {
 			h.logger.WithError(err).WithField("clerk_id", event.Data.ID).Error("failed to upsert user from webhook")
 			u.WriteJSONError(w, http.StatusInternalServerError, errors.New("internal server error"))
@@ -119,9 +123,10 @@ func (h *ClerkWebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		}
 
 	case "user.deleted":
-		err := h.userService.DeleteByClerkID(r.Context(), event.Data.ID)
+		// The data is purged by a background job once the grace period is over
+		err := h.accountDeletionService.HandleClerkDeletion(ctx, event.Data.ID)
 		if err != nil {
-			h.logger.WithError(err).WithField("clerk_id", event.Data.ID).Error("failed to delete user from webhook")
+			h.logger.WithError(err).WithField("clerk_id", event.Data.ID).Error("failed to schedule user deletion from webhook")
 			u.WriteJSONError(w, http.StatusInternalServerError, errors.New("internal server error"))
 			return
 		}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+// contactRequest is the body for creating and updating contacts.
+type contactRequest struct {
+	Name  string  `json:"name" validate:"required,min=1,max=255"`
+	Email *string `json:"email" validate:"omitempty,email,max=255"`
+}
+
+type ContactHandler struct {
+	contactService services.ContactService
+	validate       *validator.Validate
+}
+
+func NewContactHandler(contactService services.ContactService, validate *validator.Validate) *ContactHandler {
+	return &ContactHandler{
+		contactService: contactService,
+		validate:       validate,
+	}
+}
+
+func (h *ContactHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type ListContactsRequest struct {
+		Limit  int `json:"limit" validate:"min=1,max=100"`
+		Offset int `json:"offset" validate:"min=0"`
+	}
+	queryParams := ListContactsRequest{
+		Limit:  100,
+		Offset: 0,
+	}
+
+	if err := u.ParseQueryParamInt(r, &queryParams.Limit, "limit", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := u.ParseQueryParamInt(r, &queryParams.Offset, "offset", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(queryParams); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Fetching
+	contacts, err := h.contactService.ListByUser(r.Context(), clerkID, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSONList(w, r, contacts)
+}
+
+func (h *ContactHandler) Get(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Fetching
+	contact, err := h.contactService.Get(r.Context(), clerkID, id)
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSONWithETag(w, r, http.StatusOK, u.VersionETag(contact.UpdatedAt), contact)
+}
+
+func (h *ContactHandler) Create(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	reqBody := contactRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Creating
+	contact, err := h.contactService.Create(r.Context(), clerkID, &model.Contact{
+		Name:  reqBody.Name,
+		Email: reqBody.Email,
+	})
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, contact)
+}
+
+func (h *ContactHandler) Update(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	reqBody := contactRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Updating
+	contact, err := h.contactService.Update(r.Context(), clerkID, &model.Contact{
+		ID:    id,
+		Name:  reqBody.Name,
+		Email: reqBody.Email,
+	})
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		if err == u.ErrPreconditionFailed {
+			u.WriteJSONError(w, http.StatusPreconditionFailed, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSONWithETag(w, r, http.StatusOK, u.VersionETag(contact.UpdatedAt), contact)
+}
@@ -1,25 +1,61 @@
 package handlers
 
 import (
+	"fmt"
+	"mime"
 	"net/http"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
 	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/export"
 	"github.com/igorschechtel/clearflow-backend/internal/services"
 	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/sirupsen/logrus"
 )
 
+// splitRequest is a split line of an expense or income.
+type splitRequest struct {
+	CategoryID uuid.UUID `json:"categoryId" validate:"required"`
+	Amount     float64   `json:"amount" validate:"required,gt=0"`
+	Note       *string   `json:"note" validate:"omitempty,max=255"`
+}
+
+// ExpenseHandler serves the transactions of a single kind, expenses or income.
 type ExpenseHandler struct {
-	expenseService services.ExpenseService
-	validate       *validator.Validate
+	expenseService  services.ExpenseService
+	publicIDService services.PublicIDService
+	kind            string
+	validate        *validator.Validate
+}
+
+func NewExpenseHandler(expenseService services.ExpenseService, publicIDService services.PublicIDService, validate *validator.Validate) *ExpenseHandler {
+	return &ExpenseHandler{
+		expenseService:  expenseService,
+		publicIDService: publicIDService,
+		kind:            services.KindExpense,
+		validate:        validate,
+	}
+}
+
+func NewIncomeHandler(expenseService services.ExpenseService, publicIDService services.PublicIDService, validate *validator.Validate) *ExpenseHandler {
+	return &ExpenseHandler{
+		expenseService:  expenseService,
+		publicIDService: publicIDService,
+		kind:            services.KindIncome,
+		validate:        validate,
+	}
 }
 
-func NewExpenseHandler(expenseService services.ExpenseService, validate *validator.Validate) *ExpenseHandler {
+func NewTransferHandler(expenseService services.ExpenseService, publicIDService services.PublicIDService, validate *validator.Validate) *ExpenseHandler {
 	return &ExpenseHandler{
-		expenseService: expenseService,
-		validate:       validate,
+		expenseService:  expenseService,
+		publicIDService: publicIDService,
+		kind:            services.KindTransfer,
+		validate:        validate,
 	}
 }
 
@@ -34,8 +70,14 @@ func (h *ExpenseHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	type ListExpensesRequest struct {
-		Limit  int `json:"limit" validate:"min=1,max=100"`
-		Offset int `json:"offset" validate:"min=0"`
+		Limit         int      `json:"limit" validate:"min=1,max=100"`
+		Offset        int      `json:"offset" validate:"min=0"`
+		CategoryID    string   `json:"categoryId" validate:"omitempty,uuid"`
+		Uncategorized bool     `json:"uncategorized" validate:"excluded_with=CategoryID"`
+		AccountID     int      `json:"accountId" validate:"min=0"`
+		Tags          []string `json:"tags" validate:"max=20"`
+		From          string   `json:"from" validate:"omitempty,datetime=2006-01-02"`
+		To            string   `json:"to" validate:"omitempty,datetime=2006-01-02"`
 	}
 	queryParams := ListExpensesRequest{
 		Limit:  100,
@@ -51,20 +93,169 @@ func (h *ExpenseHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := u.ParseQueryParamInt(r, &queryParams.AccountID, "accountId", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	workspaceID, err := parseWorkspaceID(r)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	queryParams.CategoryID = r.URL.Query().Get("categoryId")
+	queryParams.Uncategorized = r.URL.Query().Get("uncategorized") == "true"
+	queryParams.Tags = u.ParseQueryParamList(r, "tags")
+	queryParams.From = r.URL.Query().Get("from")
+	queryParams.To = r.URL.Query().Get("to")
+
 	// Validation
 	if err := h.validate.Struct(queryParams); err != nil {
 		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
 		return
 	}
 
+	refs := services.PublicRefs{}
+	filter, err := parseExpenseFilter(queryParams.CategoryID, queryParams.From, queryParams.To, &refs)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !resolveRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+	filter.Kind = h.kind
+	filter.Uncategorized = queryParams.Uncategorized
+	filter.Tags = queryParams.Tags
+	filter.WorkspaceID = workspaceID
+	if queryParams.AccountID > 0 {
+		accountID := int32(queryParams.AccountID)
+		filter.AccountID = &accountID
+	}
+
 	// Fetching
-	expenses, err := h.expenseService.ListByUser(r.Context(), clerkID, queryParams.Limit, queryParams.Offset)
+	expenses, err := h.expenseService.ListByUser(r.Context(), clerkID, filter, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	serialRefs := services.SerialRefs{}
+	views := newExpenseDetailsViews(expenses, &serialRefs)
+	if !publishRefs(w, r.Context(), h.publicIDService, &serialRefs) {
+		return
+	}
+
+	u.WriteJSONList(w, r, views)
+}
+
+// Export downloads the transactions as a csv, xlsx or json file
+// (?format=csv). It accepts the filters of the listing and streams the file
+// instead of paginating.
+func (h *ExpenseHandler) Export(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type ExportExpensesRequest struct {
+		Format        string   `json:"format" validate:"required,oneof=csv xlsx json"`
+		CategoryID    string   `json:"categoryId" validate:"omitempty,uuid"`
+		Uncategorized bool     `json:"uncategorized" validate:"excluded_with=CategoryID"`
+		AccountID     int      `json:"accountId" validate:"min=0"`
+		Tags          []string `json:"tags" validate:"max=20"`
+		From          string   `json:"from" validate:"omitempty,datetime=2006-01-02"`
+		To            string   `json:"to" validate:"omitempty,datetime=2006-01-02"`
+	}
+	queryParams := ExportExpensesRequest{
+		Format: export.FormatCSV,
+	}
+	if format := r.URL.Query().Get("format"); format != "" {
+		queryParams.Format = format
+	}
+
+	if err := u.ParseQueryParamInt(r, &queryParams.AccountID, "accountId", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	workspaceID, err := parseWorkspaceID(r)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	queryParams.CategoryID = r.URL.Query().Get("categoryId")
+	queryParams.Uncategorized = r.URL.Query().Get("uncategorized") == "true"
+	queryParams.Tags = u.ParseQueryParamList(r, "tags")
+	queryParams.From = r.URL.Query().Get("from")
+	queryParams.To = r.URL.Query().Get("to")
+
+	// Validation
+	if err := h.validate.Struct(queryParams); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	refs := services.PublicRefs{}
+	filter, err := parseExpenseFilter(queryParams.CategoryID, queryParams.From, queryParams.To, &refs)
 	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !resolveRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+	filter.Kind = h.kind
+	filter.Uncategorized = queryParams.Uncategorized
+	filter.Tags = queryParams.Tags
+	filter.WorkspaceID = workspaceID
+	if queryParams.AccountID > 0 {
+		accountID := int32(queryParams.AccountID)
+		filter.AccountID = &accountID
+	}
+
+	// Exporting
+	fileName := fmt.Sprintf("%s-%s.%s", h.kind, time.Now().UTC().Format("2006-01-02"), queryParams.Format)
+	out := &exportWriter{w: w, contentType: export.ContentType(queryParams.Format), fileName: fileName}
+	if err := h.expenseService.Export(r.Context(), clerkID, filter, queryParams.Format, out); err != nil {
+		if out.started {
+			// The status is already sent, the client sees a truncated file
+			logrus.WithError(err).Error("failed to export transactions")
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
 		u.WriteJSONError(w, http.StatusInternalServerError, err)
 		return
 	}
+}
+
+// exportWriter sends the headers of a downloaded file along with its first
+// bytes, so that errors raised before can still be answered with JSON.
+type exportWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	fileName    string
+	started     bool
+}
 
-	u.WriteJSON(w, http.StatusOK, expenses)
+func (e *exportWriter) Write(p []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		e.w.Header().Set("Content-Type", e.contentType)
+		e.w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": e.fileName}))
+		e.w.Header().Set("Cache-Control", "no-store")
+		e.w.WriteHeader(http.StatusOK)
+	}
+	return e.w.Write(p)
 }
 
 func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -78,11 +269,16 @@ func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	type CreateExpenseRequest struct {
-		Amount       float64 `json:"amount" validate:"required,min=0"`
-		Description  string  `json:"description" validate:"required,min=1,max=255"`
-		PurchaseDate string  `json:"purchaseDate" validate:"required,datetime=2006-01-02"`
-		BillDate     string  `json:"billDate" validate:"required,datetime=2006-01-02"`
-		CategoryID   *int32  `json:"categoryId"`
+		ID           *uuid.UUID     `json:"id"`
+		Amount       float64        `json:"amount" validate:"required,min=0"`
+		Description  string         `json:"description" validate:"required,min=1,max=255"`
+		PurchaseDate string         `json:"purchaseDate" validate:"required,datetime=2006-01-02"`
+		BillDate     string         `json:"billDate" validate:"required,datetime=2006-01-02"`
+		CategoryID   *uuid.UUID     `json:"categoryId"`
+		AccountID    *int32         `json:"accountId"`
+		WorkspaceID  *int32         `json:"workspaceId"`
+		Tags         []string       `json:"tags" validate:"max=20,dive,min=1,max=50"`
+		Splits       []splitRequest `json:"splits" validate:"omitempty,min=2,max=50,dive"`
 	}
 
 	reqBody := CreateExpenseRequest{}
@@ -113,10 +309,315 @@ func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Description:  reqBody.Description,
 		PurchaseDate: purchaseDate,
 		BillDate:     billDate,
-		CategoryID:   reqBody.CategoryID,
+		AccountID:    reqBody.AccountID,
+		WorkspaceID:  reqBody.WorkspaceID,
+		Kind:         h.kind,
+	}
+	if reqBody.ID != nil {
+		modelExpense.PublicID = *reqBody.ID
+	}
+	refs := services.PublicRefs{}
+	refs.OptCategory(reqBody.CategoryID, &modelExpense.CategoryID)
+	splits := toModelSplits(reqBody.Splits, &refs)
+	if !resolveRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	createdExpense, err := h.expenseService.Create(r.Context(), clerkID, modelExpense, services.ExpenseLines{
+		Tags:   reqBody.Tags,
+		Splits: splits,
+	})
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		if err == u.ErrCategoryKindMismatch || err == u.ErrSplitMismatch {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err == u.ErrPublicIDTaken {
+			u.WriteJSONError(w, http.StatusConflict, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.writeDetails(w, r, http.StatusOK, createdExpense, false)
+}
+
+// Bulk creates, updates or deletes up to 1000 transactions at once. Updates
+// and deletes select transactions by ids or by the filters of the listing.
+// All-or-nothing operations that fail answer 422 and write nothing.
+func (h *ExpenseHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type BulkItemRequest struct {
+		ID           *uuid.UUID     `json:"id"`
+		Amount       float64        `json:"amount" validate:"required,min=0"`
+		Description  string         `json:"description" validate:"required,min=1,max=255"`
+		PurchaseDate string         `json:"purchaseDate" validate:"required,datetime=2006-01-02"`
+		BillDate     string         `json:"billDate" validate:"required,datetime=2006-01-02"`
+		CategoryID   *uuid.UUID     `json:"categoryId"`
+		AccountID    *int32         `json:"accountId"`
+		Tags         []string       `json:"tags" validate:"max=20,dive,min=1,max=50"`
+		Splits       []splitRequest `json:"splits" validate:"omitempty,min=2,max=50,dive"`
+	}
+	type BulkFilterRequest struct {
+		CategoryID string   `json:"categoryId" validate:"omitempty,uuid"`
+		AccountID  int      `json:"accountId" validate:"min=0"`
+		Tags       []string `json:"tags" validate:"max=20"`
+		From       string   `json:"from" validate:"omitempty,datetime=2006-01-02"`
+		To         string   `json:"to" validate:"omitempty,datetime=2006-01-02"`
+	}
+	type BulkRequest struct {
+		Operation   string             `json:"operation" validate:"required,oneof=create update delete"`
+		Mode        string             `json:"mode" validate:"required,oneof=all_or_nothing best_effort"`
+		WorkspaceID *int32             `json:"workspaceId"`
+		Items       []BulkItemRequest  `json:"items" validate:"required_if=Operation create,max=1000,dive"`
+		IDs         []uuid.UUID        `json:"ids" validate:"max=1000,unique"`
+		Filter      *BulkFilterRequest `json:"filter"`
+		CategoryID  *uuid.UUID         `json:"categoryId"`
+		AddTags     []string           `json:"addTags" validate:"max=20,dive,min=1,max=50"`
+		RemoveTags  []string           `json:"removeTags" validate:"max=20,dive,min=1,max=50"`
+	}
+
+	reqBody := BulkRequest{
+		Mode: services.BulkAllOrNothing,
+	}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	op := services.BulkOperation{
+		Operation:   reqBody.Operation,
+		Mode:        reqBody.Mode,
+		WorkspaceID: reqBody.WorkspaceID,
+		Items:       make([]services.BulkItem, len(reqBody.Items)),
+		AddTags:     reqBody.AddTags,
+		RemoveTags:  reqBody.RemoveTags,
+	}
+	refs := services.PublicRefs{}
+	refs.OptCategory(reqBody.CategoryID, &op.CategoryID)
+	for i, item := range reqBody.Items {
+		var purchaseDate, billDate time.Time
+		if err := u.ParseIsoDate(item.PurchaseDate, &purchaseDate); err != nil {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := u.ParseIsoDate(item.BillDate, &billDate); err != nil {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		op.Items[i] = services.BulkItem{
+			Expense: model.Expense{
+				Amount:       item.Amount,
+				Description:  item.Description,
+				PurchaseDate: purchaseDate,
+				BillDate:     billDate,
+				AccountID:    item.AccountID,
+			},
+			Lines: services.ExpenseLines{
+				Tags:   item.Tags,
+				Splits: toModelSplits(item.Splits, &refs),
+			},
+		}
+		if item.ID != nil {
+			op.Items[i].Expense.PublicID = *item.ID
+		}
+		refs.OptCategory(item.CategoryID, &op.Items[i].Expense.CategoryID)
+	}
+	if reqBody.Filter != nil {
+		filter, err := parseExpenseFilter(reqBody.Filter.CategoryID, reqBody.Filter.From, reqBody.Filter.To, &refs)
+		if err != nil {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		filter.Tags = reqBody.Filter.Tags
+		filter.WorkspaceID = reqBody.WorkspaceID
+		if reqBody.Filter.AccountID > 0 {
+			accountID := int32(reqBody.Filter.AccountID)
+			filter.AccountID = &accountID
+		}
+		op.Filter = &filter
+	}
+	if !resolveRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+	if reqBody.IDs != nil {
+		// Unknown transactions are left with a zero ID, and reported as not
+		// found by the operation
+		op.IDs = make([]int32, len(reqBody.IDs))
+		idRefs := services.PublicRefs{}
+		for i, id := range reqBody.IDs {
+			idRefs.Expense(id, &op.IDs[i])
+		}
+		if err := h.publicIDService.Resolve(r.Context(), &idRefs); err != nil && err != u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	// Applying
+	results, err := h.expenseService.Bulk(r.Context(), clerkID, h.kind, op)
+	if err != nil {
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		if err == u.ErrBulkTooLarge || err == u.ErrBulkSelection || err == u.ErrBulkEmptyUpdate {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	status := http.StatusOK
+	for _, result := range results {
+		if result.Status == services.BulkStatusFailed && op.Mode == services.BulkAllOrNothing {
+			status = http.StatusUnprocessableEntity
+			break
+		}
+	}
+
+	serialRefs := services.SerialRefs{}
+	views := make([]bulkResultView, len(results))
+	for i := range results {
+		views[i].set(results[i], &serialRefs)
+	}
+	if !publishRefs(w, r.Context(), h.publicIDService, &serialRefs) {
+		return
+	}
+
+	u.WriteJSON(w, status, views)
+}
+
+// Get returns a transaction along with its version as a strong ETag.
+// Clients send it back in If-Match to update or delete the transaction.
+func (h *ExpenseHandler) Get(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, ok := resolveExpensePath(w, r, h.publicIDService)
+	if !ok {
+		return
+	}
+
+	// Fetching
+	expense, err := h.expenseService.Get(r.Context(), clerkID, h.kind, id)
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.writeDetails(w, r, http.StatusOK, expense, true)
+}
+
+// Update replaces the fields of an expense or income. Tags are left untouched
+// when omitted from the body.
+func (h *ExpenseHandler) Update(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, ok := resolveExpensePath(w, r, h.publicIDService)
+	if !ok {
+		return
+	}
+
+	type UpdateExpenseRequest struct {
+		Amount       float64        `json:"amount" validate:"required,min=0"`
+		Description  string         `json:"description" validate:"required,min=1,max=255"`
+		PurchaseDate string         `json:"purchaseDate" validate:"required,datetime=2006-01-02"`
+		BillDate     string         `json:"billDate" validate:"required,datetime=2006-01-02"`
+		CategoryID   *uuid.UUID     `json:"categoryId"`
+		AccountID    *int32         `json:"accountId"`
+		Tags         []string       `json:"tags" validate:"omitempty,max=20,dive,min=1,max=50"`
+		Splits       []splitRequest `json:"splits" validate:"omitempty,min=2,max=50,dive"`
+	}
+
+	reqBody := UpdateExpenseRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	var purchaseDate, billDate time.Time
+	if err := u.ParseIsoDate(reqBody.PurchaseDate, &purchaseDate); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := u.ParseIsoDate(reqBody.BillDate, &billDate); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Updating
+	modelExpense := &model.Expense{
+		ID:           id,
+		Amount:       reqBody.Amount,
+		Description:  reqBody.Description,
+		PurchaseDate: purchaseDate,
+		BillDate:     billDate,
+		AccountID:    reqBody.AccountID,
+		Kind:         h.kind,
+	}
+	refs := services.PublicRefs{}
+	refs.OptCategory(reqBody.CategoryID, &modelExpense.CategoryID)
+	splits := toModelSplits(reqBody.Splits, &refs)
+	if !resolveRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
 	}
 
-	createdExpense, err := h.expenseService.Create(r.Context(), clerkID, modelExpense)
+	updatedExpense, err := h.expenseService.Update(r.Context(), clerkID, modelExpense, services.ExpenseLines{
+		Tags:   reqBody.Tags,
+		Splits: splits,
+	})
 	if err != nil {
 		if err == u.ErrNotFound {
 			u.WriteJSONError(w, http.StatusNotFound, err)
@@ -126,9 +627,465 @@ func (h *ExpenseHandler) Create(w http.ResponseWriter, r *http.Request) {
 			u.WriteJSONError(w, http.StatusForbidden, err)
 			return
 		}
+		if err == u.ErrPreconditionFailed {
+			u.WriteJSONError(w, http.StatusPreconditionFailed, err)
+			return
+		}
+		if err == u.ErrCategoryKindMismatch || err == u.ErrSplitMismatch || err == u.ErrShareMismatch || err == u.ErrRefundExceedsAmount {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
 		u.WriteJSONError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	u.WriteJSON(w, http.StatusOK, createdExpense)
+	h.writeDetails(w, r, http.StatusOK, updatedExpense, true)
+}
+
+// CreateTransfer records money moved between two of the user's accounts.
+func (h *ExpenseHandler) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type CreateTransferRequest struct {
+		Amount               float64 `json:"amount" validate:"required,gt=0"`
+		Description          string  `json:"description" validate:"max=255"`
+		Date                 string  `json:"date" validate:"required,datetime=2006-01-02"`
+		SourceAccountID      int32   `json:"sourceAccountId" validate:"required"`
+		DestinationAccountID int32   `json:"destinationAccountId" validate:"required,nefield=SourceAccountID"`
+	}
+
+	reqBody := CreateTransferRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	var date time.Time
+	if err := u.ParseIsoDate(reqBody.Date, &date); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Creating
+	transfer := &model.Expense{
+		Amount:            reqBody.Amount,
+		Description:       reqBody.Description,
+		PurchaseDate:      date,
+		BillDate:          date,
+		Kind:              services.KindTransfer,
+		AccountID:         &reqBody.SourceAccountID,
+		TransferAccountID: &reqBody.DestinationAccountID,
+	}
+
+	created, err := h.expenseService.Create(r.Context(), clerkID, transfer, services.ExpenseLines{})
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		if err == u.ErrInvalidTransfer {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.writeDetails(w, r, http.StatusOK, created, false)
+}
+
+// CreateRefund records a full or partial refund of an expense.
+func (h *ExpenseHandler) CreateRefund(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	expenseID, ok := resolveExpensePath(w, r, h.publicIDService)
+	if !ok {
+		return
+	}
+
+	type CreateRefundRequest struct {
+		Amount       float64 `json:"amount" validate:"required,gt=0"`
+		Description  string  `json:"description" validate:"max=255"`
+		PurchaseDate string  `json:"purchaseDate" validate:"required,datetime=2006-01-02"`
+		BillDate     string  `json:"billDate" validate:"omitempty,datetime=2006-01-02"`
+	}
+
+	reqBody := CreateRefundRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	var purchaseDate time.Time
+	if err := u.ParseIsoDate(reqBody.PurchaseDate, &purchaseDate); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	// Without a bill date the refund is credited on the purchase date
+	billDate := purchaseDate
+	if reqBody.BillDate != "" {
+		if err := u.ParseIsoDate(reqBody.BillDate, &billDate); err != nil {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	// Creating
+	refund := &model.Expense{
+		Amount:       reqBody.Amount,
+		Description:  reqBody.Description,
+		PurchaseDate: purchaseDate,
+		BillDate:     billDate,
+	}
+
+	created, err := h.expenseService.CreateRefund(r.Context(), clerkID, expenseID, refund)
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		if err == u.ErrRefundExceedsAmount {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	refs := services.SerialRefs{}
+	view := expenseView{}
+	view.set(*created, &refs)
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, view)
+}
+
+// Delete removes an expense or income along with its refunds and attachments.
+func (h *ExpenseHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, ok := resolveExpensePath(w, r, h.publicIDService)
+	if !ok {
+		return
+	}
+
+	// Deleting
+	if err := h.expenseService.Delete(r.Context(), clerkID, h.kind, id); err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		if err == u.ErrPreconditionFailed {
+			u.WriteJSONError(w, http.StatusPreconditionFailed, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// History lists the revisions of a transaction, latest first. Each maps the
+// fields it changed to their old and new values.
+func (h *ExpenseHandler) History(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, ok := resolveExpensePath(w, r, h.publicIDService)
+	if !ok {
+		return
+	}
+
+	// Fetching
+	revisions, err := h.expenseService.History(r.Context(), clerkID, h.kind, id)
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	refs := services.SerialRefs{}
+	views := make([]revisionView, len(revisions))
+	for i := range revisions {
+		if err := views[i].set(revisions[i], &refs); err != nil {
+			u.WriteJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	u.WriteJSONList(w, r, views)
+}
+
+// Revert undoes the changes of a revision. The undo is itself recorded as a
+// new revision.
+func (h *ExpenseHandler) Revert(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, ok := resolveExpensePath(w, r, h.publicIDService)
+	if !ok {
+		return
+	}
+	revision, err := u.ParseInt32(chi.URLParam(r, "revision"), "revision")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Reverting
+	reverted, err := h.expenseService.Revert(r.Context(), clerkID, h.kind, id, revision)
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		// The old values no longer fit the current state of the ledger
+		if err == u.ErrCategoryKindMismatch || err == u.ErrSplitMismatch || err == u.ErrShareMismatch || err == u.ErrRefundExceedsAmount {
+			u.WriteJSONError(w, http.StatusConflict, err)
+			return
+		}
+		if err == u.ErrPreconditionFailed {
+			u.WriteJSONError(w, http.StatusPreconditionFailed, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.writeDetails(w, r, http.StatusOK, reverted, true)
+}
+
+// Share splits an expense with contacts, equally, by percentage or by exact
+// amounts. A participant without contactId is the user.
+func (h *ExpenseHandler) Share(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	expenseID, ok := resolveExpensePath(w, r, h.publicIDService)
+	if !ok {
+		return
+	}
+
+	type ShareParticipantRequest struct {
+		ContactID *int32  `json:"contactId"`
+		Value     float64 `json:"value" validate:"min=0"`
+	}
+	type ShareRequest struct {
+		Mode            string                    `json:"mode" validate:"required,oneof=equal percentage exact"`
+		PaidByContactID *int32                    `json:"paidByContactId"`
+		Participants    []ShareParticipantRequest `json:"participants" validate:"required,min=2,max=50,dive"`
+	}
+
+	reqBody := ShareRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Updating
+	input := services.ShareInput{
+		Mode:            reqBody.Mode,
+		PaidByContactID: reqBody.PaidByContactID,
+		Participants:    make([]services.ShareParticipant, len(reqBody.Participants)),
+	}
+	for i, p := range reqBody.Participants {
+		input.Participants[i] = services.ShareParticipant{ContactID: p.ContactID, Value: p.Value}
+	}
+
+	shared, err := h.expenseService.Share(r.Context(), clerkID, expenseID, input)
+	if err != nil {
+		writeShareError(w, err)
+		return
+	}
+
+	h.writeDetails(w, r, http.StatusOK, shared, true)
+}
+
+// Unshare makes a shared expense fully the user's own again.
+func (h *ExpenseHandler) Unshare(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	expenseID, ok := resolveExpensePath(w, r, h.publicIDService)
+	if !ok {
+		return
+	}
+
+	// Updating
+	expense, err := h.expenseService.Share(r.Context(), clerkID, expenseID, services.ShareInput{})
+	if err != nil {
+		writeShareError(w, err)
+		return
+	}
+
+	h.writeDetails(w, r, http.StatusOK, expense, true)
+}
+
+func writeShareError(w http.ResponseWriter, err error) {
+	switch err {
+	case u.ErrNotFound:
+		u.WriteJSONError(w, http.StatusNotFound, err)
+	case u.ErrForbidden:
+		u.WriteJSONError(w, http.StatusForbidden, err)
+	case u.ErrPreconditionFailed:
+		u.WriteJSONError(w, http.StatusPreconditionFailed, err)
+	case u.ErrShareMismatch, u.ErrDuplicateParticipant:
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+	default:
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// writeDetails writes a transaction by its public IDs, along with its version
+// as an ETag when withETag is set.
+func (h *ExpenseHandler) writeDetails(w http.ResponseWriter, r *http.Request, status int, details *services.ExpenseDetails, withETag bool) {
+	refs := services.SerialRefs{}
+	view := expenseDetailsView{}
+	view.set(*details, &refs)
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	if withETag {
+		u.WriteJSONWithETag(w, r, status, u.VersionETag(details.UpdatedAt), view)
+		return
+	}
+	u.WriteJSON(w, status, view)
+}
+
+// toModelSplits keeps nil apart from empty so updates can leave splits
+// untouched. The categories of the lines are set once refs are resolved.
+func toModelSplits(splits []splitRequest, refs *services.PublicRefs) []model.ExpenseSplit {
+	if splits == nil {
+		return nil
+	}
+	result := make([]model.ExpenseSplit, len(splits))
+	for i, split := range splits {
+		result[i] = model.ExpenseSplit{
+			Amount: split.Amount,
+			Note:   split.Note,
+		}
+		refs.Category(split.CategoryID, &result[i].CategoryID)
+	}
+	return result
+}
+
+// parseExpenseFilter converts validated listing query params into a repository
+// filter. The category is set once refs are resolved.
+func parseExpenseFilter(categoryID string, from, to string, refs *services.PublicRefs) (services.ExpenseFilter, error) {
+	filter := services.ExpenseFilter{}
+	if categoryID != "" {
+		publicID, err := u.ParseUUID(categoryID, "categoryId")
+		if err != nil {
+			return filter, err
+		}
+		refs.OptCategory(&publicID, &filter.CategoryID)
+	}
+	if from != "" {
+		var fromDate time.Time
+		if err := u.ParseIsoDate(from, &fromDate); err != nil {
+			return filter, err
+		}
+		filter.From = &fromDate
+	}
+	if to != "" {
+		var toDate time.Time
+		if err := u.ParseIsoDate(to, &toDate); err != nil {
+			return filter, err
+		}
+		filter.To = &toDate
+	}
+	return filter, nil
 }
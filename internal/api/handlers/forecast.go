@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/forecast"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type ForecastHandler struct {
+	forecastService services.ForecastService
+	publicIDService services.PublicIDService
+	validate        *validator.Validate
+}
+
+func NewForecastHandler(forecastService services.ForecastService, publicIDService services.PublicIDService, validate *validator.Validate) *ForecastHandler {
+	return &ForecastHandler{
+		forecastService: forecastService,
+		publicIDService: publicIDService,
+		validate:        validate,
+	}
+}
+
+func (h *ForecastHandler) Get(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	// Forecasting
+	result, err := h.forecastService.Get(r.Context(), clerkID)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	refs := services.SerialRefs{}
+	view := forecastView{
+		Result:     result,
+		Categories: make([]categoryForecastView, len(result.Categories)),
+	}
+	for i, category := range result.Categories {
+		view.Categories[i].CategoryForecast = category
+		refs.OptCategory(category.CategoryID, &view.Categories[i].CategoryID)
+	}
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, view)
+}
+
+type forecastView struct {
+	*forecast.Result
+	Categories []categoryForecastView `json:"categories"`
+}
+
+type categoryForecastView struct {
+	forecast.CategoryForecast
+	CategoryID *uuid.UUID `json:"categoryId"`
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type ImportHandler struct {
+	importService   services.ImportService
+	publicIDService services.PublicIDService
+	validate        *validator.Validate
+}
+
+func NewImportHandler(importService services.ImportService, publicIDService services.PublicIDService, validate *validator.Validate) *ImportHandler {
+	return &ImportHandler{
+		importService:   importService,
+		publicIDService: publicIDService,
+		validate:        validate,
+	}
+}
+
+func (h *ImportHandler) Create(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type ImportRowRequest struct {
+		Amount       float64    `json:"amount" validate:"required"`
+		Description  string     `json:"description" validate:"required,min=1,max=255"`
+		PurchaseDate string     `json:"purchaseDate" validate:"required,datetime=2006-01-02"`
+		BillDate     string     `json:"billDate" validate:"required,datetime=2006-01-02"`
+		CategoryID   *uuid.UUID `json:"categoryId"`
+		AccountID    *int32     `json:"accountId"`
+	}
+	type ImportRequest struct {
+		Rows []ImportRowRequest `json:"rows" validate:"required,min=1,max=1000,dive"`
+	}
+
+	reqBody := ImportRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	rows := make([]services.ImportRow, len(reqBody.Rows))
+	refs := services.PublicRefs{}
+	for i, row := range reqBody.Rows {
+		var purchaseDate, billDate time.Time
+		if err := u.ParseIsoDate(row.PurchaseDate, &purchaseDate); err != nil {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := u.ParseIsoDate(row.BillDate, &billDate); err != nil {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		rows[i] = services.ImportRow{
+			Amount:       row.Amount,
+			Description:  row.Description,
+			PurchaseDate: purchaseDate,
+			BillDate:     billDate,
+			AccountID:    row.AccountID,
+		}
+		refs.OptCategory(row.CategoryID, &rows[i].CategoryID)
+	}
+	// Rows with an unknown category are left with a zero ID, and fail on their own
+	if err := h.publicIDService.Resolve(r.Context(), &refs); err != nil && err != u.ErrNotFound {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Importing
+	results, err := h.importService.Import(r.Context(), clerkID, rows)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	serialRefs := services.SerialRefs{}
+	views := make([]importResultView, len(results))
+	for i, result := range results {
+		views[i].ImportResult = result
+		if result.Expense != nil {
+			views[i].Expense = &expenseView{}
+			views[i].Expense.set(*result.Expense, &serialRefs)
+		}
+	}
+	if !publishRefs(w, r.Context(), h.publicIDService, &serialRefs) {
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, views)
+}
+
+type importResultView struct {
+	services.ImportResult
+	Expense *expenseView `json:"expense,omitempty"`
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type InsightHandler struct {
+	insightService  services.InsightService
+	publicIDService services.PublicIDService
+	validate        *validator.Validate
+}
+
+func NewInsightHandler(insightService services.InsightService, publicIDService services.PublicIDService, validate *validator.Validate) *InsightHandler {
+	return &InsightHandler{
+		insightService:  insightService,
+		publicIDService: publicIDService,
+		validate:        validate,
+	}
+}
+
+func (h *InsightHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type ListInsightsRequest struct {
+		Limit            int  `json:"limit" validate:"min=1,max=100"`
+		Offset           int  `json:"offset" validate:"min=0"`
+		IncludeDismissed bool `json:"includeDismissed"`
+	}
+	queryParams := ListInsightsRequest{
+		Limit:  100,
+		Offset: 0,
+	}
+
+	if err := u.ParseQueryParamInt(r, &queryParams.Limit, "limit", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := u.ParseQueryParamInt(r, &queryParams.Offset, "offset", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	queryParams.IncludeDismissed = r.URL.Query().Get("includeDismissed") == "true"
+
+	// Validation
+	if err := h.validate.Struct(queryParams); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Fetching
+	cards, err := h.insightService.ListByUser(r.Context(), clerkID, queryParams.IncludeDismissed, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	refs := services.SerialRefs{}
+	views := make([]insightView, len(cards))
+	for i := range cards {
+		if err := views[i].set(cards[i], &refs); err != nil {
+			u.WriteJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	u.WriteJSONList(w, r, views)
+}
+
+func (h *InsightHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	h.updateState(w, r, h.insightService.MarkRead)
+}
+
+func (h *InsightHandler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	h.updateState(w, r, h.insightService.Dismiss)
+}
+
+func (h *InsightHandler) updateState(
+	w http.ResponseWriter,
+	r *http.Request,
+	update func(ctx context.Context, clerkID string, id int32) (*services.InsightCard, error),
+) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Updating
+	card, err := update(r.Context(), clerkID, id)
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	refs := services.SerialRefs{}
+	view := insightView{}
+	if err := view.set(*card, &refs); err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, view)
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type MerchantHandler struct {
+	merchantService services.MerchantService
+	validate        *validator.Validate
+}
+
+func NewMerchantHandler(merchantService services.MerchantService, validate *validator.Validate) *MerchantHandler {
+	return &MerchantHandler{
+		merchantService: merchantService,
+		validate:        validate,
+	}
+}
+
+func (h *MerchantHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type ListMerchantsRequest struct {
+		Limit  int `json:"limit" validate:"min=1,max=100"`
+		Offset int `json:"offset" validate:"min=0"`
+	}
+	queryParams := ListMerchantsRequest{
+		Limit:  100,
+		Offset: 0,
+	}
+
+	if err := u.ParseQueryParamInt(r, &queryParams.Limit, "limit", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := u.ParseQueryParamInt(r, &queryParams.Offset, "offset", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(queryParams); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Fetching
+	merchants, err := h.merchantService.ListByUser(r.Context(), clerkID, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSONList(w, r, merchants)
+}
+
+func (h *MerchantHandler) Get(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Fetching
+	merchant, err := h.merchantService.Get(r.Context(), clerkID, id)
+	if err != nil {
+		switch err {
+		case u.ErrNotFound:
+			u.WriteJSONError(w, http.StatusNotFound, err)
+		case u.ErrForbidden:
+			u.WriteJSONError(w, http.StatusForbidden, err)
+		default:
+			u.WriteJSONError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	u.WriteJSONWithETag(w, r, http.StatusOK, u.VersionETag(merchant.UpdatedAt), merchant)
+}
+
+// Update changes the display name of a merchant.
+func (h *MerchantHandler) Update(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	type UpdateMerchantRequest struct {
+		Name string `json:"name" validate:"required,min=1,max=100"`
+	}
+	reqBody := UpdateMerchantRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Updating
+	merchant, err := h.merchantService.Rename(r.Context(), clerkID, id, reqBody.Name)
+	if err != nil {
+		switch err {
+		case u.ErrNotFound:
+			u.WriteJSONError(w, http.StatusNotFound, err)
+		case u.ErrForbidden:
+			u.WriteJSONError(w, http.StatusForbidden, err)
+		case u.ErrPreconditionFailed:
+			u.WriteJSONError(w, http.StatusPreconditionFailed, err)
+		default:
+			u.WriteJSONError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	u.WriteJSONWithETag(w, r, http.StatusOK, u.VersionETag(merchant.UpdatedAt), merchant)
+}
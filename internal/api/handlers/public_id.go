@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+// Views serve transactions and categories by their public IDs. They embed the
+// model and shadow the fields holding serial keys, which are filled in by
+// SerialRefs once published, so views must not be copied before then.
+
+// hidden drops a field of an embedded model from the response.
+type hidden *struct{}
+
+type expenseView struct {
+	model.Expense
+	ID         uuid.UUID
+	PublicID   hidden `json:",omitempty"`
+	CategoryID *uuid.UUID
+	RefundOfID *uuid.UUID
+}
+
+func (v *expenseView) set(expense model.Expense, refs *services.SerialRefs) {
+	v.Expense = expense
+	v.ID = expense.PublicID
+	refs.OptCategory(expense.CategoryID, &v.CategoryID)
+	refs.OptExpense(expense.RefundOfID, &v.RefundOfID)
+}
+
+type splitView struct {
+	model.ExpenseSplit
+	ExpenseID  uuid.UUID
+	CategoryID uuid.UUID
+}
+
+type shareView struct {
+	model.ExpenseShare
+	ExpenseID uuid.UUID
+}
+
+type expenseDetailsView struct {
+	expenseView
+	// Sum of the refunds recorded against the expense
+	RefundedTotal float64
+	Tags          []string
+	Splits        []splitView
+	// Shares of the people the expense is split with
+	Shares []shareView
+}
+
+func (v *expenseDetailsView) set(details services.ExpenseDetails, refs *services.SerialRefs) {
+	v.expenseView.set(details.Expense, refs)
+	v.RefundedTotal = details.RefundedTotal
+	v.Tags = details.Tags
+	if details.Splits != nil {
+		v.Splits = make([]splitView, len(details.Splits))
+	}
+	for i, split := range details.Splits {
+		v.Splits[i] = splitView{ExpenseSplit: split, ExpenseID: details.PublicID}
+		refs.Category(split.CategoryID, &v.Splits[i].CategoryID)
+	}
+	if details.Shares != nil {
+		v.Shares = make([]shareView, len(details.Shares))
+	}
+	for i, share := range details.Shares {
+		v.Shares[i] = shareView{ExpenseShare: share, ExpenseID: details.PublicID}
+	}
+}
+
+func newExpenseDetailsViews(details []services.ExpenseDetails, refs *services.SerialRefs) []expenseDetailsView {
+	views := make([]expenseDetailsView, len(details))
+	for i := range details {
+		views[i].set(details[i], refs)
+	}
+	return views
+}
+
+type categoryView struct {
+	model.Category
+	ID       uuid.UUID
+	PublicID hidden `json:",omitempty"`
+}
+
+func newCategoryView(category model.Category) categoryView {
+	return categoryView{Category: category, ID: category.PublicID}
+}
+
+func newCategoryViews(categories []model.Category) []categoryView {
+	views := make([]categoryView, len(categories))
+	for i, category := range categories {
+		views[i] = newCategoryView(category)
+	}
+	return views
+}
+
+type revisionView struct {
+	services.ExpenseRevision
+	ExpenseID uuid.UUID
+	Changes   any
+}
+
+func (v *revisionView) set(revision services.ExpenseRevision, refs *services.SerialRefs) error {
+	changes, err := categoryIDsIn(revision.Changes, refs)
+	if err != nil {
+		return err
+	}
+	v.ExpenseRevision = revision
+	v.Changes = changes
+	refs.Expense(revision.ExpenseID, &v.ExpenseID)
+	return nil
+}
+
+type insightView struct {
+	services.InsightCard
+	Data   any
+	Filter any
+}
+
+func (v *insightView) set(card services.InsightCard, refs *services.SerialRefs) error {
+	data, err := categoryIDsIn(card.Data, refs)
+	if err != nil {
+		return err
+	}
+	filter, err := categoryIDsIn(card.Filter, refs)
+	if err != nil {
+		return err
+	}
+	v.InsightCard = card
+	v.Data, v.Filter = data, filter
+	return nil
+}
+
+type bulkResultView struct {
+	services.BulkResult
+	ID      *uuid.UUID   `json:"id,omitempty"`
+	Expense *expenseView `json:"expense,omitempty"`
+}
+
+func (v *bulkResultView) set(result services.BulkResult, refs *services.SerialRefs) {
+	v.BulkResult = result
+	if result.Expense != nil {
+		v.Expense = &expenseView{}
+		v.Expense.set(*result.Expense, refs)
+		v.ID = &v.Expense.ID
+		return
+	}
+	if result.ID != 0 {
+		v.ID = new(uuid.UUID)
+		refs.Expense(result.ID, v.ID)
+	}
+}
+
+type attachmentView struct {
+	services.AttachmentDetails
+	ExpenseID uuid.UUID
+}
+
+func (v *attachmentView) set(attachment services.AttachmentDetails, refs *services.SerialRefs) {
+	v.AttachmentDetails = attachment
+	refs.Expense(attachment.ExpenseID, &v.ExpenseID)
+}
+
+type anomalyView struct {
+	model.Anomaly
+	ExpenseID uuid.UUID
+}
+
+func (v *anomalyView) set(anomaly model.Anomaly, refs *services.SerialRefs) {
+	v.Anomaly = anomaly
+	refs.Expense(anomaly.ExpenseID, &v.ExpenseID)
+}
+
+// categoryIDsIn decodes a JSON document, such as the changes of a revision or
+// the data of an insight, and swaps the numbers found under "categoryId" keys
+// for the public IDs of the categories, once refs are published.
+func categoryIDsIn(raw json.RawMessage, refs *services.SerialRefs) (any, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return swapCategoryIDs(doc, false, refs), nil
+}
+
+func swapCategoryIDs(value any, isCategoryID bool, refs *services.SerialRefs) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			v[key] = swapCategoryIDs(field, isCategoryID || key == "categoryId", refs)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = swapCategoryIDs(item, isCategoryID, refs)
+		}
+	case json.Number:
+		if !isCategoryID {
+			return v
+		}
+		id, err := strconv.ParseInt(v.String(), 10, 32)
+		if err != nil {
+			return v
+		}
+		publicID := new(uuid.UUID)
+		refs.Category(int32(id), publicID)
+		return publicID
+	}
+	return value
+}
+
+// resolveExpensePath returns the serial key of the transaction whose public ID
+// is the {id} path param. Unknown IDs answer 404.
+func resolveExpensePath(w http.ResponseWriter, r *http.Request, publicIDService services.PublicIDService) (int32, bool) {
+	return resolvePath(w, r, publicIDService, (*services.PublicRefs).Expense)
+}
+
+// resolveCategoryPath is resolveExpensePath for categories.
+func resolveCategoryPath(w http.ResponseWriter, r *http.Request, publicIDService services.PublicIDService) (int32, bool) {
+	return resolvePath(w, r, publicIDService, (*services.PublicRefs).Category)
+}
+
+func resolvePath(w http.ResponseWriter, r *http.Request, publicIDService services.PublicIDService, ref func(*services.PublicRefs, uuid.UUID, *int32)) (int32, bool) {
+	publicID, err := u.ParseUUID(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return 0, false
+	}
+
+	var id int32
+	refs := services.PublicRefs{}
+	ref(&refs, publicID, &id)
+	return id, resolveRefs(w, r.Context(), publicIDService, &refs)
+}
+
+// resolveRefs resolves the public IDs of a request, answering 404 when one of
+// them is unknown.
+func resolveRefs(w http.ResponseWriter, ctx context.Context, publicIDService services.PublicIDService, refs *services.PublicRefs) bool {
+	if err := publicIDService.Resolve(ctx, refs); err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return false
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return false
+	}
+	return true
+}
+
+// publishRefs publishes the public IDs of a response, answering 500 when they
+// cannot be looked up.
+func publishRefs(w http.ResponseWriter, ctx context.Context, publicIDService services.PublicIDService, refs *services.SerialRefs) bool {
+	if err := publicIDService.Publish(ctx, refs); err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return false
+	}
+	return true
+}
+
+// parsePublicIDParam parses an optional UUID query param.
+func parsePublicIDParam(r *http.Request, paramName string) (*uuid.UUID, error) {
+	value := r.URL.Query().Get(paramName)
+	if value == "" {
+		return nil, nil
+	}
+	publicID, err := u.ParseUUID(value, paramName)
+	if err != nil {
+		return nil, err
+	}
+	return &publicID, nil
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type ReportHandler struct {
+	reportService   services.ReportService
+	publicIDService services.PublicIDService
+	validate        *validator.Validate
+}
+
+func NewReportHandler(reportService services.ReportService, publicIDService services.PublicIDService, validate *validator.Validate) *ReportHandler {
+	return &ReportHandler{
+		reportService:   reportService,
+		publicIDService: publicIDService,
+		validate:        validate,
+	}
+}
+
+// Summary accepts a calendar period (?period=2026-09) or an inclusive range
+// (?periodFrom=...&periodTo=...) and defaults to the current month. Workspace
+// summaries (?workspaceId=...) also break spending down by member, and
+// ?groupBy=merchant adds a breakdown by merchant.
+func (h *ReportHandler) Summary(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	workspaceID, err := parseWorkspaceID(r)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	period, _, err := parsePeriodParams(r, "period")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if period == nil {
+		month := u.CurrentMonth(time.Now().UTC())
+		period = &month
+	}
+
+	type SummaryRequest struct {
+		GroupBy string `json:"groupBy" validate:"omitempty,oneof=merchant"`
+	}
+	queryParams := SummaryRequest{
+		GroupBy: r.URL.Query().Get("groupBy"),
+	}
+
+	// Validation
+	if err := h.validate.Struct(queryParams); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Fetching
+	report, err := h.reportService.Summary(r.Context(), clerkID, workspaceID, *period, queryParams.GroupBy)
+	if err != nil {
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	refs := services.SerialRefs{}
+	view := summaryReportView{
+		SummaryReport: report,
+		Categories:    make([]categorySummaryView, len(report.Categories)),
+	}
+	for i, category := range report.Categories {
+		view.Categories[i].CategorySummary = category
+		refs.OptCategory(category.CategoryID, &view.Categories[i].CategoryID)
+	}
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, view)
+}
+
+// Compare accepts either calendar periods (?current=2026-09&previous=2026-08)
+// or arbitrary inclusive ranges (?currentFrom=...&currentTo=...&previousFrom=...&previousTo=...).
+// When the previous period is omitted, the one immediately before current is used.
+func (h *ReportHandler) Compare(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	workspaceID, err := parseWorkspaceID(r)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	current, granularity, err := parsePeriodParams(r, "current")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if current == nil {
+		month := u.CurrentMonth(time.Now().UTC())
+		current, granularity = &month, u.GranularityMonth
+	}
+
+	previous, _, err := parsePeriodParams(r, "previous")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if previous == nil {
+		p := current.Previous(granularity)
+		previous = &p
+	}
+
+	// Fetching
+	report, err := h.reportService.Compare(r.Context(), clerkID, workspaceID, *current, *previous)
+	if err != nil {
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	refs := services.SerialRefs{}
+	view := comparisonReportView{
+		ComparisonReport: report,
+		Categories:       make([]categoryComparisonView, len(report.Categories)),
+	}
+	for i, category := range report.Categories {
+		view.Categories[i].CategoryComparison = category
+		refs.OptCategory(category.CategoryID, &view.Categories[i].CategoryID)
+	}
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, view)
+}
+
+// CashFlow reports monthly income and expenses between two months (?from=2026-01&to=2026-09),
+// both inclusive. It defaults to the last twelve months.
+func (h *ReportHandler) CashFlow(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type CashFlowRequest struct {
+		From string `json:"from" validate:"omitempty,datetime=2006-01"`
+		To   string `json:"to" validate:"omitempty,datetime=2006-01"`
+	}
+	queryParams := CashFlowRequest{
+		From: r.URL.Query().Get("from"),
+		To:   r.URL.Query().Get("to"),
+	}
+	workspaceID, err := parseWorkspaceID(r)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(queryParams); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	currentMonth := u.CurrentMonth(time.Now().UTC())
+	period := u.Period{From: currentMonth.From.AddDate(0, -11, 0), To: currentMonth.To}
+	if queryParams.From != "" {
+		from, _, err := u.ParsePeriod(queryParams.From)
+		if err != nil {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		period.From = from.From
+	}
+	if queryParams.To != "" {
+		to, _, err := u.ParsePeriod(queryParams.To)
+		if err != nil {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		period.To = to.To
+	}
+	if !period.From.Before(period.To) {
+		u.WriteJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid month range: from must not be after to"))
+		return
+	}
+
+	// Fetching
+	report, err := h.reportService.CashFlow(r.Context(), clerkID, workspaceID, period)
+	if err != nil {
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, report)
+}
+
+// parsePeriodParams reads a period either from the `name` query param or from
+// the `nameFrom`/`nameTo` pair. It returns nil when none of them is present.
+func parsePeriodParams(r *http.Request, name string) (*u.Period, u.PeriodGranularity, error) {
+	query := r.URL.Query()
+	value, from, to := query.Get(name), query.Get(name+"From"), query.Get(name+"To")
+
+	switch {
+	case value != "" && (from != "" || to != ""):
+		return nil, u.GranularityRange, fmt.Errorf("query param %s cannot be combined with %sFrom/%sTo", name, name, name)
+	case value != "":
+		period, granularity, err := u.ParsePeriod(value)
+		if err != nil {
+			return nil, granularity, err
+		}
+		return &period, granularity, nil
+	case from != "" || to != "":
+		if from == "" || to == "" {
+			return nil, u.GranularityRange, fmt.Errorf("query params %sFrom and %sTo must be provided together", name, name)
+		}
+		period, err := u.ParseDateRange(from, to)
+		if err != nil {
+			return nil, u.GranularityRange, err
+		}
+		return &period, u.GranularityRange, nil
+	}
+
+	return nil, u.GranularityRange, nil
+}
+
+type summaryReportView struct {
+	*services.SummaryReport
+	Categories []categorySummaryView `json:"categories"`
+}
+
+type categorySummaryView struct {
+	services.CategorySummary
+	CategoryID *uuid.UUID `json:"categoryId"`
+}
+
+type comparisonReportView struct {
+	*services.ComparisonReport
+	Categories []categoryComparisonView `json:"categories"`
+}
+
+type categoryComparisonView struct {
+	services.CategoryComparison
+	CategoryID *uuid.UUID `json:"categoryId"`
+}
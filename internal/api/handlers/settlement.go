@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type SettlementHandler struct {
+	settlementService services.SettlementService
+	validate          *validator.Validate
+}
+
+func NewSettlementHandler(settlementService services.SettlementService, validate *validator.Validate) *SettlementHandler {
+	return &SettlementHandler{
+		settlementService: settlementService,
+		validate:          validate,
+	}
+}
+
+func (h *SettlementHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type ListSettlementsRequest struct {
+		Limit  int `json:"limit" validate:"min=1,max=100"`
+		Offset int `json:"offset" validate:"min=0"`
+	}
+	queryParams := ListSettlementsRequest{
+		Limit:  100,
+		Offset: 0,
+	}
+
+	if err := u.ParseQueryParamInt(r, &queryParams.Limit, "limit", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := u.ParseQueryParamInt(r, &queryParams.Offset, "offset", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(queryParams); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Fetching
+	settlements, err := h.settlementService.ListByUser(r.Context(), clerkID, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSONList(w, r, settlements)
+}
+
+// Create records a payment between two parties. A missing contact on either
+// side is the user.
+func (h *SettlementHandler) Create(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type CreateSettlementRequest struct {
+		FromContactID *int32  `json:"fromContactId"`
+		ToContactID   *int32  `json:"toContactId"`
+		Amount        float64 `json:"amount" validate:"required,gt=0"`
+		Date          string  `json:"date" validate:"required,datetime=2006-01-02"`
+		Note          *string `json:"note" validate:"omitempty,max=255"`
+	}
+
+	reqBody := CreateSettlementRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	var date time.Time
+	if err := u.ParseIsoDate(reqBody.Date, &date); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Creating
+	settlement, err := h.settlementService.Create(r.Context(), clerkID, &model.Settlement{
+		FromContactID: reqBody.FromContactID,
+		ToContactID:   reqBody.ToContactID,
+		Amount:        reqBody.Amount,
+		Date:          date,
+		Note:          reqBody.Note,
+	})
+	if err != nil {
+		writeSettlementError(w, err)
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, settlement)
+}
+
+func (h *SettlementHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Deleting
+	if err := h.settlementService.Delete(r.Context(), clerkID, id); err != nil {
+		writeSettlementError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Balances reports what each party of the user's shared expenses owes and the
+// fewest payments that settle up.
+func (h *SettlementHandler) Balances(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	// Fetching
+	report, err := h.settlementService.Balances(r.Context(), clerkID)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, report)
+}
+
+func writeSettlementError(w http.ResponseWriter, err error) {
+	switch err {
+	case u.ErrNotFound:
+		u.WriteJSONError(w, http.StatusNotFound, err)
+	case u.ErrForbidden:
+		u.WriteJSONError(w, http.StatusForbidden, err)
+	case u.ErrPreconditionFailed:
+		u.WriteJSONError(w, http.StatusPreconditionFailed, err)
+	case u.ErrInvalidSettlement:
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+	default:
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type SyncHandler struct {
+	syncService     services.SyncService
+	publicIDService services.PublicIDService
+	validate        *validator.Validate
+}
+
+func NewSyncHandler(syncService services.SyncService, publicIDService services.PublicIDService, validate *validator.Validate) *SyncHandler {
+	return &SyncHandler{
+		syncService:     syncService,
+		publicIDService: publicIDService,
+		validate:        validate,
+	}
+}
+
+// Pull returns what changed in a ledger since the token given as ?since, or
+// everything without one, along with the token for the next sync. Expired
+// tokens answer 410: the client must sync again from scratch.
+func (h *SyncHandler) Pull(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	token := r.URL.Query().Get("since")
+	workspaceID, err := parseWorkspaceID(r)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Fetching
+	delta, err := h.syncService.Pull(r.Context(), clerkID, workspaceID, token)
+	if err != nil {
+		if err == u.ErrInvalidSyncToken {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err == u.ErrSyncTokenExpired {
+			u.WriteJSONError(w, http.StatusGone, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	refs := services.SerialRefs{}
+	view := syncDeltaView{
+		SyncDelta:  *delta,
+		Expenses:   newExpenseDetailsViews(delta.Expenses, &refs),
+		Categories: newCategoryViews(delta.Categories),
+		Deleted:    make([]syncDeletionView, len(delta.Deleted)),
+	}
+	for i, deletion := range delta.Deleted {
+		view.Deleted[i] = newSyncDeletionView(deletion)
+	}
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, view)
+}
+
+// Push applies up to 1000 changes made offline, in order, and reports the
+// outcome of each. Updates and deletes carry the ETag of the version they
+// apply to as ifMatch, and conflict when the row changed since. Transactions
+// and categories created offline may carry the public ID the client picked,
+// which later changes of the push can refer to.
+func (h *SyncHandler) Push(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type SyncExpenseRequest struct {
+		Amount       float64        `json:"amount" validate:"required,min=0"`
+		Description  string         `json:"description" validate:"required,min=1,max=255"`
+		PurchaseDate string         `json:"purchaseDate" validate:"required,datetime=2006-01-02"`
+		BillDate     string         `json:"billDate" validate:"required,datetime=2006-01-02"`
+		CategoryID   *uuid.UUID     `json:"categoryId"`
+		AccountID    *int32         `json:"accountId"`
+		Tags         []string       `json:"tags" validate:"omitempty,max=20,dive,min=1,max=50"`
+		Splits       []splitRequest `json:"splits" validate:"omitempty,min=2,max=50,dive"`
+	}
+	type SyncCategoryRequest struct {
+		Name        string `json:"name" validate:"required,min=1,max=255"`
+		Description string `json:"description" validate:"max=255"`
+		ColorHex    string `json:"colorHex" validate:"required,min=7,max=7"`
+		Kind        string `json:"kind" validate:"omitempty,oneof=expense income"`
+	}
+	type SyncChangeRequest struct {
+		Entity    string `json:"entity" validate:"required,oneof=expense category tag"`
+		Operation string `json:"operation" validate:"required,oneof=create update delete"`
+		// Public ID of transactions and categories, serial key of tags
+		ID       json.RawMessage      `json:"id" validate:"required_unless=Operation create"`
+		IfMatch  string               `json:"ifMatch"`
+		Kind     string               `json:"kind" validate:"required_if=Entity expense,omitempty,oneof=expense income"`
+		Expense  *SyncExpenseRequest  `json:"expense"`
+		Category *SyncCategoryRequest `json:"category"`
+		Tag      *tagRequest          `json:"tag"`
+	}
+	type SyncPushRequest struct {
+		WorkspaceID *int32              `json:"workspaceId"`
+		Changes     []SyncChangeRequest `json:"changes" validate:"required,max=1000,dive"`
+	}
+
+	reqBody := SyncPushRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	push := services.SyncPush{WorkspaceID: reqBody.WorkspaceID}
+	for i, c := range reqBody.Changes {
+		change := services.SyncChange{
+			Entity:    c.Entity,
+			Operation: c.Operation,
+			IfMatch:   c.IfMatch,
+			Expense:   model.Expense{Kind: c.Kind},
+		}
+		if c.ID != nil {
+			var err error
+			if c.Entity == services.SyncTag {
+				err = json.Unmarshal(c.ID, &change.ID)
+			} else {
+				err = json.Unmarshal(c.ID, &change.PublicID)
+			}
+			if err != nil {
+				u.WriteJSONError(w, http.StatusBadRequest, fmt.Errorf("changes[%d]: invalid id", i))
+				return
+			}
+		}
+		writes := c.Operation != services.SyncDelete
+		switch {
+		case c.Entity == services.SyncExpense && writes && c.Expense == nil:
+			u.WriteJSONError(w, http.StatusBadRequest, fmt.Errorf("changes[%d]: expense is required", i))
+			return
+		case c.Entity == services.SyncCategory && c.Operation == services.SyncCreate && c.Category == nil:
+			u.WriteJSONError(w, http.StatusBadRequest, fmt.Errorf("changes[%d]: category is required", i))
+			return
+		case c.Entity == services.SyncTag && writes && c.Tag == nil:
+			u.WriteJSONError(w, http.StatusBadRequest, fmt.Errorf("changes[%d]: tag is required", i))
+			return
+		}
+
+		if c.Expense != nil {
+			var purchaseDate, billDate time.Time
+			if err := u.ParseIsoDate(c.Expense.PurchaseDate, &purchaseDate); err != nil {
+				u.WriteJSONError(w, http.StatusBadRequest, err)
+				return
+			}
+			if err := u.ParseIsoDate(c.Expense.BillDate, &billDate); err != nil {
+				u.WriteJSONError(w, http.StatusBadRequest, err)
+				return
+			}
+			change.Expense.Amount = c.Expense.Amount
+			change.Expense.Description = c.Expense.Description
+			change.Expense.PurchaseDate = purchaseDate
+			change.Expense.BillDate = billDate
+			change.Expense.AccountID = c.Expense.AccountID
+			change.CategoryID = c.Expense.CategoryID
+			change.Lines = services.ExpenseLines{Tags: c.Expense.Tags}
+			// Categories of split lines are resolved as the change is applied
+			if c.Expense.Splits != nil {
+				change.Lines.Splits = make([]model.ExpenseSplit, len(c.Expense.Splits))
+				change.SplitCategoryIDs = make([]uuid.UUID, len(c.Expense.Splits))
+			}
+			for j, split := range c.Expense.Splits {
+				change.Lines.Splits[j] = model.ExpenseSplit{Amount: split.Amount, Note: split.Note}
+				change.SplitCategoryIDs[j] = split.CategoryID
+			}
+		}
+		if c.Category != nil {
+			change.Category = model.Category{
+				Name:        c.Category.Name,
+				Description: c.Category.Description,
+				ColorHex:    c.Category.ColorHex,
+				Kind:        c.Category.Kind,
+			}
+		}
+		if c.Tag != nil {
+			change.TagName = c.Tag.Name
+		}
+		push.Changes = append(push.Changes, change)
+	}
+
+	// Applying
+	results, err := h.syncService.Push(r.Context(), clerkID, push)
+	if err != nil {
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	refs := services.SerialRefs{}
+	views := make([]syncResultView, len(results))
+	for i := range results {
+		views[i].set(results[i], &refs)
+	}
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, views)
+}
+
+type syncDeltaView struct {
+	services.SyncDelta
+	Expenses   []expenseDetailsView
+	Categories []categoryView
+	Deleted    []syncDeletionView
+}
+
+// syncDeletionView identifies transactions and categories by their public IDs,
+// other rows by their serial keys.
+type syncDeletionView struct {
+	Entity string
+	ID     any
+}
+
+func newSyncDeletionView(deletion services.SyncDeletion) syncDeletionView {
+	if deletion.PublicID != uuid.Nil {
+		return syncDeletionView{Entity: deletion.Entity, ID: deletion.PublicID}
+	}
+	return syncDeletionView{Entity: deletion.Entity, ID: deletion.ID}
+}
+
+type syncResultView struct {
+	services.SyncResult
+	ID     any `json:"id,omitempty"`
+	Entity any `json:"entity,omitempty"`
+}
+
+func (v *syncResultView) set(result services.SyncResult, refs *services.SerialRefs) {
+	v.SyncResult = result
+	switch {
+	case result.PublicID != uuid.Nil:
+		v.ID = result.PublicID
+	case result.ID != 0:
+		v.ID = result.ID
+	}
+
+	switch entity := result.Entity.(type) {
+	case *services.ExpenseDetails:
+		view := &expenseDetailsView{}
+		view.set(*entity, refs)
+		v.Entity = view
+	case *model.Category:
+		view := newCategoryView(*entity)
+		v.Entity = &view
+	default:
+		v.Entity = entity
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type TrashHandler struct {
+	trashService    services.TrashService
+	publicIDService services.PublicIDService
+	validate        *validator.Validate
+}
+
+func NewTrashHandler(trashService services.TrashService, publicIDService services.PublicIDService, validate *validator.Validate) *TrashHandler {
+	return &TrashHandler{
+		trashService:    trashService,
+		publicIDService: publicIDService,
+		validate:        validate,
+	}
+}
+
+// List returns the deleted transactions and categories of a ledger, the
+// personal one unless workspaceId is given.
+func (h *TrashHandler) List(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type ListTrashRequest struct {
+		Limit  int `json:"limit" validate:"min=1,max=100"`
+		Offset int `json:"offset" validate:"min=0"`
+	}
+	queryParams := ListTrashRequest{
+		Limit:  100,
+		Offset: 0,
+	}
+
+	if err := u.ParseQueryParamInt(r, &queryParams.Limit, "limit", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := u.ParseQueryParamInt(r, &queryParams.Offset, "offset", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	workspaceID, err := parseWorkspaceID(r)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(queryParams); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Fetching
+	trash, err := h.trashService.List(r.Context(), clerkID, workspaceID, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	refs := services.SerialRefs{}
+	view := trashView{
+		Expenses:   make([]expenseView, len(trash.Expenses)),
+		Categories: newCategoryViews(trash.Categories),
+	}
+	for i := range trash.Expenses {
+		view.Expenses[i].set(trash.Expenses[i], &refs)
+	}
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	u.WriteJSONList(w, r, view)
+}
+
+// RestoreExpense takes a transaction out of the trash along with the refunds
+// deleted with it.
+func (h *TrashHandler) RestoreExpense(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, ok := resolveExpensePath(w, r, h.publicIDService)
+	if !ok {
+		return
+	}
+
+	// Restoring
+	expense, err := h.trashService.RestoreExpense(r.Context(), clerkID, id)
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		if err == u.ErrRefundedExpenseDeleted || err == u.ErrCategoryDeleted || err == u.ErrRefundExceedsAmount {
+			u.WriteJSONError(w, http.StatusConflict, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	refs := services.SerialRefs{}
+	view := expenseView{}
+	view.set(*expense, &refs)
+	if !publishRefs(w, r.Context(), h.publicIDService, &refs) {
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, view)
+}
+
+func (h *TrashHandler) RestoreCategory(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, ok := resolveCategoryPath(w, r, h.publicIDService)
+	if !ok {
+		return
+	}
+
+	// Restoring
+	category, err := h.trashService.RestoreCategory(r.Context(), clerkID, id)
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, newCategoryView(*category))
+}
+
+type trashView struct {
+	Expenses   []expenseView
+	Categories []categoryView
+}
@@ -60,7 +60,7 @@ func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	u.WriteJSON(w, http.StatusOK, users)
+	u.WriteJSONList(w, r, users)
 }
 
 func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -73,6 +73,7 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 		FirstName *string `json:"firstName"`
 		LastName  *string `json:"lastName"`
 		ImageURL  *string `json:"imageUrl"`
+		Locale    *string `json:"locale" validate:"omitempty,oneof=en-US pt-BR"`
 	}
 
 	var body CreateUserRequest
@@ -113,6 +114,14 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if body.Locale != nil && *body.Locale != upsertedUser.Locale {
+		upsertedUser, err = h.userService.UpdateLocale(r.Context(), upsertedUser.ClerkID, *body.Locale)
+		if err != nil {
+			u.WriteJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
 	if created {
 		u.WriteJSON(w, http.StatusCreated, upsertedUser)
 	} else {
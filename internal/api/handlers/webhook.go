@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+// webhookRequest is the body for registering and updating webhook endpoints.
+// Event types may end with ".*" to match a group, and none subscribes to every
+// event.
+type webhookRequest struct {
+	URL         string   `json:"url" validate:"required,url,max=2048"`
+	Description string   `json:"description" validate:"max=255"`
+	EventTypes  []string `json:"eventTypes" validate:"omitempty,max=20,dive,min=1,max=50"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+// webhookEndpointView serves an endpoint without its signing secret, which is
+// only served on its own.
+type webhookEndpointView struct {
+	services.WebhookEndpoint
+	Secret hidden `json:",omitempty"`
+}
+
+type WebhookHandler struct {
+	webhookService services.WebhookService
+	validate       *validator.Validate
+}
+
+func NewWebhookHandler(webhookService services.WebhookService, validate *validator.Validate) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		validate:       validate,
+	}
+}
+
+func (h *WebhookHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	// Fetching
+	endpoints, err := h.webhookService.ListEndpoints(r.Context(), clerkID)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	views := make([]webhookEndpointView, len(endpoints))
+	for i, endpoint := range endpoints {
+		views[i] = webhookEndpointView{WebhookEndpoint: endpoint}
+	}
+	u.WriteJSONList(w, r, views)
+}
+
+// Get returns an endpoint along with its signing secret.
+func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Fetching
+	endpoint, err := h.webhookService.GetEndpoint(r.Context(), clerkID, id)
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSONWithETag(w, r, http.StatusOK, u.VersionETag(endpoint.UpdatedAt), endpoint)
+}
+
+// Create registers an endpoint and returns it along with its signing secret.
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	reqBody := webhookRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Creating
+	endpoint, err := h.webhookService.CreateEndpoint(r.Context(), clerkID, toWebhookEndpoint(0, reqBody))
+	if err != nil {
+		if err == u.ErrInvalidWebhookURL || err == u.ErrInvalidWebhookEventType {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err == u.ErrTooManyWebhooks {
+			u.WriteJSONError(w, http.StatusConflict, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, endpoint)
+}
+
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	reqBody := webhookRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Updating
+	endpoint, err := h.webhookService.UpdateEndpoint(r.Context(), clerkID, toWebhookEndpoint(id, reqBody))
+	if err != nil {
+		if err == u.ErrInvalidWebhookURL || err == u.ErrInvalidWebhookEventType {
+			u.WriteJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		if err == u.ErrPreconditionFailed {
+			u.WriteJSONError(w, http.StatusPreconditionFailed, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSONWithETag(w, r, http.StatusOK, u.VersionETag(endpoint.UpdatedAt), webhookEndpointView{WebhookEndpoint: *endpoint})
+}
+
+// Delete removes an endpoint along with its delivery logs.
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Deleting
+	if err := h.webhookService.DeleteEndpoint(r.Context(), clerkID, id); err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		if err == u.ErrPreconditionFailed {
+			u.WriteJSONError(w, http.StatusPreconditionFailed, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries returns the delivery log of an endpoint, latest first, with
+// the response code of every attempt.
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	type ListDeliveriesRequest struct {
+		Limit  int    `json:"limit" validate:"min=1,max=100"`
+		Offset int    `json:"offset" validate:"min=0"`
+		Status string `json:"status" validate:"omitempty,oneof=pending succeeded failed"`
+	}
+	queryParams := ListDeliveriesRequest{
+		Limit:  100,
+		Offset: 0,
+	}
+
+	if err := u.ParseQueryParamInt(r, &queryParams.Limit, "limit", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := u.ParseQueryParamInt(r, &queryParams.Offset, "offset", false); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	queryParams.Status = r.URL.Query().Get("status")
+
+	// Validation
+	if err := h.validate.Struct(queryParams); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Fetching
+	deliveries, err := h.webhookService.ListDeliveries(r.Context(), clerkID, id, queryParams.Status, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSONList(w, r, deliveries)
+}
+
+// Redeliver attempts a delivery again right away and returns it with the
+// outcome of the attempt.
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Redelivering
+	delivery, err := h.webhookService.Redeliver(r.Context(), clerkID, id)
+	if err != nil {
+		if err == u.ErrNotFound {
+			u.WriteJSONError(w, http.StatusNotFound, err)
+			return
+		}
+		if err == u.ErrForbidden {
+			u.WriteJSONError(w, http.StatusForbidden, err)
+			return
+		}
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, delivery)
+}
+
+// toWebhookEndpoint turns a request into the endpoint it describes, enabled
+// unless stated otherwise.
+func toWebhookEndpoint(id int32, req webhookRequest) *services.WebhookEndpoint {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return &services.WebhookEndpoint{
+		WebhookEndpoint: model.WebhookEndpoint{
+			ID:          id,
+			URL:         req.URL,
+			Description: req.Description,
+			Enabled:     enabled,
+		},
+		EventTypes: req.EventTypes,
+	}
+}
@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type WorkspaceHandler struct {
+	workspaceService services.WorkspaceService
+	validate         *validator.Validate
+}
+
+func NewWorkspaceHandler(workspaceService services.WorkspaceService, validate *validator.Validate) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceService: workspaceService,
+		validate:         validate,
+	}
+}
+
+func (h *WorkspaceHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	// Fetching
+	workspaces, err := h.workspaceService.ListByUser(r.Context(), clerkID)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSONList(w, r, workspaces)
+}
+
+func (h *WorkspaceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	type CreateWorkspaceRequest struct {
+		Name string `json:"name" validate:"required,min=1,max=255"`
+	}
+
+	reqBody := CreateWorkspaceRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Creating
+	workspace, err := h.workspaceService.Create(r.Context(), clerkID, reqBody.Name)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, workspace)
+}
+
+func (h *WorkspaceHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Fetching
+	members, err := h.workspaceService.ListMembers(r.Context(), clerkID, id)
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	u.WriteJSONList(w, r, members)
+}
+
+func (h *WorkspaceHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	memberID, err := u.ParseUUID(chi.URLParam(r, "userId"), "userId")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	type UpdateMemberRequest struct {
+		Role string `json:"role" validate:"required,oneof=owner editor viewer"`
+	}
+
+	reqBody := UpdateMemberRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Updating
+	member, err := h.workspaceService.UpdateMemberRole(r.Context(), clerkID, id, memberID, reqBody.Role)
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	u.WriteJSONWithETag(w, r, http.StatusOK, u.VersionETag(member.UpdatedAt), member)
+}
+
+// RemoveMember removes a member from a workspace. Members can remove
+// themselves to leave it.
+func (h *WorkspaceHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	memberID, err := u.ParseUUID(chi.URLParam(r, "userId"), "userId")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Deleting
+	if err := h.workspaceService.RemoveMember(r.Context(), clerkID, id, memberID); err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WorkspaceHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Fetching
+	invitations, err := h.workspaceService.ListInvitations(r.Context(), clerkID, id)
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	u.WriteJSONList(w, r, invitations)
+}
+
+// Invite invites someone to a workspace by email. Inviting the same address
+// again replaces the pending invitation.
+func (h *WorkspaceHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	type InviteRequest struct {
+		Email string `json:"email" validate:"required,email,max=255"`
+		Role  string `json:"role" validate:"required,oneof=editor viewer"`
+	}
+
+	reqBody := InviteRequest{}
+	if err := u.ParseJSON(r, &reqBody, true); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validation
+	if err := h.validate.Struct(reqBody); err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, u.FormatValidationErrors(err))
+		return
+	}
+
+	// Creating
+	invitation, err := h.workspaceService.Invite(r.Context(), clerkID, id, reqBody.Email, reqBody.Role)
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, invitation)
+}
+
+// ListOwnInvitations lists the pending invitations sent to the user.
+func (h *WorkspaceHandler) ListOwnInvitations(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	// Fetching
+	invitations, err := h.workspaceService.ListOwnInvitations(r.Context(), clerkID)
+	if err != nil {
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	u.WriteJSONList(w, r, invitations)
+}
+
+func (h *WorkspaceHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Updating
+	member, err := h.workspaceService.AcceptInvitation(r.Context(), clerkID, id)
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	u.WriteJSON(w, http.StatusOK, member)
+}
+
+func (h *WorkspaceHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	// Parsing
+	clerkID, ok := auth.GetUserID(r.Context())
+	if !ok {
+		u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+		return
+	}
+
+	id, err := u.ParseInt32(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		u.WriteJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Deleting
+	if err := h.workspaceService.RevokeInvitation(r.Context(), clerkID, id); err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeWorkspaceError(w http.ResponseWriter, err error) {
+	switch err {
+	case u.ErrNotFound:
+		u.WriteJSONError(w, http.StatusNotFound, err)
+	case u.ErrForbidden:
+		u.WriteJSONError(w, http.StatusForbidden, err)
+	case u.ErrPreconditionFailed:
+		u.WriteJSONError(w, http.StatusPreconditionFailed, err)
+	case u.ErrLastOwner:
+		u.WriteJSONError(w, http.StatusConflict, err)
+	default:
+		u.WriteJSONError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// parseWorkspaceID reads the optional workspaceId query param. Without it,
+// requests work on the user's personal ledger.
+func parseWorkspaceID(r *http.Request) (*int32, error) {
+	var workspaceID int
+	if err := u.ParseQueryParamInt(r, &workspaceID, "workspaceId", false); err != nil {
+		return nil, err
+	}
+	if workspaceID <= 0 {
+		return nil, nil
+	}
+	id := int32(workspaceID)
+	return &id, nil
+}
@@ -1,8 +1,23 @@
 package api
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/audit"
+	"github.com/igorschechtel/clearflow-backend/internal/auth"
+	"github.com/igorschechtel/clearflow-backend/internal/idempotency"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/sirupsen/logrus"
 )
 
 // EnforceHTTPS redirects HTTP requests to HTTPS if the environment is not "local".
@@ -21,3 +36,227 @@ func EnforceHTTPS(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// AuditOrigin adds the request ID and the client IP to the request context,
+// for the audit trail. It must run after middleware.RequestID and middleware.RealIP.
+func AuditOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := audit.WithOrigin(r.Context(), audit.Origin{
+			RequestID: middleware.GetReqID(r.Context()),
+			IP:        audit.ClientIP(r.RemoteAddr),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// AuditActor adds the signed-in user to the request context as the actor of
+// the audit trail. It must run after the Clerk authentication middleware.
+func AuditActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if clerkID, ok := auth.GetUserID(r.Context()); ok {
+			r = r.WithContext(audit.WithActor(r.Context(), audit.User(clerkID)))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type timeoutTimerKey struct{}
+
+// Timeout cancels the context of requests still running after timeout and
+// answers them with 504 Gateway Timeout, as chi's middleware of the same name
+// does. Routes mounted with ExtendTimeout get a deadline of their own instead.
+func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithCancelCause(r.Context())
+			timer := time.AfterFunc(timeout, func() { cancel(context.DeadlineExceeded) })
+			defer func() {
+				timer.Stop()
+				cancel(nil)
+				if context.Cause(ctx) == context.DeadlineExceeded {
+					w.WriteHeader(http.StatusGatewayTimeout)
+				}
+			}()
+
+			ctx = context.WithValue(ctx, timeoutTimerKey{}, timer)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ExtendTimeout gives the requests of a route timeout to complete, counted
+// from when the route is reached, in place of the one set by Timeout. It is
+// meant for responses streamed for longer than other requests take.
+func ExtendTimeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if timer, ok := r.Context().Value(timeoutTimerKey{}).(*time.Timer); ok {
+				timer.Reset(timeout)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAdmin only lets through the users whose Clerk ID is listed.
+func RequireAdmin(clerkIDs []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clerkID, ok := auth.GetUserID(r.Context())
+			if !ok {
+				u.WriteJSONError(w, http.StatusUnauthorized, u.ErrUnauthorized)
+				return
+			}
+			if !slices.Contains(clerkIDs, clerkID) {
+				u.WriteJSONError(w, http.StatusForbidden, u.ErrForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireIfMatch rejects updates and deletes that do not say which version of
+// the resource they apply to, and hands the If-Match header to the services,
+// which fail with a precondition error when the resource was changed since.
+func RequireIfMatch(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("If-Match")
+		if header == "" {
+			u.WriteJSONError(w, http.StatusPreconditionRequired, u.ErrPreconditionRequired)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(u.WithIfMatch(r.Context(), header)))
+	})
+}
+
+// Idempotency replays the stored response of a mutating request retried with
+// the same Idempotency-Key header, and rejects the reuse of a key for another
+// request. Requests without the header are served as usual. Bodies sent with
+// it are read ahead, up to idempotency.MaxBodyBytes, and spooled to disk past
+// idempotency.MaxMemoryBytes. It must run after the Clerk authentication
+// middleware.
+func Idempotency(idempotencyService services.IdempotencyService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotency.Header)
+			clerkID, ok := auth.GetUserID(r.Context())
+			if key == "" || !ok || !idempotency.Applies(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !idempotency.ValidKey(key) {
+				u.WriteJSONError(w, http.StatusBadRequest, u.ErrInvalidIdempotencyKey)
+				return
+			}
+
+			// The body is hashed as it streams in and kept for the handler
+			body := idempotency.NewBody(idempotency.MaxMemoryBytes)
+			defer body.Close()
+			fingerprint, err := idempotency.Fingerprint(r.Method, r.URL.RequestURI(),
+				io.TeeReader(http.MaxBytesReader(w, r.Body, idempotency.MaxBodyBytes), body))
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					u.WriteJSONError(w, http.StatusRequestEntityTooLarge, u.ErrIdempotencyBodyTooLarge)
+					return
+				}
+				u.WriteJSONError(w, http.StatusBadRequest, err)
+				return
+			}
+			reader, err := body.Reader()
+			if err != nil {
+				u.WriteJSONError(w, http.StatusInternalServerError, err)
+				return
+			}
+			r.Body = io.NopCloser(reader)
+
+			entry, err := idempotencyService.Begin(r.Context(), clerkID, key, fingerprint)
+			if err != nil {
+				// Users signing up are not stored yet
+				if err == u.ErrNotFound {
+					next.ServeHTTP(w, r)
+					return
+				}
+				if err == u.ErrIdempotencyKeyReused {
+					u.WriteJSONError(w, http.StatusUnprocessableEntity, err)
+					return
+				}
+				if err == u.ErrIdempotencyKeyInUse {
+					u.WriteJSONError(w, http.StatusConflict, err)
+					return
+				}
+				u.WriteJSONError(w, http.StatusInternalServerError, err)
+				return
+			}
+			if entry.StatusCode != nil {
+				replayResponse(w, entry)
+				return
+			}
+
+			// The response is stored even when the client is gone, for its retry
+			ctx := context.WithoutCancel(r.Context())
+			completed := false
+			defer func() {
+				// Server errors and panics leave the key free for a retry
+				if completed {
+					return
+				}
+				if err := idempotencyService.Release(ctx, entry); err != nil {
+					logrus.WithError(err).Error("failed to release idempotency key")
+				}
+			}()
+
+			var response bytes.Buffer
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&response)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status >= http.StatusInternalServerError {
+				return
+			}
+			statusCode := int32(status)
+			entry.StatusCode = &statusCode
+			entry.ContentType = headerValue(ww.Header(), "Content-Type")
+			entry.Etag = headerValue(ww.Header(), "ETag")
+			entry.Location = headerValue(ww.Header(), "Location")
+			entry.ResponseBody = response.Bytes()
+			if err := idempotencyService.Complete(ctx, entry); err != nil {
+				logrus.WithError(err).Error("failed to store idempotent response")
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// headerValue returns the value of a response header to store, nil when it is
+// not set.
+func headerValue(header http.Header, name string) *string {
+	value := header.Get(name)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// replayResponse writes the response stored for an idempotency key, with the
+// headers clients rely on to follow up on it.
+func replayResponse(w http.ResponseWriter, entry *model.IdempotencyKey) {
+	for name, value := range map[string]*string{
+		"Content-Type": entry.ContentType,
+		"ETag":         entry.Etag,
+		"Location":     entry.Location,
+	} {
+		if value != nil {
+			w.Header().Set(name, *value)
+		}
+	}
+	w.Header().Set(idempotency.ReplayedHeader, "true")
+	w.WriteHeader(int(*entry.StatusCode))
+	w.Write(entry.ResponseBody)
+}
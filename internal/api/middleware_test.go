@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/idempotency"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIdempotencyService keeps the keys in memory, claiming them the way the
+// stored ones are.
+type fakeIdempotencyService struct {
+	mu      sync.Mutex
+	entries map[string]*model.IdempotencyKey
+}
+
+func newFakeIdempotencyService() *fakeIdempotencyService {
+	return &fakeIdempotencyService{entries: map[string]*model.IdempotencyKey{}}
+}
+
+func (s *fakeIdempotencyService) Begin(ctx context.Context, clerkID, key, fingerprint string) (*model.IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.entries[clerkID+key]
+	if !ok {
+		entry := &model.IdempotencyKey{Key: clerkID + key, Fingerprint: fingerprint}
+		s.entries[clerkID+key] = entry
+		copied := *entry
+		return &copied, nil
+	}
+	if stored.Fingerprint != fingerprint {
+		return nil, u.ErrIdempotencyKeyReused
+	}
+	if stored.StatusCode == nil {
+		return nil, u.ErrIdempotencyKeyInUse
+	}
+	copied := *stored
+	return &copied, nil
+}
+
+func (s *fakeIdempotencyService) Complete(ctx context.Context, entry *model.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *entry
+	s.entries[entry.Key] = &copied
+	return nil
+}
+
+func (s *fakeIdempotencyService) Release(ctx context.Context, entry *model.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, entry.Key)
+	return nil
+}
+
+func (s *fakeIdempotencyService) PurgeExpired(ctx context.Context) error {
+	return nil
+}
+
+func idempotentRequest(key, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/expenses", strings.NewReader(body))
+	r.Header.Set(idempotency.Header, key)
+	claims := &clerk.SessionClaims{RegisteredClaims: clerk.RegisteredClaims{Subject: "user_1"}}
+	return r.WithContext(clerk.ContextWithSessionClaims(r.Context(), claims))
+}
+
+func serve(handler http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	var calls atomic.Int32
+	handler := Idempotency(newFakeIdempotencyService())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Location", "/api/v1/expenses/1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	}))
+
+	first := serve(handler, idempotentRequest("key-1", `{"amount":12}`))
+	replayed := serve(handler, idempotentRequest("key-1", `{"amount":12}`))
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(idempotency.ReplayedHeader))
+
+	assert.Equal(t, http.StatusCreated, replayed.Code)
+	assert.Equal(t, `{"id":1}`, replayed.Body.String())
+	assert.Equal(t, "true", replayed.Header().Get(idempotency.ReplayedHeader))
+	for _, name := range []string{"Content-Type", "ETag", "Location"} {
+		assert.Equal(t, first.Header().Get(name), replayed.Header().Get(name), name)
+	}
+}
+
+func TestIdempotencyRejectsReusedKey(t *testing.T) {
+	var calls atomic.Int32
+	handler := Idempotency(newFakeIdempotencyService())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	serve(handler, idempotentRequest("key-1", `{"amount":12}`))
+	reused := serve(handler, idempotentRequest("key-1", `{"amount":13}`))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestIdempotencyRejectsKeyInFlight(t *testing.T) {
+	entered, done := make(chan struct{}), make(chan struct{})
+	handler := Idempotency(newFakeIdempotencyService())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-done
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() {
+		first <- serve(handler, idempotentRequest("key-1", `{"amount":12}`))
+	}()
+	<-entered
+
+	concurrent := serve(handler, idempotentRequest("key-1", `{"amount":12}`))
+	close(done)
+
+	assert.Equal(t, http.StatusConflict, concurrent.Code)
+	assert.Equal(t, http.StatusCreated, (<-first).Code)
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	service := newFakeIdempotencyService()
+	var calls atomic.Int32
+	handler := Idempotency(service)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	failed := serve(handler, idempotentRequest("key-1", `{"amount":12}`))
+	assert.Equal(t, http.StatusInternalServerError, failed.Code)
+	assert.Empty(t, service.entries)
+
+	retried := serve(handler, idempotentRequest("key-1", `{"amount":12}`))
+	assert.Equal(t, http.StatusCreated, retried.Code)
+	assert.Empty(t, retried.Header().Get(idempotency.ReplayedHeader))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestIdempotencyPassesLargeBodies(t *testing.T) {
+	content := strings.Repeat("0123456789", idempotency.MaxMemoryBytes/5)
+	var received string
+	handler := Idempotency(newFakeIdempotencyService())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received = string(body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	response := serve(handler, idempotentRequest("key-1", content))
+
+	assert.Equal(t, http.StatusCreated, response.Code)
+	assert.Equal(t, content, received)
+}
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		extend   bool
+		expected int
+	}{
+		{name: "cut short after the timeout", expected: http.StatusGatewayTimeout},
+		{name: "extended for streamed routes", extend: true, expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(100 * time.Millisecond):
+					w.WriteHeader(http.StatusOK)
+				}
+			})
+			if tt.extend {
+				handler = ExtendTimeout(time.Minute)(handler)
+			}
+			handler = Timeout(10 * time.Millisecond)(handler)
+
+			response := serve(handler, httptest.NewRequest(http.MethodGet, "/api/v1/expenses/export", nil))
+			assert.Equal(t, tt.expected, response.Code)
+		})
+	}
+}
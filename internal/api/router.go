@@ -11,21 +11,49 @@ import (
 	"github.com/igorschechtel/clearflow-backend/internal/api/handlers"
 	"github.com/igorschechtel/clearflow-backend/internal/auth"
 	"github.com/igorschechtel/clearflow-backend/internal/config"
+	"github.com/igorschechtel/clearflow-backend/internal/services"
 	u "github.com/igorschechtel/clearflow-backend/internal/utils"
 	"database/sql"
 	"net/http"
 )
 
+// Time after which requests are cut short
+const requestTimeout = 30 * time.Second
+
+// Time given to streamed exports, which outlast other requests on large ledgers
+const streamTimeout = 10 * time.Minute
+
 type Handlers struct {
 	User         *handlers.UserHandler
+	Deletion     *handlers.AccountDeletionHandler
 	Expense      *handlers.ExpenseHandler
+	Income       *handlers.ExpenseHandler
+	Transfer     *handlers.ExpenseHandler
 	Category     *handlers.CategoryHandler
+	Account      *handlers.AccountHandler
+	Tag          *handlers.TagHandler
+	Merchant     *handlers.MerchantHandler
+	Workspace    *handlers.WorkspaceHandler
+	Contact      *handlers.ContactHandler
+	Settlement   *handlers.SettlementHandler
+	Attachment   *handlers.AttachmentHandler
+	Report       *handlers.ReportHandler
+	Anomaly      *handlers.AnomalyHandler
+	Insight      *handlers.InsightHandler
+	Forecast     *handlers.ForecastHandler
+	Import       *handlers.ImportHandler
+	Archive      *handlers.ArchiveHandler
+	Trash        *handlers.TrashHandler
+	Audit        *handlers.AuditHandler
+	Sync         *handlers.SyncHandler
+	Webhook      *handlers.WebhookHandler
 	ClerkWebhook *handlers.ClerkWebhookHandler
 }
 
 func SetupRouter(
 	cfg *config.Config,
 	handlers *Handlers,
+	idempotencyService services.IdempotencyService,
 	db *sql.DB,
 ) *chi.Mux {
 	r := chi.NewRouter()
@@ -37,8 +65,8 @@ func SetupRouter(
 		AllowedOrigins: cfg.Server.AllowedOrigins,
 		// AllowOriginFunc:  func(r *http.Request, origin string) bool { return true },
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Idempotency-Key", "If-Match", "If-None-Match"},
+		ExposedHeaders:   []string{"Link", "Idempotent-Replayed", "ETag"},
 		AllowCredentials: false,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
@@ -46,6 +74,7 @@ func SetupRouter(
 	// Middlewares
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
+	r.Use(AuditOrigin)
 	if cfg.Env != "local" {
 		r.Use(EnforceHTTPS)
 	}
@@ -55,7 +84,7 @@ func SetupRouter(
 
 	r.Use(u.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(30 * time.Second))
+	r.Use(Timeout(requestTimeout))
 
 	// Health check
 	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
@@ -80,25 +109,184 @@ func SetupRouter(
 		// Clerk Webhook route
 		r.Post("/webhooks/clerk", handlers.ClerkWebhook.Handle)
 
+		// Attachment downloads, authorized by signed links
+		r.Get("/attachments/{id}/content", handlers.Attachment.Content)
+
 		// Protected routes
-		protected := r.With(auth.ClerkAuthMiddleware())
+		protected := r.With(auth.ClerkAuthMiddleware(), AuditActor, Idempotency(idempotencyService))
 
 		// User routes
 		protected.Route("/users", func(r chi.Router) {
 			r.Get("/", handlers.User.List)
 			r.Post("/", handlers.User.Create)
+			r.Get("/me/deletion", handlers.Deletion.Get)
+			r.Post("/me/deletion", handlers.Deletion.Request)
+			r.Delete("/me/deletion", handlers.Deletion.Cancel)
 		})
 
 		// User expense routes
 		protected.Route("/expenses", func(r chi.Router) {
 			r.Get("/", handlers.Expense.ListByUser)
+			r.With(ExtendTimeout(streamTimeout)).Get("/export", handlers.Expense.Export)
 			r.Post("/", handlers.Expense.Create)
+			r.Post("/bulk", handlers.Expense.Bulk)
+			r.Get("/{id}", handlers.Expense.Get)
+			r.With(RequireIfMatch).Put("/{id}", handlers.Expense.Update)
+			r.With(RequireIfMatch).Delete("/{id}", handlers.Expense.Delete)
+			r.Get("/{id}/history", handlers.Expense.History)
+			r.With(RequireIfMatch).Post("/{id}/revert/{revision}", handlers.Expense.Revert)
+			r.Post("/{id}/refunds", handlers.Expense.CreateRefund)
+			r.Get("/{id}/attachments", handlers.Attachment.ListByExpense)
+			r.Post("/{id}/attachments", handlers.Attachment.Create)
+			r.With(RequireIfMatch).Put("/{id}/shares", handlers.Expense.Share)
+			r.With(RequireIfMatch).Delete("/{id}/shares", handlers.Expense.Unshare)
+		})
+
+		// User income routes
+		protected.Route("/income", func(r chi.Router) {
+			r.Get("/", handlers.Income.ListByUser)
+			r.With(ExtendTimeout(streamTimeout)).Get("/export", handlers.Income.Export)
+			r.Post("/", handlers.Income.Create)
+			r.Post("/bulk", handlers.Income.Bulk)
+			r.Get("/{id}", handlers.Income.Get)
+			r.With(RequireIfMatch).Put("/{id}", handlers.Income.Update)
+			r.With(RequireIfMatch).Delete("/{id}", handlers.Income.Delete)
+			r.Get("/{id}/history", handlers.Income.History)
+			r.With(RequireIfMatch).Post("/{id}/revert/{revision}", handlers.Income.Revert)
+		})
+
+		// User transfer routes
+		protected.Route("/transfers", func(r chi.Router) {
+			r.Get("/", handlers.Transfer.ListByUser)
+			r.Post("/", handlers.Transfer.CreateTransfer)
+		})
+
+		// User account routes
+		protected.Route("/accounts", func(r chi.Router) {
+			r.Get("/", handlers.Account.ListByUser)
+			r.Post("/", handlers.Account.Create)
+		})
+
+		// User tag routes
+		protected.Route("/tags", func(r chi.Router) {
+			r.Get("/", handlers.Tag.ListByUser)
+			r.Post("/", handlers.Tag.Create)
+			r.Get("/{id}", handlers.Tag.Get)
+			r.With(RequireIfMatch).Put("/{id}", handlers.Tag.Update)
+			r.With(RequireIfMatch).Delete("/{id}", handlers.Tag.Delete)
+		})
+
+		// User merchant routes
+		protected.Route("/merchants", func(r chi.Router) {
+			r.Get("/", handlers.Merchant.ListByUser)
+			r.Get("/{id}", handlers.Merchant.Get)
+			r.With(RequireIfMatch).Put("/{id}", handlers.Merchant.Update)
+		})
+
+		// User attachment routes
+		protected.With(RequireIfMatch).Delete("/attachments/{id}", handlers.Attachment.Delete)
+
+		// User contact routes
+		protected.Route("/contacts", func(r chi.Router) {
+			r.Get("/", handlers.Contact.ListByUser)
+			r.Post("/", handlers.Contact.Create)
+			r.Get("/{id}", handlers.Contact.Get)
+			r.With(RequireIfMatch).Put("/{id}", handlers.Contact.Update)
+		})
+
+		// User settlement routes
+		protected.Route("/settlements", func(r chi.Router) {
+			r.Get("/", handlers.Settlement.ListByUser)
+			r.Post("/", handlers.Settlement.Create)
+			r.With(RequireIfMatch).Delete("/{id}", handlers.Settlement.Delete)
+			r.Get("/balances", handlers.Settlement.Balances)
+		})
+
+		// User workspace routes
+		protected.Route("/workspaces", func(r chi.Router) {
+			r.Get("/", handlers.Workspace.ListByUser)
+			r.Post("/", handlers.Workspace.Create)
+			r.Get("/{id}/members", handlers.Workspace.ListMembers)
+			r.With(RequireIfMatch).Put("/{id}/members/{userId}", handlers.Workspace.UpdateMember)
+			r.With(RequireIfMatch).Delete("/{id}/members/{userId}", handlers.Workspace.RemoveMember)
+			r.Get("/{id}/invitations", handlers.Workspace.ListInvitations)
+			r.Post("/{id}/invitations", handlers.Workspace.Invite)
+		})
+
+		// User invitation routes
+		protected.Route("/invitations", func(r chi.Router) {
+			r.Get("/", handlers.Workspace.ListOwnInvitations)
+			r.Post("/{id}/accept", handlers.Workspace.AcceptInvitation)
+			r.With(RequireIfMatch).Delete("/{id}", handlers.Workspace.RevokeInvitation)
 		})
 
 		// User category routes
 		protected.Route("/categories", func(r chi.Router) {
 			r.Get("/", handlers.Category.ListByUser)
 			r.Post("/", handlers.Category.Create)
+			r.Get("/{id}", handlers.Category.Get)
+			r.With(RequireIfMatch).Delete("/{id}", handlers.Category.Delete)
+		})
+
+		// User trash routes
+		protected.Route("/trash", func(r chi.Router) {
+			r.Get("/", handlers.Trash.List)
+			r.Post("/expenses/{id}/restore", handlers.Trash.RestoreExpense)
+			r.Post("/categories/{id}/restore", handlers.Trash.RestoreCategory)
+		})
+
+		// User report routes
+		protected.Route("/reports", func(r chi.Router) {
+			r.Get("/summary", handlers.Report.Summary)
+			r.Get("/compare", handlers.Report.Compare)
+			r.Get("/cashflow", handlers.Report.CashFlow)
+		})
+
+		// User forecast route
+		protected.Get("/forecast", handlers.Forecast.Get)
+
+		// User import route
+		protected.Post("/imports", handlers.Import.Create)
+
+		// User archive routes
+		protected.Route("/archive", func(r chi.Router) {
+			r.With(ExtendTimeout(streamTimeout)).Get("/", handlers.Archive.Export)
+			r.Post("/", handlers.Archive.Restore)
+		})
+
+		// User sync routes, for offline clients
+		protected.Route("/sync", func(r chi.Router) {
+			r.Get("/", handlers.Sync.Pull)
+			r.Post("/", handlers.Sync.Push)
+		})
+
+		// User webhook routes
+		protected.Route("/webhooks", func(r chi.Router) {
+			r.Get("/", handlers.Webhook.ListByUser)
+			r.Post("/", handlers.Webhook.Create)
+			r.Get("/{id}", handlers.Webhook.Get)
+			r.With(RequireIfMatch).Put("/{id}", handlers.Webhook.Update)
+			r.With(RequireIfMatch).Delete("/{id}", handlers.Webhook.Delete)
+			r.Get("/{id}/deliveries", handlers.Webhook.ListDeliveries)
+			r.Post("/deliveries/{id}/redeliver", handlers.Webhook.Redeliver)
+		})
+
+		// User audit trail route
+		protected.Get("/audit", handlers.Audit.ListByUser)
+
+		// Admin routes, for the users listed in the config
+		protected.Route("/admin", func(r chi.Router) {
+			r.Use(RequireAdmin(cfg.Admin.ClerkIDs))
+			r.Get("/audit", handlers.Audit.List)
+		})
+
+		// User insight routes
+		protected.Route("/insights", func(r chi.Router) {
+			r.Get("/", handlers.Insight.ListByUser)
+			r.Post("/{id}/read", handlers.Insight.MarkRead)
+			r.Post("/{id}/dismiss", handlers.Insight.Dismiss)
+			r.Get("/anomalies", handlers.Anomaly.ListByUser)
+			r.Post("/anomalies/{id}/dismiss", handlers.Anomaly.Dismiss)
 		})
 
 	})
@@ -0,0 +1,241 @@
+// Package archive packs everything a user owns into a zip file and reads it
+// back, for data portability. An archive holds a manifest, the profile as JSON,
+// one NDJSON file per table and the files of the attachments.
+//
+// Only the personal ledger is archived: workspace ledgers belong to every
+// member. Insights are left out as they are regenerated from the expenses.
+package archive
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+)
+
+// Version of the archive layout, bumped on incompatible changes
+const Version = 1
+
+var (
+	ErrInvalidArchive     = errors.New("invalid archive")
+	ErrUnsupportedVersion = errors.New("unsupported archive version")
+)
+
+const (
+	manifestFile = "manifest.json"
+	profileFile  = "profile.json"
+	// Directory of the attachment files, named after the attachment id
+	filesDir = "files/"
+)
+
+// Manifest describes an archive.
+type Manifest struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exportedAt"`
+}
+
+// Data is everything a user owns, with the ids of the database it was read from.
+type Data struct {
+	Profile       model.User
+	Accounts      []model.Account
+	Categories    []model.Category
+	Tags          []model.Tag
+	Merchants     []model.Merchant
+	Contacts      []model.Contact
+	Expenses      []model.Expense
+	ExpenseTags   []model.ExpenseTag
+	ExpenseSplits []model.ExpenseSplit
+	ExpenseShares []model.ExpenseShare
+	Settlements   []model.Settlement
+	Anomalies     []model.Anomaly
+	Attachments   []model.Attachment
+}
+
+// table is an NDJSON file of an archive, bound to the rows it holds.
+type table struct {
+	name   string
+	encode func(encoder *json.Encoder) error
+	decode func(decoder *json.Decoder) error
+}
+
+func rowsTable[T any](name string, rows *[]T) table {
+	return table{
+		name: name,
+		encode: func(encoder *json.Encoder) error {
+			for _, row := range *rows {
+				if err := encoder.Encode(row); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		decode: func(decoder *json.Decoder) error {
+			for {
+				var row T
+				err := decoder.Decode(&row)
+				if err == io.EOF {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				*rows = append(*rows, row)
+			}
+		},
+	}
+}
+
+// tables lists the NDJSON files of an archive in dependency order: rows only
+// reference rows of earlier tables, or of their own for refunds.
+func (d *Data) tables() []table {
+	return []table{
+		rowsTable("account", &d.Accounts),
+		rowsTable("category", &d.Categories),
+		rowsTable("tag", &d.Tags),
+		rowsTable("merchant", &d.Merchants),
+		rowsTable("contact", &d.Contacts),
+		rowsTable("expense", &d.Expenses),
+		rowsTable("expense_tag", &d.ExpenseTags),
+		rowsTable("expense_split", &d.ExpenseSplits),
+		rowsTable("expense_share", &d.ExpenseShares),
+		rowsTable("settlement", &d.Settlements),
+		rowsTable("anomaly", &d.Anomalies),
+		rowsTable("attachment", &d.Attachments),
+	}
+}
+
+// Writer writes an archive to an underlying writer.
+type Writer struct {
+	zip *zip.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{zip: zip.NewWriter(w)}
+}
+
+// WriteData writes the manifest, the profile and the rows of every table.
+func (w *Writer) WriteData(data *Data, exportedAt time.Time) error {
+	if err := w.writeJSON(manifestFile, Manifest{Version: Version, ExportedAt: exportedAt}); err != nil {
+		return err
+	}
+	if err := w.writeJSON(profileFile, data.Profile); err != nil {
+		return err
+	}
+
+	for _, t := range data.tables() {
+		f, err := w.zip.Create(t.name + ".ndjson")
+		if err != nil {
+			return err
+		}
+		buf := bufio.NewWriter(f)
+		if err := t.encode(json.NewEncoder(buf)); err != nil {
+			return err
+		}
+		if err := buf.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFile writes the content of an attachment.
+func (w *Writer) WriteFile(attachmentID int32, content io.Reader) error {
+	f, err := w.zip.Create(fmt.Sprintf("%s%d", filesDir, attachmentID))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, content)
+	return err
+}
+
+// Close finishes the archive. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	return w.zip.Close()
+}
+
+func (w *Writer) writeJSON(name string, v any) error {
+	f, err := w.zip.Create(name)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(f).Encode(v)
+}
+
+// Reader reads an archive.
+type Reader struct {
+	files map[string]*zip.File
+}
+
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	return &Reader{files: files}, nil
+}
+
+// Data reads the rows of the archive after checking its version.
+func (r *Reader) Data() (*Data, error) {
+	var manifest Manifest
+	if err := r.readJSON(manifestFile, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Version != Version {
+		return nil, ErrUnsupportedVersion
+	}
+
+	data := &Data{}
+	if err := r.readJSON(profileFile, &data.Profile); err != nil {
+		return nil, err
+	}
+	for _, t := range data.tables() {
+		f, ok := r.files[t.name+".ndjson"]
+		if !ok {
+			return nil, fmt.Errorf("%w: missing %s.ndjson", ErrInvalidArchive, t.name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		err = t.decode(json.NewDecoder(rc))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s.ndjson: %v", ErrInvalidArchive, t.name, err)
+		}
+	}
+	return data, nil
+}
+
+// OpenFile opens the content of an attachment.
+func (r *Reader) OpenFile(attachmentID int32) (io.ReadCloser, error) {
+	f, ok := r.files[fmt.Sprintf("%s%d", filesDir, attachmentID)]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing file of attachment %d", ErrInvalidArchive, attachmentID)
+	}
+	return f.Open()
+}
+
+func (r *Reader) readJSON(name string, v any) error {
+	f, ok := r.files[name]
+	if !ok {
+		return fmt.Errorf("%w: missing %s", ErrInvalidArchive, name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := json.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidArchive, name, err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	owner = uuid.MustParse("6f1c7a57-54c3-4c3e-9a3b-0e0d2a4f8b11")
+	at    = time.Date(2026, 9, 3, 12, 30, 15, 123000000, time.UTC)
+	day   = time.Date(2026, 9, 3, 0, 0, 0, 0, time.UTC)
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// sampleData holds a row of every table, referencing each other.
+func sampleData() *Data {
+	return &Data{
+		Profile:    model.User{ID: owner, CreatedAt: at, UpdatedAt: at, ClerkID: "user_1", Email: "ana@example.com", FirstName: ptr("Ana"), Locale: "pt-BR"},
+		Accounts:   []model.Account{{ID: 3, CreatedAt: at, UpdatedAt: at, UserID: owner, Name: "Checking", Type: "checking"}, {ID: 4, CreatedAt: at, UpdatedAt: at, UserID: owner, Name: "Savings", Type: "savings"}},
+		Categories: []model.Category{{ID: 10, CreatedAt: at, UpdatedAt: at, UserID: owner, Name: "Food", ColorHex: "#ff0000", Kind: "expense"}, {ID: 11, CreatedAt: at, UpdatedAt: at, UserID: owner, Name: "Home", ColorHex: "#00ff00", Kind: "expense"}},
+		Tags:       []model.Tag{{ID: 20, CreatedAt: at, UpdatedAt: at, UserID: owner, Name: "work"}},
+		Merchants:  []model.Merchant{{ID: 30, CreatedAt: at, UpdatedAt: at, UserID: owner, Key: "uber trip", Name: "Uber"}},
+		Contacts:   []model.Contact{{ID: 40, CreatedAt: at, UpdatedAt: at, UserID: owner, Name: "Bia", Email: ptr("bia@example.com")}},
+		Expenses: []model.Expense{
+			{ID: 50, CreatedAt: at, UpdatedAt: at, UserID: owner, Amount: 120.5, PurchaseDate: day, BillDate: day, Description: "UBER *TRIP", CategoryID: ptr(int32(10)), Kind: "expense", AccountID: ptr(int32(3)), PaidByContactID: ptr(int32(40)), MerchantID: ptr(int32(30))},
+			{ID: 51, CreatedAt: at, UpdatedAt: at, UserID: owner, Amount: 20, PurchaseDate: day, BillDate: day, Description: "UBER *TRIP", Kind: "refund", RefundOfID: ptr(int32(50))},
+			{ID: 52, CreatedAt: at, UpdatedAt: at, UserID: owner, Amount: 500, PurchaseDate: day, BillDate: day, Description: "Savings", Kind: "transfer", AccountID: ptr(int32(3)), TransferAccountID: ptr(int32(4))},
+		},
+		ExpenseTags:   []model.ExpenseTag{{ExpenseID: 50, TagID: 20}},
+		ExpenseSplits: []model.ExpenseSplit{{ID: 60, CreatedAt: at, UpdatedAt: at, ExpenseID: 50, CategoryID: 10, Amount: 100.5}, {ID: 61, CreatedAt: at, UpdatedAt: at, ExpenseID: 50, CategoryID: 11, Amount: 20, Note: ptr("tip")}},
+		ExpenseShares: []model.ExpenseShare{{ID: 70, CreatedAt: at, UpdatedAt: at, ExpenseID: 50, Amount: 60.25}, {ID: 71, CreatedAt: at, UpdatedAt: at, ExpenseID: 50, ContactID: ptr(int32(40)), Amount: 60.25}},
+		Settlements:   []model.Settlement{{ID: 80, CreatedAt: at, UpdatedAt: at, UserID: owner, FromContactID: ptr(int32(40)), Amount: 30, Date: day, Note: ptr("pix")}},
+		Anomalies:     []model.Anomaly{{ID: 90, CreatedAt: at, UpdatedAt: at, UserID: owner, ExpenseID: 50, Kind: "large_amount", Score: 4.2, Reason: "large", DismissedAt: ptr(at)}},
+		Attachments:   []model.Attachment{{ID: 95, CreatedAt: at, UpdatedAt: at, ExpenseID: 50, UserID: owner, StorageKey: "attachments/50/a.pdf", FileName: "receipt.pdf", ContentType: "application/pdf", SizeBytes: 4}},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	data := sampleData()
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	require.NoError(t, w.WriteData(data, at))
+	require.NoError(t, w.WriteFile(95, bytes.NewReader([]byte("%PDF"))))
+	require.NoError(t, w.Close())
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	read, err := r.Data()
+	require.NoError(t, err)
+	assert.Equal(t, data, read)
+
+	file, err := r.OpenFile(95)
+	require.NoError(t, err)
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF", string(content))
+
+	_, err = r.OpenFile(96)
+	assert.ErrorIs(t, err, ErrInvalidArchive)
+}
+
+func TestReadRejectsInvalidArchives(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("not a zip")), 9)
+	assert.ErrorIs(t, err, ErrInvalidArchive)
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	f, err := w.zip.Create(manifestFile)
+	require.NoError(t, err)
+	require.NoError(t, json.NewEncoder(f).Encode(Manifest{Version: Version + 1}))
+	require.NoError(t, w.Close())
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	_, err = r.Data()
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+}
+
+// allocate returns ids starting at base for every table of data.
+func allocate(data *Data, base int32) map[string][]int32 {
+	ids := map[string][]int32{}
+	for table, n := range data.Counts() {
+		for i := 0; i < n; i++ {
+			ids[table] = append(ids[table], base+int32(i))
+		}
+	}
+	return ids
+}
+
+func TestRemap(t *testing.T) {
+	data := sampleData()
+	newOwner := uuid.MustParse("0b5e0a52-1f59-4a8a-8d0f-6f3c4c2b9a77")
+	require.NoError(t, data.Remap(newOwner, allocate(data, 1000)))
+
+	refund, original, transfer := data.Expenses[1], data.Expenses[0], data.Expenses[2]
+	assert.Equal(t, int32(1000), original.ID)
+	assert.Equal(t, newOwner, original.UserID)
+	assert.Equal(t, ptr(int32(1000)), original.CategoryID)
+	assert.Equal(t, ptr(int32(1000)), original.AccountID)
+	assert.Equal(t, ptr(int32(1000)), original.PaidByContactID)
+	assert.Equal(t, ptr(int32(1000)), original.MerchantID)
+	assert.Equal(t, &original.ID, refund.RefundOfID)
+	assert.Equal(t, ptr(int32(1001)), transfer.TransferAccountID)
+	assert.Equal(t, model.ExpenseTag{ExpenseID: 1000, TagID: 1000}, data.ExpenseTags[0])
+	assert.Equal(t, int32(1001), data.ExpenseSplits[1].CategoryID)
+	assert.Nil(t, data.ExpenseShares[0].ContactID)
+	assert.Equal(t, ptr(int32(1000)), data.ExpenseShares[1].ContactID)
+	assert.Equal(t, ptr(int32(1000)), data.Settlements[0].FromContactID)
+	assert.Nil(t, data.Settlements[0].ToContactID)
+	assert.Equal(t, int32(1000), data.Anomalies[0].ExpenseID)
+	assert.Equal(t, newOwner, data.Attachments[0].UserID)
+
+	// Remapping back to the original ids restores every row as it was
+	want := sampleData()
+	back := map[string][]int32{
+		"account": {3, 4}, "category": {10, 11}, "tag": {20}, "merchant": {30}, "contact": {40},
+		"expense": {50, 51, 52}, "expense_split": {60, 61}, "expense_share": {70, 71},
+		"settlement": {80}, "anomaly": {90}, "attachment": {95},
+	}
+	require.NoError(t, data.Remap(owner, back))
+	assert.Equal(t, want, data)
+}
+
+func TestRemapRejectsBrokenArchives(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(d *Data)
+	}{
+		{name: "unknown category", modify: func(d *Data) { d.Expenses[0].CategoryID = ptr(int32(99)) }},
+		{name: "unknown refunded expense", modify: func(d *Data) { d.Expenses[1].RefundOfID = ptr(int32(99)) }},
+		{name: "unknown tag", modify: func(d *Data) { d.ExpenseTags[0].TagID = 99 }},
+		{name: "unknown contact", modify: func(d *Data) { d.Settlements[0].ToContactID = ptr(int32(99)) }},
+		{name: "duplicate id", modify: func(d *Data) { d.Accounts[1].ID = d.Accounts[0].ID }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := sampleData()
+			tt.modify(data)
+			assert.ErrorIs(t, data.Remap(owner, allocate(data, 1000)), ErrInvalidArchive)
+		})
+	}
+}
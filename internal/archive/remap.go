@@ -0,0 +1,168 @@
+package archive
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// idMap maps the ids of an archive to the ids allocated for a restore.
+type idMap map[int32]int32
+
+func newIDMap(table string, old []int32, allocated []int32) (idMap, error) {
+	if len(allocated) != len(old) {
+		return nil, fmt.Errorf("%d ids allocated for %d %s rows", len(allocated), len(old), table)
+	}
+	m := make(idMap, len(old))
+	for i, id := range old {
+		if _, ok := m[id]; ok {
+			return nil, fmt.Errorf("%w: duplicate %s %d", ErrInvalidArchive, table, id)
+		}
+		m[id] = allocated[i]
+	}
+	return m, nil
+}
+
+// Counts returns the number of rows of each table with a serial id, which is
+// how many ids to allocate before restoring the archive.
+func (d *Data) Counts() map[string]int {
+	return map[string]int{
+		"account":       len(d.Accounts),
+		"category":      len(d.Categories),
+		"tag":           len(d.Tags),
+		"merchant":      len(d.Merchants),
+		"contact":       len(d.Contacts),
+		"expense":       len(d.Expenses),
+		"expense_split": len(d.ExpenseSplits),
+		"expense_share": len(d.ExpenseShares),
+		"settlement":    len(d.Settlements),
+		"anomaly":       len(d.Anomalies),
+		"attachment":    len(d.Attachments),
+	}
+}
+
+// Remap moves the rows of the archive to a new owner and new ids. ids holds
+// the new ids of each table of Counts, in row order. References are remapped
+// along, and a reference to a row missing from the archive is an error. Rows
+// of the personal ledger never belong to a workspace.
+func (d *Data) Remap(userID uuid.UUID, ids map[string][]int32) error {
+	m := &remapper{}
+
+	accounts := m.ids("account", ids, len(d.Accounts), func(i int) *int32 { return &d.Accounts[i].ID })
+	categories := m.ids("category", ids, len(d.Categories), func(i int) *int32 { return &d.Categories[i].ID })
+	tags := m.ids("tag", ids, len(d.Tags), func(i int) *int32 { return &d.Tags[i].ID })
+	merchants := m.ids("merchant", ids, len(d.Merchants), func(i int) *int32 { return &d.Merchants[i].ID })
+	contacts := m.ids("contact", ids, len(d.Contacts), func(i int) *int32 { return &d.Contacts[i].ID })
+	expenses := m.ids("expense", ids, len(d.Expenses), func(i int) *int32 { return &d.Expenses[i].ID })
+	m.ids("expense_split", ids, len(d.ExpenseSplits), func(i int) *int32 { return &d.ExpenseSplits[i].ID })
+	m.ids("expense_share", ids, len(d.ExpenseShares), func(i int) *int32 { return &d.ExpenseShares[i].ID })
+	m.ids("settlement", ids, len(d.Settlements), func(i int) *int32 { return &d.Settlements[i].ID })
+	m.ids("anomaly", ids, len(d.Anomalies), func(i int) *int32 { return &d.Anomalies[i].ID })
+	m.ids("attachment", ids, len(d.Attachments), func(i int) *int32 { return &d.Attachments[i].ID })
+	if m.err != nil {
+		return m.err
+	}
+
+	for i := range d.Accounts {
+		d.Accounts[i].UserID = userID
+	}
+	for i := range d.Categories {
+		d.Categories[i].UserID = userID
+		d.Categories[i].WorkspaceID = nil
+	}
+	for i := range d.Tags {
+		d.Tags[i].UserID = userID
+	}
+	for i := range d.Merchants {
+		d.Merchants[i].UserID = userID
+	}
+	for i := range d.Contacts {
+		d.Contacts[i].UserID = userID
+	}
+	for i := range d.Expenses {
+		e := &d.Expenses[i]
+		e.UserID = userID
+		e.WorkspaceID = nil
+		m.optional("category", categories, &e.CategoryID)
+		m.optional("expense", expenses, &e.RefundOfID)
+		m.optional("account", accounts, &e.AccountID)
+		m.optional("account", accounts, &e.TransferAccountID)
+		m.optional("contact", contacts, &e.PaidByContactID)
+		m.optional("merchant", merchants, &e.MerchantID)
+	}
+	for i := range d.ExpenseTags {
+		m.required("expense", expenses, &d.ExpenseTags[i].ExpenseID)
+		m.required("tag", tags, &d.ExpenseTags[i].TagID)
+	}
+	for i := range d.ExpenseSplits {
+		m.required("expense", expenses, &d.ExpenseSplits[i].ExpenseID)
+		m.required("category", categories, &d.ExpenseSplits[i].CategoryID)
+	}
+	for i := range d.ExpenseShares {
+		m.required("expense", expenses, &d.ExpenseShares[i].ExpenseID)
+		m.optional("contact", contacts, &d.ExpenseShares[i].ContactID)
+	}
+	for i := range d.Settlements {
+		s := &d.Settlements[i]
+		s.UserID = userID
+		m.optional("contact", contacts, &s.FromContactID)
+		m.optional("contact", contacts, &s.ToContactID)
+	}
+	for i := range d.Anomalies {
+		d.Anomalies[i].UserID = userID
+		m.required("expense", expenses, &d.Anomalies[i].ExpenseID)
+	}
+	for i := range d.Attachments {
+		d.Attachments[i].UserID = userID
+		m.required("expense", expenses, &d.Attachments[i].ExpenseID)
+	}
+	return m.err
+}
+
+// remapper remaps ids and keeps the first error, so that Remap reads as a
+// plain list of references.
+type remapper struct {
+	err error
+}
+
+// ids replaces the ids of a table with the allocated ones and returns the
+// mapping between them.
+func (m *remapper) ids(table string, allocated map[string][]int32, n int, id func(i int) *int32) idMap {
+	if m.err != nil {
+		return nil
+	}
+	old := make([]int32, n)
+	for i := range old {
+		old[i] = *id(i)
+	}
+	mapping, err := newIDMap(table, old, allocated[table])
+	if err != nil {
+		m.err = err
+		return nil
+	}
+	for i := range old {
+		*id(i) = mapping[old[i]]
+	}
+	return mapping
+}
+
+func (m *remapper) required(table string, mapping idMap, ref *int32) {
+	if m.err != nil {
+		return
+	}
+	id, ok := mapping[*ref]
+	if !ok {
+		m.err = fmt.Errorf("%w: unknown %s %d", ErrInvalidArchive, table, *ref)
+		return
+	}
+	*ref = id
+}
+
+func (m *remapper) optional(table string, mapping idMap, ref **int32) {
+	if *ref == nil {
+		return
+	}
+	id := **ref
+	m.required(table, mapping, &id)
+	*ref = &id
+}
@@ -0,0 +1,100 @@
+// Package audit carries who performs an operation and where the request comes
+// from, so that the services can record sensitive operations without knowing
+// about HTTP.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+)
+
+// Actor types
+const (
+	ActorUser    = "user"
+	ActorWebhook = "webhook"
+	// Background jobs, and any code running without an actor in its context
+	ActorSystem = "system"
+)
+
+// Actor is who performs an operation.
+type Actor struct {
+	Type string
+	// Clerk ID of a user or source of a webhook, empty for the system
+	ID string
+}
+
+// Origin is the request an operation comes from.
+type Origin struct {
+	RequestID string
+	IP        string
+}
+
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	originKey
+)
+
+// User is the actor for a signed-in user.
+func User(clerkID string) Actor {
+	return Actor{Type: ActorUser, ID: clerkID}
+}
+
+// Webhook is the actor for a webhook of the given source.
+func Webhook(source string) Actor {
+	return Actor{Type: ActorWebhook, ID: source}
+}
+
+// WithActor returns a copy of ctx carrying the actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// ActorFrom returns the actor carried by ctx, the system when none is.
+func ActorFrom(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorKey).(Actor); ok {
+		return actor
+	}
+	return Actor{Type: ActorSystem}
+}
+
+// WithOrigin returns a copy of ctx carrying the origin.
+func WithOrigin(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, originKey, origin)
+}
+
+// OriginFrom returns the origin carried by ctx, empty outside of requests.
+func OriginFrom(ctx context.Context) Origin {
+	origin, _ := ctx.Value(originKey).(Origin)
+	return origin
+}
+
+// ClientIP returns the address of a remote address without its port.
+func ClientIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// Snapshot encodes the state of an entity as JSON. Nil values, including nil
+// pointers, have no snapshot.
+func Snapshot(v any) (*string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := string(data)
+	return &snapshot, nil
+}
@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActorFrom(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want Actor
+	}{
+		{"none is the system", context.Background(), Actor{Type: ActorSystem}},
+		{"user", WithActor(context.Background(), User("user_2abc")), Actor{Type: ActorUser, ID: "user_2abc"}},
+		{"webhook", WithActor(context.Background(), Webhook("clerk")), Actor{Type: ActorWebhook, ID: "clerk"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ActorFrom(tt.ctx))
+		})
+	}
+}
+
+func TestOriginFrom(t *testing.T) {
+	assert.Equal(t, Origin{}, OriginFrom(context.Background()))
+
+	origin := Origin{RequestID: "host/abc-000001", IP: "203.0.113.7"}
+	assert.Equal(t, origin, OriginFrom(WithOrigin(context.Background(), origin)))
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		remoteAddr string
+		want       string
+	}{
+		{"203.0.113.7:52100", "203.0.113.7"},
+		{"[2001:db8::1]:443", "2001:db8::1"},
+		// RealIP replaces the remote address with a bare IP
+		{"203.0.113.7", "203.0.113.7"},
+		{"2001:db8::1", "2001:db8::1"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.remoteAddr, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClientIP(tt.remoteAddr))
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	type entity struct {
+		ID   int32
+		Name string
+	}
+	var missing *entity
+
+	tests := []struct {
+		name  string
+		value any
+		want  *string
+	}{
+		{"nil", nil, nil},
+		{"nil pointer", missing, nil},
+		{"struct", entity{ID: 1, Name: "Food"}, ptr(`{"ID":1,"Name":"Food"}`)},
+		{"pointer", &entity{ID: 2, Name: "Home"}, ptr(`{"ID":2,"Name":"Home"}`)},
+		{"map", map[string]int{"rows": 3}, ptr(`{"rows":3}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Snapshot(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -6,15 +6,24 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	Clerk    ClerkConfig
-	Env      string
+	Database    DatabaseConfig
+	Server      ServerConfig
+	Clerk       ClerkConfig
+	Jobs        JobsConfig
+	Storage     StorageConfig
+	Account     AccountConfig
+	Trash       TrashConfig
+	Admin       AdminConfig
+	Idempotency IdempotencyConfig
+	Sync        SyncConfig
+	Webhooks    WebhooksConfig
+	Env         string
 }
 
 type DatabaseConfig struct {
@@ -31,6 +40,80 @@ type ClerkConfig struct {
 	WebhookSecret string
 }
 
+type JobsConfig struct {
+	InsightsInterval time.Duration
+	// How often expenses without a merchant are assigned one
+	MerchantsInterval time.Duration
+	// How often accounts past their deletion grace period are purged
+	AccountPurgeInterval time.Duration
+	// How often expired items are purged from the trash
+	TrashPurgeInterval time.Duration
+	// How often expired idempotency keys are purged
+	IdempotencyPurgeInterval time.Duration
+	// How often expired sync tombstones are purged
+	TombstonePurgeInterval time.Duration
+	// How often due webhook deliveries are attempted
+	WebhookDeliveryInterval time.Duration
+	// How often old webhook deliveries are purged
+	WebhookPurgeInterval time.Duration
+}
+
+type AccountConfig struct {
+	// Time between a deletion request and the purge of the account data
+	DeletionGracePeriod time.Duration
+}
+
+type TrashConfig struct {
+	// Time deleted expenses and categories stay restorable
+	Retention time.Duration
+}
+
+type IdempotencyConfig struct {
+	// Time a key keeps replaying its response
+	KeyTTL time.Duration
+}
+
+type SyncConfig struct {
+	// Time deletions are remembered for clients to sync, and sync tokens stay valid
+	TombstoneRetention time.Duration
+}
+
+type WebhooksConfig struct {
+	// Time a delivery is attempted for before the endpoint counts as down
+	Timeout time.Duration
+	// Time deliveries and their attempts are kept for users to inspect
+	DeliveryRetention time.Duration
+	// Whether endpoints may resolve to loopback and private addresses, for local development
+	AllowPrivateNetworks bool
+}
+
+type AdminConfig struct {
+	// Clerk IDs of the users allowed on the admin routes, nobody when empty
+	ClerkIDs []string
+}
+
+type StorageConfig struct {
+	// Blob store backend, "local" or "s3"
+	Backend  string
+	LocalDir string
+	S3       S3Config
+	// Secret signing attachment download links. Required outside the local
+	// environment, random per process when empty in it
+	URLSecret string
+	URLExpiry time.Duration
+	// Public base URL prefixed to download links, relative links when empty
+	PublicURL          string
+	MaxAttachmentBytes int64
+}
+
+type S3Config struct {
+	Endpoint        string
+	Bucket          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
 func Load() (*Config, error) {
 	// Load .env file if it exists
 	godotenv.Load()
@@ -57,11 +140,151 @@ func Load() (*Config, error) {
 		WebhookSecret: getEnv("CLERK_WEBHOOK_SECRET", ""),
 	}
 
+	insightsInterval, err := time.ParseDuration(getEnv("INSIGHTS_REFRESH_INTERVAL", "6h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid INSIGHTS_REFRESH_INTERVAL: %w", err)
+	}
+	merchantsInterval, err := time.ParseDuration(getEnv("MERCHANT_ASSIGN_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MERCHANT_ASSIGN_INTERVAL: %w", err)
+	}
+	accountPurgeInterval, err := time.ParseDuration(getEnv("ACCOUNT_PURGE_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ACCOUNT_PURGE_INTERVAL: %w", err)
+	}
+	trashPurgeInterval, err := time.ParseDuration(getEnv("TRASH_PURGE_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRASH_PURGE_INTERVAL: %w", err)
+	}
+	idempotencyPurgeInterval, err := time.ParseDuration(getEnv("IDEMPOTENCY_PURGE_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_PURGE_INTERVAL: %w", err)
+	}
+	tombstonePurgeInterval, err := time.ParseDuration(getEnv("TOMBSTONE_PURGE_INTERVAL", "24h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOMBSTONE_PURGE_INTERVAL: %w", err)
+	}
+	webhookDeliveryInterval, err := time.ParseDuration(getEnv("WEBHOOK_DELIVERY_INTERVAL", "15s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_DELIVERY_INTERVAL: %w", err)
+	}
+	webhookPurgeInterval, err := time.ParseDuration(getEnv("WEBHOOK_PURGE_INTERVAL", "24h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_PURGE_INTERVAL: %w", err)
+	}
+	jobsConfig := JobsConfig{
+		InsightsInterval:         insightsInterval,
+		MerchantsInterval:        merchantsInterval,
+		AccountPurgeInterval:     accountPurgeInterval,
+		TrashPurgeInterval:       trashPurgeInterval,
+		IdempotencyPurgeInterval: idempotencyPurgeInterval,
+		TombstonePurgeInterval:   tombstonePurgeInterval,
+		WebhookDeliveryInterval:  webhookDeliveryInterval,
+		WebhookPurgeInterval:     webhookPurgeInterval,
+	}
+
+	deletionGracePeriod, err := time.ParseDuration(getEnv("ACCOUNT_DELETION_GRACE_PERIOD", "720h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ACCOUNT_DELETION_GRACE_PERIOD: %w", err)
+	}
+	accountConfig := AccountConfig{
+		DeletionGracePeriod: deletionGracePeriod,
+	}
+
+	trashRetention, err := time.ParseDuration(getEnv("TRASH_RETENTION", "720h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRASH_RETENTION: %w", err)
+	}
+	trashConfig := TrashConfig{
+		Retention: trashRetention,
+	}
+
+	idempotencyKeyTTL, err := time.ParseDuration(getEnv("IDEMPOTENCY_KEY_TTL", "24h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid IDEMPOTENCY_KEY_TTL: %w", err)
+	}
+	idempotencyConfig := IdempotencyConfig{
+		KeyTTL: idempotencyKeyTTL,
+	}
+
+	tombstoneRetention, err := time.ParseDuration(getEnv("SYNC_TOMBSTONE_RETENTION", "2160h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SYNC_TOMBSTONE_RETENTION: %w", err)
+	}
+	syncConfig := SyncConfig{
+		TombstoneRetention: tombstoneRetention,
+	}
+
+	webhookTimeout, err := time.ParseDuration(getEnv("WEBHOOK_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_TIMEOUT: %w", err)
+	}
+	webhookDeliveryRetention, err := time.ParseDuration(getEnv("WEBHOOK_DELIVERY_RETENTION", "720h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_DELIVERY_RETENTION: %w", err)
+	}
+	webhooksConfig := WebhooksConfig{
+		Timeout:              webhookTimeout,
+		DeliveryRetention:    webhookDeliveryRetention,
+		AllowPrivateNetworks: getEnv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "false") == "true",
+	}
+
+	var adminClerkIDs []string
+	for _, id := range strings.Split(getEnv("ADMIN_CLERK_IDS", ""), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			adminClerkIDs = append(adminClerkIDs, id)
+		}
+	}
+	adminConfig := AdminConfig{
+		ClerkIDs: adminClerkIDs,
+	}
+
+	env := getEnv("ENV", "development")
+
+	urlExpiry, err := time.ParseDuration(getEnv("ATTACHMENT_URL_EXPIRY", "15m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ATTACHMENT_URL_EXPIRY: %w", err)
+	}
+	maxAttachmentBytes, err := strconv.ParseInt(getEnv("ATTACHMENT_MAX_BYTES", "10485760"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ATTACHMENT_MAX_BYTES: %w", err)
+	}
+	storageConfig := StorageConfig{
+		Backend:  getEnv("STORAGE_BACKEND", "local"),
+		LocalDir: getEnv("STORAGE_LOCAL_DIR", "./data/blobs"),
+		S3: S3Config{
+			Endpoint:        getEnv("S3_ENDPOINT", ""),
+			Bucket:          getEnv("S3_BUCKET", ""),
+			Region:          getEnv("S3_REGION", "us-east-1"),
+			AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+			SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+		},
+		URLSecret:          getEnv("ATTACHMENT_URL_SECRET", ""),
+		URLExpiry:          urlExpiry,
+		PublicURL:          strings.TrimSuffix(getEnv("PUBLIC_URL", ""), "/"),
+		MaxAttachmentBytes: maxAttachmentBytes,
+	}
+	if storageConfig.Backend != "local" && storageConfig.Backend != "s3" {
+		return nil, fmt.Errorf("invalid STORAGE_BACKEND %q: expected local or s3", storageConfig.Backend)
+	}
+	// Instances must share the secret for the links of one to work on another
+	if storageConfig.URLSecret == "" && env != "local" {
+		return nil, fmt.Errorf("ATTACHMENT_URL_SECRET environment variable is required outside the local environment")
+	}
+
 	return &Config{
-		Database: dbConfig,
-		Server:   serverConfig,
-		Clerk:    clerkConfig,
-		Env:      getEnv("ENV", "development"),
+		Database:    dbConfig,
+		Server:      serverConfig,
+		Clerk:       clerkConfig,
+		Jobs:        jobsConfig,
+		Storage:     storageConfig,
+		Account:     accountConfig,
+		Trash:       trashConfig,
+		Admin:       adminConfig,
+		Idempotency: idempotencyConfig,
+		Sync:        syncConfig,
+		Webhooks:    webhooksConfig,
+		Env:         env,
 	}, nil
 }
 
@@ -0,0 +1,65 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+// Byte order mark that makes spreadsheet applications read the file as UTF-8
+const utf8BOM = "\ufeff"
+
+type csvWriter struct {
+	w       *csv.Writer
+	decimal string
+}
+
+// newCSVWriter writes the header of a CSV file. Locales with a decimal comma
+// separate fields with semicolons, as spreadsheet applications expect.
+func newCSVWriter(w io.Writer, locale string) (*csvWriter, error) {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return nil, err
+	}
+
+	cw := &csvWriter{w: csv.NewWriter(w), decimal: u.DecimalSeparator(locale)}
+	if cw.decimal == "," {
+		cw.w.Comma = ';'
+	}
+	if err := cw.w.Write(columns); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(row Row) error {
+	amount := strconv.FormatFloat(row.Amount, 'f', 2, 64)
+	return cw.w.Write([]string{
+		row.ID.String(),
+		row.PurchaseDate.Format(dateLayout),
+		row.BillDate.Format(dateLayout),
+		row.Kind,
+		escapeFormula(row.Description),
+		escapeFormula(row.Merchant),
+		escapeFormula(row.Category),
+		escapeFormula(row.Account),
+		escapeFormula(strings.Join(row.Tags, ", ")),
+		strings.Replace(amount, ".", cw.decimal, 1),
+	})
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// escapeFormula keeps spreadsheet applications from evaluating user text
+// as a formula.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,79 @@
+// Package export writes transactions as CSV, XLSX or JSON files. Writers
+// stream: each row is written out as it comes, so that exporting a long
+// history does not hold it in memory.
+package export
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Supported file formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatJSON = "json"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Row is one exported transaction. Names are empty when the transaction does
+// not reference the entity.
+type Row struct {
+	// Public ID of the transaction
+	ID           uuid.UUID
+	PurchaseDate time.Time
+	BillDate     time.Time
+	Kind         string
+	Description  string
+	Merchant     string
+	Category     string
+	Account      string
+	Tags         []string
+	Amount       float64
+}
+
+// Column headers shared by the tabular formats
+var columns = []string{
+	"id", "purchase_date", "bill_date", "kind", "description",
+	"merchant", "category", "account", "tags", "amount",
+}
+
+const dateLayout = "2006-01-02"
+
+// Writer streams rows into a file.
+type Writer interface {
+	Write(row Row) error
+	// Close completes the file. It does not close the underlying writer.
+	Close() error
+}
+
+// NewWriter starts a file of the given format on w. Numbers of CSV files use
+// the separators of locale, while XLSX and JSON store them as numbers.
+func NewWriter(format string, w io.Writer, locale string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, locale)
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	case FormatJSON:
+		return newJSONWriter(w)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// ContentType returns the media type of files of a format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatJSON:
+		return "application/json"
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,128 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRows = []Row{
+	{
+		ID:           uuid.MustParse("0190a6c2-7b1e-7c3a-9d41-5e2f8a6b1c07"),
+		PurchaseDate: time.Date(2026, 9, 3, 0, 0, 0, 0, time.UTC),
+		BillDate:     time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC),
+		Kind:         "expense",
+		Description:  "Padaria São João",
+		Merchant:     "Padaria Sao Joao",
+		Category:     "Food",
+		Tags:         []string{"breakfast", "work"},
+		Amount:       1234.5,
+	},
+	{
+		ID:           uuid.MustParse("0190a6c2-7b1e-7c3a-9d41-5e2f8a6b1c08"),
+		PurchaseDate: time.Date(2026, 9, 4, 0, 0, 0, 0, time.UTC),
+		BillDate:     time.Date(2026, 9, 4, 0, 0, 0, 0, time.UTC),
+		Kind:         "income",
+		Description:  "=HYPERLINK(\"x\")",
+		Account:      "Checking",
+		Amount:       10,
+	},
+}
+
+func write(t *testing.T, format, locale string) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf, locale)
+	require.NoError(t, err)
+	for _, row := range testRows {
+		require.NoError(t, w.Write(row))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		locale   string
+		expected string
+	}{
+		{
+			name:   "decimal point",
+			locale: "en-US",
+			expected: "\ufeffid,purchase_date,bill_date,kind,description,merchant,category,account,tags,amount\n" +
+				"0190a6c2-7b1e-7c3a-9d41-5e2f8a6b1c07,2026-09-03,2026-10-10,expense,Padaria São João,Padaria Sao Joao,Food,,\"breakfast, work\",1234.50\n" +
+				"0190a6c2-7b1e-7c3a-9d41-5e2f8a6b1c08,2026-09-04,2026-09-04,income,\"'=HYPERLINK(\"\"x\"\")\",,,Checking,,10.00\n",
+		},
+		{
+			name:   "decimal comma",
+			locale: "pt-BR",
+			expected: "\ufeffid;purchase_date;bill_date;kind;description;merchant;category;account;tags;amount\n" +
+				"0190a6c2-7b1e-7c3a-9d41-5e2f8a6b1c07;2026-09-03;2026-10-10;expense;Padaria São João;Padaria Sao Joao;Food;;breakfast, work;1234,50\n" +
+				"0190a6c2-7b1e-7c3a-9d41-5e2f8a6b1c08;2026-09-04;2026-09-04;income;\"'=HYPERLINK(\"\"x\"\")\";;;Checking;;10,00\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, string(write(t, FormatCSV, tt.locale)))
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var rows []map[string]any
+	require.NoError(t, json.Unmarshal(write(t, FormatJSON, "pt-BR"), &rows))
+	require.Len(t, rows, 2)
+	assert.Equal(t, "0190a6c2-7b1e-7c3a-9d41-5e2f8a6b1c07", rows[0]["id"])
+	assert.Equal(t, "2026-09-03", rows[0]["purchaseDate"])
+	assert.Equal(t, 1234.5, rows[0]["amount"])
+	assert.Equal(t, []any{"breakfast", "work"}, rows[0]["tags"])
+	assert.Equal(t, []any{}, rows[1]["tags"])
+
+	var empty bytes.Buffer
+	w, err := NewWriter(FormatJSON, &empty, "en-US")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.Equal(t, "[]\n", empty.String())
+}
+
+func TestXLSX(t *testing.T) {
+	data := write(t, FormatXLSX, "en-US")
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	parts := map[string]string{}
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		parts[f.Name] = string(content)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		assert.Contains(t, parts, name)
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Equal(t, 3, strings.Count(sheet, "<row "))
+	// 2026-09-03 is day 46268 of the spreadsheet calendar
+	assert.Contains(t, sheet, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">0190a6c2-7b1e-7c3a-9d41-5e2f8a6b1c07</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="B2" s="1"><v>46268</v></c>`)
+	assert.Contains(t, sheet, `<c r="J2" s="2"><v>1234.50</v></c>`)
+	assert.Contains(t, sheet, `<c r="E3" t="inlineStr"><is><t xml:space="preserve">=HYPERLINK(&#34;x&#34;)</t></is></c>`)
+	assert.True(t, strings.HasSuffix(sheet, "</sheetData></worksheet>"))
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := NewWriter("pdf", io.Discard, "en-US")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -0,0 +1,70 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/google/uuid"
+)
+
+type jsonRow struct {
+	ID           uuid.UUID `json:"id"`
+	PurchaseDate string    `json:"purchaseDate"`
+	BillDate     string    `json:"billDate"`
+	Kind         string    `json:"kind"`
+	Description  string    `json:"description"`
+	Merchant     string    `json:"merchant"`
+	Category     string    `json:"category"`
+	Account      string    `json:"account"`
+	Tags         []string  `json:"tags"`
+	Amount       float64   `json:"amount"`
+}
+
+// jsonWriter writes a JSON array one element at a time.
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONWriter(w io.Writer) (*jsonWriter, error) {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return nil, err
+	}
+	return &jsonWriter{w: w}, nil
+}
+
+func (jw *jsonWriter) Write(row Row) error {
+	tags := row.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	data, err := json.Marshal(jsonRow{
+		ID:           row.ID,
+		PurchaseDate: row.PurchaseDate.Format(dateLayout),
+		BillDate:     row.BillDate.Format(dateLayout),
+		Kind:         row.Kind,
+		Description:  row.Description,
+		Merchant:     row.Merchant,
+		Category:     row.Category,
+		Account:      row.Account,
+		Tags:         tags,
+		Amount:       row.Amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	if jw.count > 0 {
+		if _, err := io.WriteString(jw.w, ","); err != nil {
+			return err
+		}
+	}
+	jw.count++
+	_, err = jw.w.Write(data)
+	return err
+}
+
+func (jw *jsonWriter) Close() error {
+	_, err := io.WriteString(jw.w, "]\n")
+	return err
+}
@@ -0,0 +1,165 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parts of a minimal SpreadsheetML workbook with a single sheet. Styles 1 and
+// 2 of styles.xml format dates and amounts.
+const (
+	xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`
+	xlsxRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Transactions" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+	xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+	xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="3">` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`</cellXfs>` +
+		`</styleSheet>`
+	xlsxSheetHeader = xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetFooter = `</sheetData></worksheet>`
+)
+
+const (
+	xlsxStyleDate   = 1
+	xlsxStyleAmount = 2
+)
+
+// Day zero of spreadsheet date serial numbers
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxWriter streams rows into the only sheet of a workbook. Text is written
+// inline so that no shared string table has to be held until the end.
+type xlsxWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	row   int
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	// The sheet is the last part, so it can stay open while rows come in
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxWriter{zip: zw, sheet: bufio.NewWriter(f)}
+	if _, err := xw.sheet.WriteString(xlsxSheetHeader); err != nil {
+		return nil, err
+	}
+
+	xw.startRow()
+	for i, name := range columns {
+		xw.text(i, name)
+	}
+	xw.endRow()
+	return xw, nil
+}
+
+func (xw *xlsxWriter) Write(row Row) error {
+	xw.startRow()
+	xw.text(0, row.ID.String())
+	xw.number(1, serialDate(row.PurchaseDate), xlsxStyleDate)
+	xw.number(2, serialDate(row.BillDate), xlsxStyleDate)
+	xw.text(3, row.Kind)
+	xw.text(4, row.Description)
+	xw.text(5, row.Merchant)
+	xw.text(6, row.Category)
+	xw.text(7, row.Account)
+	xw.text(8, strings.Join(row.Tags, ", "))
+	xw.number(9, strconv.FormatFloat(row.Amount, 'f', 2, 64), xlsxStyleAmount)
+	return xw.endRow()
+}
+
+func (xw *xlsxWriter) Close() error {
+	if _, err := xw.sheet.WriteString(xlsxSheetFooter); err != nil {
+		return err
+	}
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.zip.Close()
+}
+
+// Writes to the buffered sheet only fail once the buffer is flushed, so the
+// cell helpers leave error handling to endRow.
+
+func (xw *xlsxWriter) startRow() {
+	xw.row++
+	xw.sheet.WriteString(`<row r="` + strconv.Itoa(xw.row) + `">`)
+}
+
+func (xw *xlsxWriter) endRow() error {
+	_, err := xw.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (xw *xlsxWriter) text(column int, value string) {
+	if value == "" {
+		return
+	}
+	xw.sheet.WriteString(`<c r="` + xw.ref(column) + `" t="inlineStr"><is><t xml:space="preserve">`)
+	xml.EscapeText(xw.sheet, []byte(value))
+	xw.sheet.WriteString(`</t></is></c>`)
+}
+
+func (xw *xlsxWriter) number(column int, value string, style int) {
+	xw.sheet.WriteString(`<c r="` + xw.ref(column) + `"`)
+	if style != 0 {
+		xw.sheet.WriteString(` s="` + strconv.Itoa(style) + `"`)
+	}
+	xw.sheet.WriteString(`><v>` + value + `</v></c>`)
+}
+
+// ref returns the reference of a cell of the current row, such as "B7".
+func (xw *xlsxWriter) ref(column int) string {
+	return string(rune('A'+column)) + strconv.Itoa(xw.row)
+}
+
+// serialDate returns the spreadsheet serial number of the day of t.
+func serialDate(t time.Time) string {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return strconv.Itoa(int(day.Sub(xlsxEpoch).Hours() / 24))
+}
@@ -0,0 +1,344 @@
+// Package forecast projects month-end spending per category and the upcoming
+// card bills from the spending pace, recurring charges and known installments.
+// It is pure computation: callers load the data and serve the result.
+package forecast
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// Number of full months before the current one used as the baseline.
+	baselineMonths = 3
+	// Number of upcoming card bills forecast.
+	billCount = 3
+	// z-value of the two-sided 80% confidence range.
+	confidenceZ = 1.2816
+)
+
+// HistoryMonths is how many full months of purchases Forecast expects before the current one.
+const HistoryMonths = baselineMonths
+
+type Expense struct {
+	Amount       float64
+	CategoryID   *int32
+	PurchaseDate time.Time
+	BillDate     time.Time
+	// Recurring marks charges already accounted for by a Recurring entry.
+	Recurring bool
+}
+
+// Recurring is a monthly charge expected to happen again.
+type Recurring struct {
+	Amount     float64
+	CategoryID *int32
+	NextDate   time.Time
+}
+
+type Input struct {
+	Now           time.Time
+	Expenses      []Expense
+	Recurring     []Recurring
+	CategoryNames map[int32]string
+}
+
+type Estimate struct {
+	Point float64 `json:"point"`
+	Low   float64 `json:"low"`
+	High  float64 `json:"high"`
+}
+
+type CategoryForecast struct {
+	CategoryID   *int32   `json:"categoryId"`
+	CategoryName *string  `json:"categoryName"`
+	SpentSoFar   float64  `json:"spentSoFar"`
+	MonthEnd     Estimate `json:"monthEnd"`
+}
+
+type BillForecast struct {
+	Month string   `json:"month"`
+	Known float64  `json:"known"`
+	Total Estimate `json:"total"`
+}
+
+type Result struct {
+	Month      string             `json:"month"`
+	Categories []CategoryForecast `json:"categories"`
+	Bills      []BillForecast     `json:"bills"`
+}
+
+type categoryKey struct {
+	id    int32
+	valid bool
+}
+
+func keyOf(id *int32) categoryKey {
+	if id == nil {
+		return categoryKey{}
+	}
+	return categoryKey{id: *id, valid: true}
+}
+
+// pace holds the spending statistics of a category or of all categories together.
+type pace struct {
+	spentSoFar   float64 // everything spent in the current month so far
+	scheduled    float64 // purchases dated later in the current month
+	currentVar   float64 // non-recurring spending in the current month so far
+	baselineVar  float64 // non-recurring spending over the baseline months
+	monthlyTotal []float64
+}
+
+// dailyRate blends the current month pace with the baseline pace, trusting the
+// current month more as it progresses.
+func (p *pace) dailyRate(elapsed, monthDays, baselineDays float64) float64 {
+	weight := elapsed / monthDays
+	current := p.currentVar / elapsed
+	baseline := 0.0
+	if baselineDays > 0 {
+		baseline = p.baselineVar / baselineDays
+	}
+	return weight*current + (1-weight)*baseline
+}
+
+// monthlyStdDev is the standard deviation of the non-recurring monthly totals of the baseline.
+func (p *pace) monthlyStdDev() float64 {
+	var sum float64
+	for _, v := range p.monthlyTotal {
+		sum += v
+	}
+	mean := sum / float64(len(p.monthlyTotal))
+	var sq float64
+	for _, v := range p.monthlyTotal {
+		sq += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sq / float64(len(p.monthlyTotal)))
+}
+
+// Forecast computes the month-end totals per category and the next card bills.
+func Forecast(in Input) Result {
+	today := truncateDay(in.Now)
+	monthStart := startOfMonth(today)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	baselineStart := monthStart.AddDate(0, -baselineMonths, 0)
+
+	monthDays := days(monthStart, monthEnd)
+	elapsed := float64(today.Day())
+	remaining := monthDays - elapsed
+	baselineDays := days(baselineStart, monthStart)
+
+	paces := map[categoryKey]*pace{}
+	total := &pace{monthlyTotal: make([]float64, baselineMonths)}
+	categoryIDs := map[categoryKey]*int32{}
+	paceOf := func(id *int32) *pace {
+		k := keyOf(id)
+		if p, ok := paces[k]; ok {
+			return p
+		}
+		p := &pace{monthlyTotal: make([]float64, baselineMonths)}
+		paces[k] = p
+		categoryIDs[k] = id
+		return p
+	}
+
+	for _, e := range in.Expenses {
+		date := truncateDay(e.PurchaseDate)
+		variable := !e.Recurring && !isLaterInstallment(e)
+		switch {
+		case !date.Before(monthStart) && !date.After(today):
+			for _, p := range []*pace{paceOf(e.CategoryID), total} {
+				p.spentSoFar += e.Amount
+				if variable {
+					p.currentVar += e.Amount
+				}
+			}
+		case date.After(today) && date.Before(monthEnd):
+			// Scheduled or post-dated, already known to happen this month
+			paceOf(e.CategoryID).scheduled += e.Amount
+		case !date.Before(baselineStart) && date.Before(monthStart) && variable:
+			month := monthsBetween(baselineStart, date)
+			for _, p := range []*pace{paceOf(e.CategoryID), total} {
+				p.baselineVar += e.Amount
+				p.monthlyTotal[month] += e.Amount
+			}
+		}
+	}
+
+	// Recurring charges still expected this month
+	recurringLeft := map[categoryKey]float64{}
+	for _, r := range in.Recurring {
+		if n := len(occurrences(r, today, monthEnd)); n > 0 {
+			paceOf(r.CategoryID)
+			recurringLeft[keyOf(r.CategoryID)] += r.Amount * float64(n)
+		}
+	}
+
+	keys := make([]categoryKey, 0, len(paces))
+	for k := range paces {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].valid != keys[j].valid {
+			return keys[i].valid
+		}
+		return keys[i].id < keys[j].id
+	})
+
+	result := Result{
+		Month:      monthStart.Format("2006-01"),
+		Categories: make([]CategoryForecast, 0, len(keys)),
+	}
+	for _, k := range keys {
+		p := paces[k]
+		base := p.spentSoFar + p.scheduled + recurringLeft[k]
+		variable := p.dailyRate(elapsed, monthDays, baselineDays) * remaining
+		spread := confidenceZ * p.monthlyStdDev() * math.Sqrt(remaining/monthDays)
+
+		forecast := CategoryForecast{
+			CategoryID: categoryIDs[k],
+			SpentSoFar: round(p.spentSoFar),
+			MonthEnd:   estimate(base, variable, spread),
+		}
+		if k.valid {
+			if name, ok := in.CategoryNames[k.id]; ok {
+				forecast.CategoryName = &name
+			}
+		}
+		result.Categories = append(result.Categories, forecast)
+	}
+
+	result.Bills = forecastBills(in, today, total, elapsed, monthDays, baselineDays)
+	return result
+}
+
+// forecastBills projects the next card bills: the charges already billed to
+// them (including future installments) plus the spending still to happen,
+// assigned to bills using the usual delay between purchase and bill.
+func forecastBills(in Input, today time.Time, total *pace, elapsed, monthDays, baselineDays float64) []BillForecast {
+	first := startOfMonth(today)
+	billDatesThisMonth, pendingThisMonth := 0, false
+	for _, e := range in.Expenses {
+		if startOfMonth(e.BillDate).Equal(first) {
+			billDatesThisMonth++
+			if !truncateDay(e.BillDate).Before(today) {
+				pendingThisMonth = true
+			}
+		}
+	}
+	if billDatesThisMonth > 0 && !pendingThisMonth {
+		first = first.AddDate(0, 1, 0)
+	}
+
+	lag := billingLag(in.Expenses, startOfMonth(today))
+	known := make([]float64, billCount)
+	variable := make([]float64, billCount)
+	horizons := make([]float64, billCount)
+	recurring := make([]float64, billCount)
+
+	for _, e := range in.Expenses {
+		if i := monthsBetween(first, e.BillDate); i >= 0 && i < billCount {
+			known[i] += e.Amount
+		}
+	}
+
+	// Spending not yet made, per purchase month, is billed lag months later
+	rate := total.dailyRate(elapsed, monthDays, baselineDays)
+	currentMonth := startOfMonth(today)
+	horizonEnd := first.AddDate(0, billCount, 0)
+	for month := currentMonth; month.Before(horizonEnd); month = month.AddDate(0, 1, 0) {
+		i := monthsBetween(first, month.AddDate(0, lag, 0))
+		if i < 0 || i >= billCount {
+			continue
+		}
+		d := days(month, month.AddDate(0, 1, 0))
+		if month.Equal(currentMonth) {
+			d = monthDays - elapsed
+		}
+		variable[i] += rate * d
+		horizons[i] += d
+	}
+	for _, r := range in.Recurring {
+		for _, date := range occurrences(r, today, horizonEnd) {
+			if i := monthsBetween(first, startOfMonth(date).AddDate(0, lag, 0)); i >= 0 && i < billCount {
+				recurring[i] += r.Amount
+			}
+		}
+	}
+
+	bills := make([]BillForecast, billCount)
+	stddev := total.monthlyStdDev()
+	for i := range bills {
+		spread := confidenceZ * stddev * math.Sqrt(horizons[i]/monthDays)
+		bills[i] = BillForecast{
+			Month: first.AddDate(0, i, 0).Format("2006-01"),
+			Known: round(known[i]),
+			Total: estimate(known[i]+recurring[i], variable[i], spread),
+		}
+	}
+	return bills
+}
+
+// billingLag is the most common number of months between a purchase and its
+// bill, ignoring installments billed further ahead.
+func billingLag(expenses []Expense, before time.Time) int {
+	counts := map[int]int{}
+	for _, e := range expenses {
+		if !e.PurchaseDate.Before(before) {
+			continue
+		}
+		if lag := monthsBetween(startOfMonth(e.PurchaseDate), e.BillDate); lag >= 0 && lag <= 1 {
+			counts[lag]++
+		}
+	}
+	if counts[1] > counts[0] {
+		return 1
+	}
+	return 0
+}
+
+// isLaterInstallment reports whether e is an installment billed after the
+// bill following its purchase. Those are known in advance and do not say
+// anything about the spending pace.
+func isLaterInstallment(e Expense) bool {
+	return monthsBetween(startOfMonth(e.PurchaseDate), e.BillDate) > 1
+}
+
+// occurrences lists the dates of a recurring charge after from and before to.
+func occurrences(r Recurring, from, to time.Time) []time.Time {
+	dates := []time.Time{}
+	for date := truncateDay(r.NextDate); date.Before(to); date = date.AddDate(0, 1, 0) {
+		if date.After(from) {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
+
+func estimate(base, variable, spread float64) Estimate {
+	return Estimate{
+		Point: round(base + variable),
+		Low:   round(base + math.Max(0, variable-spread)),
+		High:  round(base + variable + spread),
+	}
+}
+
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+func days(from, to time.Time) float64 {
+	return to.Sub(from).Hours() / 24
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,135 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func on(month time.Month, day int) time.Time {
+	return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func category(id int32) *int32 {
+	return &id
+}
+
+func findCategory(result Result, id int32) *CategoryForecast {
+	for _, c := range result.Categories {
+		if c.CategoryID != nil && *c.CategoryID == id {
+			return &c
+		}
+	}
+	return nil
+}
+
+// dailyGroceries spends 10 per day on groceries from the first of `from` up to and including `until`,
+// billed on the 10th of the following month.
+func dailyGroceries(from time.Month, until time.Time) []Expense {
+	expenses := []Expense{}
+	for d := on(from, 1); !d.After(until); d = d.AddDate(0, 0, 1) {
+		bill := time.Date(d.Year(), d.Month()+1, 10, 0, 0, 0, 0, time.UTC)
+		expenses = append(expenses, Expense{Amount: 10, CategoryID: category(1), PurchaseDate: d, BillDate: bill})
+	}
+	return expenses
+}
+
+func TestForecastSteadyPace(t *testing.T) {
+	now := on(9, 15)
+	result := Forecast(Input{
+		Now:           now,
+		Expenses:      dailyGroceries(6, now),
+		CategoryNames: map[int32]string{1: "Groceries"},
+	})
+
+	assert.Equal(t, "2026-09", result.Month)
+	groceries := findCategory(result, 1)
+	if assert.NotNil(t, groceries) {
+		assert.Equal(t, "Groceries", *groceries.CategoryName)
+		assert.Equal(t, 150.0, groceries.SpentSoFar)
+		// 30 days at 10 per day
+		assert.InDelta(t, 300, groceries.MonthEnd.Point, 0.01)
+		assert.LessOrEqual(t, groceries.MonthEnd.Low, groceries.MonthEnd.Point)
+		assert.GreaterOrEqual(t, groceries.MonthEnd.High, groceries.MonthEnd.Point)
+		assert.GreaterOrEqual(t, groceries.MonthEnd.Low, groceries.SpentSoFar)
+	}
+}
+
+func TestForecastScheduledPurchases(t *testing.T) {
+	now := on(9, 15)
+	expenses := append(dailyGroceries(6, now),
+		// Post-dated later this month
+		Expense{Amount: 40, CategoryID: category(1), PurchaseDate: on(9, 28), BillDate: on(10, 10)},
+		Expense{Amount: 200, CategoryID: category(4), PurchaseDate: on(9, 25), BillDate: on(10, 10)},
+		// Scheduled for next month
+		Expense{Amount: 500, CategoryID: category(4), PurchaseDate: on(10, 1), BillDate: on(11, 10)},
+	)
+
+	result := Forecast(Input{Now: now, Expenses: expenses})
+
+	groceries := findCategory(result, 1)
+	if assert.NotNil(t, groceries) {
+		assert.Equal(t, 150.0, groceries.SpentSoFar)
+		// The usual 10 per day, plus the scheduled purchase
+		assert.InDelta(t, 340, groceries.MonthEnd.Point, 0.01)
+		assert.GreaterOrEqual(t, groceries.MonthEnd.Low, 190.0)
+	}
+	rent := findCategory(result, 4)
+	if assert.NotNil(t, rent) {
+		assert.Equal(t, 0.0, rent.SpentSoFar)
+		assert.Equal(t, Estimate{Point: 200, Low: 200, High: 200}, rent.MonthEnd)
+	}
+}
+
+func TestForecastRecurringAndInstallments(t *testing.T) {
+	now := on(9, 15)
+	expenses := dailyGroceries(6, now)
+	// An installment purchase billed over the next months
+	for i := 0; i < 3; i++ {
+		expenses = append(expenses, Expense{
+			Amount:       100,
+			CategoryID:   category(2),
+			PurchaseDate: on(9, 2),
+			BillDate:     on(time.Month(10+i), 10),
+		})
+	}
+
+	result := Forecast(Input{
+		Now:      now,
+		Expenses: expenses,
+		Recurring: []Recurring{
+			{Amount: 50, CategoryID: category(3), NextDate: on(9, 20)},
+		},
+	})
+
+	streaming := findCategory(result, 3)
+	if assert.NotNil(t, streaming) {
+		assert.Equal(t, 0.0, streaming.SpentSoFar)
+		assert.Equal(t, Estimate{Point: 50, Low: 50, High: 50}, streaming.MonthEnd)
+	}
+
+	// September purchases are billed in October together with the first installment.
+	assert.Len(t, result.Bills, 3)
+	assert.Equal(t, "2026-10", result.Bills[0].Month)
+	assert.Equal(t, 250.0, result.Bills[0].Known)
+	// 250 known + 50 recurring + the rest of September at a pace blending
+	// the current 250 / 15 days (first installment included) with the usual 10 per day
+	assert.InDelta(t, 500, result.Bills[0].Total.Point, 0.01)
+	// Later bills already know their installment
+	assert.Equal(t, "2026-11", result.Bills[1].Month)
+	assert.Equal(t, 100.0, result.Bills[1].Known)
+	assert.Equal(t, 100.0, result.Bills[2].Known)
+}
+
+func TestForecastBillsStartWithPendingBill(t *testing.T) {
+	now := on(9, 5)
+	result := Forecast(Input{Now: now, Expenses: dailyGroceries(6, now)})
+	// The September bill, due on the 10th, is still pending.
+	assert.Equal(t, "2026-09", result.Bills[0].Month)
+	assert.Equal(t, 310.0, result.Bills[0].Known)
+
+	now = on(9, 15)
+	result = Forecast(Input{Now: now, Expenses: dailyGroceries(6, now)})
+	assert.Equal(t, "2026-10", result.Bills[0].Month)
+}
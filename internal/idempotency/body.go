@@ -0,0 +1,57 @@
+package idempotency
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// Body holds a request body read ahead of its handler to fingerprint it. The
+// first bytes are kept in memory and the rest is spooled to a temporary file,
+// so that large uploads are not held in memory twice.
+type Body struct {
+	memory      bytes.Buffer
+	memoryBytes int
+	file        *os.File
+}
+
+// NewBody returns an empty body keeping up to memoryBytes in memory.
+func NewBody(memoryBytes int) *Body {
+	return &Body{memoryBytes: memoryBytes}
+}
+
+// Write appends to the body, spilling to disk past the memory limit.
+func (b *Body) Write(p []byte) (int, error) {
+	if b.file == nil && b.memory.Len()+len(p) <= b.memoryBytes {
+		return b.memory.Write(p)
+	}
+	if b.file == nil {
+		file, err := os.CreateTemp("", "idempotency-*")
+		if err != nil {
+			return 0, err
+		}
+		b.file = file
+	}
+	return b.file.Write(p)
+}
+
+// Reader returns a reader over the body written so far, from its start.
+func (b *Body) Reader() (io.Reader, error) {
+	memory := bytes.NewReader(b.memory.Bytes())
+	if b.file == nil {
+		return memory, nil
+	}
+	if _, err := b.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.MultiReader(memory, b.file), nil
+}
+
+// Close removes the file the body was spooled to, if any.
+func (b *Body) Close() error {
+	if b.file == nil {
+		return nil
+	}
+	b.file.Close()
+	return os.Remove(b.file.Name())
+}
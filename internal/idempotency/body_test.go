@@ -0,0 +1,47 @@
+package idempotency
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		spooled bool
+	}{
+		{"empty", "", false},
+		{"within the memory limit", "0123456789", false},
+		{"spooled past the memory limit", strings.Repeat("0123456789", 10), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := NewBody(16)
+			// Written in small chunks, as a copy from the request would
+			for _, chunk := range strings.SplitAfter(tt.content, "9") {
+				_, err := body.Write([]byte(chunk))
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.spooled, body.file != nil)
+
+			reader, err := body.Reader()
+			require.NoError(t, err)
+			content, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, tt.content, string(content))
+
+			require.NoError(t, body.Close())
+			if tt.spooled {
+				_, err := os.Stat(body.file.Name())
+				assert.True(t, os.IsNotExist(err))
+			}
+		})
+	}
+}
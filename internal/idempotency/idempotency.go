@@ -0,0 +1,64 @@
+// Package idempotency identifies the requests retried under an
+// Idempotency-Key header, so that replays can be answered with the response of
+// the first attempt.
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+)
+
+// Header carrying the key chosen by the client
+const Header = "Idempotency-Key"
+
+// ReplayedHeader is set on responses replayed from an earlier request.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// Maximum length of a key
+const MaxKeyLength = 255
+
+// Largest body read to fingerprint a request sent with a key. It is above the
+// limits of the routes taking uploads, which still apply to the body.
+const MaxBodyBytes = 1 << 30
+
+// Part of a body read ahead that is kept in memory, the rest is spooled to disk
+const MaxMemoryBytes = 1 << 20
+
+// Applies reports whether requests of the given method honor the header.
+func Applies(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// ValidKey reports whether a key is non-empty, short enough and made of
+// printable ASCII characters.
+func ValidKey(key string) bool {
+	if key == "" || len(key) > MaxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Fingerprint hashes what makes two requests the same: their method, target
+// and body. The body is read to its end.
+func Fingerprint(method, target string, body io.Reader) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(target))
+	hash.Write([]byte{0})
+	if _, err := io.Copy(hash, body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
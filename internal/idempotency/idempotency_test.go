@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplies(t *testing.T) {
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{"POST", true},
+		{"PUT", true},
+		{"PATCH", true},
+		{"DELETE", true},
+		{"GET", false},
+		{"HEAD", false},
+		{"OPTIONS", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			assert.Equal(t, tt.want, Applies(tt.method))
+		})
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want bool
+	}{
+		{"uuid", "0f8e2a4c-6d3b-4e8a-9c1f-2b7d5e9a3c61", true},
+		{"printable", "expense:2026-03-14 #1", true},
+		{"longest", strings.Repeat("k", MaxKeyLength), true},
+		{"empty", "", false},
+		{"too long", strings.Repeat("k", MaxKeyLength+1), false},
+		{"control character", "key\n1", false},
+		{"non ascii", "clé", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ValidKey(tt.key))
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := func(method, target, body string) string {
+		value, err := Fingerprint(method, target, strings.NewReader(body))
+		assert.NoError(t, err)
+		return value
+	}
+	base := fingerprint("POST", "/api/v1/expenses", `{"amount":12}`)
+
+	assert.Equal(t, base, fingerprint("POST", "/api/v1/expenses", `{"amount":12}`))
+	assert.Len(t, base, 64)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+	}{
+		{"method", "PUT", "/api/v1/expenses", `{"amount":12}`},
+		{"target", "POST", "/api/v1/income", `{"amount":12}`},
+		{"query", "POST", "/api/v1/expenses?workspaceId=1", `{"amount":12}`},
+		{"body", "POST", "/api/v1/expenses", `{"amount":13}`},
+		// Parts do not run into each other
+		{"boundaries", "POST", "/api/v1/expenses{", `"amount":12}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NotEqual(t, base, fingerprint(tt.method, tt.target, tt.body))
+		})
+	}
+}
@@ -0,0 +1,293 @@
+// Package insights turns a user's spending history into templated, localized
+// insight cards. It is pure computation: callers load the history and persist
+// the generated cards.
+package insights
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type Type string
+
+const (
+	TypeCategoryTrend Type = "category_trend"
+	TypeMonthlyPace   Type = "monthly_pace"
+	TypeSubscriptions Type = "subscriptions"
+)
+
+type Severity string
+
+const (
+	SeverityPositive Severity = "positive"
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+)
+
+const (
+	// Number of previous months forming the baseline of trend insights.
+	baselineMonths = 3
+	// Relative change from the baseline needed for a category trend card.
+	trendThreshold = 0.2
+	// Relative change from the baseline that turns a trend card into a warning.
+	trendWarningThreshold = 0.5
+	// Relative change from the baseline needed for a monthly pace card.
+	paceThreshold = 0.1
+	// Category trends are only reported when either side reaches this amount.
+	minTrendAmount = 50
+)
+
+// HistoryMonths is how much history Generate needs to evaluate every insight.
+const HistoryMonths = baselineMonths + 1
+
+type Expense struct {
+	Amount       float64
+	Description  string
+	CategoryID   *int32
+	PurchaseDate time.Time
+}
+
+type Input struct {
+	Now           time.Time
+	Locale        string
+	Expenses      []Expense
+	CategoryNames map[int32]string
+}
+
+// Filter is a deep link into the expense listing showing the data behind a card.
+type Filter struct {
+	CategoryID    *int32 `json:"categoryId,omitempty"`
+	Uncategorized bool   `json:"uncategorized,omitempty"`
+	From          string `json:"from,omitempty"`
+	To            string `json:"to,omitempty"`
+}
+
+type Card struct {
+	Type     Type
+	Key      string
+	Severity Severity
+	Title    string
+	Message  string
+	Data     map[string]any
+	Filter   Filter
+}
+
+// Generate computes every insight card that currently applies.
+func Generate(in Input) []Card {
+	if !u.IsSupportedLocale(in.Locale) {
+		in.Locale = u.DefaultLocale
+	}
+
+	cards := []Card{}
+	cards = append(cards, categoryTrends(in)...)
+	if card, ok := monthlyPace(in); ok {
+		cards = append(cards, card)
+	}
+	if card, ok := subscriptions(in); ok {
+		cards = append(cards, card)
+	}
+	return cards
+}
+
+// windows returns the month-to-date window of the current month followed by
+// the same day range in each baseline month.
+func windows(now time.Time) []u.Period {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	days := today.Day()
+
+	result := []u.Period{{From: monthStart, To: today.AddDate(0, 0, 1)}}
+	for i := 1; i <= baselineMonths; i++ {
+		from := monthStart.AddDate(0, -i, 0)
+		to := from.AddDate(0, 0, days)
+		if next := from.AddDate(0, 1, 0); to.After(next) {
+			to = next
+		}
+		result = append(result, u.Period{From: from, To: to})
+	}
+	return result
+}
+
+// windowTotals sums expenses per window, grouped by the given key function.
+func windowTotals[K comparable](expenses []Expense, periods []u.Period, key func(Expense) K) map[K][]float64 {
+	totals := map[K][]float64{}
+	for _, e := range expenses {
+		for i, p := range periods {
+			if e.PurchaseDate.Before(p.From) || !e.PurchaseDate.Before(p.To) {
+				continue
+			}
+			k := key(e)
+			if totals[k] == nil {
+				totals[k] = make([]float64, len(periods))
+			}
+			totals[k][i] += e.Amount
+		}
+	}
+	return totals
+}
+
+func categoryTrends(in Input) []Card {
+	periods := windows(in.Now)
+	current := periods[0]
+	totals := windowTotals(in.Expenses, periods, func(e Expense) int32 {
+		if e.CategoryID == nil {
+			return 0
+		}
+		return *e.CategoryID
+	})
+
+	ids := make([]int32, 0, len(totals))
+	for id := range totals {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	cards := []Card{}
+	for _, id := range ids {
+		sums := totals[id]
+		average := mean(sums[1:])
+		if average == 0 || math.Max(sums[0], average) < minTrendAmount {
+			continue
+		}
+		change := (sums[0] - average) / average
+		if math.Abs(change) < trendThreshold {
+			continue
+		}
+
+		name := localize(in.Locale, msgUncategorized)
+		var categoryID *int32
+		if id != 0 {
+			categoryID = &id
+			name = in.CategoryNames[id]
+		}
+
+		severity, titleID, messageID := SeverityInfo, msgTrendUpTitle, msgTrendUpMessage
+		if change < 0 {
+			severity, titleID, messageID = SeverityPositive, msgTrendDownTitle, msgTrendDownMessage
+		} else if change >= trendWarningThreshold {
+			severity = SeverityWarning
+		}
+
+		filter := periodFilter(current, categoryID)
+		filter.Uncategorized = categoryID == nil
+
+		percent := math.Round(math.Abs(change) * 100)
+		cards = append(cards, Card{
+			Type:     TypeCategoryTrend,
+			Key:      fmt.Sprintf("%s:%d:%s", TypeCategoryTrend, id, current.From.Format("2006-01")),
+			Severity: severity,
+			Title:    localize(in.Locale, titleID, name, u.FormatDecimal(in.Locale, percent, 0)),
+			Message: localize(in.Locale, messageID, name, u.FormatDecimal(in.Locale, percent, 0),
+				u.FormatDecimal(in.Locale, sums[0], 2), u.FormatDecimal(in.Locale, average, 2)),
+			Data: map[string]any{
+				"categoryId":    categoryID,
+				"current":       round(sums[0], 2),
+				"average":       round(average, 2),
+				"changePercent": round(change*100, 1),
+			},
+			Filter: filter,
+		})
+	}
+	return cards
+}
+
+func monthlyPace(in Input) (Card, bool) {
+	periods := windows(in.Now)
+	current := periods[0]
+	sums := windowTotals(in.Expenses, periods, func(Expense) bool { return true })[true]
+	if sums == nil {
+		return Card{}, false
+	}
+
+	average := mean(sums[1:])
+	if average == 0 {
+		return Card{}, false
+	}
+	change := (sums[0] - average) / average
+	if math.Abs(change) < paceThreshold {
+		return Card{}, false
+	}
+
+	severity, messageID := SeverityInfo, msgPaceUpMessage
+	if change < 0 {
+		severity, messageID = SeverityPositive, msgPaceDownMessage
+	}
+	percent := math.Round(math.Abs(change) * 100)
+
+	return Card{
+		Type:     TypeMonthlyPace,
+		Key:      fmt.Sprintf("%s:%s", TypeMonthlyPace, current.From.Format("2006-01")),
+		Severity: severity,
+		Title:    localize(in.Locale, msgPaceTitle),
+		Message: localize(in.Locale, messageID, u.FormatDecimal(in.Locale, sums[0], 2),
+			u.FormatDecimal(in.Locale, percent, 0)),
+		Data: map[string]any{
+			"current":       round(sums[0], 2),
+			"average":       round(average, 2),
+			"changePercent": round(change*100, 1),
+		},
+		Filter: periodFilter(current, nil),
+	}, true
+}
+
+func subscriptions(in Input) (Card, bool) {
+	recurring := DetectRecurring(in.Expenses, in.Now)
+	if len(recurring) == 0 {
+		return Card{}, false
+	}
+
+	var total float64
+	items := make([]map[string]any, 0, len(recurring))
+	for _, r := range recurring {
+		total += r.Amount
+		items = append(items, map[string]any{
+			"description": r.Description,
+			"amount":      round(r.Amount, 2),
+			"categoryId":  r.CategoryID,
+		})
+	}
+
+	windowStart := time.Date(in.Now.Year(), in.Now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -baselineMonths, 0)
+	return Card{
+		Type:     TypeSubscriptions,
+		Key:      fmt.Sprintf("%s:%s", TypeSubscriptions, in.Now.Format("2006-01")),
+		Severity: SeverityInfo,
+		Title:    localize(in.Locale, msgSubscriptionsTitle),
+		Message: localize(in.Locale, msgSubscriptionsMessage, len(recurring),
+			u.FormatDecimal(in.Locale, total, 2)),
+		Data: map[string]any{
+			"count":         len(recurring),
+			"monthlyTotal":  round(total, 2),
+			"subscriptions": items,
+		},
+		Filter: periodFilter(u.Period{From: windowStart, To: in.Now.AddDate(0, 0, 1)}, nil),
+	}, true
+}
+
+func periodFilter(p u.Period, categoryID *int32) Filter {
+	return Filter{
+		CategoryID: categoryID,
+		From:       p.From.Format("2006-01-02"),
+		To:         p.To.AddDate(0, 0, -1).Format("2006-01-02"),
+	}
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func round(v float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(v*p) / p
+}
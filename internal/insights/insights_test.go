@@ -0,0 +1,146 @@
+package insights
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+func on(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func categoryID(id int32) *int32 {
+	return &id
+}
+
+func findCard(cards []Card, typ Type) *Card {
+	for _, c := range cards {
+		if c.Type == typ {
+			return &c
+		}
+	}
+	return nil
+}
+
+func TestGenerateCategoryTrend(t *testing.T) {
+	dining := categoryID(7)
+	expenses := []Expense{
+		{Amount: 100, Description: "Restaurant", CategoryID: dining, PurchaseDate: on(2026, 7, 10)},
+		{Amount: 100, Description: "Restaurant", CategoryID: dining, PurchaseDate: on(2026, 8, 10)},
+		{Amount: 100, Description: "Restaurant", CategoryID: dining, PurchaseDate: on(2026, 9, 10)},
+		// Outside the month-to-date window of September, ignored
+		{Amount: 500, Description: "Restaurant", CategoryID: dining, PurchaseDate: on(2026, 9, 20)},
+		{Amount: 134, Description: "Restaurant", CategoryID: dining, PurchaseDate: on(2026, 10, 5)},
+	}
+
+	tests := []struct {
+		locale  string
+		title   string
+		message string
+	}{
+		{
+			locale:  "en-US",
+			title:   "Dining is up 34%",
+			message: "Dining is up 34% vs your 3-month average: 134.00 so far this month against 100.00 usually.",
+		},
+		{
+			locale:  "pt-BR",
+			title:   "Dining subiu 34%",
+			message: "Dining subiu 34% em relação à sua média dos últimos 3 meses: 134,00 até agora neste mês contra 100,00 normalmente.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			cards := Generate(Input{
+				Now:           now,
+				Locale:        tt.locale,
+				Expenses:      expenses,
+				CategoryNames: map[int32]string{7: "Dining"},
+			})
+
+			card := findCard(cards, TypeCategoryTrend)
+			if assert.NotNil(t, card) {
+				assert.Equal(t, "category_trend:7:2026-10", card.Key)
+				assert.Equal(t, SeverityInfo, card.Severity)
+				assert.Equal(t, tt.title, card.Title)
+				assert.Equal(t, tt.message, card.Message)
+				assert.Equal(t, 34.0, card.Data["changePercent"])
+				assert.Equal(t, Filter{CategoryID: dining, From: "2026-10-01", To: "2026-10-15"}, card.Filter)
+			}
+		})
+	}
+}
+
+func TestGenerateUncategorizedTrend(t *testing.T) {
+	expenses := []Expense{
+		{Amount: 100, Description: "Market", PurchaseDate: on(2026, 7, 3)},
+		{Amount: 100, Description: "Market", PurchaseDate: on(2026, 8, 3)},
+		{Amount: 100, Description: "Market", PurchaseDate: on(2026, 9, 3)},
+		{Amount: 150, Description: "Market", PurchaseDate: on(2026, 10, 3)},
+	}
+
+	cards := Generate(Input{Now: now, Locale: "en-US", Expenses: expenses})
+	card := findCard(cards, TypeCategoryTrend)
+	if assert.NotNil(t, card) {
+		assert.Equal(t, Filter{Uncategorized: true, From: "2026-10-01", To: "2026-10-15"}, card.Filter)
+	}
+}
+
+func TestGenerateIgnoresSmallChanges(t *testing.T) {
+	expenses := []Expense{
+		{Amount: 100, Description: "Market", CategoryID: categoryID(1), PurchaseDate: on(2026, 7, 3)},
+		{Amount: 100, Description: "Market", CategoryID: categoryID(1), PurchaseDate: on(2026, 8, 3)},
+		{Amount: 100, Description: "Market", CategoryID: categoryID(1), PurchaseDate: on(2026, 9, 3)},
+		{Amount: 105, Description: "Market", CategoryID: categoryID(1), PurchaseDate: on(2026, 10, 3)},
+	}
+
+	cards := Generate(Input{Now: now, Locale: "en-US", Expenses: expenses})
+	assert.Nil(t, findCard(cards, TypeCategoryTrend))
+	assert.Nil(t, findCard(cards, TypeMonthlyPace))
+}
+
+func TestGenerateSubscriptions(t *testing.T) {
+	expenses := []Expense{}
+	for _, month := range []time.Month{7, 8, 9, 10} {
+		expenses = append(expenses,
+			Expense{Amount: 39.9, Description: "Streaming Co", PurchaseDate: on(2026, month, 2)},
+			Expense{Amount: 20.1, Description: "Music  app", PurchaseDate: on(2026, month, 8)},
+		)
+	}
+	// Irregular amounts are not a subscription
+	expenses = append(expenses,
+		Expense{Amount: 10, Description: "Bakery", PurchaseDate: on(2026, 8, 1)},
+		Expense{Amount: 80, Description: "Bakery", PurchaseDate: on(2026, 9, 1)},
+		Expense{Amount: 35, Description: "Bakery", PurchaseDate: on(2026, 10, 1)},
+	)
+
+	cards := Generate(Input{Now: now, Locale: "en-US", Expenses: expenses})
+	card := findCard(cards, TypeSubscriptions)
+	if assert.NotNil(t, card) {
+		assert.Equal(t, "You have 2 subscriptions totaling 60.00 per month.", card.Message)
+		assert.Equal(t, 2, card.Data["count"])
+	}
+}
+
+func TestDetectRecurring(t *testing.T) {
+	monthly := []Expense{
+		{Amount: 15, Description: "Gym", PurchaseDate: on(2026, 5, 10)},
+		{Amount: 15, Description: "GYM", PurchaseDate: on(2026, 6, 10)},
+		{Amount: 15, Description: "Gym", PurchaseDate: on(2026, 7, 10)},
+	}
+
+	assert.Len(t, DetectRecurring(monthly, on(2026, 7, 20)), 1)
+	// Not charged for more than a month: cancelled
+	assert.Empty(t, DetectRecurring(monthly, on(2026, 9, 20)))
+	// Too few charges
+	assert.Empty(t, DetectRecurring(monthly[:2], on(2026, 7, 20)))
+
+	recurring := DetectRecurring(monthly, on(2026, 7, 20))[0]
+	assert.Equal(t, on(2026, 8, 10), recurring.NextDate())
+	assert.Equal(t, 3, recurring.Occurrences)
+}
@@ -0,0 +1,59 @@
+package insights
+
+import (
+	"fmt"
+
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type messageID int
+
+const (
+	msgUncategorized messageID = iota
+	msgTrendUpTitle
+	msgTrendUpMessage
+	msgTrendDownTitle
+	msgTrendDownMessage
+	msgPaceTitle
+	msgPaceUpMessage
+	msgPaceDownMessage
+	msgSubscriptionsTitle
+	msgSubscriptionsMessage
+)
+
+// messages holds the templates per locale. Arguments are positional so that
+// translations can reorder them.
+var messages = map[string]map[messageID]string{
+	"en-US": {
+		msgUncategorized:        "Uncategorized",
+		msgTrendUpTitle:         "%[1]s is up %[2]s%%",
+		msgTrendUpMessage:       "%[1]s is up %[2]s%% vs your 3-month average: %[3]s so far this month against %[4]s usually.",
+		msgTrendDownTitle:       "%[1]s is down %[2]s%%",
+		msgTrendDownMessage:     "%[1]s is down %[2]s%% vs your 3-month average: %[3]s so far this month against %[4]s usually.",
+		msgPaceTitle:            "Monthly spending pace",
+		msgPaceUpMessage:        "You have spent %[1]s so far this month, %[2]s%% more than usual by this day.",
+		msgPaceDownMessage:      "You have spent %[1]s so far this month, %[2]s%% less than usual by this day.",
+		msgSubscriptionsTitle:   "Your subscriptions",
+		msgSubscriptionsMessage: "You have %[1]d subscriptions totaling %[2]s per month.",
+	},
+	"pt-BR": {
+		msgUncategorized:        "Sem categoria",
+		msgTrendUpTitle:         "%[1]s subiu %[2]s%%",
+		msgTrendUpMessage:       "%[1]s subiu %[2]s%% em relação à sua média dos últimos 3 meses: %[3]s até agora neste mês contra %[4]s normalmente.",
+		msgTrendDownTitle:       "%[1]s caiu %[2]s%%",
+		msgTrendDownMessage:     "%[1]s caiu %[2]s%% em relação à sua média dos últimos 3 meses: %[3]s até agora neste mês contra %[4]s normalmente.",
+		msgPaceTitle:            "Ritmo de gastos do mês",
+		msgPaceUpMessage:        "Você gastou %[1]s até agora neste mês, %[2]s%% a mais do que o normal até este dia.",
+		msgPaceDownMessage:      "Você gastou %[1]s até agora neste mês, %[2]s%% a menos do que o normal até este dia.",
+		msgSubscriptionsTitle:   "Suas assinaturas",
+		msgSubscriptionsMessage: "Você tem %[1]d assinaturas totalizando %[2]s por mês.",
+	},
+}
+
+func localize(locale string, id messageID, args ...any) string {
+	templates, ok := messages[locale]
+	if !ok {
+		templates = messages[u.DefaultLocale]
+	}
+	return fmt.Sprintf(templates[id], args...)
+}
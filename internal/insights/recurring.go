@@ -0,0 +1,121 @@
+package insights
+
+import (
+	"sort"
+	"time"
+
+	"github.com/igorschechtel/clearflow-backend/internal/merchant"
+)
+
+const (
+	// Minimum number of charges before an expense is considered recurring.
+	minRecurringOccurrences = 3
+	// Accepted gap in days between two monthly charges.
+	minRecurringGapDays = 25
+	maxRecurringGapDays = 35
+	// Accepted relative deviation of a charge from the typical amount.
+	recurringAmountTolerance = 0.15
+	// A recurring expense not charged for this many days is considered cancelled.
+	recurringStaleDays = 40
+)
+
+// Recurring is a monthly charge such as a subscription.
+type Recurring struct {
+	Key         string
+	Description string
+	Amount      float64
+	CategoryID  *int32
+	LastDate    time.Time
+	Occurrences int
+}
+
+// NextDate estimates the next charge one month after the last one.
+func (r Recurring) NextDate() time.Time {
+	return r.LastDate.AddDate(0, 1, 0)
+}
+
+// DetectRecurring finds active monthly charges: expenses of the same merchant
+// charged about once a month for a similar amount.
+func DetectRecurring(expenses []Expense, now time.Time) []Recurring {
+	groups := map[string][]Expense{}
+	for _, e := range expenses {
+		key := DescriptionKey(e.Description)
+		groups[key] = append(groups[key], e)
+	}
+
+	result := []Recurring{}
+	for _, group := range groups {
+		if len(group) < minRecurringOccurrences {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool { return group[i].PurchaseDate.Before(group[j].PurchaseDate) })
+
+		last := group[len(group)-1]
+		if now.Sub(last.PurchaseDate) > recurringStaleDays*24*time.Hour {
+			continue
+		}
+		if !isMonthly(group) {
+			continue
+		}
+
+		amounts := make([]float64, len(group))
+		for i, e := range group {
+			amounts[i] = e.Amount
+		}
+		typical := median(amounts)
+		if !withinTolerance(amounts, typical) {
+			continue
+		}
+
+		result = append(result, Recurring{
+			Key:         DescriptionKey(last.Description),
+			Description: last.Description,
+			Amount:      typical,
+			CategoryID:  last.CategoryID,
+			LastDate:    last.PurchaseDate,
+			Occurrences: len(group),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Amount != result[j].Amount {
+			return result[i].Amount > result[j].Amount
+		}
+		return result[i].Description < result[j].Description
+	})
+	return result
+}
+
+func isMonthly(sorted []Expense) bool {
+	for i := 1; i < len(sorted); i++ {
+		gap := sorted[i].PurchaseDate.Sub(sorted[i-1].PurchaseDate).Hours() / 24
+		if gap < minRecurringGapDays || gap > maxRecurringGapDays {
+			return false
+		}
+	}
+	return true
+}
+
+func withinTolerance(amounts []float64, typical float64) bool {
+	for _, a := range amounts {
+		if typical == 0 || a < typical*(1-recurringAmountTolerance) || a > typical*(1+recurringAmountTolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// DescriptionKey reduces a description to the key used to group recurring charges.
+func DescriptionKey(description string) string {
+	return merchant.Key(description)
+}
@@ -0,0 +1,32 @@
+// Package jobs runs periodic background work alongside the HTTP server.
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Every runs fn immediately and then once per interval until ctx is cancelled.
+// Errors are logged and do not stop the schedule.
+func Every(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	logger := logrus.WithField("job", name)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		if err := fn(ctx); err != nil {
+			logger.WithError(err).Error("job failed")
+		} else {
+			logger.WithField("duration", time.Since(start).String()).Info("job completed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
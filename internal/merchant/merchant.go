@@ -0,0 +1,226 @@
+// Package merchant reduces the raw descriptions of bank statements to merchant
+// keys, so that "UBER *TRIP 8H2K SAO PAULO" and "UBER* TRIP" are recognized as
+// the same merchant. Normalization is deterministic: it only depends on the
+// description and the fixed word lists below.
+package merchant
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Payment processors that prefix the merchant name, as in "PAYPAL *SPOTIFY".
+// They are only stripped when followed by an asterisk.
+var processorPrefixes = []string{
+	"paypal", "pp", "sq", "sqr", "tst", "mp", "mercadopago", "pag", "pagseguro",
+	"pg", "ebanx", "ifd", "dl", "iz", "pmt", "stripe", "google", "gpay", "apple",
+}
+
+// Domain decorations stripped from names such as "WWW.NETFLIX.COM".
+var (
+	domainPrefixes = []string{"www."}
+	domainSuffixes = []string{".com.br", ".com", ".net", ".org", ".io"}
+)
+
+// Words that introduce a card number, as in "FINAL 1234".
+var cardWords = map[string]bool{
+	"final": true, "card": true, "cartao": true, "ending": true, "cc": true,
+}
+
+// Country and state codes appended to card descriptions.
+var regionCodes = map[string]bool{
+	"br": true, "bra": true, "us": true, "usa": true, "uk": true, "gb": true,
+	"pt": true, "es": true, "sp": true, "rj": true, "mg": true, "pr": true,
+	"rs": true, "sc": true, "ba": true, "df": true, "ny": true, "ca": true,
+	"wa": true, "tx": true, "fl": true,
+}
+
+// Cities appended to card descriptions, in normalized form.
+var cities = [][]string{
+	{"sao", "paulo"},
+	{"rio", "de", "janeiro"},
+	{"belo", "horizonte"},
+	{"porto", "alegre"},
+	{"curitiba"},
+	{"brasilia"},
+	{"salvador"},
+	{"recife"},
+	{"fortaleza"},
+	{"campinas"},
+	{"florianopolis"},
+	{"osasco"},
+	{"barueri"},
+	{"new", "york"},
+	{"san", "francisco"},
+	{"los", "angeles"},
+	{"los", "gatos"},
+	{"seattle"},
+	{"chicago"},
+	{"london"},
+	{"dublin"},
+	{"lisboa"},
+	{"lisbon"},
+	{"madrid"},
+	{"paris"},
+	{"amsterdam"},
+	{"luxembourg"},
+}
+
+// Key reduces a description to its merchant key: lowercase words without
+// accents, processor prefixes, domains, reference codes, card suffixes or
+// trailing locations. It returns "" for a blank description.
+func Key(description string) string {
+	s := strings.Map(foldAccent, strings.ToLower(description))
+	s = stripProcessorPrefixes(s)
+	s = strings.ReplaceAll(s, "*", " ")
+
+	words := strings.Fields(s)
+	for i, w := range words {
+		words[i] = stripDomain(w)
+	}
+	words = strings.Fields(strings.Map(punctuationToSpace, strings.Join(words, " ")))
+	if len(words) == 0 {
+		return ""
+	}
+
+	// Reference codes and card numbers carry digits. The first word is kept
+	// so that merchants such as "99 TAXI" or "7-ELEVEN" keep their name.
+	kept := words[:1]
+	for _, w := range words[1:] {
+		if !hasDigit(w) {
+			kept = append(kept, w)
+		}
+	}
+
+	return strings.Join(trimTrailing(kept), " ")
+}
+
+// Name turns a merchant key into a default display name, such as "Uber Trip".
+func Name(key string) string {
+	words := strings.Fields(key)
+	for i, w := range words {
+		runes := []rune(w)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// stripProcessorPrefixes removes leading "PROCESSOR *" markers, which may be
+// nested as in "PAYPAL *SQ *COFFEE".
+func stripProcessorPrefixes(s string) string {
+	for {
+		s = strings.TrimSpace(s)
+		star := strings.IndexByte(s, '*')
+		if star < 0 {
+			return s
+		}
+		prefix := strings.TrimSpace(s[:star])
+		if !isProcessor(prefix) || strings.TrimSpace(s[star+1:]) == "" {
+			return s
+		}
+		s = s[star+1:]
+	}
+}
+
+func isProcessor(prefix string) bool {
+	for _, p := range processorPrefixes {
+		if prefix == p {
+			return true
+		}
+	}
+	return false
+}
+
+func stripDomain(word string) string {
+	for _, p := range domainPrefixes {
+		word = strings.TrimPrefix(word, p)
+	}
+	for _, suffix := range domainSuffixes {
+		if i := strings.Index(word, suffix); i > 0 {
+			// Drop the path too, as in "AMZN.COM/BILL"
+			rest := word[i+len(suffix):]
+			if rest == "" || rest[0] == '/' {
+				return word[:i]
+			}
+		}
+	}
+	return word
+}
+
+// trimTrailing strips the card words, region codes and cities found at the end
+// of a description, always leaving at least one word.
+func trimTrailing(words []string) []string {
+	for len(words) > 1 {
+		last := words[len(words)-1]
+		if cardWords[last] || regionCodes[last] {
+			words = words[:len(words)-1]
+			continue
+		}
+		if n := trailingCity(words); n > 0 && n < len(words) {
+			words = words[:len(words)-n]
+			continue
+		}
+		break
+	}
+	return words
+}
+
+// trailingCity returns the number of words of the city ending words, or 0.
+func trailingCity(words []string) int {
+	for _, city := range cities {
+		if len(city) > len(words) {
+			continue
+		}
+		tail := words[len(words)-len(city):]
+		match := true
+		for i := range city {
+			if tail[i] != city[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return len(city)
+		}
+	}
+	return 0
+}
+
+func hasDigit(word string) bool {
+	return strings.IndexFunc(word, unicode.IsDigit) >= 0
+}
+
+// punctuationToSpace drops apostrophes, so that "MCDONALD'S" stays one word,
+// and turns any other symbol into a word separator.
+func punctuationToSpace(r rune) rune {
+	switch {
+	case r == '\'' || r == '’':
+		return -1
+	case unicode.IsLetter(r) || unicode.IsDigit(r):
+		return r
+	default:
+		return ' '
+	}
+}
+
+// foldAccent maps accented Latin letters to their base letter.
+func foldAccent(r rune) rune {
+	switch r {
+	case 'á', 'à', 'â', 'ã', 'ä', 'å':
+		return 'a'
+	case 'é', 'è', 'ê', 'ë':
+		return 'e'
+	case 'í', 'ì', 'î', 'ï':
+		return 'i'
+	case 'ó', 'ò', 'ô', 'õ', 'ö':
+		return 'o'
+	case 'ú', 'ù', 'û', 'ü':
+		return 'u'
+	case 'ç':
+		return 'c'
+	case 'ñ':
+		return 'n'
+	}
+	return r
+}
@@ -0,0 +1,52 @@
+package merchant
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		expected    string
+	}{
+		{name: "reference code and city", description: "UBER *TRIP 8H2K SAO PAULO", expected: "uber trip"},
+		{name: "asterisk without space", description: "UBER* TRIP", expected: "uber trip"},
+		{name: "extra whitespace", description: "  UBER   Trip ", expected: "uber trip"},
+		{name: "processor prefix", description: "PAYPAL *SPOTIFY", expected: "spotify"},
+		{name: "short processor prefix", description: "SQ *BLUE BOTTLE COFFEE", expected: "blue bottle coffee"},
+		{name: "nested processor prefixes", description: "PAYPAL *SQ *COFFEE", expected: "coffee"},
+		{name: "merchant named like a processor", description: "PAYPAL", expected: "paypal"},
+		{name: "domain", description: "NETFLIX.COM", expected: "netflix"},
+		{name: "domain with path, region and city", description: "AMAZON.COM*2K3L4 SEATTLE WA", expected: "amazon"},
+		{name: "card suffix", description: "POSTO SHELL 1234 FINAL 5678", expected: "posto shell"},
+		{name: "masked card", description: "MERCADO LIVRE ****4821", expected: "mercado livre"},
+		{name: "city with country", description: "IFOOD RIO DE JANEIRO BR", expected: "ifood"},
+		{name: "accents", description: "Padaria São João", expected: "padaria sao joao"},
+		{name: "apostrophe", description: "MCDONALD'S #0423", expected: "mcdonalds"},
+		{name: "leading digits kept", description: "99 *POP 7F2A", expected: "99 pop"},
+		{name: "city alone kept", description: "SAO PAULO", expected: "sao paulo"},
+		{name: "blank", description: "   ", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Key(tt.description))
+		})
+	}
+}
+
+func TestKeyIsStable(t *testing.T) {
+	for _, description := range []string{"UBER *TRIP 8H2K SAO PAULO", "AMAZON.COM*2K3L4 SEATTLE WA", "Padaria São João"} {
+		key := Key(description)
+		assert.Equal(t, key, Key(key))
+	}
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "Uber Trip", Name("uber trip"))
+	assert.Equal(t, "99 Pop", Name("99 pop"))
+	assert.Equal(t, "", Name(""))
+}
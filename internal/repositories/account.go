@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+)
+
+type AccountRepository interface {
+	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Account, error)
+	GetByID(ctx context.Context, id int32) (*model.Account, error)
+	Create(ctx context.Context, account *model.Account) (*model.Account, error)
+}
+
+type accountRepository struct {
+	db *sql.DB
+}
+
+func NewAccountRepository(db *sql.DB) AccountRepository {
+	return &accountRepository{db: db}
+}
+
+func (r *accountRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Account, error) {
+	query := table.Account.SELECT(
+		table.Account.AllColumns,
+	).FROM(
+		table.Account,
+	).WHERE(
+		table.Account.UserID.EQ(postgres.UUID(userID)),
+	).ORDER_BY(
+		table.Account.Name.ASC(),
+	).LIMIT(int64(limit)).OFFSET(int64(offset))
+
+	var dest []model.Account
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *accountRepository) GetByID(ctx context.Context, id int32) (*model.Account, error) {
+	query := table.Account.SELECT(
+		table.Account.AllColumns,
+	).FROM(
+		table.Account,
+	).WHERE(
+		table.Account.ID.EQ(postgres.Int32(id)),
+	)
+
+	var dest model.Account
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+func (r *accountRepository) Create(ctx context.Context, account *model.Account) (*model.Account, error) {
+	query := table.Account.INSERT(
+		table.Account.UserID,
+		table.Account.Name,
+		table.Account.Type,
+	).VALUES(
+		account.UserID,
+		account.Name,
+		account.Type,
+	).RETURNING(table.Account.AllColumns)
+
+	err := query.QueryContext(ctx, r.db, account)
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
@@ -0,0 +1,405 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+const (
+	// Deletion requested by the user from the app
+	DeletionRequestedByUser = "user"
+	// Deletion following the removal of the user from Clerk
+	DeletionRequestedByClerk = "clerk"
+)
+
+type AccountDeletionRepository interface {
+	// GetPending returns the scheduled deletion of a user, nil when there is none.
+	GetPending(ctx context.Context, userID uuid.UUID) (*model.AccountDeletion, error)
+	// Schedule marks the user for deletion. A deletion already scheduled is
+	// returned unchanged.
+	Schedule(ctx context.Context, user *model.User, requestedBy string, scheduledAt time.Time) (*model.AccountDeletion, error)
+	// Cancel unschedules the deletion of a user.
+	Cancel(ctx context.Context, userID uuid.UUID) (*model.AccountDeletion, error)
+	// ListDue returns the deletions whose grace period is over.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]model.AccountDeletion, error)
+	// Purge deletes the user and everything they own, and returns the storage
+	// keys of the deleted attachments.
+	Purge(ctx context.Context, deletion *model.AccountDeletion) ([]string, error)
+}
+
+type accountDeletionRepository struct {
+	db *sql.DB
+}
+
+func NewAccountDeletionRepository(db *sql.DB) AccountDeletionRepository {
+	return &accountDeletionRepository{db: db}
+}
+
+func pendingDeletion(userID uuid.UUID) postgres.BoolExpression {
+	return table.AccountDeletion.UserID.EQ(postgres.UUID(userID)).
+		AND(table.AccountDeletion.CanceledAt.IS_NULL()).
+		AND(table.AccountDeletion.PurgedAt.IS_NULL())
+}
+
+func (r *accountDeletionRepository) GetPending(ctx context.Context, userID uuid.UUID) (*model.AccountDeletion, error) {
+	return r.getPending(ctx, r.db, userID)
+}
+
+func (r *accountDeletionRepository) getPending(ctx context.Context, db qrm.Queryable, userID uuid.UUID) (*model.AccountDeletion, error) {
+	var deletion model.AccountDeletion
+	err := table.AccountDeletion.SELECT(
+		table.AccountDeletion.AllColumns,
+	).WHERE(
+		pendingDeletion(userID),
+	).QueryContext(ctx, db, &deletion)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &deletion, nil
+}
+
+func (r *accountDeletionRepository) Schedule(ctx context.Context, user *model.User, requestedBy string, scheduledAt time.Time) (*model.AccountDeletion, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Locking the user serializes scheduling, cancelling and purging
+	_, err = table.User.UPDATE(
+		table.User.DeletionScheduledAt,
+	).SET(
+		postgres.TimestampExp(postgres.COALESCE(table.User.DeletionScheduledAt, postgres.TimestampT(scheduledAt))),
+	).WHERE(
+		table.User.ID.EQ(postgres.UUID(user.ID)),
+	).ExecContext(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := r.getPending(ctx, tx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	var deletion model.AccountDeletion
+	err = table.AccountDeletion.INSERT(
+		table.AccountDeletion.UserID,
+		table.AccountDeletion.ClerkID,
+		table.AccountDeletion.RequestedBy,
+		table.AccountDeletion.ScheduledAt,
+	).VALUES(
+		user.ID,
+		user.ClerkID,
+		requestedBy,
+		scheduledAt,
+	).RETURNING(
+		table.AccountDeletion.AllColumns,
+	).QueryContext(ctx, tx, &deletion)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &deletion, nil
+}
+
+func (r *accountDeletionRepository) Cancel(ctx context.Context, userID uuid.UUID) (*model.AccountDeletion, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = table.User.UPDATE(
+		table.User.DeletionScheduledAt,
+	).SET(
+		postgres.NULL,
+	).WHERE(
+		table.User.ID.EQ(postgres.UUID(userID)),
+	).ExecContext(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var deletion model.AccountDeletion
+	err = table.AccountDeletion.UPDATE(
+		table.AccountDeletion.CanceledAt,
+	).SET(
+		time.Now().UTC(),
+	).WHERE(
+		pendingDeletion(userID),
+	).RETURNING(
+		table.AccountDeletion.AllColumns,
+	).QueryContext(ctx, tx, &deletion)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, u.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &deletion, nil
+}
+
+func (r *accountDeletionRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]model.AccountDeletion, error) {
+	query := table.AccountDeletion.SELECT(
+		table.AccountDeletion.AllColumns,
+	).WHERE(
+		table.AccountDeletion.CanceledAt.IS_NULL().
+			AND(table.AccountDeletion.PurgedAt.IS_NULL()).
+			AND(table.AccountDeletion.ScheduledAt.LT_EQ(postgres.TimestampT(now))),
+	).ORDER_BY(
+		table.AccountDeletion.ScheduledAt.ASC(),
+	).LIMIT(int64(limit))
+
+	var dest []model.AccountDeletion
+	if err := query.QueryContext(ctx, r.db, &dest); err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// Purge runs in a single transaction. Workspaces the user shares with others
+// survive: the rows the user created there are handed over to another member,
+// promoted to owner when no owner is left. Workspaces without other members
+// are deleted along with the personal ledger.
+func (r *accountDeletionRepository) Purge(ctx context.Context, deletion *model.AccountDeletion) ([]string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	user := postgres.UUID(deletion.UserID)
+
+	// Business Logic: A deletion cancelled since it was listed is left alone
+	var locked []model.User
+	err = table.User.SELECT(
+		table.User.ID,
+	).WHERE(
+		table.User.ID.EQ(user),
+	).FOR(
+		postgres.UPDATE(),
+	).QueryContext(ctx, tx, &locked)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := r.getPending(ctx, tx, deletion.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if pending == nil || pending.ID != deletion.ID {
+		return nil, u.ErrNotFound
+	}
+
+	var members []model.WorkspaceMember
+	err = table.WorkspaceMember.SELECT(
+		table.WorkspaceMember.AllColumns,
+	).WHERE(
+		table.WorkspaceMember.WorkspaceID.IN(
+			table.WorkspaceMember.SELECT(table.WorkspaceMember.WorkspaceID).WHERE(table.WorkspaceMember.UserID.EQ(user)),
+		),
+	).ORDER_BY(
+		table.WorkspaceMember.WorkspaceID.ASC(),
+		table.WorkspaceMember.CreatedAt.ASC(),
+	).QueryContext(ctx, tx, &members)
+	if err != nil {
+		return nil, err
+	}
+
+	heirs := workspaceHeirs(members, deletion.UserID)
+	var solo []postgres.Expression
+	for workspaceID, heir := range heirs {
+		if heir == nil {
+			solo = append(solo, postgres.Int32(workspaceID))
+			continue
+		}
+		if err := r.handOver(ctx, tx, workspaceID, deletion.UserID, heir); err != nil {
+			return nil, err
+		}
+	}
+
+	var attachments []model.Attachment
+	err = table.Attachment.DELETE().WHERE(
+		table.Attachment.ExpenseID.IN(table.Expense.SELECT(table.Expense.ID).WHERE(purgedExpenses(deletion.UserID, solo))).
+			OR(table.Attachment.UserID.EQ(user)),
+	).RETURNING(
+		table.Attachment.StorageKey,
+	).QueryContext(ctx, tx, &attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stmt := range purgeDeletes(deletion.UserID, solo) {
+		if _, err := stmt.ExecContext(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = table.AccountDeletion.UPDATE(
+		table.AccountDeletion.PurgedAt,
+	).SET(
+		time.Now().UTC(),
+	).WHERE(
+		table.AccountDeletion.ID.EQ(postgres.Int32(deletion.ID)),
+	).ExecContext(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(attachments))
+	for i, a := range attachments {
+		keys[i] = a.StorageKey
+	}
+	return keys, nil
+}
+
+// purgedExpenses selects the transactions purged with a user: theirs, and
+// those of the workspaces left without members.
+func purgedExpenses(userID uuid.UUID, solo []postgres.Expression) postgres.BoolExpression {
+	doomed := table.Expense.UserID.EQ(postgres.UUID(userID))
+	if len(solo) > 0 {
+		doomed = doomed.OR(table.Expense.WorkspaceID.IN(solo...))
+	}
+	return doomed
+}
+
+// purgeDeletes lists the deletes purging a user once their attachments are
+// gone, in the order they run.
+func purgeDeletes(userID uuid.UUID, solo []postgres.Expression) []postgres.DeleteStatement {
+	user := postgres.UUID(userID)
+	categories := table.Category.UserID.EQ(user)
+	if len(solo) > 0 {
+		categories = categories.OR(table.Category.WorkspaceID.IN(solo...))
+	}
+
+	// Business Logic: Rows go before the rows they reference, most foreign keys restrict deletes
+	deletes := []postgres.DeleteStatement{
+		table.Expense.DELETE().WHERE(purgedExpenses(userID, solo)),
+		table.Category.DELETE().WHERE(categories),
+		table.Settlement.DELETE().WHERE(table.Settlement.UserID.EQ(user)),
+		table.Account.DELETE().WHERE(table.Account.UserID.EQ(user)),
+		table.Tag.DELETE().WHERE(table.Tag.UserID.EQ(user)),
+		table.Merchant.DELETE().WHERE(table.Merchant.UserID.EQ(user)),
+		table.Contact.DELETE().WHERE(table.Contact.UserID.EQ(user)),
+		table.Anomaly.DELETE().WHERE(table.Anomaly.UserID.EQ(user)),
+		table.Insight.DELETE().WHERE(table.Insight.UserID.EQ(user)),
+		table.WorkspaceInvitation.DELETE().WHERE(table.WorkspaceInvitation.InvitedBy.EQ(user)),
+		table.WorkspaceMember.DELETE().WHERE(table.WorkspaceMember.UserID.EQ(user)),
+	}
+	if len(solo) > 0 {
+		deletes = append(deletes, table.Workspace.DELETE().WHERE(table.Workspace.ID.IN(solo...)))
+	}
+	deletes = append(deletes, table.User.DELETE().WHERE(table.User.ID.EQ(user)))
+	// The tombstones of the rows just deleted are nobody's to sync anymore
+	deletes = append(deletes, table.SyncTombstone.DELETE().WHERE(table.SyncTombstone.UserID.EQ(user)))
+	return deletes
+}
+
+// workspaceHeirs picks, for every workspace of the members, who takes over the
+// rows of the deleted user: another owner, else the longest-standing editor,
+// else the longest-standing member. Workspaces without other members map to nil.
+// Members are expected in joining order.
+func workspaceHeirs(members []model.WorkspaceMember, userID uuid.UUID) map[int32]*model.WorkspaceMember {
+	rank := map[string]int{RoleOwner: 0, RoleEditor: 1}
+	heirs := map[int32]*model.WorkspaceMember{}
+	for i := range members {
+		m := &members[i]
+		if _, ok := heirs[m.WorkspaceID]; !ok {
+			heirs[m.WorkspaceID] = nil
+		}
+		if m.UserID == userID {
+			continue
+		}
+		heir := heirs[m.WorkspaceID]
+		if heir == nil || rankOf(rank, m.Role) < rankOf(rank, heir.Role) {
+			heirs[m.WorkspaceID] = m
+		}
+	}
+	return heirs
+}
+
+func rankOf(rank map[string]int, role string) int {
+	if r, ok := rank[role]; ok {
+		return r
+	}
+	return len(rank)
+}
+
+// handOver gives the rows the user created in a shared workspace to the heir.
+// References to the personal accounts and contacts of the user are dropped.
+// Transfers, which only move money between those accounts, stay with the user
+// and are purged with the personal ledger.
+func (r *accountDeletionRepository) handOver(ctx context.Context, tx *sql.Tx, workspaceID int32, userID uuid.UUID, heir *model.WorkspaceMember) error {
+	user := postgres.UUID(userID)
+	heirID := postgres.UUID(heir.UserID)
+	created := table.Expense.UserID.EQ(user).AND(table.Expense.WorkspaceID.EQ(postgres.Int32(workspaceID)))
+	createdIDs := table.Expense.SELECT(table.Expense.ID).WHERE(created)
+	workspaceIDs := table.Expense.SELECT(table.Expense.ID).WHERE(table.Expense.WorkspaceID.EQ(postgres.Int32(workspaceID)))
+
+	stmts := []postgres.Statement{
+		table.ExpenseShare.DELETE().WHERE(table.ExpenseShare.ExpenseID.IN(createdIDs)),
+		table.Anomaly.UPDATE(table.Anomaly.UserID).SET(heirID).WHERE(table.Anomaly.ExpenseID.IN(createdIDs)),
+		table.Attachment.UPDATE(table.Attachment.UserID).SET(heirID).WHERE(
+			table.Attachment.UserID.EQ(user).AND(table.Attachment.ExpenseID.IN(workspaceIDs)),
+		),
+		table.Expense.UPDATE(
+			table.Expense.UserID,
+			table.Expense.AccountID,
+			table.Expense.TransferAccountID,
+			table.Expense.PaidByContactID,
+		).SET(
+			heirID,
+			postgres.NULL,
+			postgres.NULL,
+			postgres.NULL,
+		).WHERE(created.AND(table.Expense.Kind.NOT_EQ(postgres.String(KindTransfer)))),
+		table.Category.UPDATE(table.Category.UserID).SET(heirID).WHERE(
+			table.Category.UserID.EQ(user).AND(table.Category.WorkspaceID.EQ(postgres.Int32(workspaceID))),
+		),
+		table.WorkspaceInvitation.UPDATE(table.WorkspaceInvitation.InvitedBy).SET(heirID).WHERE(
+			table.WorkspaceInvitation.InvitedBy.EQ(user).AND(table.WorkspaceInvitation.WorkspaceID.EQ(postgres.Int32(workspaceID))),
+		),
+	}
+	if heir.Role != RoleOwner {
+		stmts = append(stmts, table.WorkspaceMember.UPDATE(table.WorkspaceMember.Role).SET(postgres.String(RoleOwner)).WHERE(
+			table.WorkspaceMember.WorkspaceID.EQ(postgres.Int32(workspaceID)).AND(table.WorkspaceMember.UserID.EQ(heirID)),
+		))
+	}
+
+	for _, stmt := range stmts {
+		if _, err := stmt.ExecContext(ctx, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
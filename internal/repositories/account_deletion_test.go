@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaceHeirs(t *testing.T) {
+	deleted := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	first := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	second := uuid.MustParse("00000000-0000-0000-0000-000000000003")
+	member := func(workspaceID int32, userID uuid.UUID, role string) model.WorkspaceMember {
+		return model.WorkspaceMember{WorkspaceID: workspaceID, UserID: userID, Role: role}
+	}
+
+	tests := []struct {
+		name    string
+		members []model.WorkspaceMember
+		// Heir by workspace, uuid.Nil when the workspace goes with the user
+		expected map[int32]uuid.UUID
+	}{
+		{
+			name:     "sole member",
+			members:  []model.WorkspaceMember{member(1, deleted, RoleOwner)},
+			expected: map[int32]uuid.UUID{1: uuid.Nil},
+		},
+		{
+			name: "another owner before earlier editors",
+			members: []model.WorkspaceMember{
+				member(1, deleted, RoleOwner),
+				member(1, first, RoleEditor),
+				member(1, second, RoleOwner),
+			},
+			expected: map[int32]uuid.UUID{1: second},
+		},
+		{
+			name: "longest-standing editor",
+			members: []model.WorkspaceMember{
+				member(1, first, RoleViewer),
+				member(1, deleted, RoleOwner),
+				member(1, second, RoleEditor),
+			},
+			expected: map[int32]uuid.UUID{1: second},
+		},
+		{
+			name: "longest-standing of several editors",
+			members: []model.WorkspaceMember{
+				member(1, deleted, RoleOwner),
+				member(1, first, RoleEditor),
+				member(1, second, RoleEditor),
+			},
+			expected: map[int32]uuid.UUID{1: first},
+		},
+		{
+			name: "longest-standing viewer",
+			members: []model.WorkspaceMember{
+				member(1, deleted, RoleOwner),
+				member(1, first, RoleViewer),
+				member(1, second, RoleViewer),
+			},
+			expected: map[int32]uuid.UUID{1: first},
+		},
+		{
+			name: "every workspace on its own",
+			members: []model.WorkspaceMember{
+				member(1, deleted, RoleOwner),
+				member(2, deleted, RoleEditor),
+				member(2, first, RoleOwner),
+				member(3, second, RoleOwner),
+				member(3, deleted, RoleViewer),
+			},
+			expected: map[int32]uuid.UUID{1: uuid.Nil, 2: first, 3: second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			heirs := workspaceHeirs(tt.members, deleted)
+
+			actual := map[int32]uuid.UUID{}
+			for workspaceID, heir := range heirs {
+				actual[workspaceID] = uuid.Nil
+				if heir != nil {
+					actual[workspaceID] = heir.UserID
+				}
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestPurgeDeletes(t *testing.T) {
+	// Foreign keys restricting deletes, from the referencing table to the
+	// referenced one
+	references := [][2]string{
+		{"account", "user"},
+		{"anomaly", "user"},
+		{"category", "user"},
+		{"category", "workspace"},
+		{"contact", "user"},
+		{"expense", "account"},
+		{"expense", "category"},
+		{"expense", "contact"},
+		{"expense", "user"},
+		{"expense", "workspace"},
+		{"insight", "user"},
+		{"merchant", "user"},
+		{"settlement", "contact"},
+		{"settlement", "user"},
+		{"tag", "user"},
+		{"workspace_invitation", "user"},
+		{"workspace_member", "user"},
+	}
+	deleteFrom := regexp.MustCompile(`^\s*DELETE FROM public\."?(\w+)`)
+
+	tests := []struct {
+		name string
+		solo []postgres.Expression
+	}{
+		{name: "without workspaces to delete"},
+		{name: "with workspaces left without members", solo: []postgres.Expression{postgres.Int32(1), postgres.Int32(2)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tables []string
+			for _, stmt := range purgeDeletes(uuid.New(), tt.solo) {
+				match := deleteFrom.FindStringSubmatch(stmt.DebugSql())
+				if assert.NotNil(t, match) {
+					tables = append(tables, match[1])
+				}
+			}
+
+			assert.Equal(t, len(tt.solo) > 0, slices.Contains(tables, "workspace"))
+			for _, reference := range references {
+				from, to := slices.Index(tables, reference[0]), slices.Index(tables, reference[1])
+				if from < 0 || to < 0 {
+					continue
+				}
+				assert.Less(t, from, to, "%s before %s", reference[0], reference[1])
+			}
+			// Tombstones left by the deletes are removed last
+			assert.Equal(t, "sync_tombstone", tables[len(tables)-1])
+		})
+	}
+}
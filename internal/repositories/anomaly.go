@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type AnomalyRepository interface {
+	ListByUser(ctx context.Context, userID uuid.UUID, includeDismissed bool, limit, offset int) ([]model.Anomaly, error)
+	Create(ctx context.Context, anomaly *model.Anomaly) error
+	Dismiss(ctx context.Context, userID uuid.UUID, id int32) (*model.Anomaly, error)
+}
+
+type anomalyRepository struct {
+	db *sql.DB
+}
+
+func NewAnomalyRepository(db *sql.DB) AnomalyRepository {
+	return &anomalyRepository{db: db}
+}
+
+func (r *anomalyRepository) ListByUser(ctx context.Context, userID uuid.UUID, includeDismissed bool, limit, offset int) ([]model.Anomaly, error) {
+	live := table.Expense.SELECT(table.Expense.ID).WHERE(expenseNotDeleted)
+	condition := table.Anomaly.UserID.EQ(postgres.UUID(userID)).
+		AND(table.Anomaly.ExpenseID.IN(live))
+	if !includeDismissed {
+		condition = condition.AND(table.Anomaly.DismissedAt.IS_NULL())
+	}
+
+	query := table.Anomaly.SELECT(
+		table.Anomaly.AllColumns,
+	).FROM(
+		table.Anomaly,
+	).WHERE(
+		condition,
+	).ORDER_BY(
+		table.Anomaly.CreatedAt.DESC(),
+	).LIMIT(int64(limit)).OFFSET(int64(offset))
+
+	var dest []model.Anomaly
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// Create stores a finding. Findings already recorded for the same expense and kind are kept as is,
+// so a dismissed anomaly is never resurrected by a later detection run.
+func (r *anomalyRepository) Create(ctx context.Context, anomaly *model.Anomaly) error {
+	stmt := table.Anomaly.INSERT(
+		table.Anomaly.UserID,
+		table.Anomaly.ExpenseID,
+		table.Anomaly.Kind,
+		table.Anomaly.Score,
+		table.Anomaly.Reason,
+	).VALUES(
+		anomaly.UserID,
+		anomaly.ExpenseID,
+		anomaly.Kind,
+		anomaly.Score,
+		anomaly.Reason,
+	).ON_CONFLICT(
+		table.Anomaly.ExpenseID,
+		table.Anomaly.Kind,
+	).DO_NOTHING()
+
+	_, err := stmt.ExecContext(ctx, r.db)
+	return err
+}
+
+func (r *anomalyRepository) Dismiss(ctx context.Context, userID uuid.UUID, id int32) (*model.Anomaly, error) {
+	stmt := table.Anomaly.UPDATE(
+		table.Anomaly.DismissedAt,
+	).SET(
+		postgres.TimestampExp(postgres.Raw("COALESCE(dismissed_at, NOW())")),
+	).WHERE(
+		table.Anomaly.ID.EQ(postgres.Int32(id)).
+			AND(table.Anomaly.UserID.EQ(postgres.UUID(userID))),
+	).RETURNING(
+		table.Anomaly.AllColumns,
+	)
+
+	var dest model.Anomaly
+	err := stmt.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, u.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
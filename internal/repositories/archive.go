@@ -0,0 +1,195 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+	"github.com/igorschechtel/clearflow-backend/internal/archive"
+)
+
+// Rows per INSERT statement of a restore, well below the parameter limit
+const restoreChunkSize = 500
+
+// ArchiveRepository reads and writes all the data a user owns at once.
+type ArchiveRepository interface {
+	// Load reads everything the user owns in their personal ledger.
+	Load(ctx context.Context, userID uuid.UUID) (*archive.Data, error)
+	// HasData reports whether the user owns any ledger data.
+	HasData(ctx context.Context, userID uuid.UUID) (bool, error)
+	// AllocateIDs reserves ids from the sequence of each table.
+	AllocateIDs(ctx context.Context, counts map[string]int) (map[string][]int32, error)
+	// Restore inserts remapped archive rows, keeping their ids and timestamps.
+	Restore(ctx context.Context, userID uuid.UUID, data *archive.Data) error
+}
+
+type archiveRepository struct {
+	db *sql.DB
+}
+
+func NewArchiveRepository(db *sql.DB) ArchiveRepository {
+	return &archiveRepository{db: db}
+}
+
+func (r *archiveRepository) Load(ctx context.Context, userID uuid.UUID) (*archive.Data, error) {
+	user := postgres.UUID(userID)
+	personal := table.Expense.UserID.EQ(user).AND(table.Expense.WorkspaceID.IS_NULL())
+	expenseIDs := table.Expense.SELECT(table.Expense.ID).WHERE(personal)
+
+	data := &archive.Data{}
+	queries := []struct {
+		query postgres.Statement
+		dest  any
+	}{
+		{table.User.SELECT(table.User.AllColumns).WHERE(table.User.ID.EQ(user)), &data.Profile},
+		{table.Account.SELECT(table.Account.AllColumns).WHERE(table.Account.UserID.EQ(user)).ORDER_BY(table.Account.ID), &data.Accounts},
+		{table.Category.SELECT(table.Category.AllColumns).WHERE(table.Category.UserID.EQ(user).AND(table.Category.WorkspaceID.IS_NULL())).ORDER_BY(table.Category.ID), &data.Categories},
+		{table.Tag.SELECT(table.Tag.AllColumns).WHERE(table.Tag.UserID.EQ(user)).ORDER_BY(table.Tag.ID), &data.Tags},
+		{table.Merchant.SELECT(table.Merchant.AllColumns).WHERE(table.Merchant.UserID.EQ(user)).ORDER_BY(table.Merchant.ID), &data.Merchants},
+		{table.Contact.SELECT(table.Contact.AllColumns).WHERE(table.Contact.UserID.EQ(user)).ORDER_BY(table.Contact.ID), &data.Contacts},
+		{table.Expense.SELECT(table.Expense.AllColumns).WHERE(personal).ORDER_BY(table.Expense.ID), &data.Expenses},
+		{table.ExpenseTag.SELECT(table.ExpenseTag.AllColumns).WHERE(table.ExpenseTag.ExpenseID.IN(expenseIDs)).ORDER_BY(table.ExpenseTag.ExpenseID, table.ExpenseTag.TagID), &data.ExpenseTags},
+		{table.ExpenseSplit.SELECT(table.ExpenseSplit.AllColumns).WHERE(table.ExpenseSplit.ExpenseID.IN(expenseIDs)).ORDER_BY(table.ExpenseSplit.ID), &data.ExpenseSplits},
+		{table.ExpenseShare.SELECT(table.ExpenseShare.AllColumns).WHERE(table.ExpenseShare.ExpenseID.IN(expenseIDs)).ORDER_BY(table.ExpenseShare.ID), &data.ExpenseShares},
+		{table.Settlement.SELECT(table.Settlement.AllColumns).WHERE(table.Settlement.UserID.EQ(user)).ORDER_BY(table.Settlement.ID), &data.Settlements},
+		{table.Anomaly.SELECT(table.Anomaly.AllColumns).WHERE(table.Anomaly.ExpenseID.IN(expenseIDs)).ORDER_BY(table.Anomaly.ID), &data.Anomalies},
+		{table.Attachment.SELECT(table.Attachment.AllColumns).WHERE(table.Attachment.ExpenseID.IN(expenseIDs)).ORDER_BY(table.Attachment.ID), &data.Attachments},
+	}
+
+	// A single read-only transaction gives a consistent snapshot of all tables
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, q := range queries {
+		if err := q.query.QueryContext(ctx, tx, q.dest); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (r *archiveRepository) HasData(ctx context.Context, userID uuid.UUID) (bool, error) {
+	user := postgres.UUID(userID)
+	owned := []postgres.SelectStatement{
+		table.Account.SELECT(table.Account.ID).WHERE(table.Account.UserID.EQ(user)),
+		table.Category.SELECT(table.Category.ID).WHERE(table.Category.UserID.EQ(user)),
+		table.Tag.SELECT(table.Tag.ID).WHERE(table.Tag.UserID.EQ(user)),
+		table.Merchant.SELECT(table.Merchant.ID).WHERE(table.Merchant.UserID.EQ(user)),
+		table.Contact.SELECT(table.Contact.ID).WHERE(table.Contact.UserID.EQ(user)),
+		table.Expense.SELECT(table.Expense.ID).WHERE(table.Expense.UserID.EQ(user)),
+		table.Settlement.SELECT(table.Settlement.ID).WHERE(table.Settlement.UserID.EQ(user)),
+	}
+
+	conditions := make([]postgres.BoolExpression, len(owned))
+	for i, query := range owned {
+		conditions[i] = postgres.EXISTS(query)
+	}
+
+	var dest struct {
+		HasData bool
+	}
+	err := postgres.SELECT(
+		postgres.OR(conditions...).AS("has_data"),
+	).QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return false, err
+	}
+
+	return dest.HasData, nil
+}
+
+func (r *archiveRepository) AllocateIDs(ctx context.Context, counts map[string]int) (map[string][]int32, error) {
+	result := map[string][]int32{}
+	for name, n := range counts {
+		if n == 0 {
+			continue
+		}
+
+		query := postgres.RawStatement(
+			`SELECT nextval(pg_get_serial_sequence(#table, 'id')) AS "id" FROM generate_series(1, #count)`,
+			postgres.RawArgs{"#table": name, "#count": n},
+		)
+		var dest []struct {
+			ID int32
+		}
+		if err := query.QueryContext(ctx, r.db, &dest); err != nil {
+			return nil, err
+		}
+		for _, row := range dest {
+			result[name] = append(result[name], row.ID)
+		}
+	}
+	return result, nil
+}
+
+func (r *archiveRepository) Restore(ctx context.Context, userID uuid.UUID, data *archive.Data) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = table.User.UPDATE(
+		table.User.Locale,
+	).SET(
+		data.Profile.Locale,
+	).WHERE(
+		table.User.ID.EQ(postgres.UUID(userID)),
+	).ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	// Refunds reference the expenses they refund, which must be inserted first
+	expenses := append([]model.Expense(nil), data.Expenses...)
+	sort.SliceStable(expenses, func(i, j int) bool {
+		return expenses[i].RefundOfID == nil && expenses[j].RefundOfID != nil
+	})
+
+	// Change IDs are left to the database, so that restored rows get synced.
+	// So are public IDs, which the archived rows may still hold on this server.
+	inserts := []error{
+		insertRows(ctx, tx, table.Account, table.Account.AllColumns.Except(table.Account.ChangeID), data.Accounts),
+		insertRows(ctx, tx, table.Category, table.Category.AllColumns.Except(table.Category.ChangeID, table.Category.PublicID), data.Categories),
+		insertRows(ctx, tx, table.Tag, table.Tag.AllColumns.Except(table.Tag.ChangeID), data.Tags),
+		insertRows(ctx, tx, table.Merchant, table.Merchant.AllColumns.Except(table.Merchant.ChangeID), data.Merchants),
+		insertRows(ctx, tx, table.Contact, table.Contact.AllColumns.Except(table.Contact.ChangeID), data.Contacts),
+		insertRows(ctx, tx, table.Expense, table.Expense.AllColumns.Except(table.Expense.ChangeID, table.Expense.PublicID), expenses),
+		insertRows(ctx, tx, table.ExpenseTag, table.ExpenseTag.AllColumns, data.ExpenseTags),
+		insertRows(ctx, tx, table.ExpenseSplit, table.ExpenseSplit.AllColumns, data.ExpenseSplits),
+		insertRows(ctx, tx, table.ExpenseShare, table.ExpenseShare.AllColumns, data.ExpenseShares),
+		insertRows(ctx, tx, table.Settlement, table.Settlement.AllColumns, data.Settlements),
+		insertRows(ctx, tx, table.Anomaly, table.Anomaly.AllColumns, data.Anomalies),
+		insertRows(ctx, tx, table.Attachment, table.Attachment.AllColumns, data.Attachments),
+	}
+	for _, err := range inserts {
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// insertRows inserts rows with all their columns, a chunk per statement.
+func insertRows[T any](ctx context.Context, tx *sql.Tx, tbl postgres.Table, columns postgres.ColumnList, rows []T) error {
+	for start := 0; start < len(rows); start += restoreChunkSize {
+		end := min(start+restoreChunkSize, len(rows))
+		if _, err := tbl.INSERT(columns).MODELS(rows[start:end]).ExecContext(ctx, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
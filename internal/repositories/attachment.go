@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+)
+
+type AttachmentRepository interface {
+	ListByExpense(ctx context.Context, expenseID int32) ([]model.Attachment, error)
+	// GetByID returns an attachment unless its expense is in the trash.
+	GetByID(ctx context.Context, id int32) (*model.Attachment, error)
+	Create(ctx context.Context, attachment *model.Attachment) (*model.Attachment, error)
+	Delete(ctx context.Context, id int32, updatedAt time.Time) error
+}
+
+type attachmentRepository struct {
+	db *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) ListByExpense(ctx context.Context, expenseID int32) ([]model.Attachment, error) {
+	query := table.Attachment.SELECT(
+		table.Attachment.AllColumns,
+	).FROM(
+		table.Attachment,
+	).WHERE(
+		table.Attachment.ExpenseID.EQ(postgres.Int32(expenseID)),
+	).ORDER_BY(
+		table.Attachment.CreatedAt.ASC(),
+	)
+
+	var dest []model.Attachment
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, id int32) (*model.Attachment, error) {
+	query := table.Attachment.SELECT(
+		table.Attachment.AllColumns,
+	).FROM(
+		table.Attachment.
+			INNER_JOIN(table.Expense, table.Expense.ID.EQ(table.Attachment.ExpenseID)),
+	).WHERE(
+		table.Attachment.ID.EQ(postgres.Int32(id)).AND(expenseNotDeleted),
+	)
+
+	var dest model.Attachment
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *model.Attachment) (*model.Attachment, error) {
+	query := table.Attachment.INSERT(
+		table.Attachment.ExpenseID,
+		table.Attachment.UserID,
+		table.Attachment.StorageKey,
+		table.Attachment.FileName,
+		table.Attachment.ContentType,
+		table.Attachment.SizeBytes,
+	).VALUES(
+		attachment.ExpenseID,
+		attachment.UserID,
+		attachment.StorageKey,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.SizeBytes,
+	).RETURNING(table.Attachment.AllColumns)
+
+	err := query.QueryContext(ctx, r.db, attachment)
+	if err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id int32, updatedAt time.Time) error {
+	query := table.Attachment.DELETE().WHERE(
+		table.Attachment.ID.EQ(postgres.Int32(id)).AND(atVersion(table.Attachment.UpdatedAt, updatedAt)),
+	)
+
+	result, err := query.ExecContext(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	return checkVersion(result, updatedAt)
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+)
+
+// AuditFilter narrows down audit trail listings. Nil and empty fields are not applied.
+type AuditFilter struct {
+	// User the entries are about
+	UserID *uuid.UUID
+	// Clerk ID of a user or source of a webhook
+	ActorID string
+	Action  string
+	// Creation time range, both ends inclusive
+	From *time.Time
+	To   *time.Time
+}
+
+// AuditRepository stores the audit trail, which is append-only: entries are
+// never updated nor deleted.
+type AuditRepository interface {
+	Create(ctx context.Context, entry *model.AuditLog) error
+	// List lists the entries matching filter, latest first.
+	List(ctx context.Context, filter AuditFilter, limit, offset int) ([]model.AuditLog, error)
+}
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(ctx context.Context, entry *model.AuditLog) error {
+	stmt := table.AuditLog.INSERT(
+		table.AuditLog.ActorType,
+		table.AuditLog.ActorID,
+		table.AuditLog.UserID,
+		table.AuditLog.Action,
+		table.AuditLog.EntityType,
+		table.AuditLog.EntityID,
+		table.AuditLog.Before,
+		table.AuditLog.After,
+		table.AuditLog.RequestID,
+		table.AuditLog.IP,
+	).MODEL(
+		entry,
+	).RETURNING(
+		table.AuditLog.ID,
+		table.AuditLog.CreatedAt,
+	)
+
+	return stmt.QueryContext(ctx, r.db, entry)
+}
+
+func (r *auditRepository) List(ctx context.Context, filter AuditFilter, limit, offset int) ([]model.AuditLog, error) {
+	condition := postgres.Bool(true)
+	if filter.UserID != nil {
+		condition = condition.AND(table.AuditLog.UserID.EQ(postgres.UUID(*filter.UserID)))
+	}
+	if filter.ActorID != "" {
+		condition = condition.AND(table.AuditLog.ActorID.EQ(postgres.String(filter.ActorID)))
+	}
+	if filter.Action != "" {
+		condition = condition.AND(table.AuditLog.Action.EQ(postgres.String(filter.Action)))
+	}
+	if filter.From != nil {
+		condition = condition.AND(table.AuditLog.CreatedAt.GT_EQ(postgres.TimestampT(*filter.From)))
+	}
+	if filter.To != nil {
+		condition = condition.AND(table.AuditLog.CreatedAt.LT_EQ(postgres.TimestampT(*filter.To)))
+	}
+
+	query := table.AuditLog.SELECT(
+		table.AuditLog.AllColumns,
+	).FROM(
+		table.AuditLog,
+	).WHERE(
+		condition,
+	).ORDER_BY(
+		table.AuditLog.CreatedAt.DESC(),
+		table.AuditLog.ID.DESC(),
+	).LIMIT(int64(limit)).OFFSET(int64(offset))
+
+	var dest []model.AuditLog
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
@@ -3,17 +3,44 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
 	"github.com/google/uuid"
 	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
 	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
 )
 
+// categoryNotDeleted leaves out the categories in the trash. Every query but
+// those of the trash applies it.
+var categoryNotDeleted = table.Category.DeletedAt.IS_NULL()
+
 type CategoryRepository interface {
-	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Category, error)
+	ListByScope(ctx context.Context, scope Scope, kind string, limit, offset int) ([]model.Category, error)
+	// ListAllByUser lists every category of the user to name their transactions,
+	// including those in the trash, which transactions keep until it is purged.
+	ListAllByUser(ctx context.Context, userID uuid.UUID) ([]model.Category, error)
 	GetByID(ctx context.Context, id int32) (*model.Category, error)
+	// PublicIDs maps the given serial keys to the public IDs of their
+	// categories, those in the trash included. Unknown keys are left out.
+	PublicIDs(ctx context.Context, ids []int32) (map[int32]uuid.UUID, error)
+	// IDsByPublicID maps the given public IDs to the serial keys of their
+	// categories, those in the trash included. Unknown IDs are left out.
+	IDsByPublicID(ctx context.Context, publicIDs []uuid.UUID) (map[uuid.UUID]int32, error)
 	Create(ctx context.Context, category *model.Category) (*model.Category, error)
+	// Delete moves a category to the trash. Its transactions keep it.
+	Delete(ctx context.Context, id int32, updatedAt time.Time) error
+	// ListDeleted lists the categories of the ledger in the trash, latest deleted first.
+	ListDeleted(ctx context.Context, scope Scope, limit, offset int) ([]model.Category, error)
+	GetDeletedByID(ctx context.Context, id int32) (*model.Category, error)
+	Restore(ctx context.Context, id int32) (*model.Category, error)
+	// PurgeDeleted deletes the categories in the trash since before the given
+	// time, unassigning them from their transactions. Categories still used by
+	// split lines stay in the trash. It returns how many were deleted.
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
 
 type categoryRepository struct {
@@ -24,13 +51,19 @@ func NewCategoryRepository(db *sql.DB) CategoryRepository {
 	return &categoryRepository{db: db}
 }
 
-func (r *categoryRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Category, error) {
+// ListByScope lists the ledger's categories of the given kind, or of all kinds when kind is empty.
+func (r *categoryRepository) ListByScope(ctx context.Context, scope Scope, kind string, limit, offset int) ([]model.Category, error) {
+	condition := scope.condition(table.Category.UserID, table.Category.WorkspaceID).AND(categoryNotDeleted)
+	if kind != "" {
+		condition = condition.AND(table.Category.Kind.EQ(postgres.String(kind)))
+	}
+
 	query := table.Category.SELECT(
 		table.Category.AllColumns,
 	).FROM(
 		table.Category,
 	).WHERE(
-		table.Category.UserID.EQ(postgres.UUID(userID)),
+		condition,
 	).ORDER_BY(
 		table.Category.CreatedAt.DESC(),
 	).LIMIT(int64(limit)).OFFSET(int64(offset))
@@ -45,19 +78,39 @@ func (r *categoryRepository) ListByUser(ctx context.Context, userID uuid.UUID, l
 	return dest, nil
 }
 
+func (r *categoryRepository) ListAllByUser(ctx context.Context, userID uuid.UUID) ([]model.Category, error) {
+	query := table.Category.SELECT(
+		table.Category.AllColumns,
+	).FROM(
+		table.Category,
+	).WHERE(
+		table.Category.UserID.EQ(postgres.UUID(userID)),
+	).ORDER_BY(
+		table.Category.Name.ASC(),
+	)
+
+	var dest []model.Category
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
 func (r *categoryRepository) GetByID(ctx context.Context, id int32) (*model.Category, error) {
 	query := table.Category.SELECT(
 		table.Category.AllColumns,
 	).FROM(
 		table.Category,
 	).WHERE(
-		table.Category.ID.EQ(postgres.Int32(id)),
+		table.Category.ID.EQ(postgres.Int32(id)).AND(categoryNotDeleted),
 	)
 
 	var dest model.Category
 	err := query.QueryContext(ctx, r.db, &dest)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, qrm.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
@@ -66,17 +119,85 @@ func (r *categoryRepository) GetByID(ctx context.Context, id int32) (*model.Cate
 	return &dest, nil
 }
 
+func (r *categoryRepository) PublicIDs(ctx context.Context, ids []int32) (map[int32]uuid.UUID, error) {
+	result := map[int32]uuid.UUID{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	idExpressions := make([]postgres.Expression, len(ids))
+	for i, id := range ids {
+		idExpressions[i] = postgres.Int32(id)
+	}
+
+	query := table.Category.SELECT(
+		table.Category.ID,
+		table.Category.PublicID,
+	).FROM(
+		table.Category,
+	).WHERE(
+		table.Category.ID.IN(idExpressions...),
+	)
+
+	var dest []model.Category
+	if err := query.QueryContext(ctx, r.db, &dest); err != nil {
+		return nil, err
+	}
+
+	for _, category := range dest {
+		result[category.ID] = category.PublicID
+	}
+	return result, nil
+}
+
+func (r *categoryRepository) IDsByPublicID(ctx context.Context, publicIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	result := map[uuid.UUID]int32{}
+	if len(publicIDs) == 0 {
+		return result, nil
+	}
+
+	idExpressions := make([]postgres.Expression, len(publicIDs))
+	for i, id := range publicIDs {
+		idExpressions[i] = postgres.UUID(id)
+	}
+
+	query := table.Category.SELECT(
+		table.Category.ID,
+		table.Category.PublicID,
+	).FROM(
+		table.Category,
+	).WHERE(
+		table.Category.PublicID.IN(idExpressions...),
+	)
+
+	var dest []model.Category
+	if err := query.QueryContext(ctx, r.db, &dest); err != nil {
+		return nil, err
+	}
+
+	for _, category := range dest {
+		result[category.PublicID] = category.ID
+	}
+	return result, nil
+}
+
 func (r *categoryRepository) Create(ctx context.Context, category *model.Category) (*model.Category, error) {
 	query := table.Category.INSERT(
+		table.Category.PublicID,
 		table.Category.UserID,
 		table.Category.Name,
 		table.Category.Description,
 		table.Category.ColorHex,
+		table.Category.Kind,
+		table.Category.WorkspaceID,
 	).VALUES(
+		publicIDOrDefault(category.PublicID),
 		category.UserID,
 		category.Name,
 		category.Description,
 		category.ColorHex,
+		category.Kind,
+		category.WorkspaceID,
 	).RETURNING(table.Category.AllColumns)
 
 	err := query.QueryContext(ctx, r.db, category)
@@ -86,3 +207,134 @@ func (r *categoryRepository) Create(ctx context.Context, category *model.Categor
 
 	return category, nil
 }
+
+func (r *categoryRepository) Delete(ctx context.Context, id int32, updatedAt time.Time) error {
+	query := table.Category.UPDATE(
+		table.Category.DeletedAt,
+	).SET(
+		time.Now().UTC(),
+	).WHERE(
+		table.Category.ID.EQ(postgres.Int32(id)).
+			AND(categoryNotDeleted).
+			AND(atVersion(table.Category.UpdatedAt, updatedAt)),
+	)
+
+	result, err := query.ExecContext(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	return checkVersion(result, updatedAt)
+}
+
+func (r *categoryRepository) ListDeleted(ctx context.Context, scope Scope, limit, offset int) ([]model.Category, error) {
+	query := table.Category.SELECT(
+		table.Category.AllColumns,
+	).FROM(
+		table.Category,
+	).WHERE(
+		scope.condition(table.Category.UserID, table.Category.WorkspaceID).
+			AND(table.Category.DeletedAt.IS_NOT_NULL()),
+	).ORDER_BY(
+		table.Category.DeletedAt.DESC(),
+		table.Category.ID.DESC(),
+	).LIMIT(int64(limit)).OFFSET(int64(offset))
+
+	var dest []model.Category
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *categoryRepository) GetDeletedByID(ctx context.Context, id int32) (*model.Category, error) {
+	query := table.Category.SELECT(
+		table.Category.AllColumns,
+	).FROM(
+		table.Category,
+	).WHERE(
+		table.Category.ID.EQ(postgres.Int32(id)).AND(table.Category.DeletedAt.IS_NOT_NULL()),
+	)
+
+	var dest model.Category
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if errors.Is(err, qrm.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+func (r *categoryRepository) Restore(ctx context.Context, id int32) (*model.Category, error) {
+	query := table.Category.UPDATE(
+		table.Category.DeletedAt,
+	).SET(
+		postgres.NULL,
+	).WHERE(
+		table.Category.ID.EQ(postgres.Int32(id)).AND(table.Category.DeletedAt.IS_NOT_NULL()),
+	).RETURNING(
+		table.Category.AllColumns,
+	)
+
+	var dest model.Category
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if errors.Is(err, qrm.ErrNoRows) {
+			return nil, u.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+func (r *categoryRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	inSplits := postgres.SELECT(
+		table.ExpenseSplit.CategoryID,
+	).FROM(
+		table.ExpenseSplit,
+	)
+	expired := table.Category.SELECT(
+		table.Category.ID,
+	).WHERE(
+		table.Category.DeletedAt.LT(postgres.TimestampT(before)).
+			AND(table.Category.ID.NOT_IN(inSplits)),
+	)
+
+	_, err = table.Expense.UPDATE(
+		table.Expense.CategoryID,
+	).SET(
+		postgres.NULL,
+	).WHERE(
+		table.Expense.CategoryID.IN(expired),
+	).ExecContext(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := table.Category.DELETE().WHERE(
+		table.Category.ID.IN(expired),
+	).ExecContext(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
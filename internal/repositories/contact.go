@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+)
+
+type ContactRepository interface {
+	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Contact, error)
+	GetByID(ctx context.Context, id int32) (*model.Contact, error)
+	Create(ctx context.Context, contact *model.Contact) (*model.Contact, error)
+	Update(ctx context.Context, contact *model.Contact) (*model.Contact, error)
+}
+
+type contactRepository struct {
+	db *sql.DB
+}
+
+func NewContactRepository(db *sql.DB) ContactRepository {
+	return &contactRepository{db: db}
+}
+
+func (r *contactRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Contact, error) {
+	query := table.Contact.SELECT(
+		table.Contact.AllColumns,
+	).FROM(
+		table.Contact,
+	).WHERE(
+		table.Contact.UserID.EQ(postgres.UUID(userID)),
+	).ORDER_BY(
+		table.Contact.Name.ASC(),
+	).LIMIT(int64(limit)).OFFSET(int64(offset))
+
+	var dest []model.Contact
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *contactRepository) GetByID(ctx context.Context, id int32) (*model.Contact, error) {
+	query := table.Contact.SELECT(
+		table.Contact.AllColumns,
+	).FROM(
+		table.Contact,
+	).WHERE(
+		table.Contact.ID.EQ(postgres.Int32(id)),
+	)
+
+	var dest model.Contact
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+func (r *contactRepository) Create(ctx context.Context, contact *model.Contact) (*model.Contact, error) {
+	query := table.Contact.INSERT(
+		table.Contact.UserID,
+		table.Contact.Name,
+		table.Contact.Email,
+	).VALUES(
+		contact.UserID,
+		contact.Name,
+		contact.Email,
+	).RETURNING(table.Contact.AllColumns)
+
+	err := query.QueryContext(ctx, r.db, contact)
+	if err != nil {
+		return nil, err
+	}
+
+	return contact, nil
+}
+
+func (r *contactRepository) Update(ctx context.Context, contact *model.Contact) (*model.Contact, error) {
+	query := table.Contact.UPDATE(
+		table.Contact.Name,
+		table.Contact.Email,
+	).SET(
+		contact.Name,
+		contact.Email,
+	).WHERE(
+		table.Contact.ID.EQ(postgres.Int32(contact.ID)).AND(atVersion(table.Contact.UpdatedAt, contact.UpdatedAt)),
+	).RETURNING(table.Contact.AllColumns)
+
+	err := query.QueryContext(ctx, r.db, contact)
+	if err != nil {
+		return nil, versionError(err, contact.UpdatedAt)
+	}
+
+	return contact, nil
+}
@@ -3,16 +3,156 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
 	"github.com/google/uuid"
 	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
 	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+	"github.com/igorschechtel/clearflow-backend/internal/revision"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
 )
 
+// Transaction kinds stored in expense.kind and category.kind
+const (
+	KindExpense  = "expense"
+	KindIncome   = "income"
+	KindRefund   = "refund"
+	KindTransfer = "transfer"
+)
+
+// ExpenseFilter narrows down expense listings. Nil fields are not applied.
+type ExpenseFilter struct {
+	// Shared ledger listed instead of the user's personal one
+	WorkspaceID *int32
+	// Kind of transaction listed, all kinds when empty
+	Kind string
+	// Category of the transaction or of one of its split lines
+	CategoryID *int32
+	// Only transactions without a category, on themselves or on split lines
+	Uncategorized bool
+	// Account the transaction moves money in or out of
+	AccountID *int32
+	// Names of tags of which the transaction must have at least one
+	Tags []string
+	// Purchase date range, both ends inclusive
+	From *time.Time
+	To   *time.Time
+}
+
+// ExportLine is a transaction along with the names of what it references.
+type ExportLine struct {
+	model.Expense
+	CategoryName *string
+	AccountName  *string
+	MerchantName *string
+	// Tag names separated by exportTagSeparator
+	TagNames *string
+}
+
+// Separator of the tag names of an export line, which cannot appear in a name
+const exportTagSeparator = "\x1f"
+
+// Rows per multi-row INSERT, which keeps statements well under the limit of
+// 65535 parameters
+const insertBatchRows = 1000
+
+// ExpenseInsert is a transaction to create along with its tags and split lines.
+type ExpenseInsert struct {
+	Expense model.Expense
+	// Normalized names of the tags, created when missing
+	TagNames []string
+	// Key and name of the merchant, created when missing, or nil for none
+	Merchant *model.Merchant
+	Splits   []model.ExpenseSplit
+}
+
+// BulkUpdate holds the changes applied to every transaction of a bulk update.
+// Nil and empty fields are left alone.
+type BulkUpdate struct {
+	CategoryID *int32
+	// Names of the tags to add, created for the author of each transaction
+	// when missing
+	AddTags []string
+	// Names of the tags to remove, whoever owns them
+	RemoveTags []string
+}
+
+// expenseNotDeleted leaves out the transactions in the trash. Every query but
+// those of the trash applies it.
+var expenseNotDeleted = table.Expense.DeletedAt.IS_NULL()
+
+// Tags returns the tag names of the line.
+func (l ExportLine) Tags() []string {
+	if l.TagNames == nil || *l.TagNames == "" {
+		return nil
+	}
+	return strings.Split(*l.TagNames, exportTagSeparator)
+}
+
 type ExpenseRepository interface {
-	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Expense, error)
-	Create(ctx context.Context, expense *model.Expense) (*model.Expense, error)
+	ListByUser(ctx context.Context, userID uuid.UUID, filter ExpenseFilter, limit, offset int) ([]model.Expense, error)
+	// Export calls fn with every transaction matching filter, oldest first,
+	// reading them one at a time.
+	Export(ctx context.Context, userID uuid.UUID, filter ExpenseFilter, fn func(line ExportLine) error) error
+	ListByUserInPeriod(ctx context.Context, userID uuid.UUID, kind string, period u.Period) ([]model.Expense, error)
+	ListByUserBilledFrom(ctx context.Context, userID uuid.UUID, kind string, from time.Time) ([]model.Expense, error)
+	// GetByID returns an expense, nil when there is none or it is in the trash.
+	GetByID(ctx context.Context, id int32) (*model.Expense, error)
+	// ListByIDs lists the given transactions, by id. Unknown ids are left out.
+	ListByIDs(ctx context.Context, ids []int32) ([]model.Expense, error)
+	// PublicIDs maps the given serial keys to the public IDs of their
+	// transactions, those in the trash included. Unknown keys are left out.
+	PublicIDs(ctx context.Context, ids []int32) (map[int32]uuid.UUID, error)
+	// IDsByPublicID maps the given public IDs to the serial keys of their
+	// transactions, those in the trash included. Unknown IDs are left out.
+	IDsByPublicID(ctx context.Context, publicIDs []uuid.UUID) (map[uuid.UUID]int32, error)
+	RefundedTotals(ctx context.Context, ids []int32) (map[int32]float64, error)
+	SplitsByExpense(ctx context.Context, ids []int32) (map[int32][]model.ExpenseSplit, error)
+	SharesByExpense(ctx context.Context, ids []int32) (map[int32][]model.ExpenseShare, error)
+	ListShared(ctx context.Context, userID uuid.UUID) ([]model.Expense, error)
+	ReplaceShares(ctx context.Context, expense *model.Expense, shares []model.ExpenseShare) (*model.Expense, error)
+	Create(ctx context.Context, expense *model.Expense, tagIDs []int32, splits []model.ExpenseSplit) (*model.Expense, error)
+	// CreateMany stores transactions of a user, along with the tags and
+	// merchants they need, in a single database transaction. The created
+	// transactions are returned in the order of inserts.
+	CreateMany(ctx context.Context, userID uuid.UUID, inserts []ExpenseInsert) ([]model.Expense, error)
+	// Update stores the new state of a transaction along with the revision
+	// listing the fields the user changed.
+	Update(ctx context.Context, expense *model.Expense, tagNames []string, splits []model.ExpenseSplit, editedBy uuid.UUID) (*model.Expense, error)
+	// UpdateMany applies the same changes to the given transactions, recording
+	// a revision for each.
+	UpdateMany(ctx context.Context, ids []int32, update BulkUpdate, editedBy uuid.UUID) error
+	// ListRevisions lists the revisions of a transaction, latest first.
+	ListRevisions(ctx context.Context, expenseID int32) ([]model.ExpenseRevision, error)
+	GetRevision(ctx context.Context, expenseID, number int32) (*model.ExpenseRevision, error)
+	CreateRefund(ctx context.Context, refund *model.Expense) (*model.Expense, error)
+	// Delete moves a transaction along with its refunds to the trash, as long
+	// as a non-zero updatedAt is still the version stored.
+	Delete(ctx context.Context, id int32, updatedAt time.Time) error
+	// DeleteMany moves the given transactions along with their refunds to the trash.
+	DeleteMany(ctx context.Context, ids []int32) error
+	// ListDeleted lists the transactions of the ledger in the trash, latest
+	// deleted first. Refunds deleted along with their expense are left out.
+	ListDeleted(ctx context.Context, scope Scope, limit, offset int) ([]model.Expense, error)
+	GetDeletedByID(ctx context.Context, id int32) (*model.Expense, error)
+	// Restore takes a transaction out of the trash along with the refunds
+	// deleted with it. A refund is only restored next to its expense and
+	// within its amount.
+	Restore(ctx context.Context, expense *model.Expense) (*model.Expense, error)
+	// PurgeDeleted deletes the transactions in the trash since before the given
+	// time. It returns how many were deleted and the storage keys of their
+	// attachments.
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, []string, error)
+	// ListWithoutMerchant pages, by id, through the expenses and income not
+	// assigned to a merchant yet.
+	ListWithoutMerchant(ctx context.Context, afterID int32, limit int) ([]model.Expense, error)
+	SetMerchant(ctx context.Context, id int32, merchantID int32) error
 }
 
 type expenseRepository struct {
@@ -23,13 +163,13 @@ func NewExpenseRepository(db *sql.DB) ExpenseRepository {
 	return &expenseRepository{db: db}
 }
 
-func (r *expenseRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Expense, error) {
+func (r *expenseRepository) ListByUser(ctx context.Context, userID uuid.UUID, filter ExpenseFilter, limit, offset int) ([]model.Expense, error) {
 	query := table.Expense.SELECT(
 		table.Expense.AllColumns,
 	).FROM(
 		table.Expense,
 	).WHERE(
-		table.Expense.UserID.EQ(postgres.UUID(userID)),
+		filterCondition(userID, filter),
 	).ORDER_BY(
 		table.Expense.CreatedAt.DESC(),
 	).LIMIT(int64(limit)).OFFSET(int64(offset))
@@ -44,27 +184,1440 @@ func (r *expenseRepository) ListByUser(ctx context.Context, userID uuid.UUID, li
 	return dest, nil
 }
 
-func (r *expenseRepository) Create(ctx context.Context, expense *model.Expense) (*model.Expense, error) {
+func (r *expenseRepository) Export(ctx context.Context, userID uuid.UUID, filter ExpenseFilter, fn func(line ExportLine) error) error {
+	tagNames := postgres.RawString(
+		"(SELECT string_agg(t.name, chr(31) ORDER BY t.name) FROM expense_tag et JOIN tag t ON t.id = et.tag_id WHERE et.expense_id = expense.id)",
+	)
+
+	query := postgres.SELECT(
+		table.Expense.AllColumns,
+		table.Category.Name.AS("export_line.category_name"),
+		table.Account.Name.AS("export_line.account_name"),
+		table.Merchant.Name.AS("export_line.merchant_name"),
+		tagNames.AS("export_line.tag_names"),
+	).FROM(
+		table.Expense.
+			LEFT_JOIN(table.Category, table.Category.ID.EQ(table.Expense.CategoryID)).
+			LEFT_JOIN(table.Account, table.Account.ID.EQ(table.Expense.AccountID)).
+			LEFT_JOIN(table.Merchant, table.Merchant.ID.EQ(table.Expense.MerchantID)),
+	).WHERE(
+		filterCondition(userID, filter),
+	).ORDER_BY(
+		table.Expense.PurchaseDate.ASC(),
+		table.Expense.ID.ASC(),
+	)
+
+	rows, err := query.Rows(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line ExportLine
+		if err := rows.Scan(&line); err != nil {
+			return err
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func filterCondition(userID uuid.UUID, filter ExpenseFilter) postgres.BoolExpression {
+	scope := Scope{UserID: userID, WorkspaceID: filter.WorkspaceID}
+	condition := scope.condition(table.Expense.UserID, table.Expense.WorkspaceID).AND(expenseNotDeleted)
+	if filter.Kind != "" {
+		condition = condition.AND(table.Expense.Kind.EQ(postgres.String(filter.Kind)))
+	}
+	if filter.CategoryID != nil {
+		categoryID := postgres.Int32(*filter.CategoryID)
+		split := postgres.SELECT(
+			table.ExpenseSplit.ExpenseID,
+		).FROM(
+			table.ExpenseSplit,
+		).WHERE(
+			table.ExpenseSplit.CategoryID.EQ(categoryID),
+		)
+		condition = condition.AND(table.Expense.CategoryID.EQ(categoryID).OR(table.Expense.ID.IN(split)))
+	}
+	if filter.Uncategorized {
+		split := postgres.SELECT(
+			table.ExpenseSplit.ExpenseID,
+		).FROM(
+			table.ExpenseSplit,
+		)
+		condition = condition.AND(table.Expense.CategoryID.IS_NULL().AND(table.Expense.ID.NOT_IN(split)))
+	}
+	if filter.AccountID != nil {
+		accountID := postgres.Int32(*filter.AccountID)
+		condition = condition.AND(table.Expense.AccountID.EQ(accountID).OR(table.Expense.TransferAccountID.EQ(accountID)))
+	}
+	if len(filter.Tags) > 0 {
+		names := make([]postgres.Expression, len(filter.Tags))
+		for i, name := range filter.Tags {
+			names[i] = postgres.String(name)
+		}
+		tagged := postgres.SELECT(
+			table.ExpenseTag.ExpenseID,
+		).FROM(
+			table.ExpenseTag.INNER_JOIN(table.Tag, table.Tag.ID.EQ(table.ExpenseTag.TagID)),
+		).WHERE(
+			table.Tag.Name.IN(names...),
+		)
+		condition = condition.AND(table.Expense.ID.IN(tagged))
+	}
+	if filter.From != nil {
+		condition = condition.AND(table.Expense.PurchaseDate.GT_EQ(postgres.TimestampT(*filter.From)))
+	}
+	if filter.To != nil {
+		condition = condition.AND(table.Expense.PurchaseDate.LT(postgres.TimestampT(filter.To.AddDate(0, 0, 1))))
+	}
+	return condition
+}
+
+// ListByUserInPeriod lists transactions of the user's personal ledger.
+func (r *expenseRepository) ListByUserInPeriod(ctx context.Context, userID uuid.UUID, kind string, period u.Period) ([]model.Expense, error) {
+	query := table.Expense.SELECT(
+		table.Expense.AllColumns,
+	).FROM(
+		table.Expense,
+	).WHERE(
+		table.Expense.UserID.EQ(postgres.UUID(userID)).
+			AND(table.Expense.WorkspaceID.IS_NULL()).
+			AND(expenseNotDeleted).
+			AND(table.Expense.Kind.EQ(postgres.String(kind))).
+			AND(table.Expense.PurchaseDate.GT_EQ(postgres.TimestampT(period.From))).
+			AND(table.Expense.PurchaseDate.LT(postgres.TimestampT(period.To))),
+	).ORDER_BY(
+		table.Expense.PurchaseDate.ASC(),
+	)
+
+	var dest []model.Expense
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *expenseRepository) ListByUserBilledFrom(ctx context.Context, userID uuid.UUID, kind string, from time.Time) ([]model.Expense, error) {
+	query := table.Expense.SELECT(
+		table.Expense.AllColumns,
+	).FROM(
+		table.Expense,
+	).WHERE(
+		table.Expense.UserID.EQ(postgres.UUID(userID)).
+			AND(table.Expense.WorkspaceID.IS_NULL()).
+			AND(expenseNotDeleted).
+			AND(table.Expense.Kind.EQ(postgres.String(kind))).
+			AND(table.Expense.BillDate.GT_EQ(postgres.TimestampT(from))),
+	).ORDER_BY(
+		table.Expense.BillDate.ASC(),
+	)
+
+	var dest []model.Expense
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *expenseRepository) GetByID(ctx context.Context, id int32) (*model.Expense, error) {
+	query := table.Expense.SELECT(
+		table.Expense.AllColumns,
+	).FROM(
+		table.Expense,
+	).WHERE(
+		table.Expense.ID.EQ(postgres.Int32(id)).AND(expenseNotDeleted),
+	)
+
+	var dest model.Expense
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if errors.Is(err, qrm.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+func (r *expenseRepository) ListByIDs(ctx context.Context, ids []int32) ([]model.Expense, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	idExpressions := make([]postgres.Expression, len(ids))
+	for i, id := range ids {
+		idExpressions[i] = postgres.Int32(id)
+	}
+
+	query := table.Expense.SELECT(
+		table.Expense.AllColumns,
+	).FROM(
+		table.Expense,
+	).WHERE(
+		table.Expense.ID.IN(idExpressions...).AND(expenseNotDeleted),
+	).ORDER_BY(
+		table.Expense.ID.ASC(),
+	)
+
+	var dest []model.Expense
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *expenseRepository) PublicIDs(ctx context.Context, ids []int32) (map[int32]uuid.UUID, error) {
+	result := map[int32]uuid.UUID{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	idExpressions := make([]postgres.Expression, len(ids))
+	for i, id := range ids {
+		idExpressions[i] = postgres.Int32(id)
+	}
+
+	query := table.Expense.SELECT(
+		table.Expense.ID,
+		table.Expense.PublicID,
+	).FROM(
+		table.Expense,
+	).WHERE(
+		table.Expense.ID.IN(idExpressions...),
+	)
+
+	var dest []model.Expense
+	if err := query.QueryContext(ctx, r.db, &dest); err != nil {
+		return nil, err
+	}
+
+	for _, expense := range dest {
+		result[expense.ID] = expense.PublicID
+	}
+	return result, nil
+}
+
+func (r *expenseRepository) IDsByPublicID(ctx context.Context, publicIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	result := map[uuid.UUID]int32{}
+	if len(publicIDs) == 0 {
+		return result, nil
+	}
+
+	idExpressions := make([]postgres.Expression, len(publicIDs))
+	for i, id := range publicIDs {
+		idExpressions[i] = postgres.UUID(id)
+	}
+
+	query := table.Expense.SELECT(
+		table.Expense.ID,
+		table.Expense.PublicID,
+	).FROM(
+		table.Expense,
+	).WHERE(
+		table.Expense.PublicID.IN(idExpressions...),
+	)
+
+	var dest []model.Expense
+	if err := query.QueryContext(ctx, r.db, &dest); err != nil {
+		return nil, err
+	}
+
+	for _, expense := range dest {
+		result[expense.PublicID] = expense.ID
+	}
+	return result, nil
+}
+
+// RefundedTotals returns the sum of the refunds of each given expense. Expenses
+// without refunds are absent from the result.
+func (r *expenseRepository) RefundedTotals(ctx context.Context, ids []int32) (map[int32]float64, error) {
+	result := map[int32]float64{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	idExpressions := make([]postgres.Expression, len(ids))
+	for i, id := range ids {
+		idExpressions[i] = postgres.Int32(id)
+	}
+
+	query := postgres.SELECT(
+		table.Expense.RefundOfID.AS("expense_id"),
+		postgres.SUM(table.Expense.Amount).AS("total"),
+	).FROM(
+		table.Expense,
+	).WHERE(
+		table.Expense.RefundOfID.IN(idExpressions...).AND(expenseNotDeleted),
+	).GROUP_BY(
+		table.Expense.RefundOfID,
+	)
+
+	var dest []struct {
+		ExpenseID int32
+		Total     float64
+	}
+	if err := query.QueryContext(ctx, r.db, &dest); err != nil {
+		return nil, err
+	}
+
+	for _, row := range dest {
+		result[row.ExpenseID] = row.Total
+	}
+	return result, nil
+}
+
+// SplitsByExpense returns the split lines of each given expense. Expenses that
+// are not split are absent from the result.
+func (r *expenseRepository) SplitsByExpense(ctx context.Context, ids []int32) (map[int32][]model.ExpenseSplit, error) {
+	result := map[int32][]model.ExpenseSplit{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	idExpressions := make([]postgres.Expression, len(ids))
+	for i, id := range ids {
+		idExpressions[i] = postgres.Int32(id)
+	}
+
+	query := table.ExpenseSplit.SELECT(
+		table.ExpenseSplit.AllColumns,
+	).FROM(
+		table.ExpenseSplit,
+	).WHERE(
+		table.ExpenseSplit.ExpenseID.IN(idExpressions...),
+	).ORDER_BY(
+		table.ExpenseSplit.ID.ASC(),
+	)
+
+	var dest []model.ExpenseSplit
+	if err := query.QueryContext(ctx, r.db, &dest); err != nil {
+		return nil, err
+	}
+
+	for _, split := range dest {
+		result[split.ExpenseID] = append(result[split.ExpenseID], split)
+	}
+	return result, nil
+}
+
+// Create stores the expense along with its tags and split lines.
+func (r *expenseRepository) Create(ctx context.Context, expense *model.Expense, tagIDs []int32, splits []model.ExpenseSplit) (*model.Expense, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	query := table.Expense.INSERT(
+		table.Expense.PublicID,
 		table.Expense.UserID,
 		table.Expense.Amount,
 		table.Expense.Description,
 		table.Expense.PurchaseDate,
 		table.Expense.BillDate,
 		table.Expense.CategoryID,
+		table.Expense.Kind,
+		table.Expense.AccountID,
+		table.Expense.TransferAccountID,
+		table.Expense.WorkspaceID,
+		table.Expense.MerchantID,
 	).VALUES(
+		publicIDOrDefault(expense.PublicID),
 		expense.UserID,
 		expense.Amount,
 		expense.Description,
 		expense.PurchaseDate,
 		expense.BillDate,
 		expense.CategoryID,
+		expense.Kind,
+		expense.AccountID,
+		expense.TransferAccountID,
+		expense.WorkspaceID,
+		expense.MerchantID,
 	).RETURNING(table.Expense.AllColumns)
 
-	err := query.QueryContext(ctx, r.db, expense)
+	err = query.QueryContext(ctx, tx, expense)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := replaceTags(ctx, tx, expense.ID, tagIDs); err != nil {
+		return nil, err
+	}
+	if err := replaceSplits(ctx, tx, expense.ID, splits); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	return expense, nil
 }
+
+// CreateMany ensures the tags and merchants, then inserts the transactions,
+// their tags and split lines, with multi-row INSERTs. Postgres returns the rows
+// of a multi-row INSERT in the order of its VALUES, which pairs each created
+// transaction with its lines.
+func (r *expenseRepository) CreateMany(ctx context.Context, userID uuid.UUID, inserts []ExpenseInsert) ([]model.Expense, error) {
+	if len(inserts) == 0 {
+		return nil, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Tags and merchants are ensured once for the whole batch
+	var tagNames []string
+	var merchants []model.Merchant
+	seenTags, seenMerchants := map[string]bool{}, map[string]bool{}
+	for _, insert := range inserts {
+		for _, name := range insert.TagNames {
+			if !seenTags[name] {
+				seenTags[name] = true
+				tagNames = append(tagNames, name)
+			}
+		}
+		if insert.Merchant != nil && !seenMerchants[insert.Merchant.Key] {
+			seenMerchants[insert.Merchant.Key] = true
+			merchants = append(merchants, *insert.Merchant)
+		}
+	}
+	tags, err := ensureTags(ctx, tx, userID, tagNames)
+	if err != nil {
+		return nil, err
+	}
+	tagIDs := make(map[string]int32, len(tags))
+	for _, tag := range tags {
+		tagIDs[tag.Name] = tag.ID
+	}
+	ensured, err := ensureMerchants(ctx, tx, userID, merchants)
+	if err != nil {
+		return nil, err
+	}
+	merchantIDs := make(map[string]int32, len(ensured))
+	for _, merchant := range ensured {
+		merchantIDs[merchant.Key] = merchant.ID
+	}
+
+	created := make([]model.Expense, 0, len(inserts))
+	for start := 0; start < len(inserts); start += insertBatchRows {
+		query := table.Expense.INSERT(
+			table.Expense.PublicID,
+			table.Expense.UserID,
+			table.Expense.Amount,
+			table.Expense.Description,
+			table.Expense.PurchaseDate,
+			table.Expense.BillDate,
+			table.Expense.CategoryID,
+			table.Expense.Kind,
+			table.Expense.AccountID,
+			table.Expense.TransferAccountID,
+			table.Expense.WorkspaceID,
+			table.Expense.MerchantID,
+		)
+		for _, insert := range inserts[start:min(start+insertBatchRows, len(inserts))] {
+			expense := insert.Expense
+			expense.MerchantID = nil
+			if insert.Merchant != nil {
+				merchantID := merchantIDs[insert.Merchant.Key]
+				expense.MerchantID = &merchantID
+			}
+			query = query.VALUES(
+				publicIDOrDefault(expense.PublicID),
+				expense.UserID,
+				expense.Amount,
+				expense.Description,
+				expense.PurchaseDate,
+				expense.BillDate,
+				expense.CategoryID,
+				expense.Kind,
+				expense.AccountID,
+				expense.TransferAccountID,
+				expense.WorkspaceID,
+				expense.MerchantID,
+			)
+		}
+
+		var batch []model.Expense
+		err = query.RETURNING(table.Expense.AllColumns).QueryContext(ctx, tx, &batch)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, batch...)
+	}
+
+	var expenseTags []model.ExpenseTag
+	var splits []model.ExpenseSplit
+	for i, insert := range inserts {
+		for _, name := range insert.TagNames {
+			expenseTags = append(expenseTags, model.ExpenseTag{ExpenseID: created[i].ID, TagID: tagIDs[name]})
+		}
+		for _, split := range insert.Splits {
+			split.ExpenseID = created[i].ID
+			splits = append(splits, split)
+		}
+	}
+	if err := insertTags(ctx, tx, expenseTags); err != nil {
+		return nil, err
+	}
+	if err := insertSplits(ctx, tx, splits); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// Update stores the editable fields of the expense. Its tags and split lines
+// are replaced unless tagNames and splits are nil, respectively, the missing
+// tags being created for the author of the expense. The amount
+// cannot drop below what was already refunded. A non-zero UpdatedAt must
+// still be the version stored, so that concurrent edits are not lost.
+func (r *expenseRepository) Update(ctx context.Context, expense *model.Expense, tagNames []string, splits []model.ExpenseSplit, editedBy uuid.UUID) (*model.Expense, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var existing model.Expense
+	err = table.Expense.SELECT(
+		table.Expense.AllColumns,
+	).WHERE(
+		table.Expense.ID.EQ(postgres.Int32(expense.ID)).AND(expenseNotDeleted),
+	).FOR(
+		postgres.UPDATE(),
+	).QueryContext(ctx, tx, &existing)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, u.ErrNotFound
+		}
+		return nil, err
+	}
+	if !expense.UpdatedAt.IsZero() && !existing.UpdatedAt.Equal(expense.UpdatedAt) {
+		return nil, u.ErrPreconditionFailed
+	}
+	before, err := snapshot(ctx, tx, &existing)
+	if err != nil {
+		return nil, err
+	}
+
+	var refunded struct {
+		Total float64
+	}
+	err = postgres.SELECT(
+		postgres.COALESCE(postgres.SUM(table.Expense.Amount), postgres.Float(0)).AS("total"),
+	).FROM(
+		table.Expense,
+	).WHERE(
+		table.Expense.RefundOfID.EQ(postgres.Int32(expense.ID)).AND(expenseNotDeleted),
+	).QueryContext(ctx, tx, &refunded)
+	if err != nil {
+		return nil, err
+	}
+	if math.Round(refunded.Total*100) > math.Round(expense.Amount*100) {
+		return nil, u.ErrRefundExceedsAmount
+	}
+
+	err = table.Expense.UPDATE(
+		table.Expense.Amount,
+		table.Expense.Description,
+		table.Expense.PurchaseDate,
+		table.Expense.BillDate,
+		table.Expense.CategoryID,
+		table.Expense.AccountID,
+		table.Expense.MerchantID,
+	).SET(
+		expense.Amount,
+		expense.Description,
+		expense.PurchaseDate,
+		expense.BillDate,
+		expense.CategoryID,
+		expense.AccountID,
+		expense.MerchantID,
+	).WHERE(
+		table.Expense.ID.EQ(postgres.Int32(expense.ID)).AND(expenseNotDeleted),
+	).RETURNING(
+		table.Expense.AllColumns,
+	).QueryContext(ctx, tx, expense)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, u.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if tagNames != nil {
+		tags, err := ensureTags(ctx, tx, existing.UserID, tagNames)
+		if err != nil {
+			return nil, err
+		}
+		tagIDs := make([]int32, len(tags))
+		for i, tag := range tags {
+			tagIDs[i] = tag.ID
+		}
+		if err := replaceTags(ctx, tx, expense.ID, tagIDs); err != nil {
+			return nil, err
+		}
+	}
+	if splits != nil {
+		if err := replaceSplits(ctx, tx, expense.ID, splits); err != nil {
+			return nil, err
+		}
+	}
+
+	after, err := snapshot(ctx, tx, expense)
+	if err != nil {
+		return nil, err
+	}
+	if err := insertRevision(ctx, tx, expense.ID, editedBy, before, after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return expense, nil
+}
+
+// UpdateMany locks the transactions and applies the changes to all of them at
+// once. Transactions deleted in the meantime are skipped.
+func (r *expenseRepository) UpdateMany(ctx context.Context, ids []int32, update BulkUpdate, editedBy uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	idExpressions := make([]postgres.Expression, len(ids))
+	for i, id := range ids {
+		idExpressions[i] = postgres.Int32(id)
+	}
+
+	var existing []model.Expense
+	err = table.Expense.SELECT(
+		table.Expense.AllColumns,
+	).WHERE(
+		table.Expense.ID.IN(idExpressions...).AND(expenseNotDeleted),
+	).ORDER_BY(
+		table.Expense.ID.ASC(),
+	).FOR(
+		postgres.UPDATE(),
+	).QueryContext(ctx, tx, &existing)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return nil
+	}
+
+	locked := make([]postgres.Expression, len(existing))
+	before := make([]revision.Snapshot, len(existing))
+	for i := range existing {
+		locked[i] = postgres.Int32(existing[i].ID)
+		if before[i], err = snapshot(ctx, tx, &existing[i]); err != nil {
+			return err
+		}
+	}
+
+	if update.CategoryID != nil {
+		_, err = table.Expense.UPDATE(
+			table.Expense.CategoryID,
+		).SET(
+			postgres.Int32(*update.CategoryID),
+		).WHERE(
+			table.Expense.ID.IN(locked...),
+		).ExecContext(ctx, tx)
+		if err != nil {
+			return err
+		}
+	}
+	if len(update.RemoveTags) > 0 {
+		names := make([]postgres.Expression, len(update.RemoveTags))
+		for i, name := range update.RemoveTags {
+			names[i] = postgres.String(name)
+		}
+		named := postgres.SELECT(
+			table.Tag.ID,
+		).FROM(
+			table.Tag,
+		).WHERE(
+			table.Tag.Name.IN(names...),
+		)
+		_, err = table.ExpenseTag.DELETE().WHERE(
+			table.ExpenseTag.ExpenseID.IN(locked...).AND(table.ExpenseTag.TagID.IN(named)),
+		).ExecContext(ctx, tx)
+		if err != nil {
+			return err
+		}
+	}
+	// Tags are added from the namespace of each author
+	var tags []model.ExpenseTag
+	if len(update.AddTags) > 0 {
+		authorTags := map[uuid.UUID][]model.Tag{}
+		for _, expense := range existing {
+			added, ok := authorTags[expense.UserID]
+			if !ok {
+				if added, err = ensureTags(ctx, tx, expense.UserID, update.AddTags); err != nil {
+					return err
+				}
+				authorTags[expense.UserID] = added
+			}
+			for _, tag := range added {
+				tags = append(tags, model.ExpenseTag{ExpenseID: expense.ID, TagID: tag.ID})
+			}
+		}
+	}
+	if err := insertTags(ctx, tx, tags); err != nil {
+		return err
+	}
+	if update.CategoryID == nil {
+		if err := touchExpenses(ctx, tx, table.Expense.ID.IN(locked...)); err != nil {
+			return err
+		}
+	}
+
+	for i := range existing {
+		if update.CategoryID != nil {
+			existing[i].CategoryID = update.CategoryID
+		}
+		after, err := snapshot(ctx, tx, &existing[i])
+		if err != nil {
+			return err
+		}
+		if err := insertRevision(ctx, tx, existing[i].ID, editedBy, before[i], after); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// snapshot reads the fields of a transaction tracked by revisions.
+func snapshot(ctx context.Context, tx *sql.Tx, expense *model.Expense) (revision.Snapshot, error) {
+	s := revision.Snapshot{
+		Amount:       expense.Amount,
+		Description:  expense.Description,
+		PurchaseDate: expense.PurchaseDate,
+		BillDate:     expense.BillDate,
+		CategoryID:   expense.CategoryID,
+		AccountID:    expense.AccountID,
+	}
+
+	var tags []model.Tag
+	err := table.Tag.SELECT(
+		table.Tag.Name,
+	).FROM(
+		table.Tag.INNER_JOIN(table.ExpenseTag, table.ExpenseTag.TagID.EQ(table.Tag.ID)),
+	).WHERE(
+		table.ExpenseTag.ExpenseID.EQ(postgres.Int32(expense.ID)),
+	).QueryContext(ctx, tx, &tags)
+	if err != nil {
+		return s, err
+	}
+	for _, tag := range tags {
+		s.Tags = append(s.Tags, tag.Name)
+	}
+
+	var splits []model.ExpenseSplit
+	err = table.ExpenseSplit.SELECT(
+		table.ExpenseSplit.AllColumns,
+	).WHERE(
+		table.ExpenseSplit.ExpenseID.EQ(postgres.Int32(expense.ID)),
+	).ORDER_BY(
+		table.ExpenseSplit.ID.ASC(),
+	).QueryContext(ctx, tx, &splits)
+	if err != nil {
+		return s, err
+	}
+	for _, split := range splits {
+		s.Splits = append(s.Splits, revision.Split{CategoryID: split.CategoryID, Amount: split.Amount, Note: split.Note})
+	}
+	return s, nil
+}
+
+// insertRevision records the fields changed between two snapshots as the next
+// revision of the transaction, which must be locked. Updates that change
+// nothing leave no revision.
+func insertRevision(ctx context.Context, tx *sql.Tx, expenseID int32, editedBy uuid.UUID, before, after revision.Snapshot) error {
+	changes, err := revision.Diff(before, after)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	var last struct {
+		Revision int32
+	}
+	err = postgres.SELECT(
+		postgres.COALESCE(postgres.MAXi(table.ExpenseRevision.Revision), postgres.Int32(0)).AS("revision"),
+	).FROM(
+		table.ExpenseRevision,
+	).WHERE(
+		table.ExpenseRevision.ExpenseID.EQ(postgres.Int32(expenseID)),
+	).QueryContext(ctx, tx, &last)
+	if err != nil {
+		return err
+	}
+
+	_, err = table.ExpenseRevision.INSERT(
+		table.ExpenseRevision.ExpenseID,
+		table.ExpenseRevision.Revision,
+		table.ExpenseRevision.UserID,
+		table.ExpenseRevision.Changes,
+	).VALUES(
+		expenseID,
+		last.Revision+1,
+		editedBy,
+		string(data),
+	).ExecContext(ctx, tx)
+	return err
+}
+
+func (r *expenseRepository) ListRevisions(ctx context.Context, expenseID int32) ([]model.ExpenseRevision, error) {
+	query := table.ExpenseRevision.SELECT(
+		table.ExpenseRevision.AllColumns,
+	).FROM(
+		table.ExpenseRevision,
+	).WHERE(
+		table.ExpenseRevision.ExpenseID.EQ(postgres.Int32(expenseID)),
+	).ORDER_BY(
+		table.ExpenseRevision.Revision.DESC(),
+	)
+
+	var dest []model.ExpenseRevision
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *expenseRepository) GetRevision(ctx context.Context, expenseID, number int32) (*model.ExpenseRevision, error) {
+	query := table.ExpenseRevision.SELECT(
+		table.ExpenseRevision.AllColumns,
+	).FROM(
+		table.ExpenseRevision,
+	).WHERE(
+		table.ExpenseRevision.ExpenseID.EQ(postgres.Int32(expenseID)).
+			AND(table.ExpenseRevision.Revision.EQ(postgres.Int32(number))),
+	)
+
+	var dest model.ExpenseRevision
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+// SharesByExpense returns the shares of each given expense. Expenses that are
+// not shared are absent from the result.
+func (r *expenseRepository) SharesByExpense(ctx context.Context, ids []int32) (map[int32][]model.ExpenseShare, error) {
+	result := map[int32][]model.ExpenseShare{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	idExpressions := make([]postgres.Expression, len(ids))
+	for i, id := range ids {
+		idExpressions[i] = postgres.Int32(id)
+	}
+
+	query := table.ExpenseShare.SELECT(
+		table.ExpenseShare.AllColumns,
+	).FROM(
+		table.ExpenseShare,
+	).WHERE(
+		table.ExpenseShare.ExpenseID.IN(idExpressions...),
+	).ORDER_BY(
+		table.ExpenseShare.ID.ASC(),
+	)
+
+	var dest []model.ExpenseShare
+	if err := query.QueryContext(ctx, r.db, &dest); err != nil {
+		return nil, err
+	}
+
+	for _, share := range dest {
+		result[share.ExpenseID] = append(result[share.ExpenseID], share)
+	}
+	return result, nil
+}
+
+// ListShared lists the user's expenses that are split with contacts.
+func (r *expenseRepository) ListShared(ctx context.Context, userID uuid.UUID) ([]model.Expense, error) {
+	shared := postgres.SELECT(
+		table.ExpenseShare.ExpenseID,
+	).FROM(
+		table.ExpenseShare,
+	).WHERE(
+		table.ExpenseShare.ExpenseID.EQ(table.Expense.ID),
+	)
+
+	query := table.Expense.SELECT(
+		table.Expense.AllColumns,
+	).FROM(
+		table.Expense,
+	).WHERE(
+		table.Expense.UserID.EQ(postgres.UUID(userID)).
+			AND(expenseNotDeleted).
+			AND(postgres.EXISTS(shared)),
+	).ORDER_BY(
+		table.Expense.PurchaseDate.ASC(),
+	)
+
+	var dest []model.Expense
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// ReplaceShares sets who paid an expense and how it is shared. Empty shares
+// make it a regular expense again. A non-zero UpdatedAt must still be the
+// version stored.
+func (r *expenseRepository) ReplaceShares(ctx context.Context, expense *model.Expense, shares []model.ExpenseShare) (*model.Expense, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = table.Expense.UPDATE(
+		table.Expense.PaidByContactID,
+	).SET(
+		expense.PaidByContactID,
+	).WHERE(
+		table.Expense.ID.EQ(postgres.Int32(expense.ID)).
+			AND(expenseNotDeleted).
+			AND(atVersion(table.Expense.UpdatedAt, expense.UpdatedAt)),
+	).RETURNING(
+		table.Expense.AllColumns,
+	).QueryContext(ctx, tx, expense)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, u.ErrNotFound
+		}
+		return nil, versionError(err, expense.UpdatedAt)
+	}
+
+	_, err = table.ExpenseShare.DELETE().WHERE(
+		table.ExpenseShare.ExpenseID.EQ(postgres.Int32(expense.ID)),
+	).ExecContext(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if len(shares) > 0 {
+		insert := table.ExpenseShare.INSERT(
+			table.ExpenseShare.ExpenseID,
+			table.ExpenseShare.ContactID,
+			table.ExpenseShare.Amount,
+		)
+		for _, share := range shares {
+			insert = insert.VALUES(expense.ID, share.ContactID, share.Amount)
+		}
+		if _, err := insert.ExecContext(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return expense, nil
+}
+
+// Delete stamps the transaction and its refunds with the same deletion time,
+// which tells Restore which refunds to bring back.
+func (r *expenseRepository) Delete(ctx context.Context, id int32, updatedAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var existing model.Expense
+	err = table.Expense.SELECT(
+		table.Expense.AllColumns,
+	).WHERE(
+		table.Expense.ID.EQ(postgres.Int32(id)).AND(expenseNotDeleted),
+	).FOR(
+		postgres.UPDATE(),
+	).QueryContext(ctx, tx, &existing)
+	if err != nil {
+		if errors.Is(err, qrm.ErrNoRows) {
+			return u.ErrNotFound
+		}
+		return err
+	}
+	if !updatedAt.IsZero() && !existing.UpdatedAt.Equal(updatedAt) {
+		return u.ErrPreconditionFailed
+	}
+
+	query := table.Expense.UPDATE(
+		table.Expense.DeletedAt,
+	).SET(
+		time.Now().UTC(),
+	).WHERE(
+		table.Expense.ID.EQ(postgres.Int32(id)).
+			OR(table.Expense.RefundOfID.EQ(postgres.Int32(id))).
+			AND(expenseNotDeleted),
+	)
+
+	if _, err := query.ExecContext(ctx, tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteMany stamps the transactions and their refunds with the same deletion
+// time, as Delete does.
+func (r *expenseRepository) DeleteMany(ctx context.Context, ids []int32) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	idExpressions := make([]postgres.Expression, len(ids))
+	for i, id := range ids {
+		idExpressions[i] = postgres.Int32(id)
+	}
+
+	query := table.Expense.UPDATE(
+		table.Expense.DeletedAt,
+	).SET(
+		time.Now().UTC(),
+	).WHERE(
+		table.Expense.ID.IN(idExpressions...).
+			OR(table.Expense.RefundOfID.IN(idExpressions...)).
+			AND(expenseNotDeleted),
+	)
+
+	_, err := query.ExecContext(ctx, r.db)
+	return err
+}
+
+func (r *expenseRepository) ListDeleted(ctx context.Context, scope Scope, limit, offset int) ([]model.Expense, error) {
+	original := table.Expense.AS("original")
+	deletedWithOriginal := postgres.SELECT(
+		original.ID,
+	).FROM(
+		original,
+	).WHERE(
+		original.ID.EQ(table.Expense.RefundOfID).
+			AND(original.DeletedAt.EQ(table.Expense.DeletedAt)),
+	)
+
+	query := table.Expense.SELECT(
+		table.Expense.AllColumns,
+	).FROM(
+		table.Expense,
+	).WHERE(
+		scope.condition(table.Expense.UserID, table.Expense.WorkspaceID).
+			AND(table.Expense.DeletedAt.IS_NOT_NULL()).
+			AND(postgres.NOT(postgres.EXISTS(deletedWithOriginal))),
+	).ORDER_BY(
+		table.Expense.DeletedAt.DESC(),
+		table.Expense.ID.DESC(),
+	).LIMIT(int64(limit)).OFFSET(int64(offset))
+
+	var dest []model.Expense
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *expenseRepository) GetDeletedByID(ctx context.Context, id int32) (*model.Expense, error) {
+	query := table.Expense.SELECT(
+		table.Expense.AllColumns,
+	).FROM(
+		table.Expense,
+	).WHERE(
+		table.Expense.ID.EQ(postgres.Int32(id)).AND(table.Expense.DeletedAt.IS_NOT_NULL()),
+	)
+
+	var dest model.Expense
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if errors.Is(err, qrm.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+func (r *expenseRepository) Restore(ctx context.Context, expense *model.Expense) (*model.Expense, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if expense.RefundOfID != nil {
+		var original model.Expense
+		err = table.Expense.SELECT(
+			table.Expense.AllColumns,
+		).WHERE(
+			table.Expense.ID.EQ(postgres.Int32(*expense.RefundOfID)),
+		).FOR(
+			postgres.UPDATE(),
+		).QueryContext(ctx, tx, &original)
+		if err != nil {
+			return nil, err
+		}
+		if original.DeletedAt != nil {
+			return nil, u.ErrRefundedExpenseDeleted
+		}
+
+		var refunded struct {
+			Total float64
+		}
+		err = postgres.SELECT(
+			postgres.COALESCE(postgres.SUM(table.Expense.Amount), postgres.Float(0)).AS("total"),
+		).FROM(
+			table.Expense,
+		).WHERE(
+			table.Expense.RefundOfID.EQ(postgres.Int32(original.ID)).AND(expenseNotDeleted),
+		).QueryContext(ctx, tx, &refunded)
+		if err != nil {
+			return nil, err
+		}
+		if math.Round((refunded.Total+expense.Amount)*100) > math.Round(original.Amount*100) {
+			return nil, u.ErrRefundExceedsAmount
+		}
+		if err := touchExpenses(ctx, tx, table.Expense.ID.EQ(postgres.Int32(original.ID))); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = table.Expense.UPDATE(
+		table.Expense.DeletedAt,
+	).SET(
+		postgres.NULL,
+	).WHERE(
+		table.Expense.RefundOfID.EQ(postgres.Int32(expense.ID)).
+			AND(table.Expense.DeletedAt.EQ(postgres.TimestampT(*expense.DeletedAt))),
+	).ExecContext(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = table.Expense.UPDATE(
+		table.Expense.DeletedAt,
+	).SET(
+		postgres.NULL,
+	).WHERE(
+		table.Expense.ID.EQ(postgres.Int32(expense.ID)).AND(table.Expense.DeletedAt.IS_NOT_NULL()),
+	).RETURNING(
+		table.Expense.AllColumns,
+	).QueryContext(ctx, tx, expense)
+	if err != nil {
+		if errors.Is(err, qrm.ErrNoRows) {
+			return nil, u.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return expense, nil
+}
+
+func (r *expenseRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, []string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	expired := table.Expense.DeletedAt.LT(postgres.TimestampT(before))
+	// Refunds deleted after their expense go with it
+	purged := table.Expense.SELECT(
+		table.Expense.ID,
+	).WHERE(
+		expired.OR(table.Expense.RefundOfID.IN(table.Expense.SELECT(table.Expense.ID).WHERE(expired))),
+	)
+
+	// Attachment rows go with their expense, their keys are needed to delete the files
+	var attachments []model.Attachment
+	err = table.Attachment.DELETE().WHERE(
+		table.Attachment.ExpenseID.IN(purged),
+	).RETURNING(
+		table.Attachment.StorageKey,
+	).QueryContext(ctx, tx, &attachments)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	result, err := table.Expense.DELETE().WHERE(expired).ExecContext(ctx, tx)
+	if err != nil {
+		return 0, nil, err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, nil, err
+	}
+
+	keys := make([]string, len(attachments))
+	for i, a := range attachments {
+		keys[i] = a.StorageKey
+	}
+	return deleted, keys, nil
+}
+
+func (r *expenseRepository) ListWithoutMerchant(ctx context.Context, afterID int32, limit int) ([]model.Expense, error) {
+	query := table.Expense.SELECT(
+		table.Expense.AllColumns,
+	).FROM(
+		table.Expense,
+	).WHERE(
+		table.Expense.MerchantID.IS_NULL().
+			AND(expenseNotDeleted).
+			AND(table.Expense.Kind.IN(postgres.String(KindExpense), postgres.String(KindIncome))).
+			AND(table.Expense.ID.GT(postgres.Int32(afterID))),
+	).ORDER_BY(
+		table.Expense.ID.ASC(),
+	).LIMIT(int64(limit))
+
+	var dest []model.Expense
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *expenseRepository) SetMerchant(ctx context.Context, id int32, merchantID int32) error {
+	query := table.Expense.UPDATE(
+		table.Expense.MerchantID,
+	).SET(
+		merchantID,
+	).WHERE(
+		table.Expense.ID.EQ(postgres.Int32(id)),
+	)
+
+	_, err := query.ExecContext(ctx, r.db)
+	return err
+}
+
+// publicIDOrDefault inserts the public ID picked by the client, or lets the
+// database generate one.
+func publicIDOrDefault(publicID uuid.UUID) any {
+	if publicID == uuid.Nil {
+		return postgres.DEFAULT
+	}
+	return postgres.UUID(publicID)
+}
+
+// replaceSplits sets the split lines of an expense to exactly splits.
+func replaceSplits(ctx context.Context, tx *sql.Tx, expenseID int32, splits []model.ExpenseSplit) error {
+	_, err := table.ExpenseSplit.DELETE().WHERE(
+		table.ExpenseSplit.ExpenseID.EQ(postgres.Int32(expenseID)),
+	).ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]model.ExpenseSplit, len(splits))
+	for i, split := range splits {
+		split.ExpenseID = expenseID
+		rows[i] = split
+	}
+	return insertSplits(ctx, tx, rows)
+}
+
+// insertSplits stores split lines, whatever expense they belong to.
+func insertSplits(ctx context.Context, tx *sql.Tx, splits []model.ExpenseSplit) error {
+	for start := 0; start < len(splits); start += insertBatchRows {
+		insert := table.ExpenseSplit.INSERT(
+			table.ExpenseSplit.ExpenseID,
+			table.ExpenseSplit.CategoryID,
+			table.ExpenseSplit.Amount,
+			table.ExpenseSplit.Note,
+		)
+		for _, split := range splits[start:min(start+insertBatchRows, len(splits))] {
+			insert = insert.VALUES(split.ExpenseID, split.CategoryID, split.Amount, split.Note)
+		}
+		if _, err := insert.ExecContext(ctx, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceTags sets the tags of an expense to exactly tagIDs.
+func replaceTags(ctx context.Context, tx *sql.Tx, expenseID int32, tagIDs []int32) error {
+	_, err := table.ExpenseTag.DELETE().WHERE(
+		table.ExpenseTag.ExpenseID.EQ(postgres.Int32(expenseID)),
+	).ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]model.ExpenseTag, len(tagIDs))
+	for i, tagID := range tagIDs {
+		rows[i] = model.ExpenseTag{ExpenseID: expenseID, TagID: tagID}
+	}
+	return insertTags(ctx, tx, rows)
+}
+
+// insertTags tags expenses, skipping the tags they already have.
+func insertTags(ctx context.Context, tx *sql.Tx, tags []model.ExpenseTag) error {
+	for start := 0; start < len(tags); start += insertBatchRows {
+		insert := table.ExpenseTag.INSERT(
+			table.ExpenseTag.ExpenseID,
+			table.ExpenseTag.TagID,
+		)
+		for _, tag := range tags[start:min(start+insertBatchRows, len(tags))] {
+			insert = insert.VALUES(tag.ExpenseID, tag.TagID)
+		}
+		if _, err := insert.ON_CONFLICT().DO_NOTHING().ExecContext(ctx, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateRefund stores a refund after checking, with the refunded expense locked,
+// that the refunds of the expense do not exceed its amount.
+func (r *expenseRepository) CreateRefund(ctx context.Context, refund *model.Expense) (*model.Expense, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var original model.Expense
+	err = table.Expense.SELECT(
+		table.Expense.AllColumns,
+	).WHERE(
+		table.Expense.ID.EQ(postgres.Int32(*refund.RefundOfID)).AND(expenseNotDeleted),
+	).FOR(
+		postgres.UPDATE(),
+	).QueryContext(ctx, tx, &original)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, u.ErrNotFound
+		}
+		return nil, err
+	}
+
+	var refunded struct {
+		Total float64
+	}
+	err = postgres.SELECT(
+		postgres.COALESCE(postgres.SUM(table.Expense.Amount), postgres.Float(0)).AS("total"),
+	).FROM(
+		table.Expense,
+	).WHERE(
+		table.Expense.RefundOfID.EQ(postgres.Int32(original.ID)).AND(expenseNotDeleted),
+	).QueryContext(ctx, tx, &refunded)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compare in cents to avoid floating point residue
+	if math.Round((refunded.Total+refund.Amount)*100) > math.Round(original.Amount*100) {
+		return nil, u.ErrRefundExceedsAmount
+	}
+
+	err = table.Expense.INSERT(
+		table.Expense.UserID,
+		table.Expense.Amount,
+		table.Expense.Description,
+		table.Expense.PurchaseDate,
+		table.Expense.BillDate,
+		table.Expense.Kind,
+		table.Expense.RefundOfID,
+		table.Expense.AccountID,
+		table.Expense.WorkspaceID,
+	).VALUES(
+		refund.UserID,
+		refund.Amount,
+		refund.Description,
+		refund.PurchaseDate,
+		refund.BillDate,
+		KindRefund,
+		original.ID,
+		original.AccountID,
+		original.WorkspaceID,
+	).RETURNING(
+		table.Expense.AllColumns,
+	).QueryContext(ctx, tx, refund)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := touchExpenses(ctx, tx, table.Expense.ID.EQ(postgres.Int32(original.ID))); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// touchExpenses bumps the version of transactions whose details changed while
+// their rows were left untouched, such as their tags or refunded total.
+func touchExpenses(ctx context.Context, db qrm.Executable, condition postgres.BoolExpression) error {
+	_, err := table.Expense.UPDATE(
+		table.Expense.UpdatedAt,
+	).SET(
+		postgres.LOCALTIMESTAMP(),
+	).WHERE(
+		condition,
+	).ExecContext(ctx, db)
+	return err
+}
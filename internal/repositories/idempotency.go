@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+)
+
+type IdempotencyRepository interface {
+	// Claim stores a new key, or takes over one that expired or whose request
+	// stalled before staleBefore. It reports whether the key was claimed.
+	Claim(ctx context.Context, entry *model.IdempotencyKey, now, staleBefore time.Time) (bool, error)
+	// Get returns the key of a user, nil when there is none.
+	Get(ctx context.Context, userID uuid.UUID, key string) (*model.IdempotencyKey, error)
+	// Complete stores the response of the request that claimed the key.
+	Complete(ctx context.Context, entry *model.IdempotencyKey) error
+	Delete(ctx context.Context, userID uuid.UUID, key string) error
+	// DeleteExpired deletes the keys that expired before the given time and
+	// returns how many were deleted.
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+func idempotencyKeyIs(userID uuid.UUID, key string) postgres.BoolExpression {
+	return table.IdempotencyKey.UserID.EQ(postgres.UUID(userID)).
+		AND(table.IdempotencyKey.Key.EQ(postgres.String(key)))
+}
+
+func (r *idempotencyRepository) Claim(ctx context.Context, entry *model.IdempotencyKey, now, staleBefore time.Time) (bool, error) {
+	expired := table.IdempotencyKey.ExpiresAt.LT_EQ(postgres.TimestampT(now))
+	stalled := table.IdempotencyKey.StatusCode.IS_NULL().
+		AND(table.IdempotencyKey.CreatedAt.LT_EQ(postgres.TimestampT(staleBefore)))
+
+	query := table.IdempotencyKey.INSERT(
+		table.IdempotencyKey.UserID,
+		table.IdempotencyKey.Key,
+		table.IdempotencyKey.CreatedAt,
+		table.IdempotencyKey.ExpiresAt,
+		table.IdempotencyKey.Fingerprint,
+	).VALUES(
+		entry.UserID,
+		entry.Key,
+		now,
+		entry.ExpiresAt,
+		entry.Fingerprint,
+	).ON_CONFLICT(
+		table.IdempotencyKey.UserID,
+		table.IdempotencyKey.Key,
+	).DO_UPDATE(
+		postgres.SET(
+			table.IdempotencyKey.CreatedAt.SET(table.IdempotencyKey.EXCLUDED.CreatedAt),
+			table.IdempotencyKey.ExpiresAt.SET(table.IdempotencyKey.EXCLUDED.ExpiresAt),
+			table.IdempotencyKey.Fingerprint.SET(table.IdempotencyKey.EXCLUDED.Fingerprint),
+			table.IdempotencyKey.StatusCode.SET(postgres.IntExp(postgres.NULL)),
+			table.IdempotencyKey.ContentType.SET(postgres.StringExp(postgres.NULL)),
+			table.IdempotencyKey.ResponseBody.SET(postgres.Bytea([]byte{})),
+			table.IdempotencyKey.Etag.SET(postgres.StringExp(postgres.NULL)),
+			table.IdempotencyKey.Location.SET(postgres.StringExp(postgres.NULL)),
+		).WHERE(
+			expired.OR(stalled),
+		),
+	).RETURNING(table.IdempotencyKey.AllColumns)
+
+	err := query.QueryContext(ctx, r.db, entry)
+	if err != nil {
+		// The key is held by another request
+		if errors.Is(err, qrm.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *idempotencyRepository) Get(ctx context.Context, userID uuid.UUID, key string) (*model.IdempotencyKey, error) {
+	query := table.IdempotencyKey.SELECT(
+		table.IdempotencyKey.AllColumns,
+	).FROM(
+		table.IdempotencyKey,
+	).WHERE(
+		idempotencyKeyIs(userID, key),
+	)
+
+	var dest model.IdempotencyKey
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if errors.Is(err, qrm.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, entry *model.IdempotencyKey) error {
+	query := table.IdempotencyKey.UPDATE(
+		table.IdempotencyKey.StatusCode,
+		table.IdempotencyKey.ContentType,
+		table.IdempotencyKey.ResponseBody,
+		table.IdempotencyKey.Etag,
+		table.IdempotencyKey.Location,
+	).SET(
+		entry.StatusCode,
+		entry.ContentType,
+		entry.ResponseBody,
+		entry.Etag,
+		entry.Location,
+	).WHERE(
+		idempotencyKeyIs(entry.UserID, entry.Key).
+			AND(table.IdempotencyKey.Fingerprint.EQ(postgres.String(entry.Fingerprint))),
+	)
+
+	_, err := query.ExecContext(ctx, r.db)
+	return err
+}
+
+func (r *idempotencyRepository) Delete(ctx context.Context, userID uuid.UUID, key string) error {
+	_, err := table.IdempotencyKey.DELETE().WHERE(
+		idempotencyKeyIs(userID, key),
+	).ExecContext(ctx, r.db)
+	return err
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result, err := table.IdempotencyKey.DELETE().WHERE(
+		table.IdempotencyKey.ExpiresAt.LT(postgres.TimestampT(before)),
+	).ExecContext(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type InsightRepository interface {
+	ListByUser(ctx context.Context, userID uuid.UUID, includeDismissed bool, limit, offset int) ([]model.Insight, error)
+	Upsert(ctx context.Context, insight *model.Insight) error
+	DeleteStale(ctx context.Context, userID uuid.UUID, keepKeys []string) error
+	MarkRead(ctx context.Context, userID uuid.UUID, id int32) (*model.Insight, error)
+	Dismiss(ctx context.Context, userID uuid.UUID, id int32) (*model.Insight, error)
+}
+
+type insightRepository struct {
+	db *sql.DB
+}
+
+func NewInsightRepository(db *sql.DB) InsightRepository {
+	return &insightRepository{db: db}
+}
+
+func (r *insightRepository) ListByUser(ctx context.Context, userID uuid.UUID, includeDismissed bool, limit, offset int) ([]model.Insight, error) {
+	condition := table.Insight.UserID.EQ(postgres.UUID(userID))
+	if !includeDismissed {
+		condition = condition.AND(table.Insight.DismissedAt.IS_NULL())
+	}
+
+	query := table.Insight.SELECT(
+		table.Insight.AllColumns,
+	).FROM(
+		table.Insight,
+	).WHERE(
+		condition,
+	).ORDER_BY(
+		table.Insight.ReadAt.IS_NULL().DESC(),
+		table.Insight.UpdatedAt.DESC(),
+	).LIMIT(int64(limit)).OFFSET(int64(offset))
+
+	var dest []model.Insight
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// Upsert creates or refreshes a card identified by its key, keeping its read and dismiss state.
+func (r *insightRepository) Upsert(ctx context.Context, insight *model.Insight) error {
+	stmt := table.Insight.INSERT(
+		table.Insight.UserID,
+		table.Insight.Type,
+		table.Insight.Key,
+		table.Insight.Severity,
+		table.Insight.Title,
+		table.Insight.Message,
+		table.Insight.Data,
+		table.Insight.Filter,
+	).MODEL(
+		insight,
+	).ON_CONFLICT(
+		table.Insight.UserID,
+		table.Insight.Key,
+	).DO_UPDATE(
+		postgres.SET(
+			table.Insight.Type.SET(table.Insight.EXCLUDED.Type),
+			table.Insight.Severity.SET(table.Insight.EXCLUDED.Severity),
+			table.Insight.Title.SET(table.Insight.EXCLUDED.Title),
+			table.Insight.Message.SET(table.Insight.EXCLUDED.Message),
+			table.Insight.Data.SET(table.Insight.EXCLUDED.Data),
+			table.Insight.Filter.SET(table.Insight.EXCLUDED.Filter),
+		),
+	)
+
+	_, err := stmt.ExecContext(ctx, r.db)
+	return err
+}
+
+// DeleteStale removes the cards that no longer apply. Dismissed cards are kept
+// so that they are not shown again if they become applicable later.
+func (r *insightRepository) DeleteStale(ctx context.Context, userID uuid.UUID, keepKeys []string) error {
+	condition := table.Insight.UserID.EQ(postgres.UUID(userID)).
+		AND(table.Insight.DismissedAt.IS_NULL())
+
+	if len(keepKeys) > 0 {
+		keys := make([]postgres.Expression, len(keepKeys))
+		for i, key := range keepKeys {
+			keys[i] = postgres.String(key)
+		}
+		condition = condition.AND(table.Insight.Key.NOT_IN(keys...))
+	}
+
+	_, err := table.Insight.DELETE().WHERE(condition).ExecContext(ctx, r.db)
+	return err
+}
+
+func (r *insightRepository) MarkRead(ctx context.Context, userID uuid.UUID, id int32) (*model.Insight, error) {
+	return r.setTimestamp(ctx, userID, id, table.Insight.ReadAt, "COALESCE(read_at, NOW())")
+}
+
+func (r *insightRepository) Dismiss(ctx context.Context, userID uuid.UUID, id int32) (*model.Insight, error) {
+	return r.setTimestamp(ctx, userID, id, table.Insight.DismissedAt, "COALESCE(dismissed_at, NOW())")
+}
+
+func (r *insightRepository) setTimestamp(ctx context.Context, userID uuid.UUID, id int32, column postgres.ColumnTimestamp, value string) (*model.Insight, error) {
+	stmt := table.Insight.UPDATE(
+		column,
+	).SET(
+		postgres.TimestampExp(postgres.Raw(value)),
+	).WHERE(
+		table.Insight.ID.EQ(postgres.Int32(id)).
+			AND(table.Insight.UserID.EQ(postgres.UUID(userID))),
+	).RETURNING(
+		table.Insight.AllColumns,
+	)
+
+	var dest model.Insight
+	err := stmt.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, u.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+)
+
+type MerchantRepository interface {
+	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Merchant, error)
+	GetByID(ctx context.Context, id int32) (*model.Merchant, error)
+	EnsureByKey(ctx context.Context, userID uuid.UUID, key, name string) (*model.Merchant, error)
+	Update(ctx context.Context, merchant *model.Merchant) (*model.Merchant, error)
+}
+
+type merchantRepository struct {
+	db *sql.DB
+}
+
+func NewMerchantRepository(db *sql.DB) MerchantRepository {
+	return &merchantRepository{db: db}
+}
+
+func (r *merchantRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Merchant, error) {
+	query := table.Merchant.SELECT(
+		table.Merchant.AllColumns,
+	).FROM(
+		table.Merchant,
+	).WHERE(
+		table.Merchant.UserID.EQ(postgres.UUID(userID)),
+	).ORDER_BY(
+		table.Merchant.Name.ASC(),
+		table.Merchant.ID.ASC(),
+	).LIMIT(int64(limit)).OFFSET(int64(offset))
+
+	var dest []model.Merchant
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *merchantRepository) GetByID(ctx context.Context, id int32) (*model.Merchant, error) {
+	query := table.Merchant.SELECT(
+		table.Merchant.AllColumns,
+	).FROM(
+		table.Merchant,
+	).WHERE(
+		table.Merchant.ID.EQ(postgres.Int32(id)),
+	)
+
+	var dest model.Merchant
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+// EnsureByKey returns the user's merchant with the given key, creating it with
+// name when missing. The name of an existing merchant is left untouched.
+func (r *merchantRepository) EnsureByKey(ctx context.Context, userID uuid.UUID, key, name string) (*model.Merchant, error) {
+	merchants, err := ensureMerchants(ctx, r.db, userID, []model.Merchant{{Key: key, Name: name}})
+	if err != nil {
+		return nil, err
+	}
+
+	return &merchants[0], nil
+}
+
+// ensureMerchants upserts the merchants, by key, with a single multi-row
+// INSERT. The keys must be distinct.
+func ensureMerchants(ctx context.Context, db qrm.Queryable, userID uuid.UUID, merchants []model.Merchant) ([]model.Merchant, error) {
+	if len(merchants) == 0 {
+		return []model.Merchant{}, nil
+	}
+
+	query := table.Merchant.INSERT(
+		table.Merchant.UserID,
+		table.Merchant.Key,
+		table.Merchant.Name,
+	)
+	for _, merchant := range merchants {
+		query = query.VALUES(userID, merchant.Key, merchant.Name)
+	}
+	// Updating the conflicting row makes RETURNING include existing merchants
+	query = query.ON_CONFLICT(
+		table.Merchant.UserID,
+		table.Merchant.Key,
+	).DO_UPDATE(
+		postgres.SET(
+			table.Merchant.Key.SET(table.Merchant.EXCLUDED.Key),
+		),
+	).RETURNING(table.Merchant.AllColumns)
+
+	var dest []model.Merchant
+	err := query.QueryContext(ctx, db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *merchantRepository) Update(ctx context.Context, merchant *model.Merchant) (*model.Merchant, error) {
+	query := table.Merchant.UPDATE(
+		table.Merchant.Name,
+	).SET(
+		merchant.Name,
+	).WHERE(
+		table.Merchant.ID.EQ(postgres.Int32(merchant.ID)).AND(atVersion(table.Merchant.UpdatedAt, merchant.UpdatedAt)),
+	).RETURNING(table.Merchant.AllColumns)
+
+	err := query.QueryContext(ctx, r.db, merchant)
+	if err != nil {
+		return nil, versionError(err, merchant.UpdatedAt)
+	}
+
+	return merchant, nil
+}
@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/view"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+// CategoryTotal is the aggregated spending of a single category within a period.
+// A nil CategoryID groups the expenses without a category.
+type CategoryTotal struct {
+	CategoryID   *int32
+	CategoryName *string
+	Total        float64
+	Count        int64
+}
+
+// MonthlyTotal is the sum of the transactions of one kind within a calendar month.
+type MonthlyTotal struct {
+	Month time.Time
+	Kind  string
+	Total float64
+}
+
+// TagTotal is the spending of the expenses carrying a tag in a period.
+type TagTotal struct {
+	TagID   int32
+	TagName string
+	Total   float64
+	Count   int64
+}
+
+// MerchantTotal is the spending at a merchant in a period. A nil MerchantID
+// groups the expenses not assigned to a merchant.
+type MerchantTotal struct {
+	MerchantID   *int32
+	MerchantName *string
+	Total        float64
+	Count        int64
+}
+
+// MemberTotal is the spending entered by a user in a period.
+type MemberTotal struct {
+	UserID    uuid.UUID
+	Email     string
+	FirstName *string
+	LastName  *string
+	Total     float64
+	Count     int64
+}
+
+// ReportRepository is the aggregation layer shared by all reports. Every
+// aggregation reads the report_line view, which nets refunds against the
+// expenses they refund.
+type ReportRepository interface {
+	CategoryTotals(ctx context.Context, scope Scope, period u.Period) ([]CategoryTotal, error)
+	TagTotals(ctx context.Context, scope Scope, period u.Period) ([]TagTotal, error)
+	MonthlyTotals(ctx context.Context, scope Scope, period u.Period) ([]MonthlyTotal, error)
+	MemberTotals(ctx context.Context, scope Scope, period u.Period) ([]MemberTotal, error)
+	MerchantTotals(ctx context.Context, scope Scope, period u.Period) ([]MerchantTotal, error)
+}
+
+type reportRepository struct {
+	db *sql.DB
+}
+
+func NewReportRepository(db *sql.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+func (r *reportRepository) CategoryTotals(ctx context.Context, scope Scope, period u.Period) ([]CategoryTotal, error) {
+	query := postgres.SELECT(
+		view.ReportLine.CategoryID.AS("category_total.category_id"),
+		table.Category.Name.AS("category_total.category_name"),
+		postgres.SUM(view.ReportLine.Amount).AS("category_total.total"),
+		// Refund lines are negative and do not count as transactions
+		postgres.COUNT(
+			postgres.CASE().WHEN(view.ReportLine.Amount.GT_EQ(postgres.Float(0))).THEN(view.ReportLine.ExpenseID),
+		).AS("category_total.count"),
+	).FROM(
+		view.ReportLine.LEFT_JOIN(table.Category, table.Category.ID.EQ(view.ReportLine.CategoryID)),
+	).WHERE(
+		reportLineCondition(scope, KindExpense, period),
+	).GROUP_BY(
+		view.ReportLine.CategoryID,
+		table.Category.Name,
+	)
+
+	var dest []CategoryTotal
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// TagTotals sums spending per tag. Refunds count against the tags of the
+// expense they refund.
+func (r *reportRepository) TagTotals(ctx context.Context, scope Scope, period u.Period) ([]TagTotal, error) {
+	query := postgres.SELECT(
+		table.Tag.ID.AS("tag_total.tag_id"),
+		table.Tag.Name.AS("tag_total.tag_name"),
+		postgres.SUM(view.ReportLine.Amount).AS("tag_total.total"),
+		// Refund lines are negative and do not count as transactions
+		postgres.COUNT(
+			postgres.CASE().WHEN(view.ReportLine.Amount.GT_EQ(postgres.Float(0))).THEN(view.ReportLine.ExpenseID),
+		).AS("tag_total.count"),
+	).FROM(
+		view.ReportLine.
+			INNER_JOIN(table.ExpenseTag, table.ExpenseTag.ExpenseID.EQ(view.ReportLine.SourceExpenseID)).
+			INNER_JOIN(table.Tag, table.Tag.ID.EQ(table.ExpenseTag.TagID)),
+	).WHERE(
+		reportLineCondition(scope, KindExpense, period),
+	).GROUP_BY(
+		table.Tag.ID,
+		table.Tag.Name,
+	).ORDER_BY(
+		postgres.SUM(view.ReportLine.Amount).DESC(),
+	)
+
+	var dest []TagTotal
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// MemberTotals sums spending per member who entered it. Refunds count against
+// the member who entered the refunded expense.
+func (r *reportRepository) MemberTotals(ctx context.Context, scope Scope, period u.Period) ([]MemberTotal, error) {
+	query := postgres.SELECT(
+		table.User.ID.AS("member_total.user_id"),
+		table.User.Email.AS("member_total.email"),
+		table.User.FirstName.AS("member_total.first_name"),
+		table.User.LastName.AS("member_total.last_name"),
+		postgres.SUM(view.ReportLine.Amount).AS("member_total.total"),
+		// Refund lines are negative and do not count as transactions
+		postgres.COUNT(
+			postgres.CASE().WHEN(view.ReportLine.Amount.GT_EQ(postgres.Float(0))).THEN(view.ReportLine.ExpenseID),
+		).AS("member_total.count"),
+	).FROM(
+		view.ReportLine.INNER_JOIN(table.User, table.User.ID.EQ(view.ReportLine.UserID)),
+	).WHERE(
+		reportLineCondition(scope, KindExpense, period),
+	).GROUP_BY(
+		table.User.ID,
+	).ORDER_BY(
+		postgres.SUM(view.ReportLine.Amount).DESC(),
+	)
+
+	var dest []MemberTotal
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// MerchantTotals sums spending per merchant. Refunds count against the
+// merchant of the expense they refund.
+func (r *reportRepository) MerchantTotals(ctx context.Context, scope Scope, period u.Period) ([]MerchantTotal, error) {
+	query := postgres.SELECT(
+		table.Merchant.ID.AS("merchant_total.merchant_id"),
+		table.Merchant.Name.AS("merchant_total.merchant_name"),
+		postgres.SUM(view.ReportLine.Amount).AS("merchant_total.total"),
+		// Refund lines are negative and do not count as transactions
+		postgres.COUNT(
+			postgres.CASE().WHEN(view.ReportLine.Amount.GT_EQ(postgres.Float(0))).THEN(view.ReportLine.ExpenseID),
+		).AS("merchant_total.count"),
+	).FROM(
+		view.ReportLine.
+			INNER_JOIN(table.Expense, table.Expense.ID.EQ(view.ReportLine.SourceExpenseID)).
+			LEFT_JOIN(table.Merchant, table.Merchant.ID.EQ(table.Expense.MerchantID)),
+	).WHERE(
+		reportLineCondition(scope, KindExpense, period),
+	).GROUP_BY(
+		table.Merchant.ID,
+		table.Merchant.Name,
+	).ORDER_BY(
+		postgres.SUM(view.ReportLine.Amount).DESC(),
+	)
+
+	var dest []MerchantTotal
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *reportRepository) MonthlyTotals(ctx context.Context, scope Scope, period u.Period) ([]MonthlyTotal, error) {
+	month := postgres.DATE_TRUNC(postgres.MONTH, view.ReportLine.PurchaseDate)
+
+	query := postgres.SELECT(
+		month.AS("monthly_total.month"),
+		view.ReportLine.Kind.AS("monthly_total.kind"),
+		postgres.SUM(view.ReportLine.Amount).AS("monthly_total.total"),
+	).FROM(
+		view.ReportLine,
+	).WHERE(
+		reportLineCondition(scope, "", period),
+	).GROUP_BY(
+		month,
+		view.ReportLine.Kind,
+	).ORDER_BY(
+		month.ASC(),
+	)
+
+	var dest []MonthlyTotal
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// reportLineCondition selects the ledger's report lines of a kind (all kinds when empty) within period.
+func reportLineCondition(scope Scope, kind string, period u.Period) postgres.BoolExpression {
+	condition := scope.condition(view.ReportLine.UserID, view.ReportLine.WorkspaceID).
+		AND(view.ReportLine.PurchaseDate.GT_EQ(postgres.TimestampT(period.From))).
+		AND(view.ReportLine.PurchaseDate.LT(postgres.TimestampT(period.To)))
+	if kind != "" {
+		condition = condition.AND(view.ReportLine.Kind.EQ(postgres.String(kind)))
+	}
+	return condition
+}
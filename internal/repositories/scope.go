@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+)
+
+// Workspace member roles
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// Scope selects a ledger: the personal ledger of UserID when WorkspaceID is
+// nil, or the shared ledger of a workspace.
+type Scope struct {
+	UserID      uuid.UUID
+	WorkspaceID *int32
+}
+
+// PersonalScope is the personal ledger of a user.
+func PersonalScope(userID uuid.UUID) Scope {
+	return Scope{UserID: userID}
+}
+
+// condition selects the rows of the ledger from a table with the given user and
+// workspace columns.
+func (s Scope) condition(userID postgres.ColumnString, workspaceID postgres.ColumnInteger) postgres.BoolExpression {
+	if s.WorkspaceID != nil {
+		return workspaceID.EQ(postgres.Int32(*s.WorkspaceID))
+	}
+	return userID.EQ(postgres.UUID(s.UserID)).AND(workspaceID.IS_NULL())
+}
+
+// Contains tells whether a row with the given user and workspace belongs to
+// the ledger.
+func (s Scope) Contains(userID uuid.UUID, workspaceID *int32) bool {
+	if s.WorkspaceID != nil {
+		return workspaceID != nil && *workspaceID == *s.WorkspaceID
+	}
+	return workspaceID == nil && userID == s.UserID
+}
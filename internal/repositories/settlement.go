@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+)
+
+type SettlementRepository interface {
+	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Settlement, error)
+	ListAllByUser(ctx context.Context, userID uuid.UUID) ([]model.Settlement, error)
+	GetByID(ctx context.Context, id int32) (*model.Settlement, error)
+	Create(ctx context.Context, settlement *model.Settlement) (*model.Settlement, error)
+	Delete(ctx context.Context, id int32, updatedAt time.Time) error
+}
+
+type settlementRepository struct {
+	db *sql.DB
+}
+
+func NewSettlementRepository(db *sql.DB) SettlementRepository {
+	return &settlementRepository{db: db}
+}
+
+func (r *settlementRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Settlement, error) {
+	query := table.Settlement.SELECT(
+		table.Settlement.AllColumns,
+	).FROM(
+		table.Settlement,
+	).WHERE(
+		table.Settlement.UserID.EQ(postgres.UUID(userID)),
+	).ORDER_BY(
+		table.Settlement.Date.DESC(),
+		table.Settlement.ID.DESC(),
+	).LIMIT(int64(limit)).OFFSET(int64(offset))
+
+	var dest []model.Settlement
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// ListAllByUser lists every settlement of the user, for computing balances.
+func (r *settlementRepository) ListAllByUser(ctx context.Context, userID uuid.UUID) ([]model.Settlement, error) {
+	query := table.Settlement.SELECT(
+		table.Settlement.AllColumns,
+	).FROM(
+		table.Settlement,
+	).WHERE(
+		table.Settlement.UserID.EQ(postgres.UUID(userID)),
+	).ORDER_BY(
+		table.Settlement.Date.ASC(),
+	)
+
+	var dest []model.Settlement
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *settlementRepository) GetByID(ctx context.Context, id int32) (*model.Settlement, error) {
+	query := table.Settlement.SELECT(
+		table.Settlement.AllColumns,
+	).FROM(
+		table.Settlement,
+	).WHERE(
+		table.Settlement.ID.EQ(postgres.Int32(id)),
+	)
+
+	var dest model.Settlement
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+func (r *settlementRepository) Create(ctx context.Context, settlement *model.Settlement) (*model.Settlement, error) {
+	query := table.Settlement.INSERT(
+		table.Settlement.UserID,
+		table.Settlement.FromContactID,
+		table.Settlement.ToContactID,
+		table.Settlement.Amount,
+		table.Settlement.Date,
+		table.Settlement.Note,
+	).VALUES(
+		settlement.UserID,
+		settlement.FromContactID,
+		settlement.ToContactID,
+		settlement.Amount,
+		settlement.Date,
+		settlement.Note,
+	).RETURNING(table.Settlement.AllColumns)
+
+	err := query.QueryContext(ctx, r.db, settlement)
+	if err != nil {
+		return nil, err
+	}
+
+	return settlement, nil
+}
+
+func (r *settlementRepository) Delete(ctx context.Context, id int32, updatedAt time.Time) error {
+	query := table.Settlement.DELETE().WHERE(
+		table.Settlement.ID.EQ(postgres.Int32(id)).AND(atVersion(table.Settlement.UpdatedAt, updatedAt)),
+	)
+
+	result, err := query.ExecContext(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	return checkVersion(result, updatedAt)
+}
@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+)
+
+// SyncChanges are the synced rows written since a change ID.
+type SyncChanges struct {
+	// Change ID to resume from: the lowest transaction still in progress
+	// when the rows were read
+	Next int64
+	// Expenses and categories include the ones in the trash
+	Expenses   []model.Expense
+	Categories []model.Category
+	Tags       []model.Tag
+	Accounts   []model.Account
+	Merchants  []model.Merchant
+	Contacts   []model.Contact
+	Tombstones []model.SyncTombstone
+}
+
+type SyncRepository interface {
+	// Changes reads, in a single snapshot, the rows of the ledger and the
+	// user's own tags, accounts, merchants and contacts written by
+	// transactions from the change ID on. From change 0, it leaves out what
+	// was deleted.
+	Changes(ctx context.Context, scope Scope, since int64) (*SyncChanges, error)
+	// DeleteTombstones deletes the tombstones of the rows deleted before the
+	// given time and returns how many were deleted.
+	DeleteTombstones(ctx context.Context, before time.Time) (int64, error)
+}
+
+type syncRepository struct {
+	db *sql.DB
+}
+
+func NewSyncRepository(db *sql.DB) SyncRepository {
+	return &syncRepository{db: db}
+}
+
+// Transactions from the change ID on may still be in progress and show up
+// later, so the rows they wrote are read again by the next sync. Clients apply
+// rows by ID, which makes reading them twice harmless.
+func (r *syncRepository) Changes(ctx context.Context, scope Scope, since int64) (*SyncChanges, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var position struct {
+		Next int64
+	}
+	err = postgres.RawStatement(
+		`SELECT pg_snapshot_xmin(pg_current_snapshot())::TEXT::BIGINT AS "next"`,
+	).QueryContext(ctx, tx, &position)
+	if err != nil {
+		return nil, err
+	}
+	changes := &SyncChanges{Next: position.Next}
+
+	user := postgres.UUID(scope.UserID)
+	from := postgres.Int64(since)
+
+	expenses := scope.condition(table.Expense.UserID, table.Expense.WorkspaceID).
+		AND(table.Expense.ChangeID.GT_EQ(from))
+	categories := scope.condition(table.Category.UserID, table.Category.WorkspaceID).
+		AND(table.Category.ChangeID.GT_EQ(from))
+	if since == 0 {
+		expenses = expenses.AND(expenseNotDeleted)
+		categories = categories.AND(table.Category.DeletedAt.IS_NULL())
+	}
+
+	type query struct {
+		stmt postgres.SelectStatement
+		dest any
+	}
+	queries := []query{
+		{
+			table.Expense.SELECT(table.Expense.AllColumns).WHERE(expenses).
+				ORDER_BY(table.Expense.ChangeID.ASC(), table.Expense.ID.ASC()),
+			&changes.Expenses,
+		},
+		{
+			table.Category.SELECT(table.Category.AllColumns).WHERE(categories).
+				ORDER_BY(table.Category.ChangeID.ASC(), table.Category.ID.ASC()),
+			&changes.Categories,
+		},
+		{
+			table.Tag.SELECT(table.Tag.AllColumns).
+				WHERE(table.Tag.UserID.EQ(user).AND(table.Tag.ChangeID.GT_EQ(from))).
+				ORDER_BY(table.Tag.ChangeID.ASC(), table.Tag.ID.ASC()),
+			&changes.Tags,
+		},
+		{
+			table.Account.SELECT(table.Account.AllColumns).
+				WHERE(table.Account.UserID.EQ(user).AND(table.Account.ChangeID.GT_EQ(from))).
+				ORDER_BY(table.Account.ChangeID.ASC(), table.Account.ID.ASC()),
+			&changes.Accounts,
+		},
+		{
+			table.Merchant.SELECT(table.Merchant.AllColumns).
+				WHERE(table.Merchant.UserID.EQ(user).AND(table.Merchant.ChangeID.GT_EQ(from))).
+				ORDER_BY(table.Merchant.ChangeID.ASC(), table.Merchant.ID.ASC()),
+			&changes.Merchants,
+		},
+		{
+			table.Contact.SELECT(table.Contact.AllColumns).
+				WHERE(table.Contact.UserID.EQ(user).AND(table.Contact.ChangeID.GT_EQ(from))).
+				ORDER_BY(table.Contact.ChangeID.ASC(), table.Contact.ID.ASC()),
+			&changes.Contacts,
+		},
+	}
+	if since > 0 {
+		// Tags, accounts, merchants and contacts belong to the user whatever the ledger
+		personal := table.SyncTombstone.UserID.EQ(user).AND(table.SyncTombstone.EntityType.IN(
+			postgres.String(table.Tag.TableName()),
+			postgres.String(table.Account.TableName()),
+			postgres.String(table.Merchant.TableName()),
+			postgres.String(table.Contact.TableName()),
+		))
+		queries = append(queries, query{
+			table.SyncTombstone.SELECT(table.SyncTombstone.AllColumns).
+				WHERE(
+					scope.condition(table.SyncTombstone.UserID, table.SyncTombstone.WorkspaceID).OR(personal).
+						AND(table.SyncTombstone.ChangeID.GT_EQ(from)),
+				).
+				ORDER_BY(table.SyncTombstone.ChangeID.ASC(), table.SyncTombstone.ID.ASC()),
+			&changes.Tombstones,
+		})
+	}
+
+	for _, q := range queries {
+		if err := q.stmt.QueryContext(ctx, tx, q.dest); err != nil {
+			return nil, err
+		}
+	}
+
+	return changes, nil
+}
+
+func (r *syncRepository) DeleteTombstones(ctx context.Context, before time.Time) (int64, error) {
+	result, err := table.SyncTombstone.DELETE().WHERE(
+		table.SyncTombstone.DeletedAt.LT(postgres.TimestampT(before)),
+	).ExecContext(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,253 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+)
+
+type TagRepository interface {
+	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Tag, error)
+	GetByID(ctx context.Context, id int32) (*model.Tag, error)
+	GetByName(ctx context.Context, userID uuid.UUID, name string) (*model.Tag, error)
+	EnsureByNames(ctx context.Context, userID uuid.UUID, names []string) ([]model.Tag, error)
+	NamesByExpense(ctx context.Context, expenseIDs []int32) (map[int32][]string, error)
+	Create(ctx context.Context, tag *model.Tag) (*model.Tag, error)
+	Update(ctx context.Context, tag *model.Tag) (*model.Tag, error)
+	Delete(ctx context.Context, id int32, updatedAt time.Time) error
+}
+
+type tagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.Tag, error) {
+	query := table.Tag.SELECT(
+		table.Tag.AllColumns,
+	).FROM(
+		table.Tag,
+	).WHERE(
+		table.Tag.UserID.EQ(postgres.UUID(userID)),
+	).ORDER_BY(
+		table.Tag.Name.ASC(),
+	).LIMIT(int64(limit)).OFFSET(int64(offset))
+
+	var dest []model.Tag
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *tagRepository) GetByID(ctx context.Context, id int32) (*model.Tag, error) {
+	query := table.Tag.SELECT(
+		table.Tag.AllColumns,
+	).FROM(
+		table.Tag,
+	).WHERE(
+		table.Tag.ID.EQ(postgres.Int32(id)),
+	)
+
+	var dest model.Tag
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+func (r *tagRepository) GetByName(ctx context.Context, userID uuid.UUID, name string) (*model.Tag, error) {
+	query := table.Tag.SELECT(
+		table.Tag.AllColumns,
+	).FROM(
+		table.Tag,
+	).WHERE(
+		table.Tag.UserID.EQ(postgres.UUID(userID)).
+			AND(table.Tag.Name.EQ(postgres.String(name))),
+	)
+
+	var dest model.Tag
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+// EnsureByNames returns the user's tags with the given names, creating the
+// missing ones.
+func (r *tagRepository) EnsureByNames(ctx context.Context, userID uuid.UUID, names []string) ([]model.Tag, error) {
+	return ensureTags(ctx, r.db, userID, names)
+}
+
+// ensureTags upserts the tags with a single multi-row INSERT. The names must be
+// distinct.
+func ensureTags(ctx context.Context, db qrm.Queryable, userID uuid.UUID, names []string) ([]model.Tag, error) {
+	if len(names) == 0 {
+		return []model.Tag{}, nil
+	}
+
+	query := table.Tag.INSERT(
+		table.Tag.UserID,
+		table.Tag.Name,
+	)
+	for _, name := range names {
+		query = query.VALUES(userID, name)
+	}
+	// Updating the conflicting row makes RETURNING include existing tags
+	query = query.ON_CONFLICT(
+		table.Tag.UserID,
+		table.Tag.Name,
+	).DO_UPDATE(
+		postgres.SET(
+			table.Tag.Name.SET(table.Tag.EXCLUDED.Name),
+		),
+	).RETURNING(table.Tag.AllColumns)
+
+	var dest []model.Tag
+	err := query.QueryContext(ctx, db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// NamesByExpense returns the sorted tag names of each given expense. Expenses
+// without tags are absent from the result.
+func (r *tagRepository) NamesByExpense(ctx context.Context, expenseIDs []int32) (map[int32][]string, error) {
+	result := map[int32][]string{}
+	if len(expenseIDs) == 0 {
+		return result, nil
+	}
+
+	idExpressions := make([]postgres.Expression, len(expenseIDs))
+	for i, id := range expenseIDs {
+		idExpressions[i] = postgres.Int32(id)
+	}
+
+	query := postgres.SELECT(
+		table.ExpenseTag.ExpenseID.AS("expense_id"),
+		table.Tag.Name.AS("name"),
+	).FROM(
+		table.ExpenseTag.INNER_JOIN(table.Tag, table.Tag.ID.EQ(table.ExpenseTag.TagID)),
+	).WHERE(
+		table.ExpenseTag.ExpenseID.IN(idExpressions...),
+	).ORDER_BY(
+		table.Tag.Name.ASC(),
+	)
+
+	var dest []struct {
+		ExpenseID int32
+		Name      string
+	}
+	if err := query.QueryContext(ctx, r.db, &dest); err != nil {
+		return nil, err
+	}
+
+	for _, row := range dest {
+		result[row.ExpenseID] = append(result[row.ExpenseID], row.Name)
+	}
+	return result, nil
+}
+
+func (r *tagRepository) Create(ctx context.Context, tag *model.Tag) (*model.Tag, error) {
+	query := table.Tag.INSERT(
+		table.Tag.UserID,
+		table.Tag.Name,
+	).VALUES(
+		tag.UserID,
+		tag.Name,
+	).RETURNING(table.Tag.AllColumns)
+
+	err := query.QueryContext(ctx, r.db, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// Update renames a tag, which bumps the version of the transactions tagged
+// with it.
+func (r *tagRepository) Update(ctx context.Context, tag *model.Tag) (*model.Tag, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := table.Tag.UPDATE(
+		table.Tag.Name,
+	).SET(
+		tag.Name,
+	).WHERE(
+		table.Tag.ID.EQ(postgres.Int32(tag.ID)).AND(atVersion(table.Tag.UpdatedAt, tag.UpdatedAt)),
+	).RETURNING(table.Tag.AllColumns)
+
+	if err := query.QueryContext(ctx, tx, tag); err != nil {
+		return nil, versionError(err, tag.UpdatedAt)
+	}
+	if err := touchExpenses(ctx, tx, taggedWith(tag.ID)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// Delete removes a tag, along with its links to transactions, and bumps the
+// version of these transactions.
+func (r *tagRepository) Delete(ctx context.Context, id int32, updatedAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := touchExpenses(ctx, tx, taggedWith(id)); err != nil {
+		return err
+	}
+	query := table.Tag.DELETE().WHERE(
+		table.Tag.ID.EQ(postgres.Int32(id)).AND(atVersion(table.Tag.UpdatedAt, updatedAt)),
+	)
+	result, err := query.ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(result, updatedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// taggedWith selects the transactions tagged with a tag.
+func taggedWith(tagID int32) postgres.BoolExpression {
+	return table.Expense.ID.IN(
+		table.ExpenseTag.SELECT(table.ExpenseTag.ExpenseID).WHERE(table.ExpenseTag.TagID.EQ(postgres.Int32(tagID))),
+	)
+}
@@ -16,8 +16,9 @@ type UserRepository interface {
 	List(ctx context.Context, limit, offset int) ([]model.User, error)
 	Create(ctx context.Context, user *model.User) (*model.User, error)
 	Upsert(ctx context.Context, user *model.User) (*model.User, bool, error)
-	DeleteByClerkID(ctx context.Context, clerkID string) error
 	GetInternalIDByClerkID(ctx context.Context, clerkID string) (uuid.UUID, error)
+	GetByClerkID(ctx context.Context, clerkID string) (*model.User, error)
+	UpdateLocale(ctx context.Context, clerkID, locale string) (*model.User, error)
 }
 
 type userRepository struct {
@@ -105,37 +106,55 @@ func (r *userRepository) Upsert(ctx context.Context, user *model.User) (*model.U
 	return &upsertedUser, created, nil
 }
 
-func (r *userRepository) DeleteByClerkID(ctx context.Context, clerkID string) error {
-	stmt := table.User.DELETE().WHERE(table.User.ClerkID.EQ(postgres.String(clerkID)))
+func (r *userRepository) GetInternalIDByClerkID(ctx context.Context, clerkID string) (uuid.UUID, error) {
+	stmt := table.User.SELECT(table.User.ID).WHERE(table.User.ClerkID.EQ(postgres.String(clerkID)))
 
-	result, err := stmt.ExecContext(ctx, r.db)
+	var user model.User
+	err := stmt.QueryContext(ctx, r.db, &user)
 	if err != nil {
-		return err
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, u.ErrNotFound
+		}
+		return uuid.Nil, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
+	return user.ID, nil
+}
+
+func (r *userRepository) GetByClerkID(ctx context.Context, clerkID string) (*model.User, error) {
+	stmt := table.User.SELECT(table.User.AllColumns).WHERE(table.User.ClerkID.EQ(postgres.String(clerkID)))
 
-	if rowsAffected == 0 {
-		return u.ErrNotFound
+	var user model.User
+	err := stmt.QueryContext(ctx, r.db, &user)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, u.ErrNotFound
+		}
+		return nil, err
 	}
 
-	return nil
+	return &user, nil
 }
 
-func (r *userRepository) GetInternalIDByClerkID(ctx context.Context, clerkID string) (uuid.UUID, error) {
-	stmt := table.User.SELECT(table.User.ID).WHERE(table.User.ClerkID.EQ(postgres.String(clerkID)))
+func (r *userRepository) UpdateLocale(ctx context.Context, clerkID, locale string) (*model.User, error) {
+	stmt := table.User.UPDATE(
+		table.User.Locale,
+	).SET(
+		locale,
+	).WHERE(
+		table.User.ClerkID.EQ(postgres.String(clerkID)),
+	).RETURNING(
+		table.User.AllColumns,
+	)
 
 	var user model.User
 	err := stmt.QueryContext(ctx, r.db, &user)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return uuid.Nil, u.ErrNotFound
+			return nil, u.ErrNotFound
 		}
-		return uuid.Nil, err
+		return nil, err
 	}
 
-	return user.ID, nil
+	return &user, nil
 }
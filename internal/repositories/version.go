@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+// atVersion matches the rows still at the version last updated at updatedAt,
+// or any row when it is zero. Writes guarded by it leave rows changed since
+// they were read untouched, so that concurrent edits are not lost.
+func atVersion(column postgres.ColumnTimestamp, updatedAt time.Time) postgres.BoolExpression {
+	if updatedAt.IsZero() {
+		return postgres.Bool(true)
+	}
+	return column.EQ(postgres.TimestampT(updatedAt))
+}
+
+// checkVersion fails a write guarded by atVersion that found no row at the
+// expected version.
+func checkVersion(result sql.Result, updatedAt time.Time) error {
+	if updatedAt.IsZero() {
+		return nil
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return u.ErrPreconditionFailed
+	}
+	return nil
+}
+
+// versionError reports a guarded write returning no row as a precondition
+// failure.
+func versionError(err error, updatedAt time.Time) error {
+	if errors.Is(err, qrm.ErrNoRows) && !updatedAt.IsZero() {
+		return u.ErrPreconditionFailed
+	}
+	return err
+}
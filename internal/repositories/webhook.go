@@ -0,0 +1,410 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+)
+
+// Statuses of a webhook delivery
+const (
+	WebhookPending   = "pending"
+	WebhookSucceeded = "succeeded"
+	WebhookFailed    = "failed"
+)
+
+type WebhookRepository interface {
+	ListEndpointsByUser(ctx context.Context, userID uuid.UUID) ([]model.WebhookEndpoint, error)
+	ListEndpointsByIDs(ctx context.Context, ids []int32) ([]model.WebhookEndpoint, error)
+	GetEndpoint(ctx context.Context, id int32) (*model.WebhookEndpoint, error)
+	CreateEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) (*model.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) (*model.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id int32, updatedAt time.Time) error
+	// CreateDeliveries stores deliveries to attempt from their NextAttemptAt.
+	CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error
+	// ClaimDue returns up to limit pending deliveries of enabled endpoints due
+	// by now, postponing them to leaseUntil so that no other worker claims them
+	// while they are attempted.
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]model.WebhookDelivery, error)
+	// RecordAttempt logs an attempt and stores the outcome of the delivery.
+	RecordAttempt(ctx context.Context, delivery *model.WebhookDelivery, attempt *model.WebhookAttempt) error
+	ListDeliveries(ctx context.Context, endpointID int32, status string, limit, offset int) ([]model.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, id int32) (*model.WebhookDelivery, error)
+	// AttemptsByDelivery returns the attempts of the deliveries, latest first.
+	AttemptsByDelivery(ctx context.Context, deliveryIDs []int32) (map[int32][]model.WebhookAttempt, error)
+	// Redeliver makes a delivery pending again, with a fresh retry schedule
+	// starting from nextAttemptAt.
+	Redeliver(ctx context.Context, id int32, nextAttemptAt time.Time) (*model.WebhookDelivery, error)
+	// DeleteDeliveriesBefore deletes the deliveries no longer pending created
+	// before the given time and returns how many were deleted.
+	DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) ListEndpointsByUser(ctx context.Context, userID uuid.UUID) ([]model.WebhookEndpoint, error) {
+	query := table.WebhookEndpoint.SELECT(
+		table.WebhookEndpoint.AllColumns,
+	).FROM(
+		table.WebhookEndpoint,
+	).WHERE(
+		table.WebhookEndpoint.UserID.EQ(postgres.UUID(userID)),
+	).ORDER_BY(
+		table.WebhookEndpoint.ID.ASC(),
+	)
+
+	var dest []model.WebhookEndpoint
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *webhookRepository) ListEndpointsByIDs(ctx context.Context, ids []int32) ([]model.WebhookEndpoint, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	idExpressions := make([]postgres.Expression, len(ids))
+	for i, id := range ids {
+		idExpressions[i] = postgres.Int32(id)
+	}
+
+	query := table.WebhookEndpoint.SELECT(
+		table.WebhookEndpoint.AllColumns,
+	).FROM(
+		table.WebhookEndpoint,
+	).WHERE(
+		table.WebhookEndpoint.ID.IN(idExpressions...),
+	)
+
+	var dest []model.WebhookEndpoint
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *webhookRepository) GetEndpoint(ctx context.Context, id int32) (*model.WebhookEndpoint, error) {
+	query := table.WebhookEndpoint.SELECT(
+		table.WebhookEndpoint.AllColumns,
+	).FROM(
+		table.WebhookEndpoint,
+	).WHERE(
+		table.WebhookEndpoint.ID.EQ(postgres.Int32(id)),
+	)
+
+	var dest model.WebhookEndpoint
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+func (r *webhookRepository) CreateEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) (*model.WebhookEndpoint, error) {
+	query := table.WebhookEndpoint.INSERT(
+		table.WebhookEndpoint.UserID,
+		table.WebhookEndpoint.URL,
+		table.WebhookEndpoint.Description,
+		table.WebhookEndpoint.Secret,
+		table.WebhookEndpoint.EventTypes,
+		table.WebhookEndpoint.Enabled,
+	).VALUES(
+		endpoint.UserID,
+		endpoint.URL,
+		endpoint.Description,
+		endpoint.Secret,
+		endpoint.EventTypes,
+		endpoint.Enabled,
+	).RETURNING(table.WebhookEndpoint.AllColumns)
+
+	err := query.QueryContext(ctx, r.db, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+func (r *webhookRepository) UpdateEndpoint(ctx context.Context, endpoint *model.WebhookEndpoint) (*model.WebhookEndpoint, error) {
+	query := table.WebhookEndpoint.UPDATE(
+		table.WebhookEndpoint.URL,
+		table.WebhookEndpoint.Description,
+		table.WebhookEndpoint.EventTypes,
+		table.WebhookEndpoint.Enabled,
+	).SET(
+		endpoint.URL,
+		endpoint.Description,
+		endpoint.EventTypes,
+		endpoint.Enabled,
+	).WHERE(
+		table.WebhookEndpoint.ID.EQ(postgres.Int32(endpoint.ID)).
+			AND(atVersion(table.WebhookEndpoint.UpdatedAt, endpoint.UpdatedAt)),
+	).RETURNING(table.WebhookEndpoint.AllColumns)
+
+	err := query.QueryContext(ctx, r.db, endpoint)
+	if err != nil {
+		return nil, versionError(err, endpoint.UpdatedAt)
+	}
+
+	return endpoint, nil
+}
+
+func (r *webhookRepository) DeleteEndpoint(ctx context.Context, id int32, updatedAt time.Time) error {
+	result, err := table.WebhookEndpoint.DELETE().WHERE(
+		table.WebhookEndpoint.ID.EQ(postgres.Int32(id)).
+			AND(atVersion(table.WebhookEndpoint.UpdatedAt, updatedAt)),
+	).ExecContext(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	return checkVersion(result, updatedAt)
+}
+
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	query := table.WebhookDelivery.INSERT(
+		table.WebhookDelivery.EndpointID,
+		table.WebhookDelivery.MessageID,
+		table.WebhookDelivery.EventType,
+		table.WebhookDelivery.Payload,
+		table.WebhookDelivery.NextAttemptAt,
+	)
+	for _, delivery := range deliveries {
+		query = query.VALUES(
+			delivery.EndpointID,
+			delivery.MessageID,
+			delivery.EventType,
+			delivery.Payload,
+			delivery.NextAttemptAt,
+		)
+	}
+
+	_, err := query.ExecContext(ctx, r.db)
+	return err
+}
+
+func (r *webhookRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]model.WebhookDelivery, error) {
+	due := table.WebhookDelivery.SELECT(
+		table.WebhookDelivery.ID,
+	).FROM(
+		table.WebhookDelivery.INNER_JOIN(
+			table.WebhookEndpoint,
+			table.WebhookEndpoint.ID.EQ(table.WebhookDelivery.EndpointID),
+		),
+	).WHERE(
+		table.WebhookDelivery.Status.EQ(postgres.String(WebhookPending)).
+			AND(table.WebhookDelivery.NextAttemptAt.LT_EQ(postgres.TimestampT(now))).
+			AND(table.WebhookEndpoint.Enabled.IS_TRUE()),
+	).ORDER_BY(
+		table.WebhookDelivery.NextAttemptAt.ASC(),
+	).LIMIT(int64(limit)).FOR(
+		postgres.UPDATE().OF(table.WebhookDelivery).SKIP_LOCKED(),
+	)
+
+	query := table.WebhookDelivery.UPDATE(
+		table.WebhookDelivery.NextAttemptAt,
+	).SET(
+		leaseUntil,
+	).WHERE(
+		table.WebhookDelivery.ID.IN(due),
+	).RETURNING(table.WebhookDelivery.AllColumns)
+
+	var dest []model.WebhookDelivery
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *webhookRepository) RecordAttempt(ctx context.Context, delivery *model.WebhookDelivery, attempt *model.WebhookAttempt) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = table.WebhookAttempt.INSERT(
+		table.WebhookAttempt.DeliveryID,
+		table.WebhookAttempt.ResponseCode,
+		table.WebhookAttempt.ResponseBody,
+		table.WebhookAttempt.Error,
+		table.WebhookAttempt.DurationMs,
+	).VALUES(
+		delivery.ID,
+		attempt.ResponseCode,
+		attempt.ResponseBody,
+		attempt.Error,
+		attempt.DurationMs,
+	).RETURNING(
+		table.WebhookAttempt.AllColumns,
+	).QueryContext(ctx, tx, attempt)
+	if err != nil {
+		return err
+	}
+
+	err = table.WebhookDelivery.UPDATE(
+		table.WebhookDelivery.Status,
+		table.WebhookDelivery.AttemptCount,
+		table.WebhookDelivery.NextAttemptAt,
+		table.WebhookDelivery.LastAttemptAt,
+		table.WebhookDelivery.LastResponseCode,
+	).SET(
+		delivery.Status,
+		delivery.AttemptCount,
+		delivery.NextAttemptAt,
+		delivery.LastAttemptAt,
+		delivery.LastResponseCode,
+	).WHERE(
+		table.WebhookDelivery.ID.EQ(postgres.Int32(delivery.ID)),
+	).RETURNING(
+		table.WebhookDelivery.AllColumns,
+	).QueryContext(ctx, tx, delivery)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, endpointID int32, status string, limit, offset int) ([]model.WebhookDelivery, error) {
+	condition := table.WebhookDelivery.EndpointID.EQ(postgres.Int32(endpointID))
+	if status != "" {
+		condition = condition.AND(table.WebhookDelivery.Status.EQ(postgres.String(status)))
+	}
+
+	query := table.WebhookDelivery.SELECT(
+		table.WebhookDelivery.AllColumns,
+	).FROM(
+		table.WebhookDelivery,
+	).WHERE(
+		condition,
+	).ORDER_BY(
+		table.WebhookDelivery.CreatedAt.DESC(),
+		table.WebhookDelivery.ID.DESC(),
+	).LIMIT(int64(limit)).OFFSET(int64(offset))
+
+	var dest []model.WebhookDelivery
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *webhookRepository) GetDelivery(ctx context.Context, id int32) (*model.WebhookDelivery, error) {
+	query := table.WebhookDelivery.SELECT(
+		table.WebhookDelivery.AllColumns,
+	).FROM(
+		table.WebhookDelivery,
+	).WHERE(
+		table.WebhookDelivery.ID.EQ(postgres.Int32(id)),
+	)
+
+	var dest model.WebhookDelivery
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+func (r *webhookRepository) AttemptsByDelivery(ctx context.Context, deliveryIDs []int32) (map[int32][]model.WebhookAttempt, error) {
+	if len(deliveryIDs) == 0 {
+		return map[int32][]model.WebhookAttempt{}, nil
+	}
+
+	idExpressions := make([]postgres.Expression, len(deliveryIDs))
+	for i, id := range deliveryIDs {
+		idExpressions[i] = postgres.Int32(id)
+	}
+
+	query := table.WebhookAttempt.SELECT(
+		table.WebhookAttempt.AllColumns,
+	).FROM(
+		table.WebhookAttempt,
+	).WHERE(
+		table.WebhookAttempt.DeliveryID.IN(idExpressions...),
+	).ORDER_BY(
+		table.WebhookAttempt.ID.DESC(),
+	)
+
+	var dest []model.WebhookAttempt
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := make(map[int32][]model.WebhookAttempt, len(deliveryIDs))
+	for _, attempt := range dest {
+		attempts[attempt.DeliveryID] = append(attempts[attempt.DeliveryID], attempt)
+	}
+	return attempts, nil
+}
+
+func (r *webhookRepository) Redeliver(ctx context.Context, id int32, nextAttemptAt time.Time) (*model.WebhookDelivery, error) {
+	query := table.WebhookDelivery.UPDATE(
+		table.WebhookDelivery.Status,
+		table.WebhookDelivery.AttemptCount,
+		table.WebhookDelivery.NextAttemptAt,
+	).SET(
+		WebhookPending,
+		0,
+		nextAttemptAt,
+	).WHERE(
+		table.WebhookDelivery.ID.EQ(postgres.Int32(id)),
+	).RETURNING(table.WebhookDelivery.AllColumns)
+
+	var dest model.WebhookDelivery
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+func (r *webhookRepository) DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := table.WebhookDelivery.DELETE().WHERE(
+		table.WebhookDelivery.CreatedAt.LT(postgres.TimestampT(before)).
+			AND(table.WebhookDelivery.Status.NOT_EQ(postgres.String(WebhookPending))),
+	).ExecContext(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
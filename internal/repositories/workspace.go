@@ -0,0 +1,381 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/table"
+)
+
+// MembershipDetails is a workspace along with the role of a member in it.
+type MembershipDetails struct {
+	model.Workspace
+	Role string
+}
+
+// MemberDetails is a workspace member along with their user profile.
+type MemberDetails struct {
+	model.WorkspaceMember
+	Email     string
+	FirstName *string
+	LastName  *string
+}
+
+type WorkspaceRepository interface {
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]MembershipDetails, error)
+	GetByID(ctx context.Context, id int32) (*model.Workspace, error)
+	Create(ctx context.Context, workspace *model.Workspace, ownerID uuid.UUID) (*model.Workspace, error)
+	GetMember(ctx context.Context, workspaceID int32, userID uuid.UUID) (*model.WorkspaceMember, error)
+	ListMembers(ctx context.Context, workspaceID int32) ([]MemberDetails, error)
+	UpdateMemberRole(ctx context.Context, workspaceID int32, userID uuid.UUID, role string, updatedAt time.Time) (*model.WorkspaceMember, error)
+	RemoveMember(ctx context.Context, workspaceID int32, userID uuid.UUID, updatedAt time.Time) error
+	ListInvitations(ctx context.Context, workspaceID int32) ([]model.WorkspaceInvitation, error)
+	ListPendingInvitationsByEmail(ctx context.Context, email string) ([]model.WorkspaceInvitation, error)
+	GetInvitation(ctx context.Context, id int32) (*model.WorkspaceInvitation, error)
+	CreateInvitation(ctx context.Context, invitation *model.WorkspaceInvitation) (*model.WorkspaceInvitation, error)
+	AcceptInvitation(ctx context.Context, invitation *model.WorkspaceInvitation, userID uuid.UUID) (*model.WorkspaceMember, error)
+	DeleteInvitation(ctx context.Context, id int32, updatedAt time.Time) error
+}
+
+type workspaceRepository struct {
+	db *sql.DB
+}
+
+func NewWorkspaceRepository(db *sql.DB) WorkspaceRepository {
+	return &workspaceRepository{db: db}
+}
+
+func (r *workspaceRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]MembershipDetails, error) {
+	query := postgres.SELECT(
+		table.Workspace.AllColumns,
+		table.WorkspaceMember.Role.AS("membership_details.role"),
+	).FROM(
+		table.Workspace.INNER_JOIN(table.WorkspaceMember, table.WorkspaceMember.WorkspaceID.EQ(table.Workspace.ID)),
+	).WHERE(
+		table.WorkspaceMember.UserID.EQ(postgres.UUID(userID)),
+	).ORDER_BY(
+		table.Workspace.Name.ASC(),
+	)
+
+	var dest []MembershipDetails
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *workspaceRepository) GetByID(ctx context.Context, id int32) (*model.Workspace, error) {
+	query := table.Workspace.SELECT(
+		table.Workspace.AllColumns,
+	).FROM(
+		table.Workspace,
+	).WHERE(
+		table.Workspace.ID.EQ(postgres.Int32(id)),
+	)
+
+	var dest model.Workspace
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+// Create stores the workspace and makes ownerID its owner.
+func (r *workspaceRepository) Create(ctx context.Context, workspace *model.Workspace, ownerID uuid.UUID) (*model.Workspace, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = table.Workspace.INSERT(
+		table.Workspace.Name,
+	).VALUES(
+		workspace.Name,
+	).RETURNING(
+		table.Workspace.AllColumns,
+	).QueryContext(ctx, tx, workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = table.WorkspaceMember.INSERT(
+		table.WorkspaceMember.WorkspaceID,
+		table.WorkspaceMember.UserID,
+		table.WorkspaceMember.Role,
+	).VALUES(
+		workspace.ID,
+		ownerID,
+		RoleOwner,
+	).ExecContext(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+func (r *workspaceRepository) GetMember(ctx context.Context, workspaceID int32, userID uuid.UUID) (*model.WorkspaceMember, error) {
+	query := table.WorkspaceMember.SELECT(
+		table.WorkspaceMember.AllColumns,
+	).FROM(
+		table.WorkspaceMember,
+	).WHERE(
+		table.WorkspaceMember.WorkspaceID.EQ(postgres.Int32(workspaceID)).
+			AND(table.WorkspaceMember.UserID.EQ(postgres.UUID(userID))),
+	)
+
+	var dest model.WorkspaceMember
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if errors.Is(err, qrm.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+func (r *workspaceRepository) ListMembers(ctx context.Context, workspaceID int32) ([]MemberDetails, error) {
+	query := postgres.SELECT(
+		table.WorkspaceMember.AllColumns,
+		table.User.Email.AS("member_details.email"),
+		table.User.FirstName.AS("member_details.first_name"),
+		table.User.LastName.AS("member_details.last_name"),
+	).FROM(
+		table.WorkspaceMember.INNER_JOIN(table.User, table.User.ID.EQ(table.WorkspaceMember.UserID)),
+	).WHERE(
+		table.WorkspaceMember.WorkspaceID.EQ(postgres.Int32(workspaceID)),
+	).ORDER_BY(
+		table.WorkspaceMember.CreatedAt.ASC(),
+	)
+
+	var dest []MemberDetails
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *workspaceRepository) UpdateMemberRole(ctx context.Context, workspaceID int32, userID uuid.UUID, role string, updatedAt time.Time) (*model.WorkspaceMember, error) {
+	query := table.WorkspaceMember.UPDATE(
+		table.WorkspaceMember.Role,
+	).SET(
+		role,
+	).WHERE(
+		table.WorkspaceMember.WorkspaceID.EQ(postgres.Int32(workspaceID)).
+			AND(table.WorkspaceMember.UserID.EQ(postgres.UUID(userID))).
+			AND(atVersion(table.WorkspaceMember.UpdatedAt, updatedAt)),
+	).RETURNING(
+		table.WorkspaceMember.AllColumns,
+	)
+
+	var dest model.WorkspaceMember
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, versionError(err, updatedAt)
+	}
+
+	return &dest, nil
+}
+
+func (r *workspaceRepository) RemoveMember(ctx context.Context, workspaceID int32, userID uuid.UUID, updatedAt time.Time) error {
+	query := table.WorkspaceMember.DELETE().WHERE(
+		table.WorkspaceMember.WorkspaceID.EQ(postgres.Int32(workspaceID)).
+			AND(table.WorkspaceMember.UserID.EQ(postgres.UUID(userID))).
+			AND(atVersion(table.WorkspaceMember.UpdatedAt, updatedAt)),
+	)
+
+	result, err := query.ExecContext(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	return checkVersion(result, updatedAt)
+}
+
+// ListInvitations lists the pending invitations of a workspace.
+func (r *workspaceRepository) ListInvitations(ctx context.Context, workspaceID int32) ([]model.WorkspaceInvitation, error) {
+	query := table.WorkspaceInvitation.SELECT(
+		table.WorkspaceInvitation.AllColumns,
+	).FROM(
+		table.WorkspaceInvitation,
+	).WHERE(
+		table.WorkspaceInvitation.WorkspaceID.EQ(postgres.Int32(workspaceID)).
+			AND(table.WorkspaceInvitation.AcceptedAt.IS_NULL()),
+	).ORDER_BY(
+		table.WorkspaceInvitation.CreatedAt.DESC(),
+	)
+
+	var dest []model.WorkspaceInvitation
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *workspaceRepository) ListPendingInvitationsByEmail(ctx context.Context, email string) ([]model.WorkspaceInvitation, error) {
+	query := table.WorkspaceInvitation.SELECT(
+		table.WorkspaceInvitation.AllColumns,
+	).FROM(
+		table.WorkspaceInvitation,
+	).WHERE(
+		table.WorkspaceInvitation.Email.EQ(postgres.String(email)).
+			AND(table.WorkspaceInvitation.AcceptedAt.IS_NULL()),
+	).ORDER_BY(
+		table.WorkspaceInvitation.CreatedAt.DESC(),
+	)
+
+	var dest []model.WorkspaceInvitation
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+func (r *workspaceRepository) GetInvitation(ctx context.Context, id int32) (*model.WorkspaceInvitation, error) {
+	query := table.WorkspaceInvitation.SELECT(
+		table.WorkspaceInvitation.AllColumns,
+	).FROM(
+		table.WorkspaceInvitation,
+	).WHERE(
+		table.WorkspaceInvitation.ID.EQ(postgres.Int32(id)),
+	)
+
+	var dest model.WorkspaceInvitation
+	err := query.QueryContext(ctx, r.db, &dest)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &dest, nil
+}
+
+// CreateInvitation stores an invitation. A pending invitation of the same email
+// to the same workspace is updated with the new role instead.
+func (r *workspaceRepository) CreateInvitation(ctx context.Context, invitation *model.WorkspaceInvitation) (*model.WorkspaceInvitation, error) {
+	query := table.WorkspaceInvitation.INSERT(
+		table.WorkspaceInvitation.WorkspaceID,
+		table.WorkspaceInvitation.InvitedBy,
+		table.WorkspaceInvitation.Email,
+		table.WorkspaceInvitation.Role,
+	).VALUES(
+		invitation.WorkspaceID,
+		invitation.InvitedBy,
+		invitation.Email,
+		invitation.Role,
+	).ON_CONFLICT(
+		table.WorkspaceInvitation.WorkspaceID,
+		table.WorkspaceInvitation.Email,
+	).WHERE(
+		table.WorkspaceInvitation.AcceptedAt.IS_NULL(),
+	).DO_UPDATE(
+		postgres.SET(
+			table.WorkspaceInvitation.InvitedBy.SET(table.WorkspaceInvitation.EXCLUDED.InvitedBy),
+			table.WorkspaceInvitation.Role.SET(table.WorkspaceInvitation.EXCLUDED.Role),
+		),
+	).RETURNING(table.WorkspaceInvitation.AllColumns)
+
+	err := query.QueryContext(ctx, r.db, invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// AcceptInvitation adds the user to the workspace with the invited role and
+// marks the invitation as accepted. Existing members keep their role.
+func (r *workspaceRepository) AcceptInvitation(ctx context.Context, invitation *model.WorkspaceInvitation, userID uuid.UUID) (*model.WorkspaceMember, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = table.WorkspaceInvitation.UPDATE(
+		table.WorkspaceInvitation.AcceptedAt,
+	).SET(
+		time.Now().UTC(),
+	).WHERE(
+		table.WorkspaceInvitation.ID.EQ(postgres.Int32(invitation.ID)),
+	).ExecContext(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = table.WorkspaceMember.INSERT(
+		table.WorkspaceMember.WorkspaceID,
+		table.WorkspaceMember.UserID,
+		table.WorkspaceMember.Role,
+	).VALUES(
+		invitation.WorkspaceID,
+		userID,
+		invitation.Role,
+	).ON_CONFLICT(
+		table.WorkspaceMember.WorkspaceID,
+		table.WorkspaceMember.UserID,
+	).DO_NOTHING().ExecContext(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var member model.WorkspaceMember
+	err = table.WorkspaceMember.SELECT(
+		table.WorkspaceMember.AllColumns,
+	).WHERE(
+		table.WorkspaceMember.WorkspaceID.EQ(postgres.Int32(invitation.WorkspaceID)).
+			AND(table.WorkspaceMember.UserID.EQ(postgres.UUID(userID))),
+	).QueryContext(ctx, tx, &member)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func (r *workspaceRepository) DeleteInvitation(ctx context.Context, id int32, updatedAt time.Time) error {
+	query := table.WorkspaceInvitation.DELETE().WHERE(
+		table.WorkspaceInvitation.ID.EQ(postgres.Int32(id)).
+			AND(atVersion(table.WorkspaceInvitation.UpdatedAt, updatedAt)),
+	)
+
+	result, err := query.ExecContext(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	return checkVersion(result, updatedAt)
+}
@@ -0,0 +1,106 @@
+// Package revision computes the field-level changes made to a transaction by
+// an update, and undoes them. Changes are stored as JSON, so that old and new
+// values keep their types whatever the field.
+package revision
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Split is a split line as tracked by revisions.
+type Split struct {
+	CategoryID int32   `json:"categoryId"`
+	Amount     float64 `json:"amount"`
+	Note       *string `json:"note"`
+}
+
+// Snapshot holds the fields of a transaction that users edit.
+type Snapshot struct {
+	Amount       float64
+	Description  string
+	PurchaseDate time.Time
+	BillDate     time.Time
+	CategoryID   *int32
+	AccountID    *int32
+	// Tag names, in any order
+	Tags   []string
+	Splits []Split
+}
+
+// Change is the value of a field before and after an update.
+type Change struct {
+	Old json.RawMessage `json:"old"`
+	New json.RawMessage `json:"new"`
+}
+
+// Changes are the fields changed by an update, by name.
+type Changes map[string]Change
+
+// fields returns the tracked fields of the snapshot by name.
+func (s *Snapshot) fields() map[string]any {
+	return map[string]any{
+		"amount":       &s.Amount,
+		"description":  &s.Description,
+		"purchaseDate": &s.PurchaseDate,
+		"billDate":     &s.BillDate,
+		"categoryId":   &s.CategoryID,
+		"accountId":    &s.AccountID,
+		"tags":         &s.Tags,
+		"splits":       &s.Splits,
+	}
+}
+
+// normalize makes equal snapshots encode identically: tags are sorted and
+// missing lists are empty.
+func (s Snapshot) normalize() Snapshot {
+	s.Tags = slices.Sorted(slices.Values(s.Tags))
+	if s.Tags == nil {
+		s.Tags = []string{}
+	}
+	if s.Splits == nil {
+		s.Splits = []Split{}
+	}
+	return s
+}
+
+// Diff returns the fields that differ between two snapshots, empty when none do.
+func Diff(before, after Snapshot) (Changes, error) {
+	before, after = before.normalize(), after.normalize()
+	oldFields, newFields := before.fields(), after.fields()
+
+	changes := Changes{}
+	for name, oldField := range oldFields {
+		oldValue, err := json.Marshal(oldField)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		newValue, err := json.Marshal(newFields[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		if !bytes.Equal(oldValue, newValue) {
+			changes[name] = Change{Old: oldValue, New: newValue}
+		}
+	}
+	return changes, nil
+}
+
+// Revert sets the fields of the snapshot changed by the update back to their
+// old values. Fields the update left alone keep their current values.
+func (c Changes) Revert(s *Snapshot) error {
+	fields := s.fields()
+	for name, change := range c {
+		field, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("unknown field %q", name)
+		}
+		if err := json.Unmarshal(change.Old, field); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", name, err)
+		}
+	}
+	return nil
+}
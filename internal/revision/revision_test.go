@@ -0,0 +1,146 @@
+package revision
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func date(day int) time.Time {
+	return time.Date(2026, time.March, day, 0, 0, 0, 0, time.UTC)
+}
+
+func base() Snapshot {
+	return Snapshot{
+		Amount:       120.5,
+		Description:  "Groceries",
+		PurchaseDate: date(3),
+		BillDate:     date(10),
+		CategoryID:   ptr(int32(4)),
+		Tags:         []string{"home", "food"},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(s *Snapshot)
+		want map[string][2]string
+	}{
+		{
+			name: "no change",
+			edit: func(s *Snapshot) {},
+			want: map[string][2]string{},
+		},
+		{
+			name: "tag order and missing lists are not changes",
+			edit: func(s *Snapshot) {
+				s.Tags = []string{"food", "home"}
+				s.Splits = []Split{}
+			},
+			want: map[string][2]string{},
+		},
+		{
+			name: "amount and description",
+			edit: func(s *Snapshot) {
+				s.Amount = 99
+				s.Description = "Market"
+			},
+			want: map[string][2]string{
+				"amount":      {`120.5`, `99`},
+				"description": {`"Groceries"`, `"Market"`},
+			},
+		},
+		{
+			name: "category cleared and account set",
+			edit: func(s *Snapshot) {
+				s.CategoryID = nil
+				s.AccountID = ptr(int32(7))
+			},
+			want: map[string][2]string{
+				"categoryId": {`4`, `null`},
+				"accountId":  {`null`, `7`},
+			},
+		},
+		{
+			name: "dates",
+			edit: func(s *Snapshot) {
+				s.BillDate = date(11)
+			},
+			want: map[string][2]string{
+				"billDate": {`"2026-03-10T00:00:00Z"`, `"2026-03-11T00:00:00Z"`},
+			},
+		},
+		{
+			name: "tags and splits",
+			edit: func(s *Snapshot) {
+				s.Tags = []string{"food"}
+				s.Splits = []Split{{CategoryID: 4, Amount: 100}, {CategoryID: 5, Amount: 20.5, Note: ptr("soap")}}
+			},
+			want: map[string][2]string{
+				"tags":   {`["food","home"]`, `["food"]`},
+				"splits": {`[]`, `[{"categoryId":4,"amount":100,"note":null},{"categoryId":5,"amount":20.5,"note":"soap"}]`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := base()
+			tt.edit(&after)
+
+			changes, err := Diff(base(), after)
+			require.NoError(t, err)
+
+			got := map[string][2]string{}
+			for name, change := range changes {
+				got[name] = [2]string{string(change.Old), string(change.New)}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRevert(t *testing.T) {
+	before := base()
+	after := base()
+	after.Amount = 99
+	after.CategoryID = nil
+	after.Tags = []string{"food"}
+	after.Splits = []Split{{CategoryID: 4, Amount: 99}}
+
+	changes, err := Diff(before, after)
+	require.NoError(t, err)
+
+	// A later update of a field the revision did not touch is kept
+	current := after
+	current.Description = "Market"
+	require.NoError(t, changes.Revert(&current))
+
+	want := base()
+	want.Description = "Market"
+	want.Tags = []string{"food", "home"}
+	want.Splits = []Split{}
+	assert.Equal(t, want, current)
+}
+
+func TestRevertStoredChanges(t *testing.T) {
+	// Changes read back from the database
+	var changes Changes
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":{"old":10,"new":12},"categoryId":{"old":null,"new":3}}`), &changes))
+
+	current := Snapshot{Amount: 12, CategoryID: ptr(int32(3))}
+	require.NoError(t, changes.Revert(&current))
+	assert.Equal(t, Snapshot{Amount: 10}, current)
+
+	var unknown Changes
+	require.NoError(t, json.Unmarshal([]byte(`{"color":{"old":"red","new":"blue"}}`), &unknown))
+	assert.Error(t, unknown.Revert(&current))
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type AccountService interface {
+	ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]model.Account, error)
+	Create(ctx context.Context, clerkID string, account *model.Account) (*model.Account, error)
+}
+
+type accountService struct {
+	accountRepo repositories.AccountRepository
+	userService UserService
+}
+
+func NewAccountService(accountRepo repositories.AccountRepository, userService UserService) AccountService {
+	return &accountService{
+		accountRepo: accountRepo,
+		userService: userService,
+	}
+}
+
+func (s *accountService) ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]model.Account, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	return s.accountRepo.ListByUser(ctx, userID, limit, offset)
+}
+
+func (s *accountService) Create(ctx context.Context, clerkID string, account *model.Account) (*model.Account, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	account.UserID = userID
+	return s.accountRepo.Create(ctx, account)
+}
+
+// checkAccountOwner verifies that the account exists and belongs to the user.
+func checkAccountOwner(ctx context.Context, accountRepo repositories.AccountRepository, userID uuid.UUID, accountID int32) error {
+	account, err := accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return utils.ErrNotFound
+	}
+	if account.UserID != userID {
+		return utils.ErrForbidden
+	}
+	return nil
+}
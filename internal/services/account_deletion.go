@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/storage"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Deletions purged per query of the purge job
+const accountPurgeBatchSize = 50
+
+// AccountDeletionService deletes accounts after a grace period, during which
+// the user can change their mind.
+type AccountDeletionService interface {
+	// Get returns the scheduled deletion of the user's account.
+	Get(ctx context.Context, clerkID string) (*model.AccountDeletion, error)
+	// Request schedules the deletion of the user's account.
+	Request(ctx context.Context, clerkID string) (*model.AccountDeletion, error)
+	// Cancel unschedules the deletion of the user's account.
+	Cancel(ctx context.Context, clerkID string) (*model.AccountDeletion, error)
+	// HandleClerkDeletion schedules the deletion of a user removed from Clerk.
+	// Users unknown to the database are ignored.
+	HandleClerkDeletion(ctx context.Context, clerkID string) error
+	// PurgeDue purges the accounts whose grace period is over.
+	PurgeDue(ctx context.Context) error
+}
+
+type accountDeletionService struct {
+	accountDeletionRepo repositories.AccountDeletionRepository
+	blobStore           storage.BlobStore
+	userService         UserService
+	auditService        AuditService
+	gracePeriod         time.Duration
+}
+
+func NewAccountDeletionService(
+	accountDeletionRepo repositories.AccountDeletionRepository,
+	blobStore storage.BlobStore,
+	userService UserService,
+	auditService AuditService,
+	gracePeriod time.Duration,
+) AccountDeletionService {
+	return &accountDeletionService{
+		accountDeletionRepo: accountDeletionRepo,
+		blobStore:           blobStore,
+		userService:         userService,
+		auditService:        auditService,
+		gracePeriod:         gracePeriod,
+	}
+}
+
+func (s *accountDeletionService) Get(ctx context.Context, clerkID string) (*model.AccountDeletion, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	deletion, err := s.accountDeletionRepo.GetPending(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if deletion == nil {
+		return nil, utils.ErrNotFound
+	}
+	return deletion, nil
+}
+
+func (s *accountDeletionService) Request(ctx context.Context, clerkID string) (*model.AccountDeletion, error) {
+	user, err := s.userService.GetByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user for clerk %s: %w", clerkID, err)
+	}
+	return s.schedule(ctx, user, repositories.DeletionRequestedByUser)
+}
+
+func (s *accountDeletionService) Cancel(ctx context.Context, clerkID string) (*model.AccountDeletion, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	deletion, err := s.accountDeletionRepo.Cancel(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	logrus.WithField("user_id", userID).WithField("deletion_id", deletion.ID).Info("account deletion cancelled")
+	recordAudit(ctx, s.auditService, AuditEvent{
+		UserID:     &userID,
+		Action:     AuditAccountDeletionCancelled,
+		EntityType: "account_deletion",
+		EntityID:   fmt.Sprint(deletion.ID),
+		After:      deletion,
+	})
+	return deletion, nil
+}
+
+func (s *accountDeletionService) HandleClerkDeletion(ctx context.Context, clerkID string) error {
+	user, err := s.userService.GetByClerkID(ctx, clerkID)
+	if err == utils.ErrNotFound {
+		// Business Logic: Clerk retries failed deliveries, so a user already purged is not an error
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user for clerk %s: %w", clerkID, err)
+	}
+
+	_, err = s.schedule(ctx, user, repositories.DeletionRequestedByClerk)
+	return err
+}
+
+func (s *accountDeletionService) schedule(ctx context.Context, user *model.User, requestedBy string) (*model.AccountDeletion, error) {
+	deletion, err := s.accountDeletionRepo.Schedule(ctx, user, requestedBy, time.Now().UTC().Add(s.gracePeriod))
+	if err != nil {
+		return nil, err
+	}
+	logrus.WithField("user_id", user.ID).WithField("deletion_id", deletion.ID).
+		WithField("requested_by", deletion.RequestedBy).
+		WithField("scheduled_at", deletion.ScheduledAt).
+		Info("account deletion scheduled")
+	recordAudit(ctx, s.auditService, AuditEvent{
+		UserID:     &user.ID,
+		Action:     AuditAccountDeletionRequested,
+		EntityType: "account_deletion",
+		EntityID:   fmt.Sprint(deletion.ID),
+		Before:     user,
+		After:      deletion,
+	})
+	return deletion, nil
+}
+
+// PurgeDue purges every account whose deletion is due. A failure for one
+// account is logged and does not stop the others, which are retried on the
+// next run.
+func (s *accountDeletionService) PurgeDue(ctx context.Context) error {
+	for {
+		deletions, err := s.accountDeletionRepo.ListDue(ctx, time.Now().UTC(), accountPurgeBatchSize)
+		if err != nil {
+			return err
+		}
+		purged := 0
+		for _, deletion := range deletions {
+			if err := s.purge(ctx, &deletion); err != nil {
+				logrus.WithError(err).WithField("user_id", deletion.UserID).WithField("deletion_id", deletion.ID).
+					Error("failed to purge account")
+				continue
+			}
+			purged++
+		}
+		// Failed deletions are listed again, so a batch without progress ends the run
+		if len(deletions) < accountPurgeBatchSize || purged == 0 {
+			return nil
+		}
+	}
+}
+
+// purge deletes the rows of the user, then the files of their attachments.
+// Files that fail to delete are logged and left behind.
+func (s *accountDeletionService) purge(ctx context.Context, deletion *model.AccountDeletion) error {
+	keys, err := s.accountDeletionRepo.Purge(ctx, deletion)
+	if err == utils.ErrNotFound {
+		// Cancelled since it was listed
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := s.blobStore.Delete(ctx, key); err != nil {
+			logrus.WithError(err).WithField("storage_key", key).Error("failed to delete attachment blob")
+		}
+	}
+	logrus.WithField("user_id", deletion.UserID).WithField("deletion_id", deletion.ID).
+		WithField("attachments", len(keys)).
+		Info("account purged")
+	recordAudit(ctx, s.auditService, AuditEvent{
+		UserID:     &deletion.UserID,
+		Action:     AuditAccountPurged,
+		EntityType: "account_deletion",
+		EntityID:   fmt.Sprint(deletion.ID),
+		Before:     deletion,
+		After:      map[string]any{"attachments": len(keys)},
+	})
+	return nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/anomaly"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+// How far back the detector looks when evaluating a new expense.
+const anomalyHistoryDays = 365
+
+type AnomalyService interface {
+	ListByUser(ctx context.Context, clerkID string, includeDismissed bool, limit, offset int) ([]model.Anomaly, error)
+	DetectForExpense(ctx context.Context, expense *model.Expense) error
+	Dismiss(ctx context.Context, clerkID string, id int32) (*model.Anomaly, error)
+}
+
+type anomalyService struct {
+	anomalyRepo repositories.AnomalyRepository
+	expenseRepo repositories.ExpenseRepository
+	userService UserService
+}
+
+func NewAnomalyService(
+	anomalyRepo repositories.AnomalyRepository,
+	expenseRepo repositories.ExpenseRepository,
+	userService UserService,
+) AnomalyService {
+	return &anomalyService{
+		anomalyRepo: anomalyRepo,
+		expenseRepo: expenseRepo,
+		userService: userService,
+	}
+}
+
+func (s *anomalyService) ListByUser(ctx context.Context, clerkID string, includeDismissed bool, limit, offset int) ([]model.Anomaly, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	return s.anomalyRepo.ListByUser(ctx, userID, includeDismissed, limit, offset)
+}
+
+// DetectForExpense evaluates a newly created expense against the user's history
+// and stores any finding.
+func (s *anomalyService) DetectForExpense(ctx context.Context, expense *model.Expense) error {
+	if expense.Kind != repositories.KindExpense {
+		return nil
+	}
+
+	period := u.Period{
+		From: expense.PurchaseDate.AddDate(0, 0, -anomalyHistoryDays),
+		To:   expense.PurchaseDate.AddDate(0, 0, 1),
+	}
+	expenses, err := s.expenseRepo.ListByUserInPeriod(ctx, expense.UserID, repositories.KindExpense, period)
+	if err != nil {
+		return err
+	}
+
+	history := make([]anomaly.Observation, 0, len(expenses))
+	for _, e := range expenses {
+		if e.ID == expense.ID {
+			continue
+		}
+		history = append(history, toObservation(e))
+	}
+
+	for _, finding := range anomaly.Detect(toObservation(*expense), history) {
+		err := s.anomalyRepo.Create(ctx, &model.Anomaly{
+			UserID:    expense.UserID,
+			ExpenseID: expense.ID,
+			Kind:      string(finding.Kind),
+			Score:     math.Min(math.Round(finding.Score*100)/100, 99999999.99),
+			Reason:    finding.Reason,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *anomalyService) Dismiss(ctx context.Context, clerkID string, id int32) (*model.Anomaly, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	return s.anomalyRepo.Dismiss(ctx, userID, id)
+}
+
+func toObservation(e model.Expense) anomaly.Observation {
+	return anomaly.Observation{
+		Amount:       e.Amount,
+		Description:  e.Description,
+		CategoryID:   e.CategoryID,
+		PurchaseDate: e.PurchaseDate,
+	}
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/internal/archive"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/storage"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// ArchiveService exports everything a user owns and restores it, for data
+// portability.
+type ArchiveService interface {
+	// Export writes the archive of the user's personal ledger, attachments included.
+	Export(ctx context.Context, clerkID string, w io.Writer) error
+	// Restore imports an archive into an account without any data yet.
+	Restore(ctx context.Context, clerkID string, r io.ReaderAt, size int64) error
+}
+
+type archiveService struct {
+	archiveRepo  repositories.ArchiveRepository
+	blobStore    storage.BlobStore
+	userService  UserService
+	auditService AuditService
+}
+
+func NewArchiveService(
+	archiveRepo repositories.ArchiveRepository,
+	blobStore storage.BlobStore,
+	userService UserService,
+	auditService AuditService,
+) ArchiveService {
+	return &archiveService{
+		archiveRepo:  archiveRepo,
+		blobStore:    blobStore,
+		userService:  userService,
+		auditService: auditService,
+	}
+}
+
+func (s *archiveService) Export(ctx context.Context, clerkID string, w io.Writer) error {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	data, err := s.archiveRepo.Load(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Business Logic: Nothing leaves the database without being recorded first
+	err = s.auditService.Record(ctx, AuditEvent{
+		UserID:     &userID,
+		Action:     AuditArchiveExported,
+		EntityType: "archive",
+		After:      data.Counts(),
+	})
+	if err != nil {
+		return err
+	}
+
+	writer := archive.NewWriter(w)
+	if err := writer.WriteData(data, time.Now().UTC()); err != nil {
+		return err
+	}
+	for _, attachment := range data.Attachments {
+		content, err := s.blobStore.Get(ctx, attachment.StorageKey)
+		if err != nil {
+			return fmt.Errorf("failed to read attachment %d: %w", attachment.ID, err)
+		}
+		err = writer.WriteFile(attachment.ID, content)
+		content.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// Restore remaps the ids of the archive to fresh ones, so that an archive can
+// be restored into any database, then inserts every row at once.
+func (s *archiveService) Restore(ctx context.Context, clerkID string, r io.ReaderAt, size int64) error {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	reader, err := archive.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	data, err := reader.Data()
+	if err != nil {
+		return err
+	}
+
+	hasData, err := s.archiveRepo.HasData(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if hasData {
+		return utils.ErrAccountNotEmpty
+	}
+
+	// Business Logic: Files are named after the ids of the archive, which Remap replaces
+	archivedIDs := make([]int32, len(data.Attachments))
+	for i, attachment := range data.Attachments {
+		archivedIDs[i] = attachment.ID
+	}
+
+	ids, err := s.archiveRepo.AllocateIDs(ctx, data.Counts())
+	if err != nil {
+		return err
+	}
+	if err := data.Remap(userID, ids); err != nil {
+		return err
+	}
+
+	var stored []string
+	for i := range data.Attachments {
+		attachment := &data.Attachments[i]
+		key := fmt.Sprintf("attachments/%d/%s%s", attachment.ExpenseID, uuid.New(), path.Ext(attachment.StorageKey))
+		if err := s.putFile(ctx, reader, archivedIDs[i], key, attachment.ContentType, attachment.SizeBytes); err != nil {
+			s.deleteBlobs(ctx, stored)
+			return err
+		}
+		attachment.StorageKey = key
+		stored = append(stored, key)
+	}
+
+	if err := s.archiveRepo.Restore(ctx, userID, data); err != nil {
+		// Do not leave orphan blobs behind
+		s.deleteBlobs(ctx, stored)
+		return err
+	}
+
+	recordAudit(ctx, s.auditService, AuditEvent{
+		UserID:     &userID,
+		Action:     AuditArchiveRestored,
+		EntityType: "archive",
+		After:      data.Counts(),
+	})
+	return nil
+}
+
+func (s *archiveService) putFile(ctx context.Context, reader *archive.Reader, archivedID int32, key, contentType string, size int64) error {
+	content, err := reader.OpenFile(archivedID)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	if err := s.blobStore.Put(ctx, key, contentType, content, size); err != nil {
+		return fmt.Errorf("failed to store attachment: %w", err)
+	}
+	return nil
+}
+
+func (s *archiveService) deleteBlobs(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.blobStore.Delete(ctx, key); err != nil {
+			logrus.WithError(err).WithField("storage_key", key).Error("failed to delete attachment blob")
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/storage"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Path of the signed download route of an attachment
+const attachmentContentPath = "/api/v1/attachments/%d/content"
+
+// Content types accepted for attachments, as sniffed from the file content
+var attachmentContentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// AttachmentDetails is an attachment with a signed link to download it.
+type AttachmentDetails struct {
+	model.Attachment
+	URL          string
+	URLExpiresAt time.Time
+}
+
+type AttachmentService interface {
+	ListByExpense(ctx context.Context, clerkID string, expenseID int32) ([]AttachmentDetails, error)
+	Create(ctx context.Context, clerkID string, expenseID int32, fileName string, content io.Reader) (*AttachmentDetails, error)
+	Delete(ctx context.Context, clerkID string, id int32) error
+	// Open returns an attachment and its content for a signed download link.
+	Open(ctx context.Context, id int32, path string, query url.Values) (*model.Attachment, io.ReadCloser, error)
+}
+
+type attachmentService struct {
+	attachmentRepo   repositories.AttachmentRepository
+	expenseRepo      repositories.ExpenseRepository
+	blobStore        storage.BlobStore
+	signer           *storage.URLSigner
+	userService      UserService
+	workspaceService WorkspaceService
+	// Lifetime of download links
+	urlExpiry time.Duration
+	// Base URL prefixed to download links
+	publicURL string
+	maxBytes  int64
+}
+
+func NewAttachmentService(
+	attachmentRepo repositories.AttachmentRepository,
+	expenseRepo repositories.ExpenseRepository,
+	blobStore storage.BlobStore,
+	signer *storage.URLSigner,
+	userService UserService,
+	workspaceService WorkspaceService,
+	urlExpiry time.Duration,
+	publicURL string,
+	maxBytes int64,
+) AttachmentService {
+	return &attachmentService{
+		attachmentRepo:   attachmentRepo,
+		expenseRepo:      expenseRepo,
+		blobStore:        blobStore,
+		signer:           signer,
+		userService:      userService,
+		workspaceService: workspaceService,
+		urlExpiry:        urlExpiry,
+		publicURL:        publicURL,
+		maxBytes:         maxBytes,
+	}
+}
+
+func (s *attachmentService) ListByExpense(ctx context.Context, clerkID string, expenseID int32) ([]AttachmentDetails, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	if _, err := s.expenseFor(ctx, userID, expenseID, RoleViewer); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.attachmentRepo.ListByExpense(ctx, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	details := make([]AttachmentDetails, len(attachments))
+	for i, a := range attachments {
+		details[i] = s.withURL(a)
+	}
+	return details, nil
+}
+
+// Create stores a receipt for an expense. The content type is sniffed from
+// the content rather than trusted from the client.
+func (s *attachmentService) Create(ctx context.Context, clerkID string, expenseID int32, fileName string, content io.Reader) (*AttachmentDetails, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	expense, err := s.expenseFor(ctx, userID, expenseID, RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Business Logic: Read one byte past the limit to tell a full file from a truncated one
+	data, err := io.ReadAll(io.LimitReader(content, s.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxBytes {
+		return nil, utils.ErrAttachmentTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	extension, ok := attachmentContentTypes[contentType]
+	if !ok {
+		return nil, utils.ErrUnsupportedAttachment
+	}
+
+	key := fmt.Sprintf("attachments/%d/%s%s", expense.ID, uuid.New(), extension)
+	if err := s.blobStore.Put(ctx, key, contentType, bytes.NewReader(data), int64(len(data))); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	attachment, err := s.attachmentRepo.Create(ctx, &model.Attachment{
+		ExpenseID:   expense.ID,
+		UserID:      userID,
+		StorageKey:  key,
+		FileName:    cleanFileName(fileName, extension),
+		ContentType: contentType,
+		SizeBytes:   int64(len(data)),
+	})
+	if err != nil {
+		// Do not leave an orphan blob behind
+		if err := s.blobStore.Delete(ctx, key); err != nil {
+			logrus.WithError(err).WithField("storage_key", key).Error("failed to delete attachment blob")
+		}
+		return nil, err
+	}
+
+	details := s.withURL(*attachment)
+	return &details, nil
+}
+
+func (s *attachmentService) Delete(ctx context.Context, clerkID string, id int32) error {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	attachment, err := s.attachmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if attachment == nil {
+		return utils.ErrNotFound
+	}
+	if _, err := s.expenseFor(ctx, userID, attachment.ExpenseID, RoleEditor); err != nil {
+		return err
+	}
+	if !utils.IfMatch(ctx, attachment.UpdatedAt) {
+		return utils.ErrPreconditionFailed
+	}
+
+	if err := s.attachmentRepo.Delete(ctx, id, attachment.UpdatedAt); err != nil {
+		return err
+	}
+	if err := s.blobStore.Delete(ctx, attachment.StorageKey); err != nil {
+		logrus.WithError(err).WithField("storage_key", attachment.StorageKey).Error("failed to delete attachment blob")
+	}
+	return nil
+}
+
+func (s *attachmentService) Open(ctx context.Context, id int32, path string, query url.Values) (*model.Attachment, io.ReadCloser, error) {
+	if err := s.signer.Verify(path, query, time.Now()); err != nil {
+		return nil, nil, utils.ErrInvalidDownloadLink
+	}
+
+	attachment, err := s.attachmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if attachment == nil {
+		return nil, nil, utils.ErrNotFound
+	}
+	// Business Logic: Attachments of a trashed expense go with it, even through
+	// links signed before it was trashed
+	expense, err := s.expenseRepo.GetByID(ctx, attachment.ExpenseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if expense == nil {
+		return nil, nil, utils.ErrNotFound
+	}
+
+	content, err := s.blobStore.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return nil, nil, utils.ErrNotFound
+		}
+		return nil, nil, err
+	}
+	return attachment, content, nil
+}
+
+// expenseFor returns an expense the user holds at least minRole on.
+func (s *attachmentService) expenseFor(ctx context.Context, userID uuid.UUID, expenseID int32, minRole string) (*model.Expense, error) {
+	expense, err := s.expenseRepo.GetByID(ctx, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if expense == nil {
+		return nil, utils.ErrNotFound
+	}
+	if err := checkExpenseAccess(ctx, s.workspaceService, userID, expense, minRole); err != nil {
+		return nil, err
+	}
+	return expense, nil
+}
+
+func (s *attachmentService) withURL(attachment model.Attachment) AttachmentDetails {
+	expiresAt := time.Now().Add(s.urlExpiry).UTC().Truncate(time.Second)
+	contentPath := fmt.Sprintf(attachmentContentPath, attachment.ID)
+	query := s.signer.Sign(contentPath, expiresAt)
+	return AttachmentDetails{
+		Attachment:   attachment,
+		URL:          s.publicURL + contentPath + "?" + query.Encode(),
+		URLExpiresAt: expiresAt,
+	}
+}
+
+// cleanFileName keeps the base name of an uploaded file, falling back to a
+// generic name with the sniffed extension.
+func cleanFileName(fileName, extension string) string {
+	name := strings.TrimSpace(path.Base(strings.ReplaceAll(fileName, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "receipt" + extension
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/audit"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/sirupsen/logrus"
+)
+
+// AuditFilter narrows down audit trail listings.
+type AuditFilter = repositories.AuditFilter
+
+// Audited actions
+const (
+	AuditUserCreated              = "user.created"
+	AuditUserUpdated              = "user.updated"
+	AuditAccountDeletionRequested = "account.deletion_requested"
+	AuditAccountDeletionCancelled = "account.deletion_cancelled"
+	AuditAccountPurged            = "account.purged"
+	AuditTrashPurged              = "trash.purged"
+	AuditExpensesImported         = "expenses.imported"
+	AuditExpensesExported         = "expenses.exported"
+	AuditExpensesDeleted          = "expenses.deleted"
+	AuditArchiveExported          = "archive.exported"
+	AuditArchiveRestored          = "archive.restored"
+)
+
+// AuditEvent is a sensitive operation to record. The actor and the origin of
+// the request are read from the context.
+type AuditEvent struct {
+	// User the operation is about, nil for operations across users
+	UserID     *uuid.UUID
+	Action     string
+	EntityType string
+	EntityID   string
+	// State of the entity before and after the operation, encoded as JSON
+	Before any
+	After  any
+}
+
+// AuditLogEntry is an audit entry as served by the API, with its snapshots decoded.
+type AuditLogEntry struct {
+	model.AuditLog
+	Before json.RawMessage
+	After  json.RawMessage
+}
+
+// AuditService keeps the append-only trail of sensitive operations.
+type AuditService interface {
+	Record(ctx context.Context, event AuditEvent) error
+	// ListByUser lists the trail of the user's own account.
+	ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]AuditLogEntry, error)
+	// List lists the trail across users, for administrators.
+	List(ctx context.Context, filter AuditFilter, limit, offset int) ([]AuditLogEntry, error)
+}
+
+type auditService struct {
+	auditRepo repositories.AuditRepository
+	userRepo  repositories.UserRepository
+}
+
+// NewAuditService takes the user repository rather than the user service,
+// which records its own operations.
+func NewAuditService(auditRepo repositories.AuditRepository, userRepo repositories.UserRepository) AuditService {
+	return &auditService{
+		auditRepo: auditRepo,
+		userRepo:  userRepo,
+	}
+}
+
+func (s *auditService) Record(ctx context.Context, event AuditEvent) error {
+	before, err := audit.Snapshot(event.Before)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+	after, err := audit.Snapshot(event.After)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+
+	actor := audit.ActorFrom(ctx)
+	origin := audit.OriginFrom(ctx)
+	entry := &model.AuditLog{
+		ActorType:  actor.Type,
+		ActorID:    nilIfEmpty(actor.ID),
+		UserID:     event.UserID,
+		Action:     event.Action,
+		EntityType: event.EntityType,
+		EntityID:   nilIfEmpty(event.EntityID),
+		Before:     before,
+		After:      after,
+		RequestID:  nilIfEmpty(origin.RequestID),
+		IP:         nilIfEmpty(origin.IP),
+	}
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record audit entry %s: %w", event.Action, err)
+	}
+	return nil
+}
+
+func (s *auditService) ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]AuditLogEntry, error) {
+	userID, err := s.userRepo.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	return s.List(ctx, AuditFilter{UserID: &userID}, limit, offset)
+}
+
+func (s *auditService) List(ctx context.Context, filter AuditFilter, limit, offset int) ([]AuditLogEntry, error) {
+	rows, err := s.auditRepo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]AuditLogEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = toAuditLogEntry(row)
+	}
+	return entries, nil
+}
+
+func toAuditLogEntry(row model.AuditLog) AuditLogEntry {
+	entry := AuditLogEntry{AuditLog: row}
+	if row.Before != nil {
+		entry.Before = json.RawMessage(*row.Before)
+	}
+	if row.After != nil {
+		entry.After = json.RawMessage(*row.After)
+	}
+	return entry
+}
+
+// recordAudit records an operation that already took place. Failures are
+// logged, as the operation cannot be undone anymore.
+func recordAudit(ctx context.Context, auditService AuditService, event AuditEvent) {
+	if err := auditService.Record(ctx, event); err != nil {
+		logrus.WithError(err).WithField("action", event.Action).Error("failed to record audit entry")
+	}
+}
+
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
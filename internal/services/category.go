@@ -4,33 +4,63 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/google/uuid"
 	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
 	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
 )
 
 type CategoryService interface {
-	ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]model.Category, error)
+	ListByUser(ctx context.Context, clerkID string, workspaceID *int32, kind string, limit, offset int) ([]model.Category, error)
+	Get(ctx context.Context, clerkID string, id int32) (*model.Category, error)
 	Create(ctx context.Context, clerkID string, category *model.Category) (*model.Category, error)
+	// Delete moves a category to the trash.
+	Delete(ctx context.Context, clerkID string, id int32) error
 }
 
 type categoryService struct {
-	categoryRepo repositories.CategoryRepository
-	userService  UserService
+	categoryRepo     repositories.CategoryRepository
+	userService      UserService
+	workspaceService WorkspaceService
 }
 
-func NewCategoryService(categoryRepo repositories.CategoryRepository, userService UserService) CategoryService {
+func NewCategoryService(categoryRepo repositories.CategoryRepository, userService UserService, workspaceService WorkspaceService) CategoryService {
 	return &categoryService{
-		categoryRepo: categoryRepo,
-		userService:  userService,
+		categoryRepo:     categoryRepo,
+		userService:      userService,
+		workspaceService: workspaceService,
 	}
 }
 
-func (s *categoryService) ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]model.Category, error) {
+func (s *categoryService) ListByUser(ctx context.Context, clerkID string, workspaceID *int32, kind string, limit, offset int) ([]model.Category, error) {
 	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
 	}
-	return s.categoryRepo.ListByUser(ctx, userID, limit, offset)
+	scope, err := s.workspaceService.Scope(ctx, userID, workspaceID, RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	return s.categoryRepo.ListByScope(ctx, scope, kind, limit, offset)
+}
+
+func (s *categoryService) Get(ctx context.Context, clerkID string, id int32) (*model.Category, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	category, err := s.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, utils.ErrNotFound
+	}
+	if err := checkCategoryAccess(ctx, s.workspaceService, userID, category, RoleViewer); err != nil {
+		return nil, err
+	}
+	return category, nil
 }
 
 func (s *categoryService) Create(ctx context.Context, clerkID string, category *model.Category) (*model.Category, error) {
@@ -39,7 +69,60 @@ func (s *categoryService) Create(ctx context.Context, clerkID string, category *
 		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
 	}
 	category.UserID = userID
+	if _, err := s.workspaceService.Scope(ctx, userID, category.WorkspaceID, RoleEditor); err != nil {
+		return nil, err
+	}
+	if category.Kind == "" {
+		category.Kind = repositories.KindExpense
+	}
+
+	// Business Logic: Clients may pick the public ID, as long as it is free
+	if category.PublicID != uuid.Nil {
+		taken, err := s.categoryRepo.IDsByPublicID(ctx, []uuid.UUID{category.PublicID})
+		if err != nil {
+			return nil, err
+		}
+		if len(taken) > 0 {
+			return nil, utils.ErrPublicIDTaken
+		}
+	}
 
 	// Add business logic here if needed (e.g., check for duplicate category names)
 	return s.categoryRepo.Create(ctx, category)
 }
+
+func (s *categoryService) Delete(ctx context.Context, clerkID string, id int32) error {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	category, err := s.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return utils.ErrNotFound
+	}
+	if err := checkCategoryAccess(ctx, s.workspaceService, userID, category, RoleEditor); err != nil {
+		return err
+	}
+	if !utils.IfMatch(ctx, category.UpdatedAt) {
+		return utils.ErrPreconditionFailed
+	}
+
+	return s.categoryRepo.Delete(ctx, id, category.UpdatedAt)
+}
+
+// checkCategoryAccess verifies that the user holds at least minRole on the
+// ledger of the category.
+func checkCategoryAccess(ctx context.Context, workspaceService WorkspaceService, userID uuid.UUID, category *model.Category, minRole string) error {
+	scope, err := workspaceService.Scope(ctx, userID, category.WorkspaceID, minRole)
+	if err != nil {
+		return err
+	}
+	if !scope.Contains(category.UserID, category.WorkspaceID) {
+		return utils.ErrForbidden
+	}
+	return nil
+}
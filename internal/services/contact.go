@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type ContactService interface {
+	ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]model.Contact, error)
+	Get(ctx context.Context, clerkID string, id int32) (*model.Contact, error)
+	Create(ctx context.Context, clerkID string, contact *model.Contact) (*model.Contact, error)
+	Update(ctx context.Context, clerkID string, contact *model.Contact) (*model.Contact, error)
+}
+
+type contactService struct {
+	contactRepo repositories.ContactRepository
+	userService UserService
+}
+
+func NewContactService(contactRepo repositories.ContactRepository, userService UserService) ContactService {
+	return &contactService{
+		contactRepo: contactRepo,
+		userService: userService,
+	}
+}
+
+func (s *contactService) ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]model.Contact, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	return s.contactRepo.ListByUser(ctx, userID, limit, offset)
+}
+
+func (s *contactService) Get(ctx context.Context, clerkID string, id int32) (*model.Contact, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	contact, err := s.contactRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if contact == nil {
+		return nil, utils.ErrNotFound
+	}
+	if contact.UserID != userID {
+		return nil, utils.ErrForbidden
+	}
+	return contact, nil
+}
+
+func (s *contactService) Create(ctx context.Context, clerkID string, contact *model.Contact) (*model.Contact, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	contact.UserID = userID
+	return s.contactRepo.Create(ctx, contact)
+}
+
+func (s *contactService) Update(ctx context.Context, clerkID string, contact *model.Contact) (*model.Contact, error) {
+	existing, err := s.Get(ctx, clerkID, contact.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.IfMatch(ctx, existing.UpdatedAt) {
+		return nil, utils.ErrPreconditionFailed
+	}
+	contact.UserID = existing.UserID
+	// The version checked is the one stored, or the update fails
+	contact.UpdatedAt = existing.UpdatedAt
+	return s.contactRepo.Update(ctx, contact)
+}
+
+// checkContactOwner verifies that the contact exists and belongs to the user.
+func checkContactOwner(ctx context.Context, contactRepo repositories.ContactRepository, userID uuid.UUID, contactID int32) error {
+	contact, err := contactRepo.GetByID(ctx, contactID)
+	if err != nil {
+		return err
+	}
+	if contact == nil {
+		return utils.ErrNotFound
+	}
+	if contact.UserID != userID {
+		return utils.ErrForbidden
+	}
+	return nil
+}
@@ -2,64 +2,1268 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/export"
 	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/revision"
+	"github.com/igorschechtel/clearflow-backend/internal/settle"
 	"github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/igorschechtel/clearflow-backend/internal/webhook"
+	"github.com/sirupsen/logrus"
 )
 
+// Upper bound for the asynchronous anomaly detection run after each expense is created.
+const anomalyDetectionTimeout = 30 * time.Second
+
+// ExpenseFilter narrows down expense listings.
+type ExpenseFilter = repositories.ExpenseFilter
+
+// Transaction kinds
+const (
+	KindExpense  = repositories.KindExpense
+	KindIncome   = repositories.KindIncome
+	KindRefund   = repositories.KindRefund
+	KindTransfer = repositories.KindTransfer
+)
+
+// ExpenseDetails is an expense as served by the API.
+type ExpenseDetails struct {
+	model.Expense
+	// Sum of the refunds recorded against the expense
+	RefundedTotal float64
+	Tags          []string
+	Splits        []model.ExpenseSplit
+	// Shares of the people the expense is split with
+	Shares []model.ExpenseShare
+}
+
+// ExpenseRevision is a revision as served by the API, with its changes decoded.
+type ExpenseRevision struct {
+	model.ExpenseRevision
+	Changes json.RawMessage
+}
+
+// ExpenseLines are the tags and split lines of a transaction. On update, nil
+// fields leave the stored ones untouched and empty ones clear them.
+type ExpenseLines struct {
+	Tags   []string
+	Splits []model.ExpenseSplit
+}
+
+// Bulk operations
+const (
+	BulkCreate = "create"
+	BulkUpdate = "update"
+	BulkDelete = "delete"
+)
+
+// Bulk modes. All-or-nothing writes nothing when an item fails, best-effort
+// writes the items that pass.
+const (
+	BulkAllOrNothing = "all_or_nothing"
+	BulkBestEffort   = "best_effort"
+)
+
+// Maximum number of transactions a bulk operation creates or acts on
+const BulkMaxItems = 1000
+
+// Bulk item statuses
+const (
+	BulkStatusCreated = "created"
+	BulkStatusUpdated = "updated"
+	BulkStatusDeleted = "deleted"
+	BulkStatusFailed  = "failed"
+	// Item left unwritten as another one failed in all-or-nothing mode
+	BulkStatusSkipped = "skipped"
+)
+
+// BulkItem is a transaction to create in bulk.
+type BulkItem struct {
+	Expense model.Expense
+	Lines   ExpenseLines
+}
+
+// BulkOperation describes a bulk operation on transactions of a single kind.
+// Creates take Items. Updates and deletes act on IDs or, when IDs is nil, on
+// the transactions matching Filter.
+type BulkOperation struct {
+	Operation string
+	Mode      string
+	// Ledger the transactions are created in, the personal one when nil
+	WorkspaceID *int32
+	Items       []BulkItem
+	IDs         []int32
+	Filter      *ExpenseFilter
+	// Category set on every transaction by an update
+	CategoryID *int32
+	// Tag names added to and removed from every transaction by an update
+	AddTags    []string
+	RemoveTags []string
+}
+
+// BulkResult reports the outcome of a single item. Items are indexed in the
+// order of the request, or of ids for updates and deletes by filter.
+type BulkResult struct {
+	Index   int            `json:"index"`
+	ID      int32          `json:"id,omitempty"`
+	Status  string         `json:"status"`
+	Expense *model.Expense `json:"expense,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// Ways of splitting a shared expense
+const (
+	ShareEqual      = "equal"
+	SharePercentage = "percentage"
+	ShareExact      = "exact"
+)
+
+// ShareParticipant is a person sharing an expense. A nil contact is the user.
+// Value is a percentage or an amount depending on the split mode, and unused
+// for equal splits.
+type ShareParticipant struct {
+	ContactID *int32
+	Value     float64
+}
+
+// ShareInput describes how an expense is shared and who paid it, the user
+// when PaidByContactID is nil.
+type ShareInput struct {
+	Mode            string
+	PaidByContactID *int32
+	Participants    []ShareParticipant
+}
+
 type ExpenseService interface {
-	ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]model.Expense, error)
-	Create(ctx context.Context, clerkID string, expense *model.Expense) (*model.Expense, error)
+	ListByUser(ctx context.Context, clerkID string, filter ExpenseFilter, limit, offset int) ([]ExpenseDetails, error)
+	// Export writes every transaction matching filter to w as a file of the
+	// given format. Errors returned before anything was written leave w untouched.
+	Export(ctx context.Context, clerkID string, filter ExpenseFilter, format string, w io.Writer) error
+	// Get returns a transaction of the given kind.
+	Get(ctx context.Context, clerkID string, kind string, id int32) (*ExpenseDetails, error)
+	Create(ctx context.Context, clerkID string, expense *model.Expense, lines ExpenseLines) (*ExpenseDetails, error)
+	Update(ctx context.Context, clerkID string, expense *model.Expense, lines ExpenseLines) (*ExpenseDetails, error)
+	CreateRefund(ctx context.Context, clerkID string, expenseID int32, refund *model.Expense) (*model.Expense, error)
+	Share(ctx context.Context, clerkID string, expenseID int32, input ShareInput) (*ExpenseDetails, error)
+	Delete(ctx context.Context, clerkID string, kind string, id int32) error
+	// History lists the revisions of a transaction of the given kind, latest first.
+	History(ctx context.Context, clerkID string, kind string, id int32) ([]ExpenseRevision, error)
+	// Revert undoes the changes of a revision, as a new revision. Fields changed
+	// by later revisions only are kept.
+	Revert(ctx context.Context, clerkID string, kind string, id int32, number int32) (*ExpenseDetails, error)
+	// Bulk creates, updates or deletes transactions of the given kind in a
+	// single database transaction, reporting the outcome of every item.
+	Bulk(ctx context.Context, clerkID string, kind string, op BulkOperation) ([]BulkResult, error)
 }
 
 type expenseService struct {
-	expenseRepo  repositories.ExpenseRepository
-	categoryRepo repositories.CategoryRepository
-	userService  UserService
+	expenseRepo      repositories.ExpenseRepository
+	categoryRepo     repositories.CategoryRepository
+	accountRepo      repositories.AccountRepository
+	tagRepo          repositories.TagRepository
+	contactRepo      repositories.ContactRepository
+	merchantRepo     repositories.MerchantRepository
+	userService      UserService
+	workspaceService WorkspaceService
+	anomalyService   AnomalyService
+	auditService     AuditService
+	webhookService   WebhookService
 }
 
 func NewExpenseService(
 	expenseRepo repositories.ExpenseRepository,
 	categoryRepo repositories.CategoryRepository,
+	accountRepo repositories.AccountRepository,
+	tagRepo repositories.TagRepository,
+	contactRepo repositories.ContactRepository,
+	merchantRepo repositories.MerchantRepository,
 	userService UserService,
+	workspaceService WorkspaceService,
+	anomalyService AnomalyService,
+	auditService AuditService,
+	webhookService WebhookService,
 ) ExpenseService {
 	return &expenseService{
-		expenseRepo:  expenseRepo,
-		categoryRepo: categoryRepo,
-		userService:  userService,
+		expenseRepo:      expenseRepo,
+		categoryRepo:     categoryRepo,
+		accountRepo:      accountRepo,
+		tagRepo:          tagRepo,
+		contactRepo:      contactRepo,
+		merchantRepo:     merchantRepo,
+		userService:      userService,
+		workspaceService: workspaceService,
+		anomalyService:   anomalyService,
+		auditService:     auditService,
+		webhookService:   webhookService,
 	}
 }
 
-func (s *expenseService) ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]model.Expense, error) {
+func (s *expenseService) ListByUser(ctx context.Context, clerkID string, filter ExpenseFilter, limit, offset int) ([]ExpenseDetails, error) {
 	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
 	}
-	return s.expenseRepo.ListByUser(ctx, userID, limit, offset)
+
+	if _, err := s.workspaceService.Scope(ctx, userID, filter.WorkspaceID, RoleViewer); err != nil {
+		return nil, err
+	}
+
+	if filter.Tags != nil {
+		filter.Tags = normalizeTagNames(filter.Tags)
+	}
+	expenses, err := s.expenseRepo.ListByUser(ctx, userID, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return s.withDetails(ctx, expenses)
+}
+
+func (s *expenseService) Export(ctx context.Context, clerkID string, filter ExpenseFilter, format string, w io.Writer) error {
+	user, err := s.userService.GetByClerkID(ctx, clerkID)
+	if err != nil {
+		return fmt.Errorf("failed to get user for clerk %s: %w", clerkID, err)
+	}
+
+	if _, err := s.workspaceService.Scope(ctx, user.ID, filter.WorkspaceID, RoleViewer); err != nil {
+		return err
+	}
+
+	if filter.Tags != nil {
+		filter.Tags = normalizeTagNames(filter.Tags)
+	}
+	writer, err := export.NewWriter(format, w, user.Locale)
+	if err != nil {
+		return err
+	}
+
+	// Business Logic: Nothing leaves the database without being recorded first
+	err = s.auditService.Record(ctx, AuditEvent{
+		UserID:     &user.ID,
+		Action:     AuditExpensesExported,
+		EntityType: "expense",
+		After:      map[string]any{"format": format, "filter": filter},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.expenseRepo.Export(ctx, user.ID, filter, func(line repositories.ExportLine) error {
+		return writer.Write(export.Row{
+			ID:           line.PublicID,
+			PurchaseDate: line.PurchaseDate,
+			BillDate:     line.BillDate,
+			Kind:         line.Kind,
+			Description:  line.Description,
+			Merchant:     valueOrEmpty(line.MerchantName),
+			Category:     valueOrEmpty(line.CategoryName),
+			Account:      valueOrEmpty(line.AccountName),
+			Tags:         line.Tags(),
+			Amount:       line.Amount,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Close()
 }
 
-func (s *expenseService) Create(ctx context.Context, clerkID string, expense *model.Expense) (*model.Expense, error) {
+func (s *expenseService) Create(ctx context.Context, clerkID string, expense *model.Expense, lines ExpenseLines) (*ExpenseDetails, error) {
 	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
 	}
 	expense.UserID = userID
+	if expense.Kind == "" {
+		expense.Kind = KindExpense
+	}
+	if _, err := s.workspaceService.Scope(ctx, userID, expense.WorkspaceID, RoleEditor); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkPublicID(ctx, expense.PublicID); err != nil {
+		return nil, err
+	}
+	if err := s.checkReferences(ctx, expense); err != nil {
+		return nil, err
+	}
+	if err := s.checkSplits(ctx, expense, lines.Splits); err != nil {
+		return nil, err
+	}
+	if err := assignMerchant(ctx, s.merchantRepo, expense); err != nil {
+		return nil, err
+	}
+	tagIDs, err := s.ensureTags(ctx, userID, lines.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.expenseRepo.Create(ctx, expense, tagIDs, lines.Splits)
+	if err != nil {
+		return nil, err
+	}
+
+	s.detectAnomalies(ctx, *created)
+	publishWebhooks(ctx, s.webhookService, userID, webhook.ExpenseCreated, *created)
+
+	details, err := s.withDetails(ctx, []model.Expense{*created})
+	if err != nil {
+		return nil, err
+	}
+	return &details[0], nil
+}
+
+func (s *expenseService) Get(ctx context.Context, clerkID string, kind string, id int32) (*ExpenseDetails, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	expense, err := s.expenseRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if expense == nil || expense.Kind != kind {
+		return nil, utils.ErrNotFound
+	}
+	if err := checkExpenseAccess(ctx, s.workspaceService, userID, expense, RoleViewer); err != nil {
+		return nil, err
+	}
+
+	details, err := s.withDetails(ctx, []model.Expense{*expense})
+	if err != nil {
+		return nil, err
+	}
+	return &details[0], nil
+}
+
+// Update edits an expense or income of the given kind. Its tags and split
+// lines are replaced unless they are nil.
+func (s *expenseService) Update(ctx context.Context, clerkID string, expense *model.Expense, lines ExpenseLines) (*ExpenseDetails, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	existing, err := s.expenseRepo.GetByID(ctx, expense.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil || existing.Kind != expense.Kind {
+		return nil, utils.ErrNotFound
+	}
+	if err := checkExpenseAccess(ctx, s.workspaceService, userID, existing, RoleEditor); err != nil {
+		return nil, err
+	}
+	if !utils.IfMatch(ctx, existing.UpdatedAt) {
+		return nil, utils.ErrPreconditionFailed
+	}
+	// Business Logic: Edits by other members keep the author and the workspace
+	expense.UserID = existing.UserID
+	expense.WorkspaceID = existing.WorkspaceID
+	// The version checked is the one stored, or the update fails
+	expense.UpdatedAt = existing.UpdatedAt
+
+	if err := s.checkReferences(ctx, expense); err != nil {
+		return nil, err
+	}
+
+	// Kept split lines must still add up to the new amount
+	splits := lines.Splits
+	if splits == nil {
+		stored, err := s.expenseRepo.SplitsByExpense(ctx, []int32{expense.ID})
+		if err != nil {
+			return nil, err
+		}
+		splits = stored[expense.ID]
+	}
+	if err := s.checkSplits(ctx, expense, splits); err != nil {
+		return nil, err
+	}
+	if err := s.checkShares(ctx, expense); err != nil {
+		return nil, err
+	}
+	if err := assignMerchant(ctx, s.merchantRepo, expense); err != nil {
+		return nil, err
+	}
+
+	// Business Logic: Tags are looked up and created for the author, along
+	// with the update
+	var tagNames []string
+	if lines.Tags != nil {
+		tagNames = normalizeTagNames(lines.Tags)
+	}
+
+	updated, err := s.expenseRepo.Update(ctx, expense, tagNames, lines.Splits, userID)
+	if err != nil {
+		return nil, err
+	}
+	publishWebhooks(ctx, s.webhookService, userID, webhook.ExpenseUpdated, *updated)
+
+	details, err := s.withDetails(ctx, []model.Expense{*updated})
+	if err != nil {
+		return nil, err
+	}
+	return &details[0], nil
+}
+
+// checkPublicID fails when a client picked the public ID of another
+// transaction.
+func (s *expenseService) checkPublicID(ctx context.Context, publicID uuid.UUID) error {
+	if publicID == uuid.Nil {
+		return nil
+	}
+	taken, err := s.expenseRepo.IDsByPublicID(ctx, []uuid.UUID{publicID})
+	if err != nil {
+		return err
+	}
+	if len(taken) > 0 {
+		return utils.ErrPublicIDTaken
+	}
+	return nil
+}
 
+// checkReferences verifies that the category and accounts of a transaction
+// belong to its user and suit its kind.
+func (s *expenseService) checkReferences(ctx context.Context, expense *model.Expense) error {
 	// Business Logic: If a category is provided, verify it exists and belongs to the user
 	if expense.CategoryID != nil {
-		category, err := s.categoryRepo.GetByID(ctx, *expense.CategoryID)
+		if err := s.checkCategory(ctx, expense, *expense.CategoryID); err != nil {
+			return err
+		}
+	}
+
+	// Business Logic: Accounts must belong to the user and transfers need two distinct ones
+	if expense.AccountID != nil {
+		if err := checkAccountOwner(ctx, s.accountRepo, expense.UserID, *expense.AccountID); err != nil {
+			return err
+		}
+	}
+	if expense.Kind == KindTransfer {
+		if expense.AccountID == nil || expense.TransferAccountID == nil || *expense.AccountID == *expense.TransferAccountID {
+			return utils.ErrInvalidTransfer
+		}
+		if err := checkAccountOwner(ctx, s.accountRepo, expense.UserID, *expense.TransferAccountID); err != nil {
+			return err
+		}
+	} else {
+		expense.TransferAccountID = nil
+	}
+
+	return nil
+}
+
+// checkCategory verifies that a category exists, belongs to the ledger of the
+// transaction and matches its kind.
+func (s *expenseService) checkCategory(ctx context.Context, expense *model.Expense, categoryID int32) error {
+	category, err := s.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return utils.ErrNotFound
+	}
+	scope := repositories.Scope{UserID: expense.UserID, WorkspaceID: expense.WorkspaceID}
+	if !scope.Contains(category.UserID, category.WorkspaceID) {
+		return utils.ErrForbidden
+	}
+	if category.Kind != expense.Kind {
+		return utils.ErrCategoryKindMismatch
+	}
+	return nil
+}
+
+// checkSplits verifies that split lines add up to the amount of the
+// transaction and use valid categories. Split transactions take their
+// categories from the lines only.
+func (s *expenseService) checkSplits(ctx context.Context, expense *model.Expense, splits []model.ExpenseSplit) error {
+	if len(splits) == 0 {
+		return nil
+	}
+	if expense.Kind != KindExpense && expense.Kind != KindIncome {
+		return utils.ErrSplitMismatch
+	}
+
+	var total int64
+	for _, split := range splits {
+		// Business Logic: Every split line is held to the same category rules as the transaction
+		if err := s.checkCategory(ctx, expense, split.CategoryID); err != nil {
+			return err
+		}
+		total += int64(math.Round(split.Amount * 100))
+	}
+	if total != int64(math.Round(expense.Amount*100)) {
+		return utils.ErrSplitMismatch
+	}
+
+	expense.CategoryID = nil
+	return nil
+}
+
+// checkShares verifies that the shares of a shared expense still add up to
+// its amount.
+func (s *expenseService) checkShares(ctx context.Context, expense *model.Expense) error {
+	shares, err := s.expenseRepo.SharesByExpense(ctx, []int32{expense.ID})
+	if err != nil {
+		return err
+	}
+	if len(shares[expense.ID]) == 0 {
+		return nil
+	}
+
+	var total int64
+	for _, share := range shares[expense.ID] {
+		total += toCents(share.Amount)
+	}
+	if total != toCents(expense.Amount) {
+		return utils.ErrShareMismatch
+	}
+	return nil
+}
+
+// ensureTags returns the ids of the named tags, creating the missing ones. A
+// nil slice is passed through so that updates can leave tags untouched.
+func (s *expenseService) ensureTags(ctx context.Context, userID uuid.UUID, names []string) ([]int32, error) {
+	if names == nil {
+		return nil, nil
+	}
+
+	tags, err := s.tagRepo.EnsureByNames(ctx, userID, normalizeTagNames(names))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int32, len(tags))
+	for i, tag := range tags {
+		ids[i] = tag.ID
+	}
+	return ids, nil
+}
+
+// Delete moves a transaction of the given kind with its refunds to the
+// trash. Their attachments are kept until the trash is purged.
+func (s *expenseService) Delete(ctx context.Context, clerkID string, kind string, id int32) error {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	existing, err := s.expenseRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil || existing.Kind != kind {
+		return utils.ErrNotFound
+	}
+	if err := checkExpenseAccess(ctx, s.workspaceService, userID, existing, RoleEditor); err != nil {
+		return err
+	}
+	if !utils.IfMatch(ctx, existing.UpdatedAt) {
+		return utils.ErrPreconditionFailed
+	}
+
+	if err := s.expenseRepo.Delete(ctx, id, existing.UpdatedAt); err != nil {
+		return err
+	}
+	publishWebhooks(ctx, s.webhookService, userID, webhook.ExpenseDeleted, *existing)
+	return nil
+}
+
+func (s *expenseService) History(ctx context.Context, clerkID string, kind string, id int32) ([]ExpenseRevision, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	existing, err := s.expenseRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil || existing.Kind != kind {
+		return nil, utils.ErrNotFound
+	}
+	if err := checkExpenseAccess(ctx, s.workspaceService, userID, existing, RoleViewer); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.expenseRepo.ListRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]ExpenseRevision, len(rows))
+	for i, row := range rows {
+		revisions[i] = ExpenseRevision{
+			ExpenseRevision: row,
+			Changes:         json.RawMessage(row.Changes),
+		}
+	}
+	return revisions, nil
+}
+
+// Revert goes through Update, so the reverted state is validated like any
+// edit: a category deleted since then, for instance, cannot come back.
+func (s *expenseService) Revert(ctx context.Context, clerkID string, kind string, id int32, number int32) (*ExpenseDetails, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	existing, err := s.expenseRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil || existing.Kind != kind {
+		return nil, utils.ErrNotFound
+	}
+	if err := checkExpenseAccess(ctx, s.workspaceService, userID, existing, RoleEditor); err != nil {
+		return nil, err
+	}
+	// Business Logic: Reverting overwrites the transaction, so it is held to the version the client saw
+	if !utils.IfMatch(ctx, existing.UpdatedAt) {
+		return nil, utils.ErrPreconditionFailed
+	}
+
+	stored, err := s.expenseRepo.GetRevision(ctx, id, number)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, utils.ErrNotFound
+	}
+	var changes revision.Changes
+	if err := json.Unmarshal([]byte(stored.Changes), &changes); err != nil {
+		return nil, fmt.Errorf("failed to decode revision %d of expense %d: %w", number, id, err)
+	}
+
+	details, err := s.withDetails(ctx, []model.Expense{*existing})
+	if err != nil {
+		return nil, err
+	}
+	current := revision.Snapshot{
+		Amount:       existing.Amount,
+		Description:  existing.Description,
+		PurchaseDate: existing.PurchaseDate,
+		BillDate:     existing.BillDate,
+		CategoryID:   existing.CategoryID,
+		AccountID:    existing.AccountID,
+		Tags:         details[0].Tags,
+	}
+	for _, split := range details[0].Splits {
+		current.Splits = append(current.Splits, revision.Split{CategoryID: split.CategoryID, Amount: split.Amount, Note: split.Note})
+	}
+	if err := changes.Revert(&current); err != nil {
+		return nil, fmt.Errorf("failed to revert revision %d of expense %d: %w", number, id, err)
+	}
+
+	expense := *existing
+	expense.Amount = current.Amount
+	expense.Description = current.Description
+	expense.PurchaseDate = current.PurchaseDate
+	expense.BillDate = current.BillDate
+	expense.CategoryID = current.CategoryID
+	expense.AccountID = current.AccountID
+
+	// Business Logic: Tags and split lines are only replaced when the revision changed them
+	var lines ExpenseLines
+	if _, ok := changes["tags"]; ok {
+		lines.Tags = append([]string{}, current.Tags...)
+	}
+	if _, ok := changes["splits"]; ok {
+		lines.Splits = []model.ExpenseSplit{}
+		for _, split := range current.Splits {
+			lines.Splits = append(lines.Splits, model.ExpenseSplit{CategoryID: split.CategoryID, Amount: split.Amount, Note: split.Note})
+		}
+	}
+	return s.Update(ctx, clerkID, &expense, lines)
+}
+
+// Bulk checks every item before writing any, so that failed all-or-nothing
+// operations leave no trace. Tags and merchants are only created for items
+// that get written.
+func (s *expenseService) Bulk(ctx context.Context, clerkID string, kind string, op BulkOperation) ([]BulkResult, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	switch op.Operation {
+	case BulkCreate:
+		return s.bulkCreate(ctx, userID, kind, op)
+	case BulkUpdate:
+		return s.bulkUpdate(ctx, userID, kind, op)
+	case BulkDelete:
+		return s.bulkDelete(ctx, userID, kind, op)
+	}
+	return nil, fmt.Errorf("unknown bulk operation %q", op.Operation)
+}
+
+func (s *expenseService) bulkCreate(ctx context.Context, userID uuid.UUID, kind string, op BulkOperation) ([]BulkResult, error) {
+	if len(op.Items) > BulkMaxItems {
+		return nil, utils.ErrBulkTooLarge
+	}
+	if _, err := s.workspaceService.Scope(ctx, userID, op.WorkspaceID, RoleEditor); err != nil {
+		return nil, err
+	}
+
+	// Business Logic: Clients may pick the public IDs, as long as they are free
+	var picked []uuid.UUID
+	for _, item := range op.Items {
+		if item.Expense.PublicID != uuid.Nil {
+			picked = append(picked, item.Expense.PublicID)
+		}
+	}
+	taken, err := s.expenseRepo.IDsByPublicID(ctx, picked)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkResult, len(op.Items))
+	seen := map[uuid.UUID]bool{}
+	for i := range op.Items {
+		item := &op.Items[i]
+		item.Expense.UserID = userID
+		item.Expense.WorkspaceID = op.WorkspaceID
+		item.Expense.Kind = kind
+		results[i] = BulkResult{Index: i}
+
+		var err error
+		if publicID := item.Expense.PublicID; publicID != uuid.Nil {
+			if _, ok := taken[publicID]; ok || seen[publicID] {
+				err = utils.ErrPublicIDTaken
+			}
+			seen[publicID] = true
+		}
+		if err == nil {
+			err = s.checkReferences(ctx, &item.Expense)
+		}
+		if err == nil {
+			err = s.checkSplits(ctx, &item.Expense, item.Lines.Splits)
+		}
+		if err != nil {
+			results[i].Status = BulkStatusFailed
+			results[i].Error = err.Error()
+			continue
+		}
+	}
+	if !proceedBulk(results, op.Mode) {
+		return results, nil
+	}
+
+	// Tags and merchants are created along with the transactions
+	var inserts []repositories.ExpenseInsert
+	var indexes []int
+	for i := range op.Items {
+		if results[i].Status != "" {
+			continue
+		}
+		item := &op.Items[i]
+		inserts = append(inserts, repositories.ExpenseInsert{
+			Expense:  item.Expense,
+			TagNames: normalizeTagNames(item.Lines.Tags),
+			Merchant: merchantOf(&item.Expense),
+			Splits:   item.Lines.Splits,
+		})
+		indexes = append(indexes, i)
+	}
+
+	created, err := s.expenseRepo.CreateMany(ctx, userID, inserts)
+	if err != nil {
+		return nil, err
+	}
+	for j, expense := range created {
+		result := &results[indexes[j]]
+		result.ID = expense.ID
+		result.Status = BulkStatusCreated
+		result.Expense = &created[j]
+	}
+
+	s.detectAnomalies(ctx, created...)
+	publishWebhooks(ctx, s.webhookService, userID, webhook.ExpenseCreated, created...)
+	return results, nil
+}
+
+func (s *expenseService) bulkUpdate(ctx context.Context, userID uuid.UUID, kind string, op BulkOperation) ([]BulkResult, error) {
+	if op.CategoryID == nil && len(op.AddTags) == 0 && len(op.RemoveTags) == 0 {
+		return nil, utils.ErrBulkEmptyUpdate
+	}
+	results, expenses, err := s.bulkTargets(ctx, userID, kind, op)
+	if err != nil {
+		return nil, err
+	}
+
+	if op.CategoryID != nil {
+		var ids []int32
+		for i, result := range results {
+			if result.Status == "" {
+				ids = append(ids, expenses[i].ID)
+			}
+		}
+		splits, err := s.expenseRepo.SplitsByExpense(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range results {
+			if results[i].Status != "" {
+				continue
+			}
+			// Business Logic: Split transactions take their categories from the lines only
+			err := utils.ErrSplitMismatch
+			if len(splits[expenses[i].ID]) == 0 {
+				err = s.checkCategory(ctx, &expenses[i], *op.CategoryID)
+			}
+			if err != nil {
+				results[i].Status = BulkStatusFailed
+				results[i].Error = err.Error()
+			}
+		}
+	}
+	if !proceedBulk(results, op.Mode) {
+		return results, nil
+	}
+
+	var ids []int32
+	for i, result := range results {
+		if result.Status == "" {
+			ids = append(ids, expenses[i].ID)
+		}
+	}
+	// Business Logic: Added tags come from the namespace of each author
+	update := repositories.BulkUpdate{CategoryID: op.CategoryID}
+	if len(op.AddTags) > 0 {
+		update.AddTags = normalizeTagNames(op.AddTags)
+	}
+	if len(op.RemoveTags) > 0 {
+		update.RemoveTags = normalizeTagNames(op.RemoveTags)
+	}
+	if err := s.expenseRepo.UpdateMany(ctx, ids, update, userID); err != nil {
+		return nil, err
+	}
+	updated, err := s.expenseRepo.ListByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	publishWebhooks(ctx, s.webhookService, userID, webhook.ExpenseUpdated, updated...)
+
+	for i := range results {
+		if results[i].Status == "" {
+			results[i].Status = BulkStatusUpdated
+		}
+	}
+	return results, nil
+}
+
+func (s *expenseService) bulkDelete(ctx context.Context, userID uuid.UUID, kind string, op BulkOperation) ([]BulkResult, error) {
+	results, expenses, err := s.bulkTargets(ctx, userID, kind, op)
+	if err != nil {
+		return nil, err
+	}
+	if !proceedBulk(results, op.Mode) {
+		return results, nil
+	}
+
+	var ids []int32
+	var deleted []model.Expense
+	for i, result := range results {
+		if result.Status == "" {
+			ids = append(ids, expenses[i].ID)
+			deleted = append(deleted, expenses[i])
+		}
+	}
+	if err := s.expenseRepo.DeleteMany(ctx, ids); err != nil {
+		return nil, err
+	}
+
+	selection := map[string]any{"ids": op.IDs}
+	if op.Filter != nil {
+		selection = map[string]any{"filter": op.Filter}
+	}
+	recordAudit(ctx, s.auditService, AuditEvent{
+		UserID:     &userID,
+		Action:     AuditExpensesDeleted,
+		EntityType: "expense",
+		After:      map[string]any{"kind": kind, "selection": selection, "deleted": ids},
+	})
+	publishWebhooks(ctx, s.webhookService, userID, webhook.ExpenseDeleted, deleted...)
+
+	for i := range results {
+		if results[i].Status == "" {
+			results[i].Status = BulkStatusDeleted
+		}
+	}
+	return results, nil
+}
+
+// bulkTargets resolves the transactions a bulk update or delete acts on. The
+// returned expenses are aligned with the results, which report the ids that
+// are unknown or that the user cannot edit as failed.
+func (s *expenseService) bulkTargets(ctx context.Context, userID uuid.UUID, kind string, op BulkOperation) ([]BulkResult, []model.Expense, error) {
+	if (op.IDs == nil) == (op.Filter == nil) {
+		return nil, nil, utils.ErrBulkSelection
+	}
+
+	if op.Filter != nil {
+		filter := *op.Filter
+		filter.Kind = kind
+		if _, err := s.workspaceService.Scope(ctx, userID, filter.WorkspaceID, RoleEditor); err != nil {
+			return nil, nil, err
+		}
+		expenses, err := s.expenseRepo.ListByUser(ctx, userID, filter, BulkMaxItems+1, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(expenses) > BulkMaxItems {
+			return nil, nil, utils.ErrBulkTooLarge
+		}
+
+		results := make([]BulkResult, len(expenses))
+		for i, expense := range expenses {
+			results[i] = BulkResult{Index: i, ID: expense.ID}
+		}
+		return results, expenses, nil
+	}
+
+	if len(op.IDs) > BulkMaxItems {
+		return nil, nil, utils.ErrBulkTooLarge
+	}
+	found, err := s.expenseRepo.ListByIDs(ctx, op.IDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[int32]model.Expense, len(found))
+	for _, expense := range found {
+		byID[expense.ID] = expense
+	}
+
+	results := make([]BulkResult, len(op.IDs))
+	expenses := make([]model.Expense, len(op.IDs))
+	for i, id := range op.IDs {
+		results[i] = BulkResult{Index: i, ID: id}
+		expense, ok := byID[id]
+		err := utils.ErrNotFound
+		if ok && expense.Kind == kind {
+			expenses[i] = expense
+			err = checkExpenseAccess(ctx, s.workspaceService, userID, &expense, RoleEditor)
+		}
 		if err != nil {
+			results[i].Status = BulkStatusFailed
+			results[i].Error = err.Error()
+		}
+	}
+	return results, expenses, nil
+}
+
+// proceedBulk reports whether the items that passed their checks are to be
+// written. In all-or-nothing mode, a single failure marks them skipped.
+func proceedBulk(results []BulkResult, mode string) bool {
+	if mode != BulkAllOrNothing {
+		return true
+	}
+	for _, result := range results {
+		if result.Status != BulkStatusFailed {
+			continue
+		}
+		for i := range results {
+			if results[i].Status == "" {
+				results[i].Status = BulkStatusSkipped
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// checkExpenseAccess verifies that the user holds at least minRole on the
+// ledger of a transaction: their own personal one, or a workspace they are a
+// member of.
+func checkExpenseAccess(ctx context.Context, workspaceService WorkspaceService, userID uuid.UUID, expense *model.Expense, minRole string) error {
+	scope, err := workspaceService.Scope(ctx, userID, expense.WorkspaceID, minRole)
+	if err != nil {
+		return err
+	}
+	if !scope.Contains(expense.UserID, expense.WorkspaceID) {
+		return utils.ErrForbidden
+	}
+	return nil
+}
+
+// CreateRefund records a full or partial refund of one of the user's expenses.
+func (s *expenseService) CreateRefund(ctx context.Context, clerkID string, expenseID int32, refund *model.Expense) (*model.Expense, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	original, err := s.expenseRepo.GetByID(ctx, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil || original.Kind != KindExpense {
+		return nil, utils.ErrNotFound
+	}
+	if err := checkExpenseAccess(ctx, s.workspaceService, userID, original, RoleEditor); err != nil {
+		return nil, err
+	}
+
+	refund.UserID = userID
+	refund.RefundOfID = &original.ID
+	if refund.Description == "" {
+		refund.Description = original.Description
+	}
+	created, err := s.expenseRepo.CreateRefund(ctx, refund)
+	if err != nil {
+		return nil, err
+	}
+	publishWebhooks(ctx, s.webhookService, userID, webhook.ExpenseCreated, *created)
+	return created, nil
+}
+
+// Share splits one of the user's expenses with contacts, replacing any
+// previous split. No participants make it a regular expense again.
+func (s *expenseService) Share(ctx context.Context, clerkID string, expenseID int32, input ShareInput) (*ExpenseDetails, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	expense, err := s.expenseRepo.GetByID(ctx, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if expense == nil || expense.Kind != KindExpense {
+		return nil, utils.ErrNotFound
+	}
+	// Business Logic: Contacts are personal, so only the author can share an expense
+	if expense.UserID != userID {
+		return nil, utils.ErrForbidden
+	}
+	if !utils.IfMatch(ctx, expense.UpdatedAt) {
+		return nil, utils.ErrPreconditionFailed
+	}
+
+	shares, err := s.allocateShares(ctx, userID, expense, input)
+	if err != nil {
+		return nil, err
+	}
+	expense.PaidByContactID = nil
+	if len(shares) > 0 {
+		expense.PaidByContactID = input.PaidByContactID
+	}
+
+	updated, err := s.expenseRepo.ReplaceShares(ctx, expense, shares)
+	if err != nil {
+		return nil, err
+	}
+	publishWebhooks(ctx, s.webhookService, userID, webhook.ExpenseUpdated, *updated)
+
+	details, err := s.withDetails(ctx, []model.Expense{*updated})
+	if err != nil {
+		return nil, err
+	}
+	return &details[0], nil
+}
+
+// allocateShares turns a share input into one share per participant adding
+// up to the amount of the expense.
+func (s *expenseService) allocateShares(ctx context.Context, userID uuid.UUID, expense *model.Expense, input ShareInput) ([]model.ExpenseShare, error) {
+	if len(input.Participants) == 0 {
+		return nil, nil
+	}
+
+	// Business Logic: Every contact involved must belong to the user and take part once
+	seen := map[int32]bool{}
+	for _, p := range input.Participants {
+		if seen[party(p.ContactID)] {
+			return nil, utils.ErrDuplicateParticipant
+		}
+		seen[party(p.ContactID)] = true
+		if p.ContactID != nil {
+			if err := checkContactOwner(ctx, s.contactRepo, userID, *p.ContactID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if input.PaidByContactID != nil {
+		if err := checkContactOwner(ctx, s.contactRepo, userID, *input.PaidByContactID); err != nil {
 			return nil, err
 		}
-		if category == nil {
-			return nil, utils.ErrNotFound
+	}
+
+	total := toCents(expense.Amount)
+	var amounts []int64
+	switch input.Mode {
+	case ShareEqual:
+		shares, err := settle.Equal(total, len(input.Participants))
+		if err != nil {
+			return nil, utils.ErrShareMismatch
+		}
+		amounts = shares
+	case SharePercentage:
+		percentages := make([]float64, len(input.Participants))
+		for i, p := range input.Participants {
+			percentages[i] = p.Value
+		}
+		shares, err := settle.ByPercentages(total, percentages)
+		if err != nil {
+			return nil, utils.ErrShareMismatch
+		}
+		amounts = shares
+	case ShareExact:
+		var sum int64
+		for _, p := range input.Participants {
+			amounts = append(amounts, toCents(p.Value))
+			sum += toCents(p.Value)
+		}
+		if sum != total {
+			return nil, utils.ErrShareMismatch
+		}
+	default:
+		return nil, fmt.Errorf("unknown share mode %q", input.Mode)
+	}
+
+	shares := make([]model.ExpenseShare, len(input.Participants))
+	for i, p := range input.Participants {
+		shares[i] = model.ExpenseShare{
+			ExpenseID: expense.ID,
+			ContactID: p.ContactID,
+			Amount:    float64(amounts[i]) / 100,
+		}
+	}
+	return shares, nil
+}
+
+// withDetails adds the refunded totals to the expenses.
+func (s *expenseService) withDetails(ctx context.Context, expenses []model.Expense) ([]ExpenseDetails, error) {
+	return expenseDetails(ctx, s.expenseRepo, s.tagRepo, expenses)
+}
+
+// expenseDetails adds the refunded totals, tags, split lines and shares to
+// the expenses.
+func expenseDetails(ctx context.Context, expenseRepo repositories.ExpenseRepository, tagRepo repositories.TagRepository, expenses []model.Expense) ([]ExpenseDetails, error) {
+	ids := make([]int32, len(expenses))
+	for i, e := range expenses {
+		ids[i] = e.ID
+	}
+	refunded, err := expenseRepo.RefundedTotals(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := tagRepo.NamesByExpense(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	splits, err := expenseRepo.SplitsByExpense(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := expenseRepo.SharesByExpense(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]ExpenseDetails, len(expenses))
+	for i, e := range expenses {
+		details[i] = ExpenseDetails{
+			Expense:       e,
+			RefundedTotal: refunded[e.ID],
+			Tags:          tags[e.ID],
+			Splits:        splits[e.ID],
+			Shares:        shares[e.ID],
+		}
+		if details[i].Tags == nil {
+			details[i].Tags = []string{}
+		}
+		if details[i].Splits == nil {
+			details[i].Splits = []model.ExpenseSplit{}
+		}
+		if details[i].Shares == nil {
+			details[i].Shares = []model.ExpenseShare{}
+		}
+	}
+	return details, nil
+}
+
+// detectAnomalies runs incremental anomaly detection for new expenses in the
+// background, one after the other. Failures are logged and never affect the
+// request.
+func (s *expenseService) detectAnomalies(ctx context.Context, expenses ...model.Expense) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		for _, expense := range expenses {
+			ctx, cancel := context.WithTimeout(ctx, anomalyDetectionTimeout)
+			if err := s.anomalyService.DetectForExpense(ctx, &expense); err != nil {
+				logrus.WithError(err).WithField("expense_id", expense.ID).Error("failed to detect anomalies")
+			}
+			cancel()
+		}
+	}()
+}
+
+// netSpending prepares transactions for derived figures. Refunded totals are
+// subtracted from the amounts, shared transactions only count the user's own
+// share, fully refunded transactions are dropped, and split transactions are
+// replaced by one entry per split line carrying its category and its share of
+// the net amount.
+func netSpending(ctx context.Context, expenseRepo repositories.ExpenseRepository, expenses []model.Expense) ([]model.Expense, error) {
+	ids := make([]int32, len(expenses))
+	for i, e := range expenses {
+		ids[i] = e.ID
+	}
+	refunded, err := expenseRepo.RefundedTotals(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	splits, err := expenseRepo.SplitsByExpense(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := expenseRepo.SharesByExpense(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	net := make([]model.Expense, 0, len(expenses))
+	for _, e := range expenses {
+		amount := e.Amount - refunded[e.ID]
+		if len(shares[e.ID]) > 0 && e.Amount > 0 {
+			amount *= ownShare(shares[e.ID]) / e.Amount
+		}
+		if amount <= 0 {
+			continue
+		}
+		if len(splits[e.ID]) == 0 {
+			e.Amount = amount
+			net = append(net, e)
+			continue
 		}
-		if category.UserID != expense.UserID {
-			return nil, utils.ErrForbidden
+		for _, split := range splits[e.ID] {
+			line := e
+			line.CategoryID = &split.CategoryID
+			line.Amount = split.Amount * amount / e.Amount
+			net = append(net, line)
 		}
 	}
+	return net, nil
+}
+
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
 
-	return s.expenseRepo.Create(ctx, expense)
+// ownShare returns the user's share of a shared transaction.
+func ownShare(shares []model.ExpenseShare) float64 {
+	for _, share := range shares {
+		if share.ContactID == nil {
+			return share.Amount
+		}
+	}
+	return 0
 }
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	bulkUserID  = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	bulkOtherID = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+)
+
+// newBulkService returns an expense service over fakes holding:
+//   - expenses 1 and 2 in the personal ledger of the user, 2 being split
+//   - expense 3 in the personal ledger of another user
+//   - expense 4 in workspace 7, where the user is an editor
+//   - expense 5 in workspace 8, where the user is a viewer
+//   - income 6 in the personal ledger of the user
+//   - category 10 for expenses and 11 for income, in the user's ledger
+func newBulkService() (ExpenseService, *fakeExpenseRepository) {
+	expense := func(id int32, userID uuid.UUID, workspaceID *int32, kind string) model.Expense {
+		return model.Expense{ID: id, UserID: userID, WorkspaceID: workspaceID, Kind: kind, Amount: 100, Description: "Groceries"}
+	}
+	expenseRepo := &fakeExpenseRepository{
+		expenses: map[int32]model.Expense{
+			1: expense(1, bulkUserID, nil, KindExpense),
+			2: expense(2, bulkUserID, nil, KindExpense),
+			3: expense(3, bulkOtherID, nil, KindExpense),
+			4: expense(4, bulkOtherID, int32Ptr(7), KindExpense),
+			5: expense(5, bulkOtherID, int32Ptr(8), KindExpense),
+			6: expense(6, bulkUserID, nil, KindIncome),
+		},
+		splits: map[int32][]model.ExpenseSplit{
+			2: {{ExpenseID: 2, CategoryID: 10, Amount: 100}},
+		},
+	}
+	categoryRepo := &fakeCategoryRepository{categories: map[int32]model.Category{
+		10: {ID: 10, UserID: bulkUserID, Kind: KindExpense},
+		11: {ID: 11, UserID: bulkUserID, Kind: KindIncome},
+	}}
+	userService := &fakeUserService{clerkIDs: map[string]uuid.UUID{"user_1": bulkUserID}}
+	workspaceService := NewWorkspaceService(&fakeWorkspaceRepository{roles: map[int32]map[uuid.UUID]string{
+		7: {bulkUserID: RoleEditor, bulkOtherID: RoleOwner},
+		8: {bulkUserID: RoleViewer, bulkOtherID: RoleOwner},
+	}}, userService)
+
+	service := NewExpenseService(
+		expenseRepo, categoryRepo, nil, nil, nil, nil,
+		userService, workspaceService, &fakeAnomalyService{}, &fakeAuditService{}, &fakeWebhookService{},
+	)
+	return service, expenseRepo
+}
+
+func bulkStatuses(results []BulkResult) []string {
+	statuses := make([]string, len(results))
+	for i, result := range results {
+		statuses[i] = result.Status
+	}
+	return statuses
+}
+
+func bulkItem(amount float64, categoryID *int32, splits ...model.ExpenseSplit) BulkItem {
+	return BulkItem{
+		Expense: model.Expense{Amount: amount, Description: "Groceries", CategoryID: categoryID},
+		Lines:   ExpenseLines{Splits: splits},
+	}
+}
+
+func TestBulkCreate(t *testing.T) {
+	publicID := uuid.MustParse("00000000-0000-0000-0000-0000000000aa")
+	withPublicID := func(item BulkItem) BulkItem {
+		item.Expense.PublicID = publicID
+		return item
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		items    []BulkItem
+		expected []string
+		created  int
+	}{
+		{
+			name:     "all valid",
+			mode:     BulkAllOrNothing,
+			items:    []BulkItem{bulkItem(10, int32Ptr(10)), bulkItem(20, nil)},
+			expected: []string{BulkStatusCreated, BulkStatusCreated},
+			created:  2,
+		},
+		{
+			name:     "all-or-nothing skips every item when a later one fails",
+			mode:     BulkAllOrNothing,
+			items:    []BulkItem{bulkItem(10, nil), bulkItem(20, nil), bulkItem(30, int32Ptr(11))},
+			expected: []string{BulkStatusSkipped, BulkStatusSkipped, BulkStatusFailed},
+		},
+		{
+			name:     "best-effort writes the valid items",
+			mode:     BulkBestEffort,
+			items:    []BulkItem{bulkItem(10, nil), bulkItem(20, int32Ptr(99)), bulkItem(30, nil)},
+			expected: []string{BulkStatusCreated, BulkStatusFailed, BulkStatusCreated},
+			created:  2,
+		},
+		{
+			name: "split lines must add up to the amount",
+			mode: BulkBestEffort,
+			items: []BulkItem{
+				bulkItem(30, nil, model.ExpenseSplit{CategoryID: 10, Amount: 10}, model.ExpenseSplit{CategoryID: 10, Amount: 20}),
+				bulkItem(30, nil, model.ExpenseSplit{CategoryID: 10, Amount: 10}),
+			},
+			expected: []string{BulkStatusCreated, BulkStatusFailed},
+			created:  1,
+		},
+		{
+			name:     "a public ID is only used once",
+			mode:     BulkBestEffort,
+			items:    []BulkItem{withPublicID(bulkItem(10, nil)), withPublicID(bulkItem(20, nil))},
+			expected: []string{BulkStatusCreated, BulkStatusFailed},
+			created:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, expenseRepo := newBulkService()
+
+			results, err := service.Bulk(context.Background(), "user_1", KindExpense, BulkOperation{
+				Operation: BulkCreate,
+				Mode:      tt.mode,
+				Items:     tt.items,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, bulkStatuses(results))
+			assert.Len(t, expenseRepo.created, tt.created)
+			for i, result := range results {
+				// Results follow the order of the request
+				assert.Equal(t, i, result.Index)
+				if result.Status == BulkStatusCreated {
+					require.NotNil(t, result.Expense)
+					assert.Equal(t, tt.items[i].Expense.Amount, result.Expense.Amount)
+					assert.Equal(t, result.Expense.ID, result.ID)
+				} else {
+					assert.Nil(t, result.Expense)
+				}
+				if result.Status == BulkStatusFailed {
+					assert.NotEmpty(t, result.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestBulkUpdateAndDelete(t *testing.T) {
+	tests := []struct {
+		name       string
+		operation  string
+		mode       string
+		ids        []int32
+		categoryID *int32
+		expected   []string
+		written    []int32
+	}{
+		{
+			name:      "delete in all-or-nothing mode",
+			operation: BulkDelete,
+			mode:      BulkAllOrNothing,
+			ids:       []int32{1, 4},
+			expected:  []string{BulkStatusDeleted, BulkStatusDeleted},
+			written:   []int32{1, 4},
+		},
+		{
+			name:      "all-or-nothing skips every item when a later one fails",
+			operation: BulkDelete,
+			mode:      BulkAllOrNothing,
+			ids:       []int32{1, 2, 99},
+			expected:  []string{BulkStatusSkipped, BulkStatusSkipped, BulkStatusFailed},
+		},
+		{
+			name:      "best-effort deletes what the user can edit",
+			operation: BulkDelete,
+			mode:      BulkBestEffort,
+			ids:       []int32{3, 1, 5, 6, 4},
+			expected:  []string{BulkStatusFailed, BulkStatusDeleted, BulkStatusFailed, BulkStatusFailed, BulkStatusDeleted},
+			written:   []int32{1, 4},
+		},
+		{
+			name:       "split transactions keep their categories",
+			operation:  BulkUpdate,
+			mode:       BulkBestEffort,
+			ids:        []int32{1, 2},
+			categoryID: int32Ptr(10),
+			expected:   []string{BulkStatusUpdated, BulkStatusFailed},
+			written:    []int32{1},
+		},
+		{
+			name:       "all-or-nothing update leaves everything untouched",
+			operation:  BulkUpdate,
+			mode:       BulkAllOrNothing,
+			ids:        []int32{1, 2},
+			categoryID: int32Ptr(10),
+			expected:   []string{BulkStatusSkipped, BulkStatusFailed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, expenseRepo := newBulkService()
+
+			results, err := service.Bulk(context.Background(), "user_1", KindExpense, BulkOperation{
+				Operation:  tt.operation,
+				Mode:       tt.mode,
+				IDs:        tt.ids,
+				CategoryID: tt.categoryID,
+				AddTags:    []string{"groceries"},
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, bulkStatuses(results))
+			for i, result := range results {
+				assert.Equal(t, i, result.Index)
+				assert.Equal(t, tt.ids[i], result.ID)
+			}
+			written := expenseRepo.deleted
+			if tt.operation == BulkUpdate {
+				written = expenseRepo.updated
+			}
+			assert.Equal(t, tt.written, written)
+		})
+	}
+}
+
+func TestBulkWriteFailure(t *testing.T) {
+	failure := errors.New("connection reset")
+	operations := []BulkOperation{
+		{Operation: BulkCreate, Items: []BulkItem{bulkItem(10, nil), bulkItem(20, nil)}},
+		{Operation: BulkUpdate, IDs: []int32{1, 4}, AddTags: []string{"groceries"}},
+		{Operation: BulkDelete, IDs: []int32{1, 4}},
+	}
+
+	for _, op := range operations {
+		t.Run(op.Operation, func(t *testing.T) {
+			service, expenseRepo := newBulkService()
+			expenseRepo.writeErr = failure
+
+			op.Mode = BulkAllOrNothing
+			results, err := service.Bulk(context.Background(), "user_1", KindExpense, op)
+
+			// The repository rolls back, and no item is reported written
+			assert.ErrorIs(t, err, failure)
+			assert.Nil(t, results)
+			assert.Empty(t, expenseRepo.created)
+			assert.Empty(t, expenseRepo.updated)
+			assert.Empty(t, expenseRepo.deleted)
+		})
+	}
+}
+
+func TestBulkSelection(t *testing.T) {
+	service, _ := newBulkService()
+
+	_, err := service.Bulk(context.Background(), "user_1", KindExpense, BulkOperation{Operation: BulkDelete})
+	assert.Equal(t, u.ErrBulkSelection, err)
+
+	_, err = service.Bulk(context.Background(), "user_1", KindExpense, BulkOperation{Operation: BulkUpdate, IDs: []int32{1}})
+	assert.Equal(t, u.ErrBulkEmptyUpdate, err)
+}
+
+func TestCheckSplits(t *testing.T) {
+	s := &expenseService{categoryRepo: &fakeCategoryRepository{categories: map[int32]model.Category{
+		10: {ID: 10, UserID: bulkUserID, Kind: KindExpense},
+		11: {ID: 11, UserID: bulkUserID, Kind: KindIncome},
+		12: {ID: 12, UserID: bulkOtherID, Kind: KindExpense},
+	}}}
+	split := func(categoryID int32, amount float64) model.ExpenseSplit {
+		return model.ExpenseSplit{CategoryID: categoryID, Amount: amount}
+	}
+
+	tests := []struct {
+		name     string
+		kind     string
+		amount   float64
+		splits   []model.ExpenseSplit
+		expected error
+	}{
+		{name: "no split lines", kind: KindExpense, amount: 100},
+		{name: "lines adding up to the amount", kind: KindExpense, amount: 100, splits: []model.ExpenseSplit{split(10, 60), split(10, 40)}},
+		{name: "sums compared in cents", kind: KindExpense, amount: 0.3, splits: []model.ExpenseSplit{split(10, 0.1), split(10, 0.2)}},
+		{name: "a cent short", kind: KindExpense, amount: 100, splits: []model.ExpenseSplit{split(10, 60), split(10, 39.99)}, expected: u.ErrSplitMismatch},
+		{name: "over the amount", kind: KindExpense, amount: 100, splits: []model.ExpenseSplit{split(10, 60), split(10, 60)}, expected: u.ErrSplitMismatch},
+		{name: "transfers are not split", kind: KindTransfer, amount: 100, splits: []model.ExpenseSplit{split(10, 100)}, expected: u.ErrSplitMismatch},
+		{name: "category of the other kind", kind: KindExpense, amount: 100, splits: []model.ExpenseSplit{split(11, 100)}, expected: u.ErrCategoryKindMismatch},
+		{name: "unknown category", kind: KindExpense, amount: 100, splits: []model.ExpenseSplit{split(99, 100)}, expected: u.ErrNotFound},
+		{name: "category of another ledger", kind: KindExpense, amount: 100, splits: []model.ExpenseSplit{split(12, 100)}, expected: u.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expense := &model.Expense{UserID: bulkUserID, Kind: tt.kind, Amount: tt.amount, CategoryID: int32Ptr(10)}
+
+			err := s.checkSplits(context.Background(), expense, tt.splits)
+
+			assert.Equal(t, tt.expected, err)
+			// Split transactions take their categories from the lines only
+			if err == nil && len(tt.splits) > 0 {
+				assert.Nil(t, expense.CategoryID)
+			} else {
+				assert.Equal(t, int32Ptr(10), expense.CategoryID)
+			}
+		})
+	}
+}
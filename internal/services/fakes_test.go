@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/storage"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+// The fakes below keep their rows in memory. Each embeds the interface it
+// stands for, so that a call the test did not plan for panics.
+
+type fakeUserService struct {
+	UserService
+	clerkIDs map[string]uuid.UUID
+}
+
+func (s *fakeUserService) GetInternalIDByClerkID(ctx context.Context, clerkID string) (uuid.UUID, error) {
+	userID, ok := s.clerkIDs[clerkID]
+	if !ok {
+		return uuid.Nil, utils.ErrNotFound
+	}
+	return userID, nil
+}
+
+type fakeWorkspaceRepository struct {
+	repositories.WorkspaceRepository
+	// Roles of the members, by workspace
+	roles map[int32]map[uuid.UUID]string
+}
+
+func (r *fakeWorkspaceRepository) GetMember(ctx context.Context, workspaceID int32, userID uuid.UUID) (*model.WorkspaceMember, error) {
+	role, ok := r.roles[workspaceID][userID]
+	if !ok {
+		return nil, nil
+	}
+	return &model.WorkspaceMember{WorkspaceID: workspaceID, UserID: userID, Role: role}, nil
+}
+
+type fakeCategoryRepository struct {
+	repositories.CategoryRepository
+	categories map[int32]model.Category
+	// Cutoff of the last purge
+	purgedBefore *time.Time
+}
+
+func (r *fakeCategoryRepository) GetByID(ctx context.Context, id int32) (*model.Category, error) {
+	category, ok := r.categories[id]
+	if !ok || category.DeletedAt != nil {
+		return nil, nil
+	}
+	return &category, nil
+}
+
+func (r *fakeCategoryRepository) GetDeletedByID(ctx context.Context, id int32) (*model.Category, error) {
+	category, ok := r.categories[id]
+	if !ok || category.DeletedAt == nil {
+		return nil, nil
+	}
+	return &category, nil
+}
+
+func (r *fakeCategoryRepository) Restore(ctx context.Context, id int32) (*model.Category, error) {
+	category := r.categories[id]
+	category.DeletedAt = nil
+	r.categories[id] = category
+	return &category, nil
+}
+
+func (r *fakeCategoryRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.purgedBefore = &before
+	var purged int64
+	for id, category := range r.categories {
+		if category.DeletedAt != nil && category.DeletedAt.Before(before) {
+			delete(r.categories, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+type fakeExpenseRepository struct {
+	repositories.ExpenseRepository
+	expenses map[int32]model.Expense
+	splits   map[int32][]model.ExpenseSplit
+	shares   map[int32][]model.ExpenseShare
+	refunded map[int32]float64
+	// Storage keys of the attachments, by expense
+	attachments map[int32][]string
+	// Error returned by the writes, standing for a failed transaction
+	writeErr error
+	// Writes that went through
+	created  []repositories.ExpenseInsert
+	updated  []int32
+	deleted  []int32
+	restored []int32
+	// Cutoff of the last purge
+	purgedBefore *time.Time
+}
+
+func (r *fakeExpenseRepository) GetByID(ctx context.Context, id int32) (*model.Expense, error) {
+	expense, ok := r.expenses[id]
+	if !ok || expense.DeletedAt != nil {
+		return nil, nil
+	}
+	return &expense, nil
+}
+
+func (r *fakeExpenseRepository) ListByIDs(ctx context.Context, ids []int32) ([]model.Expense, error) {
+	var expenses []model.Expense
+	for _, id := range ids {
+		if expense, ok := r.expenses[id]; ok && expense.DeletedAt == nil {
+			expenses = append(expenses, expense)
+		}
+	}
+	return expenses, nil
+}
+
+func (r *fakeExpenseRepository) ListByUser(ctx context.Context, userID uuid.UUID, filter repositories.ExpenseFilter, limit, offset int) ([]model.Expense, error) {
+	var expenses []model.Expense
+	for _, expense := range r.expenses {
+		if expense.DeletedAt == nil && expense.UserID == userID && expense.Kind == filter.Kind {
+			expenses = append(expenses, expense)
+		}
+	}
+	slices.SortFunc(expenses, func(a, b model.Expense) int { return int(a.ID - b.ID) })
+	return expenses, nil
+}
+
+func (r *fakeExpenseRepository) IDsByPublicID(ctx context.Context, publicIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	ids := map[uuid.UUID]int32{}
+	for _, expense := range r.expenses {
+		if slices.Contains(publicIDs, expense.PublicID) {
+			ids[expense.PublicID] = expense.ID
+		}
+	}
+	return ids, nil
+}
+
+func (r *fakeExpenseRepository) SplitsByExpense(ctx context.Context, ids []int32) (map[int32][]model.ExpenseSplit, error) {
+	splits := map[int32][]model.ExpenseSplit{}
+	for _, id := range ids {
+		if lines, ok := r.splits[id]; ok {
+			splits[id] = lines
+		}
+	}
+	return splits, nil
+}
+
+func (r *fakeExpenseRepository) SharesByExpense(ctx context.Context, ids []int32) (map[int32][]model.ExpenseShare, error) {
+	shares := map[int32][]model.ExpenseShare{}
+	for _, id := range ids {
+		if lines, ok := r.shares[id]; ok {
+			shares[id] = lines
+		}
+	}
+	return shares, nil
+}
+
+func (r *fakeExpenseRepository) RefundedTotals(ctx context.Context, ids []int32) (map[int32]float64, error) {
+	refunded := map[int32]float64{}
+	for _, id := range ids {
+		if total, ok := r.refunded[id]; ok {
+			refunded[id] = total
+		}
+	}
+	return refunded, nil
+}
+
+func (r *fakeExpenseRepository) CreateMany(ctx context.Context, userID uuid.UUID, inserts []repositories.ExpenseInsert) ([]model.Expense, error) {
+	if r.writeErr != nil {
+		return nil, r.writeErr
+	}
+	created := make([]model.Expense, len(inserts))
+	for i, insert := range inserts {
+		created[i] = insert.Expense
+		created[i].ID = int32(len(r.expenses) + 1)
+		r.expenses[created[i].ID] = created[i]
+	}
+	r.created = append(r.created, inserts...)
+	return created, nil
+}
+
+func (r *fakeExpenseRepository) UpdateMany(ctx context.Context, ids []int32, update repositories.BulkUpdate, editedBy uuid.UUID) error {
+	if r.writeErr != nil {
+		return r.writeErr
+	}
+	for _, id := range ids {
+		expense := r.expenses[id]
+		if update.CategoryID != nil {
+			expense.CategoryID = update.CategoryID
+		}
+		r.expenses[id] = expense
+	}
+	r.updated = append(r.updated, ids...)
+	return nil
+}
+
+func (r *fakeExpenseRepository) DeleteMany(ctx context.Context, ids []int32) error {
+	if r.writeErr != nil {
+		return r.writeErr
+	}
+	r.deleted = append(r.deleted, ids...)
+	return nil
+}
+
+func (r *fakeExpenseRepository) GetDeletedByID(ctx context.Context, id int32) (*model.Expense, error) {
+	expense, ok := r.expenses[id]
+	if !ok || expense.DeletedAt == nil {
+		return nil, nil
+	}
+	return &expense, nil
+}
+
+func (r *fakeExpenseRepository) Restore(ctx context.Context, expense *model.Expense) (*model.Expense, error) {
+	expense.DeletedAt = nil
+	r.expenses[expense.ID] = *expense
+	r.restored = append(r.restored, expense.ID)
+	return expense, nil
+}
+
+func (r *fakeExpenseRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, []string, error) {
+	r.purgedBefore = &before
+	var purged int64
+	var keys []string
+	for id, expense := range r.expenses {
+		if expense.DeletedAt != nil && expense.DeletedAt.Before(before) {
+			delete(r.expenses, id)
+			keys = append(keys, r.attachments[id]...)
+			purged++
+		}
+	}
+	return purged, keys, nil
+}
+
+type fakeBlobStore struct {
+	storage.BlobStore
+	deleted []string
+}
+
+func (s *fakeBlobStore) Delete(ctx context.Context, key string) error {
+	s.deleted = append(s.deleted, key)
+	return nil
+}
+
+type fakeAuditService struct {
+	AuditService
+	events []AuditEvent
+}
+
+func (s *fakeAuditService) Record(ctx context.Context, event AuditEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+type fakeWebhookService struct {
+	WebhookService
+}
+
+func (s *fakeWebhookService) PublishExpenses(ctx context.Context, userID uuid.UUID, eventType string, expenses []model.Expense) error {
+	return nil
+}
+
+type fakeAnomalyService struct {
+	AnomalyService
+}
+
+func (s *fakeAnomalyService) DetectForExpense(ctx context.Context, expense *model.Expense) error {
+	return nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/forecast"
+	"github.com/igorschechtel/clearflow-backend/internal/insights"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type ForecastService interface {
+	Get(ctx context.Context, clerkID string) (*forecast.Result, error)
+}
+
+type forecastService struct {
+	expenseRepo  repositories.ExpenseRepository
+	categoryRepo repositories.CategoryRepository
+	userService  UserService
+}
+
+func NewForecastService(
+	expenseRepo repositories.ExpenseRepository,
+	categoryRepo repositories.CategoryRepository,
+	userService UserService,
+) ForecastService {
+	return &forecastService{
+		expenseRepo:  expenseRepo,
+		categoryRepo: categoryRepo,
+		userService:  userService,
+	}
+}
+
+func (s *forecastService) Get(ctx context.Context, clerkID string) (*forecast.Result, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	now := time.Now().UTC()
+	month := u.CurrentMonth(now)
+
+	// Purchases of the baseline and current months, including those dated
+	// later this month, plus everything billed from this month on, which
+	// includes future installments of older purchases.
+	purchased, err := s.expenseRepo.ListByUserInPeriod(ctx, userID, repositories.KindExpense, u.Period{
+		From: month.From.AddDate(0, -forecast.HistoryMonths, 0),
+		To:   month.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+	billed, err := s.expenseRepo.ListByUserBilledFrom(ctx, userID, repositories.KindExpense, month.From)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categoryRepo.ListAllByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	expenses, err := netSpending(ctx, s.expenseRepo, mergeExpenses(purchased, billed))
+	if err != nil {
+		return nil, err
+	}
+	history := make([]insights.Expense, len(expenses))
+	for i, e := range expenses {
+		history[i] = insights.Expense{
+			Amount:       e.Amount,
+			Description:  e.Description,
+			CategoryID:   e.CategoryID,
+			PurchaseDate: e.PurchaseDate,
+		}
+	}
+
+	input := forecast.Input{
+		Now:           now,
+		Expenses:      make([]forecast.Expense, len(expenses)),
+		CategoryNames: make(map[int32]string, len(categories)),
+	}
+	recurringKeys := map[string]bool{}
+	for _, r := range insights.DetectRecurring(history, now) {
+		recurringKeys[r.Key] = true
+		input.Recurring = append(input.Recurring, forecast.Recurring{
+			Amount:     r.Amount,
+			CategoryID: r.CategoryID,
+			NextDate:   r.NextDate(),
+		})
+	}
+	for i, e := range expenses {
+		input.Expenses[i] = forecast.Expense{
+			Amount:       e.Amount,
+			CategoryID:   e.CategoryID,
+			PurchaseDate: e.PurchaseDate,
+			BillDate:     e.BillDate,
+			Recurring:    recurringKeys[insights.DescriptionKey(e.Description)],
+		}
+	}
+	for _, c := range categories {
+		input.CategoryNames[c.ID] = c.Name
+	}
+
+	result := forecast.Forecast(input)
+	return &result, nil
+}
+
+// mergeExpenses concatenates expense lists, dropping duplicates.
+func mergeExpenses(lists ...[]model.Expense) []model.Expense {
+	seen := map[int32]bool{}
+	result := []model.Expense{}
+	for _, list := range lists {
+		for _, e := range list {
+			if !seen[e.ID] {
+				seen[e.ID] = true
+				result = append(result, e)
+			}
+		}
+	}
+	return result
+}
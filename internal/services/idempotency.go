@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Time after which a key whose request never completed, such as one cut short
+// by a crash, can be claimed again. It outlasts the request timeout.
+const idempotencyStallTimeout = time.Minute
+
+// IdempotencyService stores the responses of mutating requests sent with an
+// Idempotency-Key header, so that retries replay them instead of repeating
+// the operation.
+type IdempotencyService interface {
+	// Begin claims a key of the user for a request and returns its entry. An
+	// entry that already holds a response was claimed by an earlier identical
+	// request, and its response is to be replayed.
+	Begin(ctx context.Context, clerkID, key, fingerprint string) (*model.IdempotencyKey, error)
+	// Complete stores the response of the request that claimed the entry.
+	Complete(ctx context.Context, entry *model.IdempotencyKey) error
+	// Release frees the key of a request that failed, so that it can be retried.
+	Release(ctx context.Context, entry *model.IdempotencyKey) error
+	// PurgeExpired deletes the keys past their time to live.
+	PurgeExpired(ctx context.Context) error
+}
+
+type idempotencyService struct {
+	idempotencyRepo repositories.IdempotencyRepository
+	userService     UserService
+	ttl             time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepo repositories.IdempotencyRepository, userService UserService, ttl time.Duration) IdempotencyService {
+	return &idempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		userService:     userService,
+		ttl:             ttl,
+	}
+}
+
+func (s *idempotencyService) Begin(ctx context.Context, clerkID, key, fingerprint string) (*model.IdempotencyKey, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		if err == utils.ErrNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	now := time.Now().UTC()
+	entry := &model.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		ExpiresAt:   now.Add(s.ttl),
+		Fingerprint: fingerprint,
+	}
+	claimed, err := s.idempotencyRepo.Claim(ctx, entry, now, now.Add(-idempotencyStallTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if claimed {
+		return entry, nil
+	}
+
+	stored, err := s.idempotencyRepo.Get(ctx, userID, key)
+	if err != nil {
+		return nil, err
+	}
+	// Business Logic: A key only ever stands for one request
+	if stored != nil && stored.Fingerprint != fingerprint {
+		return nil, utils.ErrIdempotencyKeyReused
+	}
+	// The first request is still being served, or was released meanwhile
+	if stored == nil || stored.StatusCode == nil {
+		return nil, utils.ErrIdempotencyKeyInUse
+	}
+	return stored, nil
+}
+
+func (s *idempotencyService) Complete(ctx context.Context, entry *model.IdempotencyKey) error {
+	if err := s.idempotencyRepo.Complete(ctx, entry); err != nil {
+		return fmt.Errorf("failed to store response for idempotency key %s: %w", entry.Key, err)
+	}
+	return nil
+}
+
+func (s *idempotencyService) Release(ctx context.Context, entry *model.IdempotencyKey) error {
+	if err := s.idempotencyRepo.Delete(ctx, entry.UserID, entry.Key); err != nil {
+		return fmt.Errorf("failed to release idempotency key %s: %w", entry.Key, err)
+	}
+	return nil
+}
+
+func (s *idempotencyService) PurgeExpired(ctx context.Context) error {
+	deleted, err := s.idempotencyRepo.DeleteExpired(ctx, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+	if deleted > 0 {
+		logrus.WithField("deleted", deleted).Info("purged expired idempotency keys")
+	}
+	return nil
+}
@@ -0,0 +1,433 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/anomaly"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/igorschechtel/clearflow-backend/internal/webhook"
+	"github.com/sirupsen/logrus"
+)
+
+// How far back a negative imported amount is matched against earlier expenses.
+const refundMatchDays = 180
+
+// Maximum number of days between the two legs of a transfer.
+const transferMatchDays = 3
+
+// Import row statuses
+const (
+	ImportStatusCreated  = "created"
+	ImportStatusRefund   = "refund"
+	ImportStatusTransfer = "transfer"
+	ImportStatusFailed   = "failed"
+)
+
+// ImportRow is a transaction read from a statement. Positive amounts leave the
+// account and negative amounts enter it, as refunds or incoming transfers.
+type ImportRow struct {
+	Amount       float64
+	Description  string
+	PurchaseDate time.Time
+	BillDate     time.Time
+	CategoryID   *int32
+	// Account of the statement, required for transfers to be detected
+	AccountID *int32
+}
+
+// ImportResult reports the outcome of a single imported row.
+type ImportResult struct {
+	Index   int            `json:"index"`
+	Status  string         `json:"status"`
+	Expense *model.Expense `json:"expense,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+type ImportService interface {
+	Import(ctx context.Context, clerkID string, rows []ImportRow) ([]ImportResult, error)
+}
+
+type importService struct {
+	expenseRepo    repositories.ExpenseRepository
+	expenseService ExpenseService
+	userService    UserService
+	auditService   AuditService
+	webhookService WebhookService
+}
+
+func NewImportService(
+	expenseRepo repositories.ExpenseRepository,
+	expenseService ExpenseService,
+	userService UserService,
+	auditService AuditService,
+	webhookService WebhookService,
+) ImportService {
+	return &importService{
+		expenseRepo:    expenseRepo,
+		expenseService: expenseService,
+		userService:    userService,
+		auditService:   auditService,
+		webhookService: webhookService,
+	}
+}
+
+// refundCandidate is an expense that imported refunds can be matched against.
+type refundCandidate struct {
+	expense   model.Expense
+	remaining float64
+}
+
+// Import creates an expense for each positive row. Negative rows are recorded
+// as refunds of the most recent earlier expense of the same merchant that still
+// has enough unrefunded amount. An outgoing row and an incoming row of the same
+// amount on two different accounts are paired into a single transfer, unless a
+// matching transfer already exists. Incoming rows refunding an existing or
+// earlier imported expense are never paired. Rows are processed in order and
+// failures do not stop the import.
+func (s *importService) Import(ctx context.Context, clerkID string, rows []ImportRow) ([]ImportResult, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	candidates, err := s.loadRefundCandidates(ctx, rows, userID)
+	if err != nil {
+		return nil, err
+	}
+	existingTransfers, err := s.loadTransfers(ctx, rows, userID)
+	if err != nil {
+		return nil, err
+	}
+	legs := pairTransferLegs(rows, refundRows(rows, candidates))
+
+	results := make([]ImportResult, len(rows))
+	// Refunds are written directly, other rows through the expense service
+	var refunds []model.Expense
+	for i, row := range rows {
+		results[i] = ImportResult{Index: i}
+
+		if other, ok := legs[i]; ok {
+			// Both legs share the transfer created for the first one
+			if other < i {
+				results[i] = results[other]
+				results[i].Index = i
+				continue
+			}
+
+			out, in := rows[i], rows[other]
+			if out.Amount < 0 {
+				out, in = in, out
+			}
+			var transfer *model.Expense
+			if k := matchTransfer(existingTransfers, *out.AccountID, *in.AccountID, out.Amount, out.PurchaseDate); k != -1 {
+				// Each existing transfer stands for a single pair of legs
+				matched := existingTransfers[k]
+				transfer = &matched
+				existingTransfers = append(existingTransfers[:k], existingTransfers[k+1:]...)
+			} else {
+				created, err := s.expenseService.Create(ctx, clerkID, &model.Expense{
+					Amount:            out.Amount,
+					Description:       out.Description,
+					PurchaseDate:      out.PurchaseDate,
+					BillDate:          out.PurchaseDate,
+					Kind:              KindTransfer,
+					AccountID:         out.AccountID,
+					TransferAccountID: in.AccountID,
+				}, ExpenseLines{})
+				if err != nil {
+					results[i].Status = ImportStatusFailed
+					results[i].Error = err.Error()
+					continue
+				}
+				transfer = &created.Expense
+			}
+			results[i].Status = ImportStatusTransfer
+			results[i].Expense = transfer
+			continue
+		}
+
+		if row.Amount >= 0 {
+			created, err := s.expenseService.Create(ctx, clerkID, &model.Expense{
+				Amount:       row.Amount,
+				Description:  row.Description,
+				PurchaseDate: row.PurchaseDate,
+				BillDate:     row.BillDate,
+				CategoryID:   row.CategoryID,
+				AccountID:    row.AccountID,
+				Kind:         KindExpense,
+			}, ExpenseLines{})
+			if err != nil {
+				results[i].Status = ImportStatusFailed
+				results[i].Error = err.Error()
+				continue
+			}
+			results[i].Status = ImportStatusCreated
+			results[i].Expense = &created.Expense
+			candidates = append(candidates, &refundCandidate{expense: created.Expense, remaining: created.Amount})
+			continue
+		}
+
+		refund, err := s.importRefund(ctx, userID, row, candidates)
+		if err != nil {
+			results[i].Status = ImportStatusFailed
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Status = ImportStatusRefund
+		results[i].Expense = refund
+		refunds = append(refunds, *refund)
+	}
+
+	statuses := map[string]int{}
+	for _, result := range results {
+		statuses[result.Status]++
+	}
+	recordAudit(ctx, s.auditService, AuditEvent{
+		UserID:     &userID,
+		Action:     AuditExpensesImported,
+		EntityType: "import",
+		After:      map[string]any{"rows": len(rows), "statuses": statuses},
+	})
+	publishWebhooks(ctx, s.webhookService, userID, webhook.ExpenseCreated, refunds...)
+	err = s.webhookService.Publish(ctx, userID, webhook.ImportCompleted, func() ([]any, error) {
+		return []any{map[string]any{"rows": len(rows), "statuses": statuses}}, nil
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("event", webhook.ImportCompleted).Error("failed to publish webhook event")
+	}
+	return results, nil
+}
+
+// importRefund matches a negative row against the candidates and records it.
+func (s *importService) importRefund(ctx context.Context, userID uuid.UUID, row ImportRow, candidates []*refundCandidate) (*model.Expense, error) {
+	amount := -row.Amount
+	match := matchRefund(candidates, row.Description, row.PurchaseDate, amount)
+	if match == nil {
+		return nil, u.ErrRefundNoMatch
+	}
+
+	refund, err := s.expenseRepo.CreateRefund(ctx, &model.Expense{
+		UserID:       userID,
+		Amount:       amount,
+		Description:  row.Description,
+		PurchaseDate: row.PurchaseDate,
+		BillDate:     row.BillDate,
+		RefundOfID:   &match.expense.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	match.remaining -= amount
+	return refund, nil
+}
+
+// loadRefundCandidates fetches the expenses that the negative rows may refund,
+// along with how much of each is still refundable.
+func (s *importService) loadRefundCandidates(ctx context.Context, rows []ImportRow, userID uuid.UUID) ([]*refundCandidate, error) {
+	var from, to time.Time
+	for _, row := range rows {
+		if row.Amount >= 0 {
+			continue
+		}
+		if from.IsZero() || row.PurchaseDate.Before(from) {
+			from = row.PurchaseDate
+		}
+		if to.IsZero() || row.PurchaseDate.After(to) {
+			to = row.PurchaseDate
+		}
+	}
+	if from.IsZero() {
+		return nil, nil
+	}
+
+	expenses, err := s.expenseRepo.ListByUserInPeriod(ctx, userID, KindExpense, u.Period{
+		From: from.AddDate(0, 0, -refundMatchDays),
+		To:   to.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int32, len(expenses))
+	for i, e := range expenses {
+		ids[i] = e.ID
+	}
+	refunded, err := s.expenseRepo.RefundedTotals(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*refundCandidate, len(expenses))
+	for i, e := range expenses {
+		candidates[i] = &refundCandidate{expense: e, remaining: e.Amount - refunded[e.ID]}
+	}
+	return candidates, nil
+}
+
+// matchRefund picks the most recent expense of the same merchant purchased up
+// to refundMatchDays before the refund that can still absorb the amount.
+func matchRefund(candidates []*refundCandidate, description string, date time.Time, amount float64) *refundCandidate {
+	key := anomaly.MerchantKey(description)
+	earliest := date.AddDate(0, 0, -refundMatchDays)
+
+	matches := make([]*refundCandidate, 0)
+	for _, c := range candidates {
+		if c.expense.PurchaseDate.After(date) || c.expense.PurchaseDate.Before(earliest) {
+			continue
+		}
+		if anomaly.MerchantKey(c.expense.Description) != key {
+			continue
+		}
+		if math.Round(c.remaining*100) < math.Round(amount*100) {
+			continue
+		}
+		matches = append(matches, c)
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].expense.PurchaseDate.After(matches[j].expense.PurchaseDate)
+	})
+	return matches[0]
+}
+
+// refundRows returns the indexes of the negative rows refunding an expense of
+// the same merchant, either existing or imported earlier in the file, which
+// must not be taken for transfer legs. Imported expenses only match refunds on
+// the same account, so that the legs of a transfer are not mistaken for a
+// purchase and its refund.
+func refundRows(rows []ImportRow, candidates []*refundCandidate) map[int]bool {
+	// Work on copies so the refundable amounts are left intact for the import
+	pending := make([]*refundCandidate, len(candidates))
+	for i, c := range candidates {
+		candidate := *c
+		pending[i] = &candidate
+	}
+
+	var imported []*refundCandidate
+	refunds := map[int]bool{}
+	for i, row := range rows {
+		if row.Amount >= 0 {
+			imported = append(imported, &refundCandidate{
+				expense: model.Expense{
+					Amount:       row.Amount,
+					Description:  row.Description,
+					PurchaseDate: row.PurchaseDate,
+					AccountID:    row.AccountID,
+				},
+				remaining: row.Amount,
+			})
+			continue
+		}
+
+		eligible := append([]*refundCandidate{}, pending...)
+		for _, c := range imported {
+			if c.expense.AccountID != nil && row.AccountID != nil && *c.expense.AccountID != *row.AccountID {
+				continue
+			}
+			eligible = append(eligible, c)
+		}
+		if match := matchRefund(eligible, row.Description, row.PurchaseDate, -row.Amount); match != nil {
+			match.remaining += row.Amount
+			refunds[i] = true
+		}
+	}
+	return refunds
+}
+
+// loadTransfers fetches the user's transfers that the imported legs may
+// duplicate.
+func (s *importService) loadTransfers(ctx context.Context, rows []ImportRow, userID uuid.UUID) ([]model.Expense, error) {
+	var from, to time.Time
+	for _, row := range rows {
+		if row.AccountID == nil {
+			continue
+		}
+		if from.IsZero() || row.PurchaseDate.Before(from) {
+			from = row.PurchaseDate
+		}
+		if to.IsZero() || row.PurchaseDate.After(to) {
+			to = row.PurchaseDate
+		}
+	}
+	if from.IsZero() {
+		return nil, nil
+	}
+
+	return s.expenseRepo.ListByUserInPeriod(ctx, userID, KindTransfer, u.Period{
+		From: from.AddDate(0, 0, -transferMatchDays),
+		To:   to.AddDate(0, 0, transferMatchDays+1),
+	})
+}
+
+// pairTransferLegs pairs each outgoing row with the closest incoming row of the
+// same amount on another account, up to transferMatchDays apart, skipping the
+// refunds. The result maps the index of each paired row to the index of its
+// other leg.
+func pairTransferLegs(rows []ImportRow, refunds map[int]bool) map[int]int {
+	legs := map[int]int{}
+	for i, out := range rows {
+		if out.AccountID == nil || out.Amount <= 0 {
+			continue
+		}
+
+		best := -1
+		var bestGap time.Duration
+		for j, in := range rows {
+			if _, paired := legs[j]; paired || refunds[j] || in.AccountID == nil || *in.AccountID == *out.AccountID {
+				continue
+			}
+			if math.Round(in.Amount*100) != -math.Round(out.Amount*100) {
+				continue
+			}
+			gap := absDuration(in.PurchaseDate.Sub(out.PurchaseDate))
+			if gap > transferMatchDays*24*time.Hour {
+				continue
+			}
+			if best == -1 || gap < bestGap {
+				best, bestGap = j, gap
+			}
+		}
+		if best != -1 {
+			legs[i] = best
+			legs[best] = i
+		}
+	}
+	return legs
+}
+
+// matchTransfer finds an existing transfer between the accounts with the same
+// amount, up to transferMatchDays away from date, and returns its index or -1.
+func matchTransfer(transfers []model.Expense, fromAccountID, toAccountID int32, amount float64, date time.Time) int {
+	for i, t := range transfers {
+		if t.AccountID == nil || t.TransferAccountID == nil {
+			continue
+		}
+		if *t.AccountID != fromAccountID || *t.TransferAccountID != toAccountID {
+			continue
+		}
+		if math.Round(t.Amount*100) != math.Round(amount*100) {
+			continue
+		}
+		if absDuration(t.PurchaseDate.Sub(date)) > transferMatchDays*24*time.Hour {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func day(month time.Month, d int) time.Time {
+	return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func candidate(id int32, description string, amount, remaining float64, date time.Time) *refundCandidate {
+	return &refundCandidate{
+		expense:   model.Expense{ID: id, Amount: amount, Description: description, PurchaseDate: date},
+		remaining: remaining,
+	}
+}
+
+func TestMatchRefund(t *testing.T) {
+	candidates := []*refundCandidate{
+		candidate(1, "Acme Store", 100, 100, day(3, 1)),
+		candidate(2, "ACME STORE #123", 80, 30, day(3, 10)),
+		candidate(3, "Other Shop", 50, 50, day(3, 5)),
+	}
+
+	tests := []struct {
+		name        string
+		description string
+		date        time.Time
+		amount      float64
+		expected    int32
+	}{
+		{name: "most recent expense of the merchant", description: "Acme Store", date: day(3, 15), amount: 20, expected: 2},
+		{name: "older expense when the recent one has too little left", description: "Acme Store", date: day(3, 15), amount: 50, expected: 1},
+		{name: "amount over the original", description: "Acme Store", date: day(3, 15), amount: 150},
+		{name: "outside the date window", description: "Acme Store", date: day(3, 1).AddDate(0, 0, refundMatchDays+10), amount: 20},
+		{name: "purchased after the refund", description: "Acme Store", date: day(2, 28), amount: 20},
+		{name: "description mismatch", description: "Another Merchant", date: day(3, 15), amount: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := matchRefund(candidates, tt.description, tt.date, tt.amount)
+			if tt.expected == 0 {
+				assert.Nil(t, match)
+				return
+			}
+			if assert.NotNil(t, match) {
+				assert.Equal(t, tt.expected, match.expense.ID)
+			}
+		})
+	}
+}
+
+func TestRefundRows(t *testing.T) {
+	checking, savings := int32Ptr(1), int32Ptr(2)
+
+	tests := []struct {
+		name       string
+		rows       []ImportRow
+		candidates []*refundCandidate
+		expected   map[int]bool
+	}{
+		{
+			name: "refund of an existing expense",
+			rows: []ImportRow{
+				{Amount: -20, Description: "Acme Store", PurchaseDate: day(3, 15), AccountID: checking},
+			},
+			candidates: []*refundCandidate{candidate(1, "Acme Store", 100, 100, day(3, 1))},
+			expected:   map[int]bool{0: true},
+		},
+		{
+			name: "same-file refund",
+			rows: []ImportRow{
+				{Amount: 100, Description: "Acme Store", PurchaseDate: day(3, 1), AccountID: checking},
+				{Amount: -40, Description: "ACME STORE", PurchaseDate: day(3, 5), AccountID: checking},
+			},
+			expected: map[int]bool{1: true},
+		},
+		{
+			name: "refund before the purchase in the file",
+			rows: []ImportRow{
+				{Amount: -40, Description: "Acme Store", PurchaseDate: day(3, 5), AccountID: checking},
+				{Amount: 100, Description: "Acme Store", PurchaseDate: day(3, 1), AccountID: checking},
+			},
+			expected: map[int]bool{},
+		},
+		{
+			name: "amount over the original",
+			rows: []ImportRow{
+				{Amount: 100, Description: "Acme Store", PurchaseDate: day(3, 1), AccountID: checking},
+				{Amount: -150, Description: "Acme Store", PurchaseDate: day(3, 5), AccountID: checking},
+			},
+			expected: map[int]bool{},
+		},
+		{
+			name: "refunds share what is left",
+			rows: []ImportRow{
+				{Amount: -60, Description: "Acme Store", PurchaseDate: day(3, 15)},
+				{Amount: -60, Description: "Acme Store", PurchaseDate: day(3, 16)},
+			},
+			candidates: []*refundCandidate{candidate(1, "Acme Store", 100, 100, day(3, 1))},
+			expected:   map[int]bool{0: true},
+		},
+		{
+			name: "outside the date window",
+			rows: []ImportRow{
+				{Amount: -20, Description: "Acme Store", PurchaseDate: day(10, 1), AccountID: checking},
+			},
+			candidates: []*refundCandidate{candidate(1, "Acme Store", 100, 100, day(3, 1))},
+			expected:   map[int]bool{},
+		},
+		{
+			name: "description mismatch",
+			rows: []ImportRow{
+				{Amount: 100, Description: "Acme Store", PurchaseDate: day(3, 1), AccountID: checking},
+				{Amount: -40, Description: "Other Shop", PurchaseDate: day(3, 5), AccountID: checking},
+			},
+			expected: map[int]bool{},
+		},
+		{
+			name: "transfer legs on two accounts are not a refund",
+			rows: []ImportRow{
+				{Amount: 500, Description: "Transfer to savings", PurchaseDate: day(3, 1), AccountID: checking},
+				{Amount: -500, Description: "Transfer to savings", PurchaseDate: day(3, 1), AccountID: savings},
+			},
+			expected: map[int]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining := make([]float64, len(tt.candidates))
+			for i, c := range tt.candidates {
+				remaining[i] = c.remaining
+			}
+
+			assert.Equal(t, tt.expected, refundRows(tt.rows, tt.candidates))
+			// The candidates are left for the import itself
+			for i, c := range tt.candidates {
+				assert.Equal(t, remaining[i], c.remaining)
+			}
+		})
+	}
+}
+
+func TestPairTransferLegs(t *testing.T) {
+	checking, savings, card := int32Ptr(1), int32Ptr(2), int32Ptr(3)
+
+	tests := []struct {
+		name     string
+		rows     []ImportRow
+		refunds  map[int]bool
+		expected map[int]int
+	}{
+		{
+			name: "two legs on different accounts",
+			rows: []ImportRow{
+				{Amount: 100, PurchaseDate: day(3, 1), AccountID: checking},
+				{Amount: -100, PurchaseDate: day(3, 2), AccountID: savings},
+			},
+			expected: map[int]int{0: 1, 1: 0},
+		},
+		{
+			name: "legs on the same account are rejected",
+			rows: []ImportRow{
+				{Amount: 100, PurchaseDate: day(3, 1), AccountID: checking},
+				{Amount: -100, PurchaseDate: day(3, 1), AccountID: checking},
+			},
+			expected: map[int]int{},
+		},
+		{
+			name: "rows without an account are rejected",
+			rows: []ImportRow{
+				{Amount: 100, PurchaseDate: day(3, 1), AccountID: checking},
+				{Amount: -100, PurchaseDate: day(3, 1)},
+			},
+			expected: map[int]int{},
+		},
+		{
+			name: "closest of several candidates",
+			rows: []ImportRow{
+				{Amount: 100, PurchaseDate: day(3, 5), AccountID: checking},
+				{Amount: -100, PurchaseDate: day(3, 2), AccountID: savings},
+				{Amount: -100, PurchaseDate: day(3, 4), AccountID: card},
+			},
+			expected: map[int]int{0: 2, 2: 0},
+		},
+		{
+			name: "each incoming leg is paired once",
+			rows: []ImportRow{
+				{Amount: 100, PurchaseDate: day(3, 5), AccountID: checking},
+				{Amount: 100, PurchaseDate: day(3, 5), AccountID: checking},
+				{Amount: -100, PurchaseDate: day(3, 5), AccountID: savings},
+			},
+			expected: map[int]int{0: 2, 2: 0},
+		},
+		{
+			name: "amounts match to the cent",
+			rows: []ImportRow{
+				{Amount: 100.004, PurchaseDate: day(3, 1), AccountID: checking},
+				{Amount: -100, PurchaseDate: day(3, 1), AccountID: savings},
+				{Amount: 50.01, PurchaseDate: day(3, 1), AccountID: checking},
+				{Amount: -50, PurchaseDate: day(3, 1), AccountID: savings},
+			},
+			expected: map[int]int{0: 1, 1: 0},
+		},
+		{
+			name: "legs up to transferMatchDays apart",
+			rows: []ImportRow{
+				{Amount: 100, PurchaseDate: day(3, 1), AccountID: checking},
+				{Amount: -100, PurchaseDate: day(3, 1).AddDate(0, 0, transferMatchDays), AccountID: savings},
+				{Amount: 50, PurchaseDate: day(3, 1), AccountID: checking},
+				{Amount: -50, PurchaseDate: day(3, 1).AddDate(0, 0, transferMatchDays+1), AccountID: savings},
+			},
+			expected: map[int]int{0: 1, 1: 0},
+		},
+		{
+			name: "rows claimed as refunds are excluded",
+			rows: []ImportRow{
+				{Amount: 100, PurchaseDate: day(3, 1), AccountID: checking},
+				{Amount: -100, PurchaseDate: day(3, 1), AccountID: savings},
+			},
+			refunds:  map[int]bool{1: true},
+			expected: map[int]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, pairTransferLegs(tt.rows, tt.refunds))
+		})
+	}
+}
+
+func TestMatchTransfer(t *testing.T) {
+	transfer := func(from, to *int32, amount float64, date time.Time) model.Expense {
+		return model.Expense{Amount: amount, PurchaseDate: date, AccountID: from, TransferAccountID: to, Kind: KindTransfer}
+	}
+	checking, savings := int32Ptr(1), int32Ptr(2)
+	transfers := []model.Expense{
+		transfer(nil, nil, 100, day(3, 1)),
+		transfer(checking, savings, 100, day(3, 1)),
+		transfer(checking, savings, 100, day(3, 10)),
+		transfer(savings, checking, 50, day(3, 1)),
+	}
+
+	tests := []struct {
+		name     string
+		from, to int32
+		amount   float64
+		date     time.Time
+		expected int
+	}{
+		{name: "same accounts, amount and day", from: 1, to: 2, amount: 100, date: day(3, 1), expected: 1},
+		{name: "within transferMatchDays", from: 1, to: 2, amount: 100, date: day(3, 12), expected: 2},
+		{name: "amounts match to the cent", from: 1, to: 2, amount: 100.004, date: day(3, 1), expected: 1},
+		{name: "other direction", from: 2, to: 1, amount: 100, date: day(3, 1), expected: -1},
+		{name: "other amount", from: 1, to: 2, amount: 100.5, date: day(3, 1), expected: -1},
+		{name: "too far apart", from: 1, to: 2, amount: 100, date: day(3, 20), expected: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchTransfer(transfers, tt.from, tt.to, tt.amount, tt.date))
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/insights"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Number of users loaded per page when refreshing everyone's insights.
+const insightRefreshPageSize = 100
+
+// InsightCard is an insight as served by the API, with its JSON columns decoded.
+type InsightCard struct {
+	model.Insight
+	Data   json.RawMessage
+	Filter json.RawMessage
+}
+
+type InsightService interface {
+	ListByUser(ctx context.Context, clerkID string, includeDismissed bool, limit, offset int) ([]InsightCard, error)
+	MarkRead(ctx context.Context, clerkID string, id int32) (*InsightCard, error)
+	Dismiss(ctx context.Context, clerkID string, id int32) (*InsightCard, error)
+	RefreshAll(ctx context.Context) error
+	RefreshForUser(ctx context.Context, user model.User) error
+}
+
+type insightService struct {
+	insightRepo  repositories.InsightRepository
+	expenseRepo  repositories.ExpenseRepository
+	categoryRepo repositories.CategoryRepository
+	userRepo     repositories.UserRepository
+	userService  UserService
+}
+
+func NewInsightService(
+	insightRepo repositories.InsightRepository,
+	expenseRepo repositories.ExpenseRepository,
+	categoryRepo repositories.CategoryRepository,
+	userRepo repositories.UserRepository,
+	userService UserService,
+) InsightService {
+	return &insightService{
+		insightRepo:  insightRepo,
+		expenseRepo:  expenseRepo,
+		categoryRepo: categoryRepo,
+		userRepo:     userRepo,
+		userService:  userService,
+	}
+}
+
+func (s *insightService) ListByUser(ctx context.Context, clerkID string, includeDismissed bool, limit, offset int) ([]InsightCard, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	rows, err := s.insightRepo.ListByUser(ctx, userID, includeDismissed, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	cards := make([]InsightCard, len(rows))
+	for i, row := range rows {
+		cards[i] = toInsightCard(row)
+	}
+	return cards, nil
+}
+
+func (s *insightService) MarkRead(ctx context.Context, clerkID string, id int32) (*InsightCard, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	row, err := s.insightRepo.MarkRead(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	card := toInsightCard(*row)
+	return &card, nil
+}
+
+func (s *insightService) Dismiss(ctx context.Context, clerkID string, id int32) (*InsightCard, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	row, err := s.insightRepo.Dismiss(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	card := toInsightCard(*row)
+	return &card, nil
+}
+
+// RefreshAll recomputes the insights of every user. A failure for one user is
+// logged and does not stop the others.
+func (s *insightService) RefreshAll(ctx context.Context) error {
+	for offset := 0; ; offset += insightRefreshPageSize {
+		users, err := s.userRepo.List(ctx, insightRefreshPageSize, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			if err := s.RefreshForUser(ctx, user); err != nil {
+				logrus.WithError(err).WithField("user_id", user.ID).Error("failed to refresh insights")
+			}
+		}
+
+		if len(users) < insightRefreshPageSize {
+			return nil
+		}
+	}
+}
+
+func (s *insightService) RefreshForUser(ctx context.Context, user model.User) error {
+	now := time.Now().UTC()
+	period := u.Period{
+		From: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -insights.HistoryMonths, 0),
+		To:   now.AddDate(0, 0, 1),
+	}
+
+	expenses, err := s.expenseRepo.ListByUserInPeriod(ctx, user.ID, repositories.KindExpense, period)
+	if err != nil {
+		return err
+	}
+	expenses, err = netSpending(ctx, s.expenseRepo, expenses)
+	if err != nil {
+		return err
+	}
+	categories, err := s.categoryRepo.ListAllByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	input := insights.Input{
+		Now:           now,
+		Locale:        user.Locale,
+		Expenses:      make([]insights.Expense, len(expenses)),
+		CategoryNames: make(map[int32]string, len(categories)),
+	}
+	for i, e := range expenses {
+		input.Expenses[i] = insights.Expense{
+			Amount:       e.Amount,
+			Description:  e.Description,
+			CategoryID:   e.CategoryID,
+			PurchaseDate: e.PurchaseDate,
+		}
+	}
+	for _, c := range categories {
+		input.CategoryNames[c.ID] = c.Name
+	}
+
+	cards := insights.Generate(input)
+	keys := make([]string, 0, len(cards))
+	for _, card := range cards {
+		data, err := json.Marshal(card.Data)
+		if err != nil {
+			return err
+		}
+		filter, err := json.Marshal(card.Filter)
+		if err != nil {
+			return err
+		}
+
+		err = s.insightRepo.Upsert(ctx, &model.Insight{
+			UserID:   user.ID,
+			Type:     string(card.Type),
+			Key:      card.Key,
+			Severity: string(card.Severity),
+			Title:    card.Title,
+			Message:  card.Message,
+			Data:     string(data),
+			Filter:   string(filter),
+		})
+		if err != nil {
+			return err
+		}
+		keys = append(keys, card.Key)
+	}
+
+	return s.insightRepo.DeleteStale(ctx, user.ID, keys)
+}
+
+func toInsightCard(row model.Insight) InsightCard {
+	return InsightCard{
+		Insight: row,
+		Data:    json.RawMessage(row.Data),
+		Filter:  json.RawMessage(row.Filter),
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/merchant"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+// Number of expenses assigned a merchant per query by AssignMissing.
+const merchantAssignBatchSize = 500
+
+type MerchantService interface {
+	ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]model.Merchant, error)
+	Get(ctx context.Context, clerkID string, id int32) (*model.Merchant, error)
+	Rename(ctx context.Context, clerkID string, id int32, name string) (*model.Merchant, error)
+	// AssignMissing assigns merchants to the expenses and income that have
+	// none, such as the ones recorded before merchants existed.
+	AssignMissing(ctx context.Context) error
+}
+
+type merchantService struct {
+	merchantRepo repositories.MerchantRepository
+	expenseRepo  repositories.ExpenseRepository
+	userService  UserService
+}
+
+func NewMerchantService(
+	merchantRepo repositories.MerchantRepository,
+	expenseRepo repositories.ExpenseRepository,
+	userService UserService,
+) MerchantService {
+	return &merchantService{
+		merchantRepo: merchantRepo,
+		expenseRepo:  expenseRepo,
+		userService:  userService,
+	}
+}
+
+func (s *merchantService) ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]model.Merchant, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	return s.merchantRepo.ListByUser(ctx, userID, limit, offset)
+}
+
+func (s *merchantService) Get(ctx context.Context, clerkID string, id int32) (*model.Merchant, error) {
+	return s.getOwned(ctx, clerkID, id)
+}
+
+// Rename changes the display name of a merchant. Its key, and so the
+// descriptions it matches, stay the same.
+func (s *merchantService) Rename(ctx context.Context, clerkID string, id int32, name string) (*model.Merchant, error) {
+	m, err := s.getOwned(ctx, clerkID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.IfMatch(ctx, m.UpdatedAt) {
+		return nil, utils.ErrPreconditionFailed
+	}
+
+	m.Name = strings.TrimSpace(name)
+	return s.merchantRepo.Update(ctx, m)
+}
+
+func (s *merchantService) getOwned(ctx context.Context, clerkID string, id int32) (*model.Merchant, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	m, err := s.merchantRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, utils.ErrNotFound
+	}
+	if m.UserID != userID {
+		return nil, utils.ErrForbidden
+	}
+	return m, nil
+}
+
+func (s *merchantService) AssignMissing(ctx context.Context) error {
+	var afterID int32
+	for {
+		expenses, err := s.expenseRepo.ListWithoutMerchant(ctx, afterID, merchantAssignBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, e := range expenses {
+			afterID = e.ID
+			if err := assignMerchant(ctx, s.merchantRepo, &e); err != nil {
+				return err
+			}
+			// Descriptions without a merchant key are skipped
+			if e.MerchantID == nil {
+				continue
+			}
+			if err := s.expenseRepo.SetMerchant(ctx, e.ID, *e.MerchantID); err != nil {
+				return err
+			}
+		}
+		if len(expenses) < merchantAssignBatchSize {
+			return nil
+		}
+	}
+}
+
+// assignMerchant sets the merchant of an expense or income from its
+// description, creating the merchant for the expense's author when needed.
+func assignMerchant(ctx context.Context, merchantRepo repositories.MerchantRepository, expense *model.Expense) error {
+	target := merchantOf(expense)
+	if target == nil {
+		expense.MerchantID = nil
+		return nil
+	}
+
+	m, err := merchantRepo.EnsureByKey(ctx, expense.UserID, target.Key, target.Name)
+	if err != nil {
+		return err
+	}
+	expense.MerchantID = &m.ID
+	return nil
+}
+
+// merchantOf returns the key and name of the merchant an expense or income
+// belongs to. Transfers and blank descriptions have no merchant.
+func merchantOf(expense *model.Expense) *model.Merchant {
+	key := merchant.Key(expense.Description)
+	if key == "" || (expense.Kind != KindExpense && expense.Kind != KindIncome) {
+		return nil
+	}
+	return &model.Merchant{Key: key, Name: merchant.Name(key)}
+}
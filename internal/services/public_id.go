@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+// Transactions and categories are known to clients by their public IDs only,
+// UUIDs which clients may pick to create rows offline. Serial keys stay
+// internal: requests have their public IDs swapped for serial keys on the way
+// in, and responses their serial keys for public IDs on the way out.
+
+// PublicRefs collects the public IDs a request refers to, along with where
+// their serial keys go, so that they are looked up in a query per table.
+type PublicRefs struct {
+	expenses   map[uuid.UUID][]*int32
+	categories map[uuid.UUID][]*int32
+}
+
+// Expense stores in dst the serial key of the transaction once resolved.
+func (r *PublicRefs) Expense(publicID uuid.UUID, dst *int32) {
+	if r.expenses == nil {
+		r.expenses = map[uuid.UUID][]*int32{}
+	}
+	r.expenses[publicID] = append(r.expenses[publicID], dst)
+}
+
+// Category stores in dst the serial key of the category once resolved.
+func (r *PublicRefs) Category(publicID uuid.UUID, dst *int32) {
+	if r.categories == nil {
+		r.categories = map[uuid.UUID][]*int32{}
+	}
+	r.categories[publicID] = append(r.categories[publicID], dst)
+}
+
+// OptCategory is Category for an optional reference, which leaves dst nil
+// without a public ID.
+func (r *PublicRefs) OptCategory(publicID *uuid.UUID, dst **int32) {
+	if publicID == nil {
+		*dst = nil
+		return
+	}
+	*dst = new(int32)
+	r.Category(*publicID, *dst)
+}
+
+// SerialRefs collects the serial keys of a response, along with where their
+// public IDs go, so that they are looked up in a query per table.
+type SerialRefs struct {
+	expenses   map[int32][]*uuid.UUID
+	categories map[int32][]*uuid.UUID
+}
+
+// Expense stores in dst the public ID of the transaction once published.
+func (r *SerialRefs) Expense(id int32, dst *uuid.UUID) {
+	if r.expenses == nil {
+		r.expenses = map[int32][]*uuid.UUID{}
+	}
+	r.expenses[id] = append(r.expenses[id], dst)
+}
+
+// Category stores in dst the public ID of the category once published.
+func (r *SerialRefs) Category(id int32, dst *uuid.UUID) {
+	if r.categories == nil {
+		r.categories = map[int32][]*uuid.UUID{}
+	}
+	r.categories[id] = append(r.categories[id], dst)
+}
+
+// OptExpense is Expense for an optional reference, which leaves dst nil
+// without a serial key.
+func (r *SerialRefs) OptExpense(id *int32, dst **uuid.UUID) {
+	if id == nil {
+		*dst = nil
+		return
+	}
+	*dst = new(uuid.UUID)
+	r.Expense(*id, *dst)
+}
+
+// OptCategory is Category for an optional reference, which leaves dst nil
+// without a serial key.
+func (r *SerialRefs) OptCategory(id *int32, dst **uuid.UUID) {
+	if id == nil {
+		*dst = nil
+		return
+	}
+	*dst = new(uuid.UUID)
+	r.Category(*id, *dst)
+}
+
+// PublicIDService swaps public IDs and serial keys. It does not check access:
+// the services a request goes to next do.
+type PublicIDService interface {
+	// Resolve stores the serial keys of the public IDs collected. It fails with
+	// ErrNotFound when one of them is unknown.
+	Resolve(ctx context.Context, refs *PublicRefs) error
+	// Publish stores the public IDs of the serial keys collected.
+	Publish(ctx context.Context, refs *SerialRefs) error
+}
+
+type publicIDService struct {
+	expenseRepo  repositories.ExpenseRepository
+	categoryRepo repositories.CategoryRepository
+}
+
+func NewPublicIDService(expenseRepo repositories.ExpenseRepository, categoryRepo repositories.CategoryRepository) PublicIDService {
+	return &publicIDService{
+		expenseRepo:  expenseRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+func (s *publicIDService) Resolve(ctx context.Context, refs *PublicRefs) error {
+	if len(refs.expenses) > 0 {
+		ids, err := s.expenseRepo.IDsByPublicID(ctx, slices.Collect(maps.Keys(refs.expenses)))
+		if err != nil {
+			return err
+		}
+		if err := storeRefs(refs.expenses, ids); err != nil {
+			return err
+		}
+	}
+	if len(refs.categories) > 0 {
+		ids, err := s.categoryRepo.IDsByPublicID(ctx, slices.Collect(maps.Keys(refs.categories)))
+		if err != nil {
+			return err
+		}
+		if err := storeRefs(refs.categories, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *publicIDService) Publish(ctx context.Context, refs *SerialRefs) error {
+	if len(refs.expenses) > 0 {
+		publicIDs, err := s.expenseRepo.PublicIDs(ctx, slices.Collect(maps.Keys(refs.expenses)))
+		if err != nil {
+			return err
+		}
+		// Rows deleted for good meanwhile keep a nil UUID
+		_ = storeRefs(refs.expenses, publicIDs)
+	}
+	if len(refs.categories) > 0 {
+		publicIDs, err := s.categoryRepo.PublicIDs(ctx, slices.Collect(maps.Keys(refs.categories)))
+		if err != nil {
+			return err
+		}
+		_ = storeRefs(refs.categories, publicIDs)
+	}
+	return nil
+}
+
+// storeRefs writes the value of every key to its destinations. It fails with
+// ErrNotFound when a key has no value, after storing the others.
+func storeRefs[K comparable, V any](refs map[K][]*V, values map[K]V) error {
+	var err error
+	for key, dsts := range refs {
+		value, ok := values[key]
+		if !ok {
+			err = u.ErrNotFound
+			continue
+		}
+		for _, dst := range dsts {
+			*dst = value
+		}
+	}
+	return err
+}
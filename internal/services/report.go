@@ -0,0 +1,426 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+// Number of categories flagged as the biggest movers in a comparison.
+const topMoversCount = 3
+
+const uncategorizedName = "Uncategorized"
+
+const unknownMerchantName = "Unknown merchant"
+
+// Optional breakdowns of a summary
+const (
+	GroupByMerchant = "merchant"
+)
+
+// Presence of a category across the two compared periods.
+const (
+	PresenceBoth         = "both"
+	PresenceOnlyCurrent  = "onlyCurrent"
+	PresenceOnlyPrevious = "onlyPrevious"
+)
+
+type CategoryComparison struct {
+	CategoryID   *int32   `json:"categoryId"`
+	CategoryName string   `json:"categoryName"`
+	Current      float64  `json:"current"`
+	Previous     float64  `json:"previous"`
+	Delta        float64  `json:"delta"`
+	DeltaPercent *float64 `json:"deltaPercent"`
+	Presence     string   `json:"presence"`
+	TopMover     bool     `json:"topMover"`
+}
+
+type ComparisonReport struct {
+	Current       u.Period             `json:"current"`
+	Previous      u.Period             `json:"previous"`
+	CurrentTotal  float64              `json:"currentTotal"`
+	PreviousTotal float64              `json:"previousTotal"`
+	Delta         float64              `json:"delta"`
+	DeltaPercent  *float64             `json:"deltaPercent"`
+	Categories    []CategoryComparison `json:"categories"`
+}
+
+type MonthlyCashFlow struct {
+	Month    string  `json:"month"`
+	Income   float64 `json:"income"`
+	Expenses float64 `json:"expenses"`
+	Net      float64 `json:"net"`
+	// Share of the income that was not spent, nil without income
+	SavingsRate *float64 `json:"savingsRate"`
+}
+
+type CashFlowReport struct {
+	Period   u.Period          `json:"period"`
+	Months   []MonthlyCashFlow `json:"months"`
+	Income   float64           `json:"income"`
+	Expenses float64           `json:"expenses"`
+	Net      float64           `json:"net"`
+	// Share of the income that was not spent over the whole period, nil without income
+	SavingsRate *float64 `json:"savingsRate"`
+}
+
+type CategorySummary struct {
+	CategoryID   *int32  `json:"categoryId"`
+	CategoryName string  `json:"categoryName"`
+	Total        float64 `json:"total"`
+	Count        int64   `json:"count"`
+	// Percentage of the period total, nil when nothing was spent
+	Share *float64 `json:"share"`
+}
+
+type MemberSummary struct {
+	UserID    uuid.UUID `json:"userId"`
+	Email     string    `json:"email"`
+	FirstName *string   `json:"firstName"`
+	LastName  *string   `json:"lastName"`
+	Total     float64   `json:"total"`
+	Count     int64     `json:"count"`
+}
+
+type MerchantSummary struct {
+	MerchantID   *int32  `json:"merchantId"`
+	MerchantName string  `json:"merchantName"`
+	Total        float64 `json:"total"`
+	Count        int64   `json:"count"`
+}
+
+type TagSummary struct {
+	TagID   int32   `json:"tagId"`
+	TagName string  `json:"tagName"`
+	Total   float64 `json:"total"`
+	Count   int64   `json:"count"`
+}
+
+// SummaryReport breaks down the spending of a period. An expense counts toward
+// each of its tags, so tag totals may add up to more than the period total.
+type SummaryReport struct {
+	Period     u.Period          `json:"period"`
+	Total      float64           `json:"total"`
+	Count      int64             `json:"count"`
+	Categories []CategorySummary `json:"categories"`
+	Tags       []TagSummary      `json:"tags"`
+	// Spending per member who entered it, only for workspaces
+	Members []MemberSummary `json:"members,omitempty"`
+	// Spending per merchant, only when grouping by merchant
+	Merchants []MerchantSummary `json:"merchants,omitempty"`
+}
+
+type ReportService interface {
+	// Summary breaks down spending by category and tag, and additionally by
+	// groupBy when it is not empty.
+	Summary(ctx context.Context, clerkID string, workspaceID *int32, period u.Period, groupBy string) (*SummaryReport, error)
+	Compare(ctx context.Context, clerkID string, workspaceID *int32, current, previous u.Period) (*ComparisonReport, error)
+	CashFlow(ctx context.Context, clerkID string, workspaceID *int32, period u.Period) (*CashFlowReport, error)
+}
+
+type reportService struct {
+	reportRepo       repositories.ReportRepository
+	userService      UserService
+	workspaceService WorkspaceService
+}
+
+func NewReportService(reportRepo repositories.ReportRepository, userService UserService, workspaceService WorkspaceService) ReportService {
+	return &reportService{
+		reportRepo:       reportRepo,
+		userService:      userService,
+		workspaceService: workspaceService,
+	}
+}
+
+// scope resolves the ledger a report reads from.
+func (s *reportService) scope(ctx context.Context, clerkID string, workspaceID *int32) (repositories.Scope, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return repositories.Scope{}, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	return s.workspaceService.Scope(ctx, userID, workspaceID, RoleViewer)
+}
+
+func (s *reportService) Summary(ctx context.Context, clerkID string, workspaceID *int32, period u.Period, groupBy string) (*SummaryReport, error) {
+	scope, err := s.scope(ctx, clerkID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryTotals, err := s.reportRepo.CategoryTotals(ctx, scope, period)
+	if err != nil {
+		return nil, err
+	}
+	tagTotals, err := s.reportRepo.TagTotals(ctx, scope, period)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SummaryReport{
+		Period:     period,
+		Categories: make([]CategorySummary, 0, len(categoryTotals)),
+		Tags:       make([]TagSummary, 0, len(tagTotals)),
+	}
+	for _, t := range categoryTotals {
+		report.Total += t.Total
+		report.Count += t.Count
+	}
+	report.Total = roundCents(report.Total)
+
+	for _, t := range categoryTotals {
+		name := uncategorizedName
+		if t.CategoryName != nil {
+			name = *t.CategoryName
+		}
+		report.Categories = append(report.Categories, CategorySummary{
+			CategoryID:   t.CategoryID,
+			CategoryName: name,
+			Total:        roundCents(t.Total),
+			Count:        t.Count,
+			Share:        share(t.Total, report.Total),
+		})
+	}
+	sort.SliceStable(report.Categories, func(i, j int) bool {
+		return report.Categories[i].Total > report.Categories[j].Total
+	})
+
+	for _, t := range tagTotals {
+		report.Tags = append(report.Tags, TagSummary{
+			TagID:   t.TagID,
+			TagName: t.TagName,
+			Total:   roundCents(t.Total),
+			Count:   t.Count,
+		})
+	}
+
+	if scope.WorkspaceID != nil {
+		memberTotals, err := s.reportRepo.MemberTotals(ctx, scope, period)
+		if err != nil {
+			return nil, err
+		}
+		report.Members = make([]MemberSummary, 0, len(memberTotals))
+		for _, t := range memberTotals {
+			report.Members = append(report.Members, MemberSummary{
+				UserID:    t.UserID,
+				Email:     t.Email,
+				FirstName: t.FirstName,
+				LastName:  t.LastName,
+				Total:     roundCents(t.Total),
+				Count:     t.Count,
+			})
+		}
+	}
+
+	if groupBy == GroupByMerchant {
+		merchantTotals, err := s.reportRepo.MerchantTotals(ctx, scope, period)
+		if err != nil {
+			return nil, err
+		}
+		report.Merchants = make([]MerchantSummary, 0, len(merchantTotals))
+		for _, t := range merchantTotals {
+			name := unknownMerchantName
+			if t.MerchantName != nil {
+				name = *t.MerchantName
+			}
+			report.Merchants = append(report.Merchants, MerchantSummary{
+				MerchantID:   t.MerchantID,
+				MerchantName: name,
+				Total:        roundCents(t.Total),
+				Count:        t.Count,
+			})
+		}
+	}
+
+	return report, nil
+}
+
+func (s *reportService) Compare(ctx context.Context, clerkID string, workspaceID *int32, current, previous u.Period) (*ComparisonReport, error) {
+	scope, err := s.scope(ctx, clerkID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTotals, err := s.reportRepo.CategoryTotals(ctx, scope, current)
+	if err != nil {
+		return nil, err
+	}
+	previousTotals, err := s.reportRepo.CategoryTotals(ctx, scope, previous)
+	if err != nil {
+		return nil, err
+	}
+
+	report := compareCategoryTotals(currentTotals, previousTotals)
+	report.Current = current
+	report.Previous = previous
+	return report, nil
+}
+
+// CashFlow reports income, expenses, net cash flow and savings rate per calendar month.
+// The period must start on the first day of a month.
+func (s *reportService) CashFlow(ctx context.Context, clerkID string, workspaceID *int32, period u.Period) (*CashFlowReport, error) {
+	scope, err := s.scope(ctx, clerkID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.reportRepo.MonthlyTotals(ctx, scope, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return cashFlowReport(period, totals), nil
+}
+
+// cashFlowReport buckets the monthly totals into every month of the period,
+// including months without any transactions.
+func cashFlowReport(period u.Period, totals []repositories.MonthlyTotal) *CashFlowReport {
+	byMonth := map[string]*MonthlyCashFlow{}
+	report := &CashFlowReport{Period: period, Months: []MonthlyCashFlow{}}
+	for month := period.From; month.Before(period.To); month = month.AddDate(0, 1, 0) {
+		report.Months = append(report.Months, MonthlyCashFlow{Month: month.Format("2006-01")})
+	}
+	for i := range report.Months {
+		byMonth[report.Months[i].Month] = &report.Months[i]
+	}
+
+	for _, t := range totals {
+		m, ok := byMonth[t.Month.Format("2006-01")]
+		if !ok {
+			continue
+		}
+		switch t.Kind {
+		case repositories.KindIncome:
+			m.Income += t.Total
+		case repositories.KindExpense:
+			m.Expenses += t.Total
+		}
+	}
+
+	for i := range report.Months {
+		m := &report.Months[i]
+		m.Income = roundCents(m.Income)
+		m.Expenses = roundCents(m.Expenses)
+		m.Net = roundCents(m.Income - m.Expenses)
+		m.SavingsRate = savingsRate(m.Income, m.Expenses)
+		report.Income += m.Income
+		report.Expenses += m.Expenses
+	}
+	report.Income = roundCents(report.Income)
+	report.Expenses = roundCents(report.Expenses)
+	report.Net = roundCents(report.Income - report.Expenses)
+	report.SavingsRate = savingsRate(report.Income, report.Expenses)
+
+	return report
+}
+
+// savingsRate returns the percentage of income left after expenses.
+func savingsRate(income, expenses float64) *float64 {
+	if income == 0 {
+		return nil
+	}
+	rate := math.Round((income-expenses)/income*10000) / 100
+	return &rate
+}
+
+// compareCategoryTotals matches the categories of both periods and computes
+// their deltas, sorted by the absolute size of the change.
+func compareCategoryTotals(current, previous []repositories.CategoryTotal) *ComparisonReport {
+	type key struct {
+		id    int32
+		valid bool
+	}
+	keyOf := func(t repositories.CategoryTotal) key {
+		if t.CategoryID == nil {
+			return key{}
+		}
+		return key{id: *t.CategoryID, valid: true}
+	}
+
+	rows := map[key]*CategoryComparison{}
+	order := []key{}
+	row := func(t repositories.CategoryTotal) *CategoryComparison {
+		k := keyOf(t)
+		if c, ok := rows[k]; ok {
+			return c
+		}
+		name := uncategorizedName
+		if t.CategoryName != nil {
+			name = *t.CategoryName
+		}
+		c := &CategoryComparison{CategoryID: t.CategoryID, CategoryName: name}
+		rows[k] = c
+		order = append(order, k)
+		return c
+	}
+
+	report := &ComparisonReport{}
+	for _, t := range current {
+		row(t).Current += t.Total
+		report.CurrentTotal += t.Total
+	}
+	for _, t := range previous {
+		row(t).Previous += t.Total
+		report.PreviousTotal += t.Total
+	}
+
+	report.Categories = make([]CategoryComparison, 0, len(order))
+	for _, k := range order {
+		c := rows[k]
+		c.Current = roundCents(c.Current)
+		c.Previous = roundCents(c.Previous)
+		c.Delta = roundCents(c.Current - c.Previous)
+		c.DeltaPercent = percentChange(c.Current, c.Previous)
+		switch {
+		case c.Previous == 0:
+			c.Presence = PresenceOnlyCurrent
+		case c.Current == 0:
+			c.Presence = PresenceOnlyPrevious
+		default:
+			c.Presence = PresenceBoth
+		}
+		report.Categories = append(report.Categories, *c)
+	}
+
+	sort.SliceStable(report.Categories, func(i, j int) bool {
+		return math.Abs(report.Categories[i].Delta) > math.Abs(report.Categories[j].Delta)
+	})
+	for i := 0; i < len(report.Categories) && i < topMoversCount; i++ {
+		if report.Categories[i].Delta != 0 {
+			report.Categories[i].TopMover = true
+		}
+	}
+
+	report.CurrentTotal = roundCents(report.CurrentTotal)
+	report.PreviousTotal = roundCents(report.PreviousTotal)
+	report.Delta = roundCents(report.CurrentTotal - report.PreviousTotal)
+	report.DeltaPercent = percentChange(report.CurrentTotal, report.PreviousTotal)
+	return report
+}
+
+// share returns the percentage of total that part represents, nil when total is zero.
+func share(part, total float64) *float64 {
+	if total == 0 {
+		return nil
+	}
+	p := math.Round(part/total*10000) / 100
+	return &p
+}
+
+// percentChange returns nil when there is no baseline to compare against.
+func percentChange(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	p := math.Round((current-previous)/previous*10000) / 100
+	return &p
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
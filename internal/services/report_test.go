@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func categoryTotal(id int32, name string, total float64) repositories.CategoryTotal {
+	return repositories.CategoryTotal{CategoryID: &id, CategoryName: &name, Total: total}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+func TestCompareCategoryTotals(t *testing.T) {
+	tests := []struct {
+		name          string
+		current       []repositories.CategoryTotal
+		previous      []repositories.CategoryTotal
+		expected      []CategoryComparison
+		delta         float64
+		deltaPercent  *float64
+		currentTotal  float64
+		previousTotal float64
+	}{
+		{
+			name: "deltas, presence and uncategorized",
+			current: []repositories.CategoryTotal{
+				categoryTotal(1, "Food", 150),
+				categoryTotal(2, "Rent", 1000),
+				{Total: 30},
+			},
+			previous: []repositories.CategoryTotal{
+				categoryTotal(1, "Food", 100),
+				categoryTotal(3, "Travel", 200),
+				{Total: 30},
+			},
+			expected: []CategoryComparison{
+				{CategoryID: int32Ptr(2), CategoryName: "Rent", Current: 1000, Delta: 1000, Presence: PresenceOnlyCurrent, TopMover: true},
+				{CategoryID: int32Ptr(3), CategoryName: "Travel", Previous: 200, Delta: -200, DeltaPercent: floatPtr(-100), Presence: PresenceOnlyPrevious, TopMover: true},
+				{CategoryID: int32Ptr(1), CategoryName: "Food", Current: 150, Previous: 100, Delta: 50, DeltaPercent: floatPtr(50), Presence: PresenceBoth, TopMover: true},
+				{CategoryName: uncategorizedName, Current: 30, Previous: 30, Delta: 0, DeltaPercent: floatPtr(0), Presence: PresenceBoth},
+			},
+			currentTotal:  1180,
+			previousTotal: 330,
+			delta:         850,
+			deltaPercent:  floatPtr(257.58),
+		},
+		{
+			name: "only the biggest changes are top movers, ties keep their order",
+			current: []repositories.CategoryTotal{
+				categoryTotal(1, "A", 10),
+				categoryTotal(2, "B", 40),
+				categoryTotal(3, "C", 10),
+				categoryTotal(4, "D", 5),
+			},
+			expected: []CategoryComparison{
+				{CategoryID: int32Ptr(2), CategoryName: "B", Current: 40, Delta: 40, Presence: PresenceOnlyCurrent, TopMover: true},
+				{CategoryID: int32Ptr(1), CategoryName: "A", Current: 10, Delta: 10, Presence: PresenceOnlyCurrent, TopMover: true},
+				{CategoryID: int32Ptr(3), CategoryName: "C", Current: 10, Delta: 10, Presence: PresenceOnlyCurrent, TopMover: true},
+				{CategoryID: int32Ptr(4), CategoryName: "D", Current: 5, Delta: 5, Presence: PresenceOnlyCurrent},
+			},
+			currentTotal: 65,
+			delta:        65,
+		},
+		{
+			name:     "no spending",
+			expected: []CategoryComparison{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := compareCategoryTotals(tt.current, tt.previous)
+			assert.Equal(t, tt.expected, report.Categories)
+			assert.Equal(t, tt.currentTotal, report.CurrentTotal)
+			assert.Equal(t, tt.previousTotal, report.PreviousTotal)
+			assert.Equal(t, tt.delta, report.Delta)
+			assert.Equal(t, tt.deltaPercent, report.DeltaPercent)
+		})
+	}
+}
+
+func TestSavingsRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		income   float64
+		expenses float64
+		expected *float64
+	}{
+		{name: "no income", income: 0, expenses: 120, expected: nil},
+		{name: "no income and no expenses", income: 0, expenses: 0, expected: nil},
+		{name: "positive net", income: 3000, expenses: 2250, expected: floatPtr(25)},
+		{name: "negative net", income: 1000, expenses: 1500, expected: floatPtr(-50)},
+		{name: "rounded to two decimals", income: 3, expenses: 2, expected: floatPtr(33.33)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, savingsRate(tt.income, tt.expenses))
+		})
+	}
+}
+
+func TestCashFlowReport(t *testing.T) {
+	month := func(m time.Month) time.Time {
+		return time.Date(2026, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+	period := u.Period{From: month(1), To: month(4)}
+
+	tests := []struct {
+		name     string
+		totals   []repositories.MonthlyTotal
+		expected *CashFlowReport
+	}{
+		{
+			name: "months without rows still appear",
+			totals: []repositories.MonthlyTotal{
+				{Month: month(1), Kind: repositories.KindIncome, Total: 3000},
+				{Month: month(1), Kind: repositories.KindExpense, Total: 2250.104},
+				{Month: month(3), Kind: repositories.KindExpense, Total: 500},
+			},
+			expected: &CashFlowReport{
+				Period: period,
+				Months: []MonthlyCashFlow{
+					{Month: "2026-01", Income: 3000, Expenses: 2250.1, Net: 749.9, SavingsRate: floatPtr(25)},
+					{Month: "2026-02"},
+					{Month: "2026-03", Expenses: 500, Net: -500},
+				},
+				Income:      3000,
+				Expenses:    2750.1,
+				Net:         249.9,
+				SavingsRate: floatPtr(8.33),
+			},
+		},
+		{
+			name: "negative net",
+			totals: []repositories.MonthlyTotal{
+				{Month: month(2), Kind: repositories.KindIncome, Total: 1000},
+				{Month: month(2), Kind: repositories.KindExpense, Total: 1500},
+			},
+			expected: &CashFlowReport{
+				Period: period,
+				Months: []MonthlyCashFlow{
+					{Month: "2026-01"},
+					{Month: "2026-02", Income: 1000, Expenses: 1500, Net: -500, SavingsRate: floatPtr(-50)},
+					{Month: "2026-03"},
+				},
+				Income:      1000,
+				Expenses:    1500,
+				Net:         -500,
+				SavingsRate: floatPtr(-50),
+			},
+		},
+		{
+			name: "rows outside the period are ignored",
+			totals: []repositories.MonthlyTotal{
+				{Month: month(4), Kind: repositories.KindIncome, Total: 1000},
+			},
+			expected: &CashFlowReport{
+				Period: period,
+				Months: []MonthlyCashFlow{
+					{Month: "2026-01"},
+					{Month: "2026-02"},
+					{Month: "2026-03"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, cashFlowReport(period, tt.totals))
+		})
+	}
+}
+
+func TestNetSpendingSplitLines(t *testing.T) {
+	expense := func(id int32, amount float64) model.Expense {
+		return model.Expense{ID: id, Kind: KindExpense, Amount: amount, CategoryID: int32Ptr(1)}
+	}
+	split := func(categoryID int32, amount float64) model.ExpenseSplit {
+		return model.ExpenseSplit{CategoryID: categoryID, Amount: amount}
+	}
+	expenseRepo := &fakeExpenseRepository{
+		splits: map[int32][]model.ExpenseSplit{
+			2: {split(10, 60), split(20, 40)},
+			3: {split(10, 60), split(20, 40)},
+			4: {split(10, 60), split(20, 40)},
+			5: {split(10, 60), split(20, 40)},
+		},
+		refunded: map[int32]float64{3: 50, 5: 100},
+		shares: map[int32][]model.ExpenseShare{
+			4: {{Amount: 25}, {ContactID: int32Ptr(1), Amount: 75}},
+		},
+	}
+
+	net, err := netSpending(context.Background(), expenseRepo, []model.Expense{
+		expense(1, 100),
+		expense(2, 100),
+		expense(3, 100),
+		expense(4, 100),
+		expense(5, 100),
+	})
+	require.NoError(t, err)
+
+	type line struct {
+		id         int32
+		categoryID int32
+		amount     float64
+	}
+	lines := make([]line, len(net))
+	for i, e := range net {
+		lines[i] = line{e.ID, *e.CategoryID, roundCents(e.Amount)}
+	}
+	assert.Equal(t, []line{
+		// Transactions without split lines keep their category
+		{1, 1, 100},
+		// Split lines carry their own categories and amounts
+		{2, 10, 60},
+		{2, 20, 40},
+		// Refunds are taken from every line in proportion
+		{3, 10, 30},
+		{3, 20, 20},
+		// Shared transactions only count the user's share of every line
+		{4, 10, 15},
+		{4, 20, 10},
+		// Fully refunded transactions are dropped
+	}, lines)
+}
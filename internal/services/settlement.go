@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/settle"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+// PartyBalance is what a party of shared bills is owed, when positive, or
+// owes, when negative. A nil contact is the user.
+type PartyBalance struct {
+	ContactID *int32  `json:"contactId"`
+	Balance   float64 `json:"balance"`
+}
+
+// Debt is a payment that settles up balances. A nil contact is the user.
+type Debt struct {
+	FromContactID *int32  `json:"fromContactId"`
+	ToContactID   *int32  `json:"toContactId"`
+	Amount        float64 `json:"amount"`
+}
+
+type BalanceReport struct {
+	Balances []PartyBalance `json:"balances"`
+	// Fewest payments that settle every balance
+	Debts []Debt `json:"debts"`
+}
+
+type SettlementService interface {
+	ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]model.Settlement, error)
+	Create(ctx context.Context, clerkID string, settlement *model.Settlement) (*model.Settlement, error)
+	Delete(ctx context.Context, clerkID string, id int32) error
+	Balances(ctx context.Context, clerkID string) (*BalanceReport, error)
+}
+
+type settlementService struct {
+	settlementRepo repositories.SettlementRepository
+	contactRepo    repositories.ContactRepository
+	expenseRepo    repositories.ExpenseRepository
+	userService    UserService
+}
+
+func NewSettlementService(
+	settlementRepo repositories.SettlementRepository,
+	contactRepo repositories.ContactRepository,
+	expenseRepo repositories.ExpenseRepository,
+	userService UserService,
+) SettlementService {
+	return &settlementService{
+		settlementRepo: settlementRepo,
+		contactRepo:    contactRepo,
+		expenseRepo:    expenseRepo,
+		userService:    userService,
+	}
+}
+
+func (s *settlementService) ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]model.Settlement, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	return s.settlementRepo.ListByUser(ctx, userID, limit, offset)
+}
+
+// Create records a payment between two parties of shared bills, either of
+// which can be the user.
+func (s *settlementService) Create(ctx context.Context, clerkID string, settlement *model.Settlement) (*model.Settlement, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	settlement.UserID = userID
+
+	if party(settlement.FromContactID) == party(settlement.ToContactID) {
+		return nil, utils.ErrInvalidSettlement
+	}
+	for _, contactID := range []*int32{settlement.FromContactID, settlement.ToContactID} {
+		if contactID == nil {
+			continue
+		}
+		if err := checkContactOwner(ctx, s.contactRepo, userID, *contactID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.settlementRepo.Create(ctx, settlement)
+}
+
+func (s *settlementService) Delete(ctx context.Context, clerkID string, id int32) error {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	settlement, err := s.settlementRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if settlement == nil {
+		return utils.ErrNotFound
+	}
+	if settlement.UserID != userID {
+		return utils.ErrForbidden
+	}
+	if !utils.IfMatch(ctx, settlement.UpdatedAt) {
+		return utils.ErrPreconditionFailed
+	}
+	return s.settlementRepo.Delete(ctx, id, settlement.UpdatedAt)
+}
+
+// Balances adds up the user's shared expenses and settlements into what each
+// party owes and the simplified payments that settle up.
+func (s *settlementService) Balances(ctx context.Context, clerkID string) (*BalanceReport, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	ledger, err := s.ledger(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BalanceReport{Balances: []PartyBalance{}, Debts: []Debt{}}
+	balances := ledger.Balances()
+	for _, b := range balances {
+		report.Balances = append(report.Balances, PartyBalance{
+			ContactID: contactOf(b.Party),
+			Balance:   float64(b.Amount) / 100,
+		})
+	}
+	for _, t := range settle.Simplify(balances) {
+		report.Debts = append(report.Debts, Debt{
+			FromContactID: contactOf(t.From),
+			ToContactID:   contactOf(t.To),
+			Amount:        float64(t.Amount) / 100,
+		})
+	}
+	return report, nil
+}
+
+func (s *settlementService) ledger(ctx context.Context, userID uuid.UUID) (*settle.Ledger, error) {
+	expenses, err := s.expenseRepo.ListShared(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int32, len(expenses))
+	for i, e := range expenses {
+		ids[i] = e.ID
+	}
+	shares, err := s.expenseRepo.SharesByExpense(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	settlements, err := s.settlementRepo.ListAllByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := settle.NewLedger()
+	for _, e := range expenses {
+		amounts := map[int32]int64{}
+		for _, share := range shares[e.ID] {
+			amounts[party(share.ContactID)] += toCents(share.Amount)
+		}
+		ledger.Bill(party(e.PaidByContactID), amounts)
+	}
+	for _, settlement := range settlements {
+		ledger.Settle(party(settlement.FromContactID), party(settlement.ToContactID), toCents(settlement.Amount))
+	}
+	return ledger, nil
+}
+
+// party maps a contact id to a settle party, nil being the user.
+func party(contactID *int32) int32 {
+	if contactID == nil {
+		return settle.Me
+	}
+	return *contactID
+}
+
+// contactOf maps a settle party back to a contact id.
+func contactOf(party int32) *int32 {
+	if party == settle.Me {
+		return nil
+	}
+	return &party
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
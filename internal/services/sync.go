@@ -0,0 +1,499 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/synctoken"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Synced entities. Expenses include every kind of transaction.
+const (
+	SyncExpense  = "expense"
+	SyncCategory = "category"
+	SyncTag      = "tag"
+	SyncAccount  = "account"
+	SyncMerchant = "merchant"
+	SyncContact  = "contact"
+)
+
+// Operations pushed by clients
+const (
+	SyncCreate = "create"
+	SyncUpdate = "update"
+	SyncDelete = "delete"
+)
+
+// Outcomes of pushed changes
+const (
+	SyncApplied = "applied"
+	// The row was changed or deleted since the client read it
+	SyncConflict = "conflict"
+	SyncFailed   = "failed"
+)
+
+// SyncDeletion is a row deleted, or moved to the trash, since the last sync.
+type SyncDeletion struct {
+	Entity string
+	ID     int32
+	// Public ID of transactions and categories
+	PublicID uuid.UUID
+}
+
+// SyncDelta is what changed since the last sync: the current state of the
+// rows created or updated, and the rows deleted.
+type SyncDelta struct {
+	// Token to send on the next sync
+	Token      string
+	Expenses   []ExpenseDetails
+	Categories []model.Category
+	Tags       []model.Tag
+	Accounts   []model.Account
+	Merchants  []model.Merchant
+	Contacts   []model.Contact
+	Deleted    []SyncDeletion
+}
+
+// SyncChange is a change made by a client while offline. Expenses and income
+// are created, updated and deleted, categories created and deleted, and tags
+// created, renamed and deleted.
+type SyncChange struct {
+	Entity    string
+	Operation string
+	// Tag updated or deleted
+	ID int32
+	// Transaction or category updated or deleted, or the public ID picked for
+	// the one created
+	PublicID uuid.UUID
+	// ETag of the version of the row the client changed, required by updates
+	// and deletes
+	IfMatch string
+	// Transaction to create or update, of kind expense or income. Deletes
+	// only need its kind.
+	Expense model.Expense
+	Lines   ExpenseLines
+	// Public IDs of the category of the transaction and of the categories of
+	// its split lines, in order. They are swapped for serial keys as the
+	// change is applied, so that they may refer to categories created earlier
+	// in the same push.
+	CategoryID       *uuid.UUID
+	SplitCategoryIDs []uuid.UUID
+	// Category to create
+	Category model.Category
+	// Name of the tag to create or rename
+	TagName string
+}
+
+// SyncPush is a batch of changes made by a client while offline.
+type SyncPush struct {
+	// Ledger the transactions and categories are created in, the personal one when nil
+	WorkspaceID *int32
+	Changes     []SyncChange
+}
+
+// SyncResult reports the outcome of a pushed change, indexed in the order of
+// the push. Applied changes carry the row as written, conflicts the current
+// row, unless it was deleted.
+type SyncResult struct {
+	Index int   `json:"index"`
+	ID    int32 `json:"id,omitempty"`
+	// Public ID of transactions and categories, served in place of ID
+	PublicID uuid.UUID `json:"-"`
+	Status   string    `json:"status"`
+	ETag     string    `json:"etag,omitempty"`
+	Entity   any       `json:"entity,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// SyncService lets offline clients catch up with the server and send the
+// changes they made meanwhile.
+type SyncService interface {
+	// Pull returns what changed in a ledger, and in the user's own tags,
+	// accounts, merchants and contacts, since the token was issued. Without a
+	// token, it returns everything there is.
+	Pull(ctx context.Context, clerkID string, workspaceID *int32, token string) (*SyncDelta, error)
+	// Push applies changes one after the other, each on its own, and reports
+	// the outcome of every one.
+	Push(ctx context.Context, clerkID string, push SyncPush) ([]SyncResult, error)
+	// PurgeTombstones forgets the deletions older than the retention period,
+	// which sync tokens outlive.
+	PurgeTombstones(ctx context.Context) error
+}
+
+type syncService struct {
+	syncRepo         repositories.SyncRepository
+	expenseRepo      repositories.ExpenseRepository
+	tagRepo          repositories.TagRepository
+	userService      UserService
+	workspaceService WorkspaceService
+	expenseService   ExpenseService
+	categoryService  CategoryService
+	tagService       TagService
+	publicIDService  PublicIDService
+	retention        time.Duration
+}
+
+func NewSyncService(
+	syncRepo repositories.SyncRepository,
+	expenseRepo repositories.ExpenseRepository,
+	tagRepo repositories.TagRepository,
+	userService UserService,
+	workspaceService WorkspaceService,
+	expenseService ExpenseService,
+	categoryService CategoryService,
+	tagService TagService,
+	publicIDService PublicIDService,
+	retention time.Duration,
+) SyncService {
+	return &syncService{
+		syncRepo:         syncRepo,
+		expenseRepo:      expenseRepo,
+		tagRepo:          tagRepo,
+		userService:      userService,
+		workspaceService: workspaceService,
+		expenseService:   expenseService,
+		categoryService:  categoryService,
+		tagService:       tagService,
+		publicIDService:  publicIDService,
+		retention:        retention,
+	}
+}
+
+func (s *syncService) Pull(ctx context.Context, clerkID string, workspaceID *int32, token string) (*SyncDelta, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	scope, err := s.workspaceService.Scope(ctx, userID, workspaceID, RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	var since int64
+	if token != "" {
+		decoded, err := synctoken.Decode(token)
+		if err != nil {
+			return nil, utils.ErrInvalidSyncToken
+		}
+		// Business Logic: Deletions older than the retention period are forgotten
+		if decoded.Expired(now, s.retention) {
+			return nil, utils.ErrSyncTokenExpired
+		}
+		since = decoded.ChangeID
+	}
+
+	changes, err := s.syncRepo.Changes(ctx, scope, since)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := &SyncDelta{
+		Token:      synctoken.Token{ChangeID: changes.Next, IssuedAt: now}.Encode(),
+		Categories: []model.Category{},
+		Tags:       changes.Tags,
+		Accounts:   changes.Accounts,
+		Merchants:  changes.Merchants,
+		Contacts:   changes.Contacts,
+		Deleted:    []SyncDeletion{},
+	}
+
+	// Rows in the trash are deleted as far as clients are concerned
+	var expenses []model.Expense
+	for _, expense := range changes.Expenses {
+		if expense.DeletedAt != nil {
+			delta.Deleted = append(delta.Deleted, SyncDeletion{Entity: SyncExpense, ID: expense.ID, PublicID: expense.PublicID})
+			continue
+		}
+		expenses = append(expenses, expense)
+	}
+	for _, category := range changes.Categories {
+		if category.DeletedAt != nil {
+			delta.Deleted = append(delta.Deleted, SyncDeletion{Entity: SyncCategory, ID: category.ID, PublicID: category.PublicID})
+			continue
+		}
+		delta.Categories = append(delta.Categories, category)
+	}
+	for _, tombstone := range changes.Tombstones {
+		deletion := SyncDeletion{Entity: tombstone.EntityType, ID: tombstone.EntityID}
+		if tombstone.EntityPublicID != nil {
+			deletion.PublicID = *tombstone.EntityPublicID
+		}
+		delta.Deleted = append(delta.Deleted, deletion)
+	}
+
+	delta.Expenses, err = expenseDetails(ctx, s.expenseRepo, s.tagRepo, expenses)
+	if err != nil {
+		return nil, err
+	}
+
+	if delta.Tags == nil {
+		delta.Tags = []model.Tag{}
+	}
+	if delta.Accounts == nil {
+		delta.Accounts = []model.Account{}
+	}
+	if delta.Merchants == nil {
+		delta.Merchants = []model.Merchant{}
+	}
+	if delta.Contacts == nil {
+		delta.Contacts = []model.Contact{}
+	}
+	return delta, nil
+}
+
+// Push checks the ledger once, then goes through the services of every
+// entity, so that pushed changes are validated like any other. A change
+// failing does not stop the ones after it.
+func (s *syncService) Push(ctx context.Context, clerkID string, push SyncPush) ([]SyncResult, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	if _, err := s.workspaceService.Scope(ctx, userID, push.WorkspaceID, RoleEditor); err != nil {
+		return nil, err
+	}
+
+	results := make([]SyncResult, len(push.Changes))
+	for i, change := range push.Changes {
+		changeCtx := ctx
+		if change.Operation != SyncCreate {
+			// Business Logic: Offline edits must say which version they apply to
+			if change.IfMatch == "" {
+				results[i] = failedChange(utils.ErrPreconditionRequired)
+				results[i].Index, results[i].ID, results[i].PublicID = i, change.ID, change.PublicID
+				continue
+			}
+			changeCtx = utils.WithIfMatch(ctx, change.IfMatch)
+		}
+
+		switch change.Entity {
+		case SyncExpense:
+			results[i] = s.pushExpense(changeCtx, clerkID, push.WorkspaceID, change)
+		case SyncCategory:
+			results[i] = s.pushCategory(changeCtx, clerkID, push.WorkspaceID, change)
+		case SyncTag:
+			results[i] = s.pushTag(changeCtx, clerkID, change)
+		default:
+			results[i] = failedChange(utils.ErrSyncUnsupported)
+		}
+		results[i].Index = i
+		if results[i].ID == 0 {
+			results[i].ID = change.ID
+		}
+		if results[i].PublicID == uuid.Nil {
+			results[i].PublicID = change.PublicID
+		}
+	}
+	return results, nil
+}
+
+func (s *syncService) pushExpense(ctx context.Context, clerkID string, workspaceID *int32, change SyncChange) SyncResult {
+	kind := change.Expense.Kind
+	if kind != KindExpense && kind != KindIncome {
+		return failedChange(utils.ErrSyncUnsupported)
+	}
+	current := func() (any, time.Time, error) {
+		id, err := s.serialID(ctx, SyncExpense, change.PublicID)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		details, err := s.expenseService.Get(ctx, clerkID, kind, id)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		return details, details.UpdatedAt, nil
+	}
+
+	switch change.Operation {
+	case SyncCreate:
+		expense, lines, err := s.expenseInput(ctx, change)
+		if err != nil {
+			return failedChange(err)
+		}
+		expense.PublicID = change.PublicID
+		expense.WorkspaceID = workspaceID
+		created, err := s.expenseService.Create(ctx, clerkID, &expense, lines)
+		if err != nil {
+			// Business Logic: A create pushed again, after its response was
+			// lost, conflicts with the row it created
+			if err == utils.ErrPublicIDTaken {
+				return rejectedChange(err, current)
+			}
+			return failedChange(err)
+		}
+		return appliedChange(created.ID, created.PublicID, created.UpdatedAt, created)
+	case SyncUpdate:
+		id, err := s.serialID(ctx, SyncExpense, change.PublicID)
+		if err != nil {
+			return rejectedChange(err, current)
+		}
+		expense, lines, err := s.expenseInput(ctx, change)
+		if err != nil {
+			return failedChange(err)
+		}
+		expense.ID = id
+		updated, err := s.expenseService.Update(ctx, clerkID, &expense, lines)
+		if err != nil {
+			return rejectedChange(err, current)
+		}
+		return appliedChange(updated.ID, updated.PublicID, updated.UpdatedAt, updated)
+	case SyncDelete:
+		id, err := s.serialID(ctx, SyncExpense, change.PublicID)
+		if err == nil {
+			err = s.expenseService.Delete(ctx, clerkID, kind, id)
+		}
+		return deletedChange(err, current)
+	}
+	return failedChange(utils.ErrSyncUnsupported)
+}
+
+// expenseInput returns the transaction and lines of a change, with the public
+// IDs of their categories swapped for serial keys.
+func (s *syncService) expenseInput(ctx context.Context, change SyncChange) (model.Expense, ExpenseLines, error) {
+	expense, lines := change.Expense, change.Lines
+	lines.Splits = slices.Clone(lines.Splits)
+
+	refs := PublicRefs{}
+	refs.OptCategory(change.CategoryID, &expense.CategoryID)
+	for i, categoryID := range change.SplitCategoryIDs {
+		refs.Category(categoryID, &lines.Splits[i].CategoryID)
+	}
+	if err := s.publicIDService.Resolve(ctx, &refs); err != nil {
+		return expense, lines, err
+	}
+	return expense, lines, nil
+}
+
+func (s *syncService) pushCategory(ctx context.Context, clerkID string, workspaceID *int32, change SyncChange) SyncResult {
+	current := func() (any, time.Time, error) {
+		id, err := s.serialID(ctx, SyncCategory, change.PublicID)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		category, err := s.categoryService.Get(ctx, clerkID, id)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		return category, category.UpdatedAt, nil
+	}
+
+	switch change.Operation {
+	case SyncCreate:
+		category := change.Category
+		category.PublicID = change.PublicID
+		category.WorkspaceID = workspaceID
+		created, err := s.categoryService.Create(ctx, clerkID, &category)
+		if err != nil {
+			if err == utils.ErrPublicIDTaken {
+				return rejectedChange(err, current)
+			}
+			return failedChange(err)
+		}
+		return appliedChange(created.ID, created.PublicID, created.UpdatedAt, created)
+	case SyncDelete:
+		id, err := s.serialID(ctx, SyncCategory, change.PublicID)
+		if err == nil {
+			err = s.categoryService.Delete(ctx, clerkID, id)
+		}
+		return deletedChange(err, current)
+	}
+	return failedChange(utils.ErrSyncUnsupported)
+}
+
+// serialID returns the serial key of the transaction or category with the
+// given public ID.
+func (s *syncService) serialID(ctx context.Context, entity string, publicID uuid.UUID) (int32, error) {
+	var id int32
+	refs := PublicRefs{}
+	if entity == SyncCategory {
+		refs.Category(publicID, &id)
+	} else {
+		refs.Expense(publicID, &id)
+	}
+	if err := s.publicIDService.Resolve(ctx, &refs); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *syncService) pushTag(ctx context.Context, clerkID string, change SyncChange) SyncResult {
+	current := func() (any, time.Time, error) {
+		tag, err := s.tagService.Get(ctx, clerkID, change.ID)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		return tag, tag.UpdatedAt, nil
+	}
+
+	switch change.Operation {
+	case SyncCreate:
+		created, err := s.tagService.Create(ctx, clerkID, change.TagName)
+		if err != nil {
+			return failedChange(err)
+		}
+		return appliedChange(created.ID, uuid.Nil, created.UpdatedAt, created)
+	case SyncUpdate:
+		renamed, err := s.tagService.Rename(ctx, clerkID, change.ID, change.TagName)
+		if err != nil {
+			return rejectedChange(err, current)
+		}
+		return appliedChange(renamed.ID, uuid.Nil, renamed.UpdatedAt, renamed)
+	case SyncDelete:
+		return deletedChange(s.tagService.Delete(ctx, clerkID, change.ID), current)
+	}
+	return failedChange(utils.ErrSyncUnsupported)
+}
+
+func (s *syncService) PurgeTombstones(ctx context.Context) error {
+	deleted, err := s.syncRepo.DeleteTombstones(ctx, time.Now().UTC().Add(-s.retention))
+	if err != nil {
+		return fmt.Errorf("failed to purge sync tombstones: %w", err)
+	}
+	if deleted > 0 {
+		logrus.WithField("deleted", deleted).Info("purged expired sync tombstones")
+	}
+	return nil
+}
+
+func appliedChange(id int32, publicID uuid.UUID, updatedAt time.Time, entity any) SyncResult {
+	return SyncResult{ID: id, PublicID: publicID, Status: SyncApplied, ETag: utils.VersionETag(updatedAt), Entity: entity}
+}
+
+func failedChange(err error) SyncResult {
+	return SyncResult{Status: SyncFailed, Error: err.Error()}
+}
+
+// rejectedChange reports an update that failed. Updates of rows changed since
+// the client read them, and creates of rows that exist already, conflict and
+// carry the current row. Updates of rows deleted meanwhile conflict with
+// nothing to carry.
+func rejectedChange(err error, current func() (any, time.Time, error)) SyncResult {
+	switch err {
+	case utils.ErrPreconditionFailed, utils.ErrPublicIDTaken:
+		entity, updatedAt, currentErr := current()
+		if currentErr != nil {
+			return failedChange(currentErr)
+		}
+		return SyncResult{Status: SyncConflict, ETag: utils.VersionETag(updatedAt), Entity: entity, Error: err.Error()}
+	case utils.ErrNotFound:
+		return SyncResult{Status: SyncConflict, Error: err.Error()}
+	}
+	return failedChange(err)
+}
+
+// deletedChange reports a delete. Rows already deleted on the server count as
+// deleted.
+func deletedChange(err error, current func() (any, time.Time, error)) SyncResult {
+	if err == nil || err == utils.ErrNotFound {
+		return SyncResult{Status: SyncApplied}
+	}
+	return rejectedChange(err, current)
+}
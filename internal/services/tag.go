@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+type TagService interface {
+	ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]model.Tag, error)
+	Get(ctx context.Context, clerkID string, id int32) (*model.Tag, error)
+	Create(ctx context.Context, clerkID string, name string) (*model.Tag, error)
+	Rename(ctx context.Context, clerkID string, id int32, name string) (*model.Tag, error)
+	Delete(ctx context.Context, clerkID string, id int32) error
+}
+
+type tagService struct {
+	tagRepo     repositories.TagRepository
+	userService UserService
+}
+
+func NewTagService(tagRepo repositories.TagRepository, userService UserService) TagService {
+	return &tagService{
+		tagRepo:     tagRepo,
+		userService: userService,
+	}
+}
+
+func (s *tagService) ListByUser(ctx context.Context, clerkID string, limit, offset int) ([]model.Tag, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	return s.tagRepo.ListByUser(ctx, userID, limit, offset)
+}
+
+func (s *tagService) Get(ctx context.Context, clerkID string, id int32) (*model.Tag, error) {
+	return s.getOwned(ctx, clerkID, id)
+}
+
+func (s *tagService) Create(ctx context.Context, clerkID string, name string) (*model.Tag, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	name = normalizeTagName(name)
+	existing, err := s.tagRepo.GetByName(ctx, userID, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, utils.ErrTagExists
+	}
+
+	return s.tagRepo.Create(ctx, &model.Tag{UserID: userID, Name: name})
+}
+
+// Rename changes the name of a tag, which applies to every expense tagged with it.
+func (s *tagService) Rename(ctx context.Context, clerkID string, id int32, name string) (*model.Tag, error) {
+	tag, err := s.getOwned(ctx, clerkID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.IfMatch(ctx, tag.UpdatedAt) {
+		return nil, utils.ErrPreconditionFailed
+	}
+
+	name = normalizeTagName(name)
+	existing, err := s.tagRepo.GetByName(ctx, tag.UserID, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.ID != tag.ID {
+		return nil, utils.ErrTagExists
+	}
+
+	tag.Name = name
+	return s.tagRepo.Update(ctx, tag)
+}
+
+// Delete removes a tag from every expense and then deletes it.
+func (s *tagService) Delete(ctx context.Context, clerkID string, id int32) error {
+	tag, err := s.getOwned(ctx, clerkID, id)
+	if err != nil {
+		return err
+	}
+	if !utils.IfMatch(ctx, tag.UpdatedAt) {
+		return utils.ErrPreconditionFailed
+	}
+	return s.tagRepo.Delete(ctx, id, tag.UpdatedAt)
+}
+
+func (s *tagService) getOwned(ctx context.Context, clerkID string, id int32) (*model.Tag, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	tag, err := s.tagRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, utils.ErrNotFound
+	}
+	if tag.UserID != userID {
+		return nil, utils.ErrForbidden
+	}
+	return tag, nil
+}
+
+// normalizeTagName makes tag names case and whitespace insensitive.
+func normalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeTagNames normalizes, deduplicates and sorts tag names, dropping
+// blank ones.
+func normalizeTagNames(names []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, name := range names {
+		name = normalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
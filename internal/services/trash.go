@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/storage"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Trash holds the deleted transactions and categories of a ledger.
+type Trash struct {
+	Expenses   []model.Expense
+	Categories []model.Category
+}
+
+// TrashService restores deleted transactions and categories, and purges them
+// once the retention period is over.
+type TrashService interface {
+	List(ctx context.Context, clerkID string, workspaceID *int32, limit, offset int) (*Trash, error)
+	RestoreExpense(ctx context.Context, clerkID string, id int32) (*model.Expense, error)
+	RestoreCategory(ctx context.Context, clerkID string, id int32) (*model.Category, error)
+	// PurgeExpired deletes for good what has been in the trash for longer
+	// than the retention period.
+	PurgeExpired(ctx context.Context) error
+}
+
+type trashService struct {
+	expenseRepo      repositories.ExpenseRepository
+	categoryRepo     repositories.CategoryRepository
+	blobStore        storage.BlobStore
+	userService      UserService
+	workspaceService WorkspaceService
+	auditService     AuditService
+	retention        time.Duration
+}
+
+func NewTrashService(
+	expenseRepo repositories.ExpenseRepository,
+	categoryRepo repositories.CategoryRepository,
+	blobStore storage.BlobStore,
+	userService UserService,
+	workspaceService WorkspaceService,
+	auditService AuditService,
+	retention time.Duration,
+) TrashService {
+	return &trashService{
+		expenseRepo:      expenseRepo,
+		categoryRepo:     categoryRepo,
+		blobStore:        blobStore,
+		userService:      userService,
+		workspaceService: workspaceService,
+		auditService:     auditService,
+		retention:        retention,
+	}
+}
+
+func (s *trashService) List(ctx context.Context, clerkID string, workspaceID *int32, limit, offset int) (*Trash, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	scope, err := s.workspaceService.Scope(ctx, userID, workspaceID, RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	expenses, err := s.expenseRepo.ListDeleted(ctx, scope, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categoryRepo.ListDeleted(ctx, scope, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	trash := &Trash{Expenses: expenses, Categories: categories}
+	if trash.Expenses == nil {
+		trash.Expenses = []model.Expense{}
+	}
+	if trash.Categories == nil {
+		trash.Categories = []model.Category{}
+	}
+	return trash, nil
+}
+
+func (s *trashService) RestoreExpense(ctx context.Context, clerkID string, id int32) (*model.Expense, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	expense, err := s.expenseRepo.GetDeletedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if expense == nil {
+		return nil, utils.ErrNotFound
+	}
+	if err := checkExpenseAccess(ctx, s.workspaceService, userID, expense, RoleEditor); err != nil {
+		return nil, err
+	}
+
+	// Business Logic: A transaction cannot come back under a category that is
+	// still in the trash, the category is restored first
+	if expense.CategoryID != nil {
+		category, err := s.categoryRepo.GetByID(ctx, *expense.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if category == nil {
+			return nil, utils.ErrCategoryDeleted
+		}
+	}
+
+	return s.expenseRepo.Restore(ctx, expense)
+}
+
+func (s *trashService) RestoreCategory(ctx context.Context, clerkID string, id int32) (*model.Category, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	category, err := s.categoryRepo.GetDeletedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, utils.ErrNotFound
+	}
+	if err := checkCategoryAccess(ctx, s.workspaceService, userID, category, RoleEditor); err != nil {
+		return nil, err
+	}
+
+	return s.categoryRepo.Restore(ctx, id)
+}
+
+// PurgeExpired purges transactions before categories, so that categories only
+// held by purged split lines go in the same run. Files that fail to delete
+// are logged and left behind.
+func (s *trashService) PurgeExpired(ctx context.Context) error {
+	before := time.Now().UTC().Add(-s.retention)
+
+	expenses, keys, err := s.expenseRepo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return fmt.Errorf("failed to purge expenses: %w", err)
+	}
+	for _, key := range keys {
+		if err := s.blobStore.Delete(ctx, key); err != nil {
+			logrus.WithError(err).WithField("storage_key", key).Error("failed to delete attachment blob")
+		}
+	}
+
+	categories, err := s.categoryRepo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return fmt.Errorf("failed to purge categories: %w", err)
+	}
+
+	if expenses > 0 || categories > 0 {
+		recordAudit(ctx, s.auditService, AuditEvent{
+			Action:     AuditTrashPurged,
+			EntityType: "trash",
+			After: map[string]any{
+				"deletedBefore": before,
+				"expenses":      expenses,
+				"categories":    categories,
+				"attachments":   len(keys),
+			},
+		})
+	}
+	return nil
+}
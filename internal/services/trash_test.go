@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const trashRetention = 30 * 24 * time.Hour
+
+func daysAgo(days int) *time.Time {
+	t := time.Now().UTC().AddDate(0, 0, -days)
+	return &t
+}
+
+// newTrashService returns a trash service over fakes holding, in the trash
+// unless noted:
+//   - expense 1 of the user, without a category
+//   - expense 2 of the user, under category 10
+//   - expense 3 of the user, under category 11 which is in the trash too
+//   - expense 4 of another member in workspace 1, where the user is a viewer
+//   - expense 5 of another member in workspace 2, where the user is an editor
+//   - expense 6 of the user, not in the trash
+//   - category 10 of the user, not in the trash, and 11 of the user
+//   - categories 12 and 13 of another member in workspaces 1 and 2
+//
+// Expenses 1 and 4 and categories 11 and 12 have been in the trash for longer
+// than the retention period, expense 1 with two attachments.
+func newTrashService() (TrashService, *fakeExpenseRepository, *fakeCategoryRepository, *fakeBlobStore, *fakeAuditService) {
+	expenseRepo := &fakeExpenseRepository{
+		expenses: map[int32]model.Expense{
+			1: {ID: 1, UserID: memberID, DeletedAt: daysAgo(40)},
+			2: {ID: 2, UserID: memberID, CategoryID: int32Ptr(10), DeletedAt: daysAgo(10)},
+			3: {ID: 3, UserID: memberID, CategoryID: int32Ptr(11), DeletedAt: daysAgo(10)},
+			4: {ID: 4, UserID: authorID, WorkspaceID: int32Ptr(1), DeletedAt: daysAgo(40)},
+			5: {ID: 5, UserID: authorID, WorkspaceID: int32Ptr(2), DeletedAt: daysAgo(10)},
+			6: {ID: 6, UserID: memberID},
+		},
+		attachments: map[int32][]string{
+			1: {"attachments/1/receipt.pdf", "attachments/1/invoice.pdf"},
+		},
+	}
+	categoryRepo := &fakeCategoryRepository{categories: map[int32]model.Category{
+		10: {ID: 10, UserID: memberID},
+		11: {ID: 11, UserID: memberID, DeletedAt: daysAgo(40)},
+		12: {ID: 12, UserID: authorID, WorkspaceID: int32Ptr(1), DeletedAt: daysAgo(40)},
+		13: {ID: 13, UserID: authorID, WorkspaceID: int32Ptr(2), DeletedAt: daysAgo(10)},
+	}}
+	blobStore := &fakeBlobStore{}
+	auditService := &fakeAuditService{}
+	userService := &fakeUserService{clerkIDs: map[string]uuid.UUID{"user_1": memberID}}
+
+	service := NewTrashService(expenseRepo, categoryRepo, blobStore, userService, newScopeService(), auditService, trashRetention)
+	return service, expenseRepo, categoryRepo, blobStore, auditService
+}
+
+func TestRestoreExpense(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       int32
+		expected error
+	}{
+		{name: "own transaction", id: 1},
+		{name: "transaction under a category", id: 2},
+		{name: "category still in the trash", id: 3, expected: u.ErrCategoryDeleted},
+		{name: "viewer cannot restore", id: 4, expected: u.ErrForbidden},
+		{name: "editor restores a member's transaction", id: 5},
+		{name: "transaction not in the trash", id: 6, expected: u.ErrNotFound},
+		{name: "unknown transaction", id: 99, expected: u.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, expenseRepo, _, _, _ := newTrashService()
+
+			expense, err := service.RestoreExpense(context.Background(), "user_1", tt.id)
+
+			assert.Equal(t, tt.expected, err)
+			if tt.expected != nil {
+				assert.Nil(t, expense)
+				assert.Empty(t, expenseRepo.restored)
+				return
+			}
+			require.NotNil(t, expense)
+			assert.Nil(t, expense.DeletedAt)
+			assert.Equal(t, []int32{tt.id}, expenseRepo.restored)
+		})
+	}
+}
+
+func TestRestoreCategory(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       int32
+		expected error
+	}{
+		{name: "own category", id: 11},
+		{name: "viewer cannot restore", id: 12, expected: u.ErrForbidden},
+		{name: "editor restores a member's category", id: 13},
+		{name: "category not in the trash", id: 10, expected: u.ErrNotFound},
+		{name: "unknown category", id: 99, expected: u.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, categoryRepo, _, _ := newTrashService()
+
+			category, err := service.RestoreCategory(context.Background(), "user_1", tt.id)
+
+			assert.Equal(t, tt.expected, err)
+			if tt.expected != nil {
+				assert.Nil(t, category)
+				return
+			}
+			require.NotNil(t, category)
+			assert.Nil(t, category.DeletedAt)
+			assert.Nil(t, categoryRepo.categories[tt.id].DeletedAt)
+		})
+	}
+}
+
+func TestRestoreExpenseAfterItsCategory(t *testing.T) {
+	service, expenseRepo, _, _, _ := newTrashService()
+
+	_, err := service.RestoreCategory(context.Background(), "user_1", 11)
+	require.NoError(t, err)
+	expense, err := service.RestoreExpense(context.Background(), "user_1", 3)
+
+	require.NoError(t, err)
+	assert.Equal(t, int32Ptr(11), expense.CategoryID)
+	assert.Equal(t, []int32{3}, expenseRepo.restored)
+}
+
+func TestPurgeExpired(t *testing.T) {
+	service, expenseRepo, categoryRepo, blobStore, auditService := newTrashService()
+
+	err := service.PurgeExpired(context.Background())
+
+	require.NoError(t, err)
+	cutoff := time.Now().UTC().Add(-trashRetention)
+	require.NotNil(t, expenseRepo.purgedBefore)
+	assert.WithinDuration(t, cutoff, *expenseRepo.purgedBefore, time.Minute)
+	assert.Equal(t, expenseRepo.purgedBefore, categoryRepo.purgedBefore)
+
+	assert.NotContains(t, expenseRepo.expenses, int32(1))
+	assert.NotContains(t, expenseRepo.expenses, int32(4))
+	assert.Len(t, expenseRepo.expenses, 4)
+	assert.NotContains(t, categoryRepo.categories, int32(11))
+	assert.NotContains(t, categoryRepo.categories, int32(12))
+	assert.Len(t, categoryRepo.categories, 2)
+	assert.ElementsMatch(t, []string{"attachments/1/receipt.pdf", "attachments/1/invoice.pdf"}, blobStore.deleted)
+
+	require.Len(t, auditService.events, 1)
+	event := auditService.events[0]
+	assert.Equal(t, AuditTrashPurged, event.Action)
+	after, ok := event.After.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, int64(2), after["expenses"])
+	assert.Equal(t, int64(2), after["categories"])
+	assert.Equal(t, 2, after["attachments"])
+}
+
+func TestPurgeExpiredWithNothingExpired(t *testing.T) {
+	service, expenseRepo, categoryRepo, blobStore, auditService := newTrashService()
+	require.NoError(t, service.PurgeExpired(context.Background()))
+
+	err := service.PurgeExpired(context.Background())
+
+	require.NoError(t, err)
+	assert.Len(t, expenseRepo.expenses, 4)
+	assert.Len(t, categoryRepo.categories, 2)
+	assert.Len(t, blobStore.deleted, 2)
+	assert.Len(t, auditService.events, 1, "a run that purged nothing is not audited")
+}
@@ -6,23 +6,27 @@ import (
 	"github.com/google/uuid"
 	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
 	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
 )
 
 type UserService interface {
 	List(ctx context.Context, limit, offset int) ([]model.User, error)
 	Create(ctx context.Context, user *model.User) (*model.User, error)
 	Upsert(ctx context.Context, user *model.User) (*model.User, bool, error)
-	DeleteByClerkID(ctx context.Context, clerkID string) error
 	GetInternalIDByClerkID(ctx context.Context, clerkID string) (uuid.UUID, error)
+	GetByClerkID(ctx context.Context, clerkID string) (*model.User, error)
+	UpdateLocale(ctx context.Context, clerkID, locale string) (*model.User, error)
 }
 
 type userService struct {
-	userRepo repositories.UserRepository
+	userRepo     repositories.UserRepository
+	auditService AuditService
 }
 
-func NewUserService(userRepo repositories.UserRepository) UserService {
+func NewUserService(userRepo repositories.UserRepository, auditService AuditService) UserService {
 	return &userService{
-		userRepo: userRepo,
+		userRepo:     userRepo,
+		auditService: auditService,
 	}
 }
 
@@ -34,14 +38,41 @@ func (s *userService) Create(ctx context.Context, user *model.User) (*model.User
 	return s.userRepo.Create(ctx, user)
 }
 
+// Upsert creates or updates a user and records the change in the audit trail.
 func (s *userService) Upsert(ctx context.Context, user *model.User) (*model.User, bool, error) {
-	return s.userRepo.Upsert(ctx, user)
-}
+	before, err := s.userRepo.GetByClerkID(ctx, user.ClerkID)
+	if err != nil && err != utils.ErrNotFound {
+		return nil, false, err
+	}
 
-func (s *userService) DeleteByClerkID(ctx context.Context, clerkID string) error {
-	return s.userRepo.DeleteByClerkID(ctx, clerkID)
+	upserted, created, err := s.userRepo.Upsert(ctx, user)
+	if err != nil {
+		return nil, false, err
+	}
+
+	action := AuditUserUpdated
+	if created {
+		action = AuditUserCreated
+	}
+	recordAudit(ctx, s.auditService, AuditEvent{
+		UserID:     &upserted.ID,
+		Action:     action,
+		EntityType: "user",
+		EntityID:   upserted.ID.String(),
+		Before:     before,
+		After:      upserted,
+	})
+	return upserted, created, nil
 }
 
 func (s *userService) GetInternalIDByClerkID(ctx context.Context, clerkID string) (uuid.UUID, error) {
 	return s.userRepo.GetInternalIDByClerkID(ctx, clerkID)
 }
+
+func (s *userService) GetByClerkID(ctx context.Context, clerkID string) (*model.User, error) {
+	return s.userRepo.GetByClerkID(ctx, clerkID)
+}
+
+func (s *userService) UpdateLocale(ctx context.Context, clerkID, locale string) (*model.User, error) {
+	return s.userRepo.UpdateLocale(ctx, clerkID, locale)
+}
@@ -0,0 +1,681 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/igorschechtel/clearflow-backend/internal/webhook"
+	"github.com/sirupsen/logrus"
+)
+
+// Maximum number of endpoints per user
+const webhookMaxEndpoints = 10
+
+// Number of due deliveries attempted per run of the delivery job, and how many
+// of them are attempted at once.
+const (
+	webhookBatchSize   = 100
+	webhookConcurrency = 10
+)
+
+// Time a claimed delivery is held for its attempt before another run of the
+// delivery job may claim it again, such as after a crash. It outlasts a batch.
+const webhookLease = 5 * time.Minute
+
+// WebhookEndpoint is an endpoint with its event type filters decoded.
+type WebhookEndpoint struct {
+	model.WebhookEndpoint
+	EventTypes []string
+}
+
+// WebhookDelivery is a delivery with its payload decoded and its attempts,
+// latest first.
+type WebhookDelivery struct {
+	model.WebhookDelivery
+	Payload  json.RawMessage
+	Attempts []model.WebhookAttempt
+}
+
+// webhookExpense is a transaction as delivered to webhooks, identified by its
+// public ID like in the API.
+type webhookExpense struct {
+	ID           uuid.UUID  `json:"id"`
+	Kind         string     `json:"kind"`
+	Amount       float64    `json:"amount"`
+	Description  string     `json:"description"`
+	PurchaseDate time.Time  `json:"purchaseDate"`
+	BillDate     time.Time  `json:"billDate"`
+	CategoryID   *uuid.UUID `json:"categoryId"`
+	AccountID    *int32     `json:"accountId"`
+	RefundOfID   *uuid.UUID `json:"refundOfId"`
+	WorkspaceID  *int32     `json:"workspaceId"`
+	Tags         []string   `json:"tags"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+// webhookDeletion identifies a deleted transaction.
+type webhookDeletion struct {
+	ID   uuid.UUID `json:"id"`
+	Kind string    `json:"kind"`
+}
+
+// WebhookService delivers events to the endpoints users register. Events are
+// stored as deliveries when published and attempted by DeliverDue, which
+// retries failed ones with an exponential backoff.
+type WebhookService interface {
+	ListEndpoints(ctx context.Context, clerkID string) ([]WebhookEndpoint, error)
+	GetEndpoint(ctx context.Context, clerkID string, id int32) (*WebhookEndpoint, error)
+	// CreateEndpoint registers an endpoint along with a new signing secret.
+	CreateEndpoint(ctx context.Context, clerkID string, endpoint *WebhookEndpoint) (*WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, clerkID string, endpoint *WebhookEndpoint) (*WebhookEndpoint, error)
+	// DeleteEndpoint removes an endpoint along with its deliveries.
+	DeleteEndpoint(ctx context.Context, clerkID string, id int32) error
+	// ListDeliveries returns the deliveries of an endpoint, latest first,
+	// optionally narrowed down to a status.
+	ListDeliveries(ctx context.Context, clerkID string, endpointID int32, status string, limit, offset int) ([]WebhookDelivery, error)
+	// Redeliver attempts a delivery again right away, whatever its status. It
+	// is retried as a new delivery would be if the attempt fails.
+	Redeliver(ctx context.Context, clerkID string, deliveryID int32) (*WebhookDelivery, error)
+	// Publish queues an event of the given type for every item of data to the
+	// enabled endpoints of the user subscribed to it. Data is only built when
+	// there is such an endpoint.
+	Publish(ctx context.Context, userID uuid.UUID, eventType string, data func() ([]any, error)) error
+	// PublishExpenses publishes an event of the given type per transaction.
+	PublishExpenses(ctx context.Context, userID uuid.UUID, eventType string, expenses []model.Expense) error
+	// DeliverDue attempts the deliveries due.
+	DeliverDue(ctx context.Context) error
+	// PurgeDeliveries deletes the deliveries past their retention that are no
+	// longer pending.
+	PurgeDeliveries(ctx context.Context) error
+}
+
+type webhookService struct {
+	webhookRepo          repositories.WebhookRepository
+	expenseRepo          repositories.ExpenseRepository
+	categoryRepo         repositories.CategoryRepository
+	tagRepo              repositories.TagRepository
+	userService          UserService
+	client               *http.Client
+	retention            time.Duration
+	allowPrivateNetworks bool
+}
+
+// NewWebhookService returns a service attempting deliveries for up to timeout.
+// Unless allowPrivateNetworks is set, endpoints must use HTTPS and resolve to
+// public addresses.
+func NewWebhookService(
+	webhookRepo repositories.WebhookRepository,
+	expenseRepo repositories.ExpenseRepository,
+	categoryRepo repositories.CategoryRepository,
+	tagRepo repositories.TagRepository,
+	userService UserService,
+	timeout time.Duration,
+	retention time.Duration,
+	allowPrivateNetworks bool,
+) WebhookService {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivateNetworks {
+		dialer.Control = webhook.DialControl
+	}
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+		},
+		// Business Logic: Redirects are not followed, they count as failures
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return &webhookService{
+		webhookRepo:          webhookRepo,
+		expenseRepo:          expenseRepo,
+		categoryRepo:         categoryRepo,
+		tagRepo:              tagRepo,
+		userService:          userService,
+		client:               client,
+		retention:            retention,
+		allowPrivateNetworks: allowPrivateNetworks,
+	}
+}
+
+func (s *webhookService) ListEndpoints(ctx context.Context, clerkID string) ([]WebhookEndpoint, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	endpoints, err := s.webhookRepo.ListEndpointsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	decoded := make([]WebhookEndpoint, len(endpoints))
+	for i, endpoint := range endpoints {
+		decoded[i], err = decodeWebhookEndpoint(endpoint)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return decoded, nil
+}
+
+func (s *webhookService) GetEndpoint(ctx context.Context, clerkID string, id int32) (*WebhookEndpoint, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	endpoint, err := s.ownEndpoint(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decodeWebhookEndpoint(*endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &decoded, nil
+}
+
+func (s *webhookService) CreateEndpoint(ctx context.Context, clerkID string, endpoint *WebhookEndpoint) (*WebhookEndpoint, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	if err := s.checkEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.webhookRepo.ListEndpointsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= webhookMaxEndpoints {
+		return nil, utils.ErrTooManyWebhooks
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	endpoint.UserID = userID
+	endpoint.Secret = secret
+	if err := encodeWebhookEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	created, err := s.webhookRepo.CreateEndpoint(ctx, &endpoint.WebhookEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decodeWebhookEndpoint(*created)
+	if err != nil {
+		return nil, err
+	}
+	return &decoded, nil
+}
+
+func (s *webhookService) UpdateEndpoint(ctx context.Context, clerkID string, endpoint *WebhookEndpoint) (*WebhookEndpoint, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	existing, err := s.ownEndpoint(ctx, userID, endpoint.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.IfMatch(ctx, existing.UpdatedAt) {
+		return nil, utils.ErrPreconditionFailed
+	}
+	if err := s.checkEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+	endpoint.UserID = existing.UserID
+	// The version checked is the one stored, or the update fails
+	endpoint.UpdatedAt = existing.UpdatedAt
+	if err := encodeWebhookEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.webhookRepo.UpdateEndpoint(ctx, &endpoint.WebhookEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decodeWebhookEndpoint(*updated)
+	if err != nil {
+		return nil, err
+	}
+	return &decoded, nil
+}
+
+func (s *webhookService) DeleteEndpoint(ctx context.Context, clerkID string, id int32) error {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	existing, err := s.ownEndpoint(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !utils.IfMatch(ctx, existing.UpdatedAt) {
+		return utils.ErrPreconditionFailed
+	}
+	return s.webhookRepo.DeleteEndpoint(ctx, id, existing.UpdatedAt)
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, clerkID string, endpointID int32, status string, limit, offset int) ([]WebhookDelivery, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	if _, err := s.ownEndpoint(ctx, userID, endpointID); err != nil {
+		return nil, err
+	}
+	deliveries, err := s.webhookRepo.ListDeliveries(ctx, endpointID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return s.withAttempts(ctx, deliveries)
+}
+
+func (s *webhookService) Redeliver(ctx context.Context, clerkID string, deliveryID int32) (*WebhookDelivery, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+
+	delivery, err := s.webhookRepo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery == nil {
+		return nil, utils.ErrNotFound
+	}
+	endpoint, err := s.ownEndpoint(ctx, userID, delivery.EndpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The delivery is leased so that the delivery job leaves it alone meanwhile
+	delivery, err = s.webhookRepo.Redeliver(ctx, deliveryID, time.Now().UTC().Add(webhookLease))
+	if err != nil {
+		return nil, err
+	}
+	if delivery == nil {
+		return nil, utils.ErrNotFound
+	}
+	if err := s.attempt(ctx, endpoint, delivery); err != nil {
+		return nil, err
+	}
+
+	withAttempts, err := s.withAttempts(ctx, []model.WebhookDelivery{*delivery})
+	if err != nil {
+		return nil, err
+	}
+	return &withAttempts[0], nil
+}
+
+func (s *webhookService) Publish(ctx context.Context, userID uuid.UUID, eventType string, data func() ([]any, error)) error {
+	endpoints, err := s.webhookRepo.ListEndpointsByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	var subscribed []model.WebhookEndpoint
+	for _, endpoint := range endpoints {
+		if !endpoint.Enabled {
+			continue
+		}
+		decoded, err := decodeWebhookEndpoint(endpoint)
+		if err != nil {
+			return err
+		}
+		if webhook.Subscribed(decoded.EventTypes, eventType) {
+			subscribed = append(subscribed, endpoint)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	items, err := data()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	var deliveries []model.WebhookDelivery
+	for _, item := range items {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		payload, err := json.Marshal(webhook.Envelope{Type: eventType, Timestamp: now, Data: raw})
+		if err != nil {
+			return err
+		}
+		// Business Logic: Endpoints receive the same message ID for an event
+		messageID := uuid.New()
+		for _, endpoint := range subscribed {
+			deliveries = append(deliveries, model.WebhookDelivery{
+				EndpointID:    endpoint.ID,
+				MessageID:     messageID,
+				EventType:     eventType,
+				Payload:       string(payload),
+				NextAttemptAt: &now,
+			})
+		}
+	}
+	return s.webhookRepo.CreateDeliveries(ctx, deliveries)
+}
+
+func (s *webhookService) PublishExpenses(ctx context.Context, userID uuid.UUID, eventType string, expenses []model.Expense) error {
+	if len(expenses) == 0 {
+		return nil
+	}
+	return s.Publish(ctx, userID, eventType, func() ([]any, error) {
+		items := make([]any, len(expenses))
+		if eventType == webhook.ExpenseDeleted {
+			for i, expense := range expenses {
+				items[i] = webhookDeletion{ID: expense.PublicID, Kind: expense.Kind}
+			}
+			return items, nil
+		}
+
+		payloads, err := s.expensePayloads(ctx, expenses)
+		if err != nil {
+			return nil, err
+		}
+		for i := range payloads {
+			items[i] = payloads[i]
+		}
+		return items, nil
+	})
+}
+
+// expensePayloads swaps the serial keys of the transactions for public IDs and
+// adds their tags.
+func (s *webhookService) expensePayloads(ctx context.Context, expenses []model.Expense) ([]webhookExpense, error) {
+	ids := make([]int32, len(expenses))
+	var categoryIDs, refundOfIDs []int32
+	for i, expense := range expenses {
+		ids[i] = expense.ID
+		if expense.CategoryID != nil {
+			categoryIDs = append(categoryIDs, *expense.CategoryID)
+		}
+		if expense.RefundOfID != nil {
+			refundOfIDs = append(refundOfIDs, *expense.RefundOfID)
+		}
+	}
+	tags, err := s.tagRepo.NamesByExpense(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	categories := map[int32]uuid.UUID{}
+	if len(categoryIDs) > 0 {
+		if categories, err = s.categoryRepo.PublicIDs(ctx, categoryIDs); err != nil {
+			return nil, err
+		}
+	}
+	refunded := map[int32]uuid.UUID{}
+	if len(refundOfIDs) > 0 {
+		if refunded, err = s.expenseRepo.PublicIDs(ctx, refundOfIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	payloads := make([]webhookExpense, len(expenses))
+	for i, expense := range expenses {
+		payloads[i] = webhookExpense{
+			ID:           expense.PublicID,
+			Kind:         expense.Kind,
+			Amount:       expense.Amount,
+			Description:  expense.Description,
+			PurchaseDate: expense.PurchaseDate,
+			BillDate:     expense.BillDate,
+			AccountID:    expense.AccountID,
+			WorkspaceID:  expense.WorkspaceID,
+			Tags:         tags[expense.ID],
+			UpdatedAt:    expense.UpdatedAt,
+		}
+		if payloads[i].Tags == nil {
+			payloads[i].Tags = []string{}
+		}
+		if expense.CategoryID != nil {
+			if publicID, ok := categories[*expense.CategoryID]; ok {
+				payloads[i].CategoryID = &publicID
+			}
+		}
+		if expense.RefundOfID != nil {
+			if publicID, ok := refunded[*expense.RefundOfID]; ok {
+				payloads[i].RefundOfID = &publicID
+			}
+		}
+	}
+	return payloads, nil
+}
+
+func (s *webhookService) DeliverDue(ctx context.Context) error {
+	now := time.Now().UTC()
+	deliveries, err := s.webhookRepo.ClaimDue(ctx, now, now.Add(webhookLease), webhookBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	endpointIDs := make([]int32, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		endpointIDs = append(endpointIDs, delivery.EndpointID)
+	}
+	endpoints, err := s.webhookRepo.ListEndpointsByIDs(ctx, endpointIDs)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int32]*model.WebhookEndpoint, len(endpoints))
+	for i := range endpoints {
+		byID[endpoints[i].ID] = &endpoints[i]
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, webhookConcurrency)
+	for i := range deliveries {
+		endpoint, ok := byID[deliveries[i].EndpointID]
+		// Deleted meanwhile, along with the delivery
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(delivery *model.WebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if err := s.attempt(ctx, endpoint, delivery); err != nil {
+				logrus.WithError(err).WithField("delivery", delivery.ID).Error("failed to record webhook attempt")
+			}
+		}(&deliveries[i])
+	}
+	wg.Wait()
+
+	logrus.WithField("attempted", len(deliveries)).Info("attempted webhook deliveries")
+	return nil
+}
+
+// attempt sends a delivery to its endpoint and records the outcome, scheduling
+// the next attempt when it failed.
+func (s *webhookService) attempt(ctx context.Context, endpoint *model.WebhookEndpoint, delivery *model.WebhookDelivery) error {
+	attempt := model.WebhookAttempt{}
+	start := time.Now().UTC()
+	statusCode, body, err := s.send(ctx, endpoint, delivery, start)
+	attempt.DurationMs = int32(time.Since(start).Milliseconds())
+	if err != nil {
+		message := err.Error()
+		attempt.Error = &message
+	} else {
+		attempt.ResponseCode = &statusCode
+		attempt.ResponseBody = &body
+	}
+
+	delivery.AttemptCount++
+	delivery.LastAttemptAt = &start
+	delivery.LastResponseCode = attempt.ResponseCode
+	delivery.NextAttemptAt = nil
+	switch next, retry := webhook.NextAttempt(int(delivery.AttemptCount), start); {
+	case err == nil && webhook.Successful(int(statusCode)):
+		delivery.Status = repositories.WebhookSucceeded
+	case retry:
+		delivery.Status = repositories.WebhookPending
+		delivery.NextAttemptAt = &next
+	default:
+		delivery.Status = repositories.WebhookFailed
+	}
+
+	// Recorded even when the request that triggered a redelivery is cancelled
+	return s.webhookRepo.RecordAttempt(context.WithoutCancel(ctx), delivery, &attempt)
+}
+
+// send posts a signed delivery and returns the status code and the beginning
+// of the body of the response.
+func (s *webhookService) send(ctx context.Context, endpoint *model.WebhookEndpoint, delivery *model.WebhookDelivery, timestamp time.Time) (int32, string, error) {
+	payload := []byte(delivery.Payload)
+	headers, err := webhook.Sign(endpoint.Secret, delivery.MessageID.String(), timestamp, payload)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to sign delivery: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header = headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ClearFlow-Webhooks")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, webhook.MaxResponseBody))
+	if err != nil {
+		body = nil
+	}
+	// Stored as text, which holds neither invalid UTF-8 nor NUL characters
+	text := strings.ReplaceAll(strings.ToValidUTF8(string(body), ""), "\x00", "")
+	return int32(resp.StatusCode), text, nil
+}
+
+func (s *webhookService) PurgeDeliveries(ctx context.Context) error {
+	deleted, err := s.webhookRepo.DeleteDeliveriesBefore(ctx, time.Now().UTC().Add(-s.retention))
+	if err != nil {
+		return fmt.Errorf("failed to purge webhook deliveries: %w", err)
+	}
+	if deleted > 0 {
+		logrus.WithField("deleted", deleted).Info("purged old webhook deliveries")
+	}
+	return nil
+}
+
+// ownEndpoint returns an endpoint of the user.
+func (s *webhookService) ownEndpoint(ctx context.Context, userID uuid.UUID, id int32) (*model.WebhookEndpoint, error) {
+	endpoint, err := s.webhookRepo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint == nil {
+		return nil, utils.ErrNotFound
+	}
+	if endpoint.UserID != userID {
+		return nil, utils.ErrForbidden
+	}
+	return endpoint, nil
+}
+
+// checkEndpoint verifies the URL and the event type filters of an endpoint.
+func (s *webhookService) checkEndpoint(endpoint *WebhookEndpoint) error {
+	target, err := url.Parse(endpoint.URL)
+	if err != nil || target.Host == "" {
+		return utils.ErrInvalidWebhookURL
+	}
+	// Business Logic: Plain HTTP is only allowed where private networks are, for local development
+	if target.Scheme != "https" && !(s.allowPrivateNetworks && target.Scheme == "http") {
+		return utils.ErrInvalidWebhookURL
+	}
+	for _, filter := range endpoint.EventTypes {
+		if !webhook.ValidFilter(filter) {
+			return utils.ErrInvalidWebhookEventType
+		}
+	}
+	return nil
+}
+
+func (s *webhookService) withAttempts(ctx context.Context, deliveries []model.WebhookDelivery) ([]WebhookDelivery, error) {
+	ids := make([]int32, len(deliveries))
+	for i, delivery := range deliveries {
+		ids[i] = delivery.ID
+	}
+	attempts, err := s.webhookRepo.AttemptsByDelivery(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	withAttempts := make([]WebhookDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		withAttempts[i] = WebhookDelivery{
+			WebhookDelivery: delivery,
+			Payload:         json.RawMessage(delivery.Payload),
+			Attempts:        attempts[delivery.ID],
+		}
+		if withAttempts[i].Attempts == nil {
+			withAttempts[i].Attempts = []model.WebhookAttempt{}
+		}
+	}
+	return withAttempts, nil
+}
+
+func encodeWebhookEndpoint(endpoint *WebhookEndpoint) error {
+	eventTypes := endpoint.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	encoded, err := json.Marshal(eventTypes)
+	if err != nil {
+		return err
+	}
+	endpoint.WebhookEndpoint.EventTypes = string(encoded)
+	return nil
+}
+
+func decodeWebhookEndpoint(endpoint model.WebhookEndpoint) (WebhookEndpoint, error) {
+	decoded := WebhookEndpoint{WebhookEndpoint: endpoint, EventTypes: []string{}}
+	if err := json.Unmarshal([]byte(endpoint.EventTypes), &decoded.EventTypes); err != nil {
+		return WebhookEndpoint{}, fmt.Errorf("failed to decode event types of webhook %d: %w", endpoint.ID, err)
+	}
+	return decoded, nil
+}
+
+// publishWebhooks hands transactions written over to the webhooks of the user.
+// Failures are logged, as the transactions are stored already.
+func publishWebhooks(ctx context.Context, webhookService WebhookService, userID uuid.UUID, eventType string, expenses ...model.Expense) {
+	if err := webhookService.PublishExpenses(ctx, userID, eventType, expenses); err != nil {
+		logrus.WithError(err).WithField("event", eventType).Error("failed to publish webhook event")
+	}
+}
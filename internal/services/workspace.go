@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	"github.com/igorschechtel/clearflow-backend/internal/utils"
+)
+
+// Workspace member roles
+const (
+	RoleOwner  = repositories.RoleOwner
+	RoleEditor = repositories.RoleEditor
+	RoleViewer = repositories.RoleViewer
+)
+
+// roleRank orders roles by the permissions they grant.
+var roleRank = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+type WorkspaceService interface {
+	ListByUser(ctx context.Context, clerkID string) ([]repositories.MembershipDetails, error)
+	Create(ctx context.Context, clerkID string, name string) (*model.Workspace, error)
+	ListMembers(ctx context.Context, clerkID string, workspaceID int32) ([]repositories.MemberDetails, error)
+	UpdateMemberRole(ctx context.Context, clerkID string, workspaceID int32, memberID uuid.UUID, role string) (*model.WorkspaceMember, error)
+	RemoveMember(ctx context.Context, clerkID string, workspaceID int32, memberID uuid.UUID) error
+	ListInvitations(ctx context.Context, clerkID string, workspaceID int32) ([]model.WorkspaceInvitation, error)
+	Invite(ctx context.Context, clerkID string, workspaceID int32, email, role string) (*model.WorkspaceInvitation, error)
+	RevokeInvitation(ctx context.Context, clerkID string, invitationID int32) error
+	ListOwnInvitations(ctx context.Context, clerkID string) ([]model.WorkspaceInvitation, error)
+	AcceptInvitation(ctx context.Context, clerkID string, invitationID int32) (*model.WorkspaceMember, error)
+	// Scope resolves the ledger a user works on, checking that they hold at
+	// least minRole in the workspace when one is given.
+	Scope(ctx context.Context, userID uuid.UUID, workspaceID *int32, minRole string) (repositories.Scope, error)
+}
+
+type workspaceService struct {
+	workspaceRepo repositories.WorkspaceRepository
+	userService   UserService
+}
+
+func NewWorkspaceService(workspaceRepo repositories.WorkspaceRepository, userService UserService) WorkspaceService {
+	return &workspaceService{
+		workspaceRepo: workspaceRepo,
+		userService:   userService,
+	}
+}
+
+func (s *workspaceService) ListByUser(ctx context.Context, clerkID string) ([]repositories.MembershipDetails, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	return s.workspaceRepo.ListByUser(ctx, userID)
+}
+
+func (s *workspaceService) Create(ctx context.Context, clerkID string, name string) (*model.Workspace, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	return s.workspaceRepo.Create(ctx, &model.Workspace{Name: name}, userID)
+}
+
+func (s *workspaceService) ListMembers(ctx context.Context, clerkID string, workspaceID int32) ([]repositories.MemberDetails, error) {
+	if _, err := s.authorize(ctx, clerkID, workspaceID, RoleViewer); err != nil {
+		return nil, err
+	}
+	return s.workspaceRepo.ListMembers(ctx, workspaceID)
+}
+
+func (s *workspaceService) UpdateMemberRole(ctx context.Context, clerkID string, workspaceID int32, memberID uuid.UUID, role string) (*model.WorkspaceMember, error) {
+	if _, err := s.authorize(ctx, clerkID, workspaceID, RoleOwner); err != nil {
+		return nil, err
+	}
+	existing, err := s.memberAtVersion(ctx, workspaceID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if role != RoleOwner {
+		if err := s.checkNotLastOwner(ctx, workspaceID, memberID); err != nil {
+			return nil, err
+		}
+	}
+
+	member, err := s.workspaceRepo.UpdateMemberRole(ctx, workspaceID, memberID, role, existing.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, utils.ErrNotFound
+	}
+	return member, nil
+}
+
+// RemoveMember removes a member from a workspace. Owners can remove anyone and
+// other members can only leave.
+func (s *workspaceService) RemoveMember(ctx context.Context, clerkID string, workspaceID int32, memberID uuid.UUID) error {
+	minRole := RoleOwner
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	if userID == memberID {
+		minRole = RoleViewer
+	}
+	if _, err := s.authorize(ctx, clerkID, workspaceID, minRole); err != nil {
+		return err
+	}
+	existing, err := s.memberAtVersion(ctx, workspaceID, memberID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkNotLastOwner(ctx, workspaceID, memberID); err != nil {
+		return err
+	}
+	return s.workspaceRepo.RemoveMember(ctx, workspaceID, memberID, existing.UpdatedAt)
+}
+
+func (s *workspaceService) ListInvitations(ctx context.Context, clerkID string, workspaceID int32) ([]model.WorkspaceInvitation, error) {
+	if _, err := s.authorize(ctx, clerkID, workspaceID, RoleOwner); err != nil {
+		return nil, err
+	}
+	return s.workspaceRepo.ListInvitations(ctx, workspaceID)
+}
+
+func (s *workspaceService) Invite(ctx context.Context, clerkID string, workspaceID int32, email, role string) (*model.WorkspaceInvitation, error) {
+	userID, err := s.authorize(ctx, clerkID, workspaceID, RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	return s.workspaceRepo.CreateInvitation(ctx, &model.WorkspaceInvitation{
+		WorkspaceID: workspaceID,
+		InvitedBy:   userID,
+		Email:       normalizeEmail(email),
+		Role:        role,
+	})
+}
+
+func (s *workspaceService) RevokeInvitation(ctx context.Context, clerkID string, invitationID int32) error {
+	invitation, err := s.workspaceRepo.GetInvitation(ctx, invitationID)
+	if err != nil {
+		return err
+	}
+	if invitation == nil || invitation.AcceptedAt != nil {
+		return utils.ErrNotFound
+	}
+	if _, err := s.authorize(ctx, clerkID, invitation.WorkspaceID, RoleOwner); err != nil {
+		return err
+	}
+	if !utils.IfMatch(ctx, invitation.UpdatedAt) {
+		return utils.ErrPreconditionFailed
+	}
+	return s.workspaceRepo.DeleteInvitation(ctx, invitationID, invitation.UpdatedAt)
+}
+
+// ListOwnInvitations lists the pending invitations sent to the user's email.
+func (s *workspaceService) ListOwnInvitations(ctx context.Context, clerkID string) ([]model.WorkspaceInvitation, error) {
+	user, err := s.userService.GetByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user for clerk %s: %w", clerkID, err)
+	}
+	return s.workspaceRepo.ListPendingInvitationsByEmail(ctx, normalizeEmail(user.Email))
+}
+
+func (s *workspaceService) AcceptInvitation(ctx context.Context, clerkID string, invitationID int32) (*model.WorkspaceMember, error) {
+	user, err := s.userService.GetByClerkID(ctx, clerkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user for clerk %s: %w", clerkID, err)
+	}
+
+	invitation, err := s.workspaceRepo.GetInvitation(ctx, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil || invitation.AcceptedAt != nil {
+		return nil, utils.ErrNotFound
+	}
+	if invitation.Email != normalizeEmail(user.Email) {
+		return nil, utils.ErrForbidden
+	}
+
+	return s.workspaceRepo.AcceptInvitation(ctx, invitation, user.ID)
+}
+
+func (s *workspaceService) Scope(ctx context.Context, userID uuid.UUID, workspaceID *int32, minRole string) (repositories.Scope, error) {
+	scope := repositories.Scope{UserID: userID, WorkspaceID: workspaceID}
+	if workspaceID == nil {
+		return scope, nil
+	}
+
+	member, err := s.workspaceRepo.GetMember(ctx, *workspaceID, userID)
+	if err != nil {
+		return scope, err
+	}
+	if member == nil || roleRank[member.Role] < roleRank[minRole] {
+		return scope, utils.ErrForbidden
+	}
+	return scope, nil
+}
+
+// authorize checks that the user holds at least minRole in the workspace.
+func (s *workspaceService) authorize(ctx context.Context, clerkID string, workspaceID int32, minRole string) (uuid.UUID, error) {
+	userID, err := s.userService.GetInternalIDByClerkID(ctx, clerkID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get internal user ID for clerk %s: %w", clerkID, err)
+	}
+	if _, err := s.Scope(ctx, userID, &workspaceID, minRole); err != nil {
+		return uuid.Nil, err
+	}
+	return userID, nil
+}
+
+// memberAtVersion returns a member, failing when it is missing or was changed
+// since the version the request applies to.
+func (s *workspaceService) memberAtVersion(ctx context.Context, workspaceID int32, memberID uuid.UUID) (*model.WorkspaceMember, error) {
+	member, err := s.workspaceRepo.GetMember(ctx, workspaceID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, utils.ErrNotFound
+	}
+	if !utils.IfMatch(ctx, member.UpdatedAt) {
+		return nil, utils.ErrPreconditionFailed
+	}
+	return member, nil
+}
+
+// checkNotLastOwner prevents a workspace from being left without an owner.
+func (s *workspaceService) checkNotLastOwner(ctx context.Context, workspaceID int32, memberID uuid.UUID) error {
+	members, err := s.workspaceRepo.ListMembers(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	owners, isOwner := 0, false
+	for _, m := range members {
+		if m.Role == RoleOwner {
+			owners++
+			isOwner = isOwner || m.UserID == memberID
+		}
+	}
+	if isOwner && owners == 1 {
+		return utils.ErrLastOwner
+	}
+	return nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
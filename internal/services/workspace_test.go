@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/igorschechtel/clearflow-backend/db/model/app_db/public/model"
+	"github.com/igorschechtel/clearflow-backend/internal/repositories"
+	u "github.com/igorschechtel/clearflow-backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	memberID = uuid.MustParse("00000000-0000-0000-0000-000000000011")
+	authorID = uuid.MustParse("00000000-0000-0000-0000-000000000012")
+)
+
+// newScopeService returns a workspace service where the user is a viewer of
+// workspace 1, an editor of workspace 2, an owner of workspace 3 and not a
+// member of workspace 4. Another member authors transactions in all of them.
+func newScopeService() WorkspaceService {
+	return NewWorkspaceService(&fakeWorkspaceRepository{roles: map[int32]map[uuid.UUID]string{
+		1: {memberID: RoleViewer, authorID: RoleOwner},
+		2: {memberID: RoleEditor, authorID: RoleOwner},
+		3: {memberID: RoleOwner, authorID: RoleEditor},
+		4: {authorID: RoleOwner},
+	}}, nil)
+}
+
+func TestScope(t *testing.T) {
+	tests := []struct {
+		name        string
+		workspaceID *int32
+		minRole     string
+		expected    error
+	}{
+		{name: "personal ledger", minRole: RoleOwner},
+		{name: "viewer reads", workspaceID: int32Ptr(1), minRole: RoleViewer},
+		{name: "viewer cannot edit", workspaceID: int32Ptr(1), minRole: RoleEditor, expected: u.ErrForbidden},
+		{name: "editor reads", workspaceID: int32Ptr(2), minRole: RoleViewer},
+		{name: "editor edits", workspaceID: int32Ptr(2), minRole: RoleEditor},
+		{name: "editor cannot manage", workspaceID: int32Ptr(2), minRole: RoleOwner, expected: u.ErrForbidden},
+		{name: "owner manages", workspaceID: int32Ptr(3), minRole: RoleOwner},
+		{name: "non-member cannot read", workspaceID: int32Ptr(4), minRole: RoleViewer, expected: u.ErrForbidden},
+		{name: "unknown workspace", workspaceID: int32Ptr(99), minRole: RoleViewer, expected: u.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, err := newScopeService().Scope(context.Background(), memberID, tt.workspaceID, tt.minRole)
+
+			assert.Equal(t, tt.expected, err)
+			assert.Equal(t, repositories.Scope{UserID: memberID, WorkspaceID: tt.workspaceID}, scope)
+		})
+	}
+}
+
+func TestCheckExpenseAccess(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      uuid.UUID
+		workspaceID *int32
+		minRole     string
+		expected    error
+	}{
+		{name: "own personal transaction", userID: memberID, minRole: RoleOwner},
+		{name: "personal transaction of someone else", userID: authorID, minRole: RoleViewer, expected: u.ErrForbidden},
+		{name: "viewer reads a member's transaction", userID: authorID, workspaceID: int32Ptr(1), minRole: RoleViewer},
+		{name: "viewer cannot edit a member's transaction", userID: authorID, workspaceID: int32Ptr(1), minRole: RoleEditor, expected: u.ErrForbidden},
+		{name: "viewer cannot edit their own transaction", userID: memberID, workspaceID: int32Ptr(1), minRole: RoleEditor, expected: u.ErrForbidden},
+		{name: "editor edits a member's transaction", userID: authorID, workspaceID: int32Ptr(2), minRole: RoleEditor},
+		{name: "owner edits a member's transaction", userID: authorID, workspaceID: int32Ptr(3), minRole: RoleEditor},
+		{name: "non-member cannot read", userID: authorID, workspaceID: int32Ptr(4), minRole: RoleViewer, expected: u.ErrForbidden},
+		{name: "former member loses their own transactions", userID: memberID, workspaceID: int32Ptr(4), minRole: RoleViewer, expected: u.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expense := &model.Expense{ID: 1, UserID: tt.userID, WorkspaceID: tt.workspaceID}
+
+			err := checkExpenseAccess(context.Background(), newScopeService(), memberID, expense, tt.minRole)
+
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+// Package settle splits shared bills between people and works out who owes
+// whom. Amounts are in cents and parties are contact ids, with Me standing for
+// the user. It is pure computation: callers load the data and serve the result.
+package settle
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// Me is the party of the user, who is not a contact.
+const Me int32 = 0
+
+var (
+	ErrNoParticipants     = errors.New("a split needs at least one participant")
+	ErrPercentagesInvalid = errors.New("percentages must be positive and add up to 100")
+)
+
+// Balance is what a party is owed, when positive, or owes, when negative.
+type Balance struct {
+	Party  int32
+	Amount int64
+}
+
+// Transfer is a payment that settles debts between two parties.
+type Transfer struct {
+	From   int32
+	To     int32
+	Amount int64
+}
+
+// Equal splits total into n shares that differ by at most one cent. The
+// leftover cents go to the first shares.
+func Equal(total int64, n int) ([]int64, error) {
+	if n <= 0 {
+		return nil, ErrNoParticipants
+	}
+	weights := make([]float64, n)
+	for i := range weights {
+		weights[i] = 1
+	}
+	return allocate(total, weights), nil
+}
+
+// ByPercentages splits total according to percentages adding up to 100.
+// Rounding leftovers go to the shares with the largest remainders.
+func ByPercentages(total int64, percentages []float64) ([]int64, error) {
+	if len(percentages) == 0 {
+		return nil, ErrNoParticipants
+	}
+	var sum float64
+	for _, p := range percentages {
+		if p <= 0 {
+			return nil, ErrPercentagesInvalid
+		}
+		sum += p
+	}
+	if math.Abs(sum-100) > 0.001 {
+		return nil, ErrPercentagesInvalid
+	}
+	return allocate(total, percentages), nil
+}
+
+// allocate splits total in proportion to weights with the largest remainder
+// method, so that the shares always add up to total.
+func allocate(total int64, weights []float64) []int64 {
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+
+	shares := make([]int64, len(weights))
+	remainders := make([]float64, len(weights))
+	var allocated int64
+	for i, w := range weights {
+		exact := float64(total) * w / sum
+		shares[i] = int64(math.Floor(exact))
+		remainders[i] = exact - float64(shares[i])
+		allocated += shares[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for i := 0; allocated < total; i++ {
+		shares[order[i%len(order)]]++
+		allocated++
+	}
+	return shares
+}
+
+// Ledger accumulates shared bills and settlements into balances.
+type Ledger struct {
+	balances map[int32]int64
+}
+
+func NewLedger() *Ledger {
+	return &Ledger{balances: map[int32]int64{}}
+}
+
+// Bill records that payer paid a bill split into the given shares.
+func (l *Ledger) Bill(payer int32, shares map[int32]int64) {
+	for party, amount := range shares {
+		l.balances[payer] += amount
+		l.balances[party] -= amount
+	}
+}
+
+// Settle records that from paid amount to to.
+func (l *Ledger) Settle(from, to int32, amount int64) {
+	l.balances[from] += amount
+	l.balances[to] -= amount
+}
+
+// Balances returns the non-zero balances ordered by party.
+func (l *Ledger) Balances() []Balance {
+	balances := make([]Balance, 0, len(l.balances))
+	for party, amount := range l.balances {
+		if amount != 0 {
+			balances = append(balances, Balance{Party: party, Amount: amount})
+		}
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Party < balances[j].Party
+	})
+	return balances
+}
+
+// Simplify returns a short list of transfers that settles all balances. The
+// largest debtor repeatedly pays the largest creditor, which needs at most one
+// transfer less than the number of parties.
+func Simplify(balances []Balance) []Transfer {
+	var creditors, debtors []Balance
+	for _, b := range balances {
+		switch {
+		case b.Amount > 0:
+			creditors = append(creditors, b)
+		case b.Amount < 0:
+			debtors = append(debtors, Balance{Party: b.Party, Amount: -b.Amount})
+		}
+	}
+	byAmount := func(s []Balance) func(i, j int) bool {
+		return func(i, j int) bool {
+			if s[i].Amount != s[j].Amount {
+				return s[i].Amount > s[j].Amount
+			}
+			return s[i].Party < s[j].Party
+		}
+	}
+
+	transfers := []Transfer{}
+	for len(creditors) > 0 && len(debtors) > 0 {
+		sort.Slice(creditors, byAmount(creditors))
+		sort.Slice(debtors, byAmount(debtors))
+
+		creditor, debtor := &creditors[0], &debtors[0]
+		amount := min(creditor.Amount, debtor.Amount)
+		transfers = append(transfers, Transfer{From: debtor.Party, To: creditor.Party, Amount: amount})
+
+		creditor.Amount -= amount
+		debtor.Amount -= amount
+		if creditor.Amount == 0 {
+			creditors = creditors[1:]
+		}
+		if debtor.Amount == 0 {
+			debtors = debtors[1:]
+		}
+	}
+	return transfers
+}
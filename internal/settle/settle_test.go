@@ -0,0 +1,123 @@
+package settle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int64
+		n        int
+		expected []int64
+	}{
+		{name: "even", total: 12000, n: 3, expected: []int64{4000, 4000, 4000}},
+		{name: "leftover cents go first", total: 10000, n: 3, expected: []int64{3334, 3333, 3333}},
+		{name: "single participant", total: 999, n: 1, expected: []int64{999}},
+		{name: "fewer cents than participants", total: 2, n: 3, expected: []int64{1, 1, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := Equal(tt.total, tt.n)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, shares)
+		})
+	}
+
+	_, err := Equal(100, 0)
+	assert.ErrorIs(t, err, ErrNoParticipants)
+}
+
+func TestByPercentages(t *testing.T) {
+	tests := []struct {
+		name        string
+		total       int64
+		percentages []float64
+		expected    []int64
+		err         error
+	}{
+		{name: "exact", total: 10000, percentages: []float64{50, 30, 20}, expected: []int64{5000, 3000, 2000}},
+		{name: "largest remainder gets the cent", total: 1001, percentages: []float64{50, 50}, expected: []int64{501, 500}},
+		{name: "thirds", total: 10000, percentages: []float64{33.33, 33.33, 33.34}, expected: []int64{3333, 3333, 3334}},
+		{name: "not adding up", total: 10000, percentages: []float64{50, 40}, err: ErrPercentagesInvalid},
+		{name: "zero share", total: 10000, percentages: []float64{100, 0}, err: ErrPercentagesInvalid},
+		{name: "empty", total: 10000, percentages: nil, err: ErrNoParticipants},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := ByPercentages(tt.total, tt.percentages)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, shares)
+		})
+	}
+}
+
+func TestLedger(t *testing.T) {
+	ledger := NewLedger()
+	// I paid 120 split three ways with contacts 1 and 2
+	ledger.Bill(Me, map[int32]int64{Me: 4000, 1: 4000, 2: 4000})
+	// Contact 1 paid 30 for contact 2
+	ledger.Bill(1, map[int32]int64{2: 3000})
+	// Contact 2 paid me back 20
+	ledger.Settle(2, Me, 2000)
+
+	assert.Equal(t, []Balance{
+		{Party: Me, Amount: 6000},
+		{Party: 1, Amount: -1000},
+		{Party: 2, Amount: -5000},
+	}, ledger.Balances())
+}
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		name     string
+		balances []Balance
+		expected []Transfer
+	}{
+		{
+			name:     "settled",
+			balances: nil,
+			expected: []Transfer{},
+		},
+		{
+			name:     "one debt",
+			balances: []Balance{{Party: Me, Amount: 500}, {Party: 1, Amount: -500}},
+			expected: []Transfer{{From: 1, To: Me, Amount: 500}},
+		},
+		{
+			// 1 owes 2 and 2 owes me: 1 pays me directly
+			name:     "chain collapses",
+			balances: []Balance{{Party: Me, Amount: 1000}, {Party: 1, Amount: -1000}, {Party: 2, Amount: 0}},
+			expected: []Transfer{{From: 1, To: Me, Amount: 1000}},
+		},
+		{
+			name: "largest debts first",
+			balances: []Balance{
+				{Party: Me, Amount: 6000},
+				{Party: 1, Amount: -1000},
+				{Party: 2, Amount: -5000},
+				{Party: 3, Amount: 1000},
+				{Party: 4, Amount: -1000},
+			},
+			expected: []Transfer{
+				{From: 2, To: Me, Amount: 5000},
+				{From: 1, To: Me, Amount: 1000},
+				{From: 4, To: 3, Amount: 1000},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Simplify(tt.balances))
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// Package storage keeps binary objects such as receipts out of the database,
+// behind a BlobStore backed by the local filesystem or an S3-compatible
+// service.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore stores objects under opaque keys chosen by the caller.
+type BlobStore interface {
+	Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error
+	// Get returns the object content, or ErrNotFound. Callers close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes an object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,59 @@
+// Package synctoken encodes the position clients resume syncing from as an
+// opaque token, so that its layout can change without breaking clients.
+package synctoken
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version of the token layout
+const version = "1"
+
+// ErrInvalid is returned for tokens that were not issued by Encode.
+var ErrInvalid = errors.New("invalid sync token")
+
+// Token is a position in the history of changes.
+type Token struct {
+	// Changes made by transactions from this ID on are yet to be synced
+	ChangeID int64
+	// IssuedAt is when the token was issued, with millisecond precision
+	IssuedAt time.Time
+}
+
+// Encode returns the token as sent to clients.
+func (t Token) Encode() string {
+	raw := fmt.Sprintf("%s.%d.%d", version, t.ChangeID, t.IssuedAt.UnixMilli())
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decode reads a token sent back by a client.
+func Decode(s string) (Token, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Token{}, ErrInvalid
+	}
+	parts := strings.Split(string(raw), ".")
+	if len(parts) != 3 || parts[0] != version {
+		return Token{}, ErrInvalid
+	}
+	changeID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || changeID < 0 {
+		return Token{}, ErrInvalid
+	}
+	issuedAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return Token{}, ErrInvalid
+	}
+	return Token{ChangeID: changeID, IssuedAt: time.UnixMilli(issuedAt).UTC()}, nil
+}
+
+// Expired reports whether the token is older than the retention of deletions,
+// in which case clients may have missed some and must sync from scratch.
+func (t Token) Expired(now time.Time, retention time.Duration) bool {
+	return t.IssuedAt.Before(now.Add(-retention))
+}
@@ -0,0 +1,52 @@
+package synctoken
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	token := Token{ChangeID: 48213, IssuedAt: time.Date(2026, time.March, 14, 9, 30, 0, 123000000, time.UTC)}
+
+	decoded, err := Decode(token.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, token, decoded)
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"not base64", "not a token!"},
+		{"other version", encode("2.10.1773480600123")},
+		{"missing part", encode("1.10")},
+		{"negative change", encode("1.-10.1773480600123")},
+		{"bad time", encode("1.10.yesterday")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.token)
+			assert.ErrorIs(t, err, ErrInvalid)
+		})
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Date(2026, time.March, 31, 12, 0, 0, 0, time.UTC)
+	retention := 30 * 24 * time.Hour
+
+	assert.False(t, Token{IssuedAt: now.Add(-time.Hour)}.Expired(now, retention))
+	assert.False(t, Token{IssuedAt: now.Add(-retention)}.Expired(now, retention))
+	assert.True(t, Token{IssuedAt: now.Add(-retention - time.Millisecond)}.Expired(now, retention))
+}
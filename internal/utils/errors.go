@@ -40,4 +40,36 @@ type ErrorResponse struct {
 var ErrUnauthorized = errors.New("Unauthorized")
 var ErrForbidden = errors.New("Forbidden")
 var ErrNotFound = errors.New("Not Found")
-var ErrInternal = errors.New("Internal Server Error")
\ No newline at end of file
+var ErrInternal = errors.New("Internal Server Error")
+var ErrCategoryKindMismatch = errors.New("Category kind does not match the transaction kind")
+var ErrRefundExceedsAmount = errors.New("Refunds cannot exceed the amount of the refunded expense")
+var ErrRefundNoMatch = errors.New("No earlier expense of the same merchant can absorb the refund")
+var ErrInvalidTransfer = errors.New("Transfers need distinct source and destination accounts")
+var ErrTagExists = errors.New("A tag with this name already exists")
+var ErrSplitMismatch = errors.New("Split lines must add up to the amount of an expense or income")
+var ErrLastOwner = errors.New("A workspace must keep at least one owner")
+var ErrShareMismatch = errors.New("Shares must add up to the full amount of the expense")
+var ErrDuplicateParticipant = errors.New("Each participant can only have one share")
+var ErrInvalidSettlement = errors.New("A settlement needs two different parties")
+var ErrAttachmentTooLarge = errors.New("Attachment exceeds the maximum size")
+var ErrUnsupportedAttachment = errors.New("Attachments must be JPEG, PNG or WebP images or PDF documents")
+var ErrInvalidDownloadLink = errors.New("Download link is invalid or has expired")
+var ErrAccountNotEmpty = errors.New("Archives can only be restored into an empty account")
+var ErrRefundedExpenseDeleted = errors.New("Restore the refunded transaction first")
+var ErrCategoryDeleted = errors.New("Restore the category of the transaction first")
+var ErrBulkTooLarge = errors.New("Bulk operations are limited to 1000 transactions")
+var ErrBulkSelection = errors.New("Bulk updates and deletes select transactions either by ids or by filter")
+var ErrBulkEmptyUpdate = errors.New("Bulk updates need a category or tags to add or remove")
+var ErrInvalidIdempotencyKey = errors.New("Idempotency-Key must be 1 to 255 printable ASCII characters")
+var ErrIdempotencyKeyReused = errors.New("Idempotency-Key was already used for a different request")
+var ErrIdempotencyKeyInUse = errors.New("A request with this Idempotency-Key is still in progress")
+var ErrIdempotencyBodyTooLarge = errors.New("Requests with an Idempotency-Key are limited to 1 GB")
+var ErrPreconditionRequired = errors.New("Updates and deletes must send an If-Match header with the ETag of the resource")
+var ErrPreconditionFailed = errors.New("The resource was changed since it was fetched")
+var ErrInvalidSyncToken = errors.New("Invalid sync token")
+var ErrSyncTokenExpired = errors.New("Sync token expired, sync again without one")
+var ErrSyncUnsupported = errors.New("This change cannot be synced")
+var ErrPublicIDTaken = errors.New("This ID is already taken, pick another one")
+var ErrInvalidWebhookURL = errors.New("Webhook URLs must use HTTPS")
+var ErrInvalidWebhookEventType = errors.New("Unknown webhook event type")
+var ErrTooManyWebhooks = errors.New("Users can register up to 10 webhook endpoints")
\ No newline at end of file
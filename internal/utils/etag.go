@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ifMatchKey struct{}
+
+// VersionETag returns the strong entity tag of a row last updated at the given
+// time. Rows are stored with millisecond precision.
+func VersionETag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMilli(), 36) + `"`
+}
+
+// ContentETag returns the weak entity tag of a listing, which changes along
+// with its encoded content.
+func ContentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// ETagInList reports whether tag is listed in an If-Match or If-None-Match
+// header, "*" listing every tag. Strong comparison never matches weak tags,
+// weak comparison ignores the weakness indicator.
+func ETagInList(header, tag string, strong bool) bool {
+	for _, listed := range strings.Split(header, ",") {
+		listed = strings.TrimSpace(listed)
+		if listed == "*" {
+			return true
+		}
+		if strong {
+			if listed == tag && !strings.HasPrefix(tag, "W/") {
+				return true
+			}
+			continue
+		}
+		if strings.TrimPrefix(listed, "W/") == strings.TrimPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// WithIfMatch adds the If-Match header of a request to its context, for the
+// services to check against the version of the row they change.
+func WithIfMatch(ctx context.Context, header string) context.Context {
+	return context.WithValue(ctx, ifMatchKey{}, header)
+}
+
+// IfMatch reports whether the If-Match header carried by the context matches
+// a row last updated at the given time. Contexts without one always match.
+func IfMatch(ctx context.Context, updatedAt time.Time) bool {
+	header, ok := ctx.Value(ifMatchKey{}).(string)
+	if !ok {
+		return true
+	}
+	return ETagInList(header, VersionETag(updatedAt), true)
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionETag(t *testing.T) {
+	updatedAt := time.Date(2026, time.March, 14, 9, 30, 0, 123000000, time.UTC)
+
+	tag := VersionETag(updatedAt)
+	assert.Equal(t, `"mmq4k4zf"`, tag)
+	assert.Equal(t, tag, VersionETag(updatedAt.In(time.FixedZone("BRT", -3*60*60))))
+	assert.NotEqual(t, tag, VersionETag(updatedAt.Add(time.Millisecond)))
+}
+
+func TestContentETag(t *testing.T) {
+	tag := ContentETag([]byte(`[{"ID":1}]`))
+
+	assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, tag)
+	assert.Equal(t, tag, ContentETag([]byte(`[{"ID":1}]`)))
+	assert.NotEqual(t, tag, ContentETag([]byte(`[{"ID":2}]`)))
+}
+
+func TestETagInList(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		tag    string
+		strong bool
+		want   bool
+	}{
+		{"same strong tag", `"abc"`, `"abc"`, true, true},
+		{"other tag", `"abd"`, `"abc"`, true, false},
+		{"listed among others", `"x", "abc" ,"y"`, `"abc"`, true, true},
+		{"any", `*`, `"abc"`, true, true},
+		{"empty header", ``, `"abc"`, true, false},
+		{"weak header under strong comparison", `W/"abc"`, `"abc"`, true, false},
+		{"weak tag under strong comparison", `"abc"`, `W/"abc"`, true, false},
+		{"weak header under weak comparison", `W/"abc"`, `"abc"`, false, true},
+		{"weak tag under weak comparison", `"abc"`, `W/"abc"`, false, true},
+		{"other tag under weak comparison", `W/"abd"`, `W/"abc"`, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ETagInList(tt.header, tt.tag, tt.strong))
+		})
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	updatedAt := time.Date(2026, time.March, 14, 9, 30, 0, 123000000, time.UTC)
+
+	assert.True(t, IfMatch(context.Background(), updatedAt))
+	assert.True(t, IfMatch(WithIfMatch(context.Background(), VersionETag(updatedAt)), updatedAt))
+	assert.True(t, IfMatch(WithIfMatch(context.Background(), "*"), updatedAt))
+	assert.False(t, IfMatch(WithIfMatch(context.Background(), VersionETag(updatedAt)), updatedAt.Add(time.Second)))
+	assert.False(t, IfMatch(WithIfMatch(context.Background(), "W/"+VersionETag(updatedAt)), updatedAt))
+}
@@ -36,20 +36,53 @@ func WriteJSON(w http.ResponseWriter, status int, v any) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 
-	// Handle nil slices and maps
+	return json.NewEncoder(w).Encode(orEmpty(v))
+}
+
+// WriteJSONWithETag writes a single resource along with its strong entity tag,
+// or only a 304 when the client already holds that version.
+func WriteJSONWithETag(w http.ResponseWriter, r *http.Request, status int, tag string, v any) error {
+	w.Header().Set("ETag", tag)
+	if notModified(r, tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	return WriteJSON(w, status, v)
+}
+
+// WriteJSONList writes a listing along with a weak entity tag derived from its
+// content, or only a 304 when the client already holds the same content.
+func WriteJSONList(w http.ResponseWriter, r *http.Request, v any) error {
+	body, err := json.Marshal(orEmpty(v))
+	if err != nil {
+		return WriteJSONError(w, http.StatusInternalServerError, err)
+	}
+	return WriteJSONWithETag(w, r, http.StatusOK, ContentETag(body), json.RawMessage(body))
+}
+
+// notModified reports whether a conditional GET matches the current tag.
+func notModified(r *http.Request, tag string) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	header := r.Header.Get("If-None-Match")
+	return header != "" && ETagInList(header, tag, false)
+}
+
+// orEmpty encodes nil values as empty JSON objects and nil slices as empty arrays.
+func orEmpty(v any) any {
 	if v == nil {
-		v = struct{}{} // Encode nil as an empty JSON object
-	} else {
-		// Use reflection to check for nil slices or maps
-		val := reflect.ValueOf(v)
-		if val.Kind() == reflect.Slice && val.IsNil() {
-			v = []any{} // Encode nil slice as an empty JSON array
-		} else if val.Kind() == reflect.Map && val.IsNil() {
-			v = map[string]any{} // Encode nil map as an empty JSON object
-		}
+		return struct{}{} // Encode nil as an empty JSON object
 	}
 
-	return json.NewEncoder(w).Encode(v)
+	// Use reflection to check for nil slices or maps
+	val := reflect.ValueOf(v)
+	if val.Kind() == reflect.Slice && val.IsNil() {
+		return []any{} // Encode nil slice as an empty JSON array
+	} else if val.Kind() == reflect.Map && val.IsNil() {
+		return map[string]any{} // Encode nil map as an empty JSON object
+	}
+	return v
 }
 
 func WriteJSONError(w http.ResponseWriter, status int, err error) error {
@@ -110,3 +110,59 @@ func TestWriteJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteJSONWithETag(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		expectCode  int
+	}{
+		{"no validator", http.MethodGet, "", http.StatusOK},
+		{"same version", http.MethodGet, `"abc"`, http.StatusNotModified},
+		{"weak validator", http.MethodGet, `W/"abc"`, http.StatusNotModified},
+		{"other version", http.MethodGet, `"abd"`, http.StatusOK},
+		{"not a read", http.MethodPut, `"abc"`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/", nil)
+			if tt.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			err := WriteJSONWithETag(w, r, http.StatusOK, `"abc"`, map[string]string{"message": "success"})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectCode, w.Code)
+			assert.Equal(t, `"abc"`, w.Header().Get("ETag"))
+			if tt.expectCode == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWriteJSONList(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := WriteJSONList(w, httptest.NewRequest(http.MethodGet, "/", nil), []string(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String())
+
+	tag := w.Header().Get("ETag")
+	assert.Equal(t, ContentETag([]byte(`[]`)), tag)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("If-None-Match", tag)
+	w = httptest.NewRecorder()
+	err = WriteJSONList(w, r, []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = httptest.NewRecorder()
+	err = WriteJSONList(w, r, []string{"food"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, tag, w.Header().Get("ETag"))
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const DefaultLocale = "en-US"
+
+type numberFormat struct {
+	decimal   string
+	thousands string
+}
+
+var numberFormats = map[string]numberFormat{
+	"en-US": {decimal: ".", thousands: ","},
+	"pt-BR": {decimal: ",", thousands: "."},
+}
+
+// IsSupportedLocale reports whether texts and numbers can be rendered for locale.
+func IsSupportedLocale(locale string) bool {
+	_, ok := numberFormats[locale]
+	return ok
+}
+
+// DecimalSeparator returns the decimal separator of locale, falling back to the default locale.
+func DecimalSeparator(locale string) string {
+	return formatFor(locale).decimal
+}
+
+// FormatDecimal renders v with the given number of decimals and the separators of locale.
+func FormatDecimal(locale string, v float64, decimals int) string {
+	format := formatFor(locale)
+
+	s := strconv.FormatFloat(math.Abs(v), 'f', decimals, 64)
+	intPart, fracPart, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteString("-")
+	}
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(format.thousands)
+		}
+		b.WriteRune(digit)
+	}
+	if fracPart != "" {
+		b.WriteString(format.decimal)
+		b.WriteString(fracPart)
+	}
+	return b.String()
+}
+
+func formatFor(locale string) numberFormat {
+	if format, ok := numberFormats[locale]; ok {
+		return format
+	}
+	return numberFormats[DefaultLocale]
+}
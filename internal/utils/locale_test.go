@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		name     string
+		locale   string
+		value    float64
+		decimals int
+		expected string
+	}{
+		{
+			name:     "en-US with thousands",
+			locale:   "en-US",
+			value:    1234567.891,
+			decimals: 2,
+			expected: "1,234,567.89",
+		},
+		{
+			name:     "pt-BR with thousands",
+			locale:   "pt-BR",
+			value:    1234567.891,
+			decimals: 2,
+			expected: "1.234.567,89",
+		},
+		{
+			name:     "negative",
+			locale:   "pt-BR",
+			value:    -12.5,
+			decimals: 2,
+			expected: "-12,50",
+		},
+		{
+			name:     "no decimals",
+			locale:   "en-US",
+			value:    999.6,
+			decimals: 0,
+			expected: "1,000",
+		},
+		{
+			name:     "unknown locale falls back",
+			locale:   "xx-XX",
+			value:    1000,
+			decimals: 2,
+			expected: "1,000.00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatDecimal(tt.locale, tt.value, tt.decimals))
+		})
+	}
+}
+
+func TestDecimalSeparator(t *testing.T) {
+	assert.Equal(t, ",", DecimalSeparator("pt-BR"))
+	assert.Equal(t, ".", DecimalSeparator("en-US"))
+	assert.True(t, IsSupportedLocale("pt-BR"))
+	assert.False(t, IsSupportedLocale("fr-FR"))
+}
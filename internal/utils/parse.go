@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -27,6 +28,20 @@ func ParseQueryParamInt(r *http.Request, dest *int, paramName string, required b
 	return nil
 }
 
+// ParseQueryParamList reads a comma separated query param, which may also be
+// repeated. Blank items are dropped.
+func ParseQueryParamList(r *http.Request, paramName string) []string {
+	var result []string
+	for _, param := range r.URL.Query()[paramName] {
+		for _, item := range strings.Split(param, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
 func ParseUUID(str, paramName string) (uuid.UUID, error) {
 	if str == "" {
 		return uuid.UUID{}, fmt.Errorf("path parameter %s is required", paramName)
@@ -40,6 +55,19 @@ func ParseUUID(str, paramName string) (uuid.UUID, error) {
 	return parsedUUID, nil
 }
 
+func ParseInt32(str, paramName string) (int32, error) {
+	if str == "" {
+		return 0, fmt.Errorf("path parameter %s is required", paramName)
+	}
+
+	parsedValue, err := strconv.ParseInt(str, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid path parameter %s: expected an integer", paramName)
+	}
+
+	return int32(parsedValue), nil
+}
+
 func ParseIsoDate(dateStr string, dest *time.Time) error {
 	result, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
@@ -67,6 +67,43 @@ func TestParseQueryParamInt(t *testing.T) {
 	}
 }
 
+func TestParseQueryParamList(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{
+			name:     "comma separated",
+			query:    "tags=vacation-2026,work-trip",
+			expected: []string{"vacation-2026", "work-trip"},
+		},
+		{
+			name:     "repeated",
+			query:    "tags=vacation-2026&tags=work-trip",
+			expected: []string{"vacation-2026", "work-trip"},
+		},
+		{
+			name:     "blank items dropped",
+			query:    "tags=+vacation-2026+,,",
+			expected: []string{"vacation-2026"},
+		},
+		{
+			name:     "missing",
+			query:    "",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := url.Parse("http://example.com?" + tt.query)
+			r := &http.Request{URL: u}
+			assert.Equal(t, tt.expected, ParseQueryParamList(r, "tags"))
+		})
+	}
+}
+
 func TestParseUUID(t *testing.T) {
 	validUUID := uuid.New()
 	tests := []struct {
@@ -108,6 +145,54 @@ func TestParseUUID(t *testing.T) {
 	}
 }
 
+func TestParseInt32(t *testing.T) {
+	tests := []struct {
+		name      string
+		str       string
+		paramName string
+		expected  int32
+		wantErr   bool
+	}{
+		{
+			name:      "valid integer",
+			str:       "42",
+			paramName: "id",
+			expected:  42,
+			wantErr:   false,
+		},
+		{
+			name:      "empty string",
+			str:       "",
+			paramName: "id",
+			wantErr:   true,
+		},
+		{
+			name:      "not an integer",
+			str:       "abc",
+			paramName: "id",
+			wantErr:   true,
+		},
+		{
+			name:      "out of range",
+			str:       "4294967296",
+			paramName: "id",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInt32(tt.str, tt.paramName)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+		})
+	}
+}
+
 func TestParseIsoDate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// Period is a half-open date range [From, To).
+type Period struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// PeriodGranularity describes how a period was expressed so that the
+// matching previous period can be derived from it.
+type PeriodGranularity int
+
+const (
+	GranularityRange PeriodGranularity = iota
+	GranularityDay
+	GranularityMonth
+	GranularityYear
+)
+
+// ParsePeriod parses a calendar period written as YYYY, YYYY-MM or YYYY-MM-DD.
+func ParsePeriod(value string) (Period, PeriodGranularity, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return Period{From: t, To: t.AddDate(0, 0, 1)}, GranularityDay, nil
+	}
+	if t, err := time.Parse("2006-01", value); err == nil {
+		return Period{From: t, To: t.AddDate(0, 1, 0)}, GranularityMonth, nil
+	}
+	if t, err := time.Parse("2006", value); err == nil {
+		return Period{From: t, To: t.AddDate(1, 0, 0)}, GranularityYear, nil
+	}
+	return Period{}, GranularityRange, fmt.Errorf("invalid period format: expected YYYY, YYYY-MM or YYYY-MM-DD, got %s", value)
+}
+
+// ParseDateRange builds a period from two inclusive ISO dates.
+func ParseDateRange(fromStr, toStr string) (Period, error) {
+	var from, to time.Time
+	if err := ParseIsoDate(fromStr, &from); err != nil {
+		return Period{}, err
+	}
+	if err := ParseIsoDate(toStr, &to); err != nil {
+		return Period{}, err
+	}
+	if to.Before(from) {
+		return Period{}, fmt.Errorf("invalid date range: %s is before %s", toStr, fromStr)
+	}
+	return Period{From: from, To: to.AddDate(0, 0, 1)}, nil
+}
+
+// Previous returns the period immediately preceding p. Calendar periods step
+// back by one unit of their granularity; arbitrary ranges by their own length.
+func (p Period) Previous(granularity PeriodGranularity) Period {
+	switch granularity {
+	case GranularityYear:
+		return Period{From: p.From.AddDate(-1, 0, 0), To: p.From}
+	case GranularityMonth:
+		return Period{From: p.From.AddDate(0, -1, 0), To: p.From}
+	default:
+		return Period{From: p.From.Add(-p.To.Sub(p.From)), To: p.From}
+	}
+}
+
+// CurrentMonth returns the calendar month containing now.
+func CurrentMonth(now time.Time) Period {
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return Period{From: from, To: from.AddDate(0, 1, 0)}
+}
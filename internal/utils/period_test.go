@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		from        string
+		to          string
+		granularity PeriodGranularity
+		wantErr     bool
+	}{
+		{
+			name:        "year",
+			value:       "2026",
+			from:        "2026-01-01",
+			to:          "2027-01-01",
+			granularity: GranularityYear,
+		},
+		{
+			name:        "month",
+			value:       "2026-09",
+			from:        "2026-09-01",
+			to:          "2026-10-01",
+			granularity: GranularityMonth,
+		},
+		{
+			name:        "day",
+			value:       "2026-09-15",
+			from:        "2026-09-15",
+			to:          "2026-09-16",
+			granularity: GranularityDay,
+		},
+		{
+			name:    "invalid",
+			value:   "09-2026",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			period, granularity, err := ParsePeriod(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, date(tt.from), period.From)
+			assert.Equal(t, date(tt.to), period.To)
+			assert.Equal(t, tt.granularity, granularity)
+		})
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	period, err := ParseDateRange("2026-09-01", "2026-09-10")
+	assert.NoError(t, err)
+	assert.Equal(t, date("2026-09-01"), period.From)
+	assert.Equal(t, date("2026-09-11"), period.To)
+
+	_, err = ParseDateRange("2026-09-10", "2026-09-01")
+	assert.Error(t, err)
+}
+
+func TestPeriodPrevious(t *testing.T) {
+	tests := []struct {
+		name        string
+		period      Period
+		granularity PeriodGranularity
+		from        string
+		to          string
+	}{
+		{
+			name:        "month",
+			period:      Period{From: date("2026-03-01"), To: date("2026-04-01")},
+			granularity: GranularityMonth,
+			from:        "2026-02-01",
+			to:          "2026-03-01",
+		},
+		{
+			name:        "year",
+			period:      Period{From: date("2026-01-01"), To: date("2027-01-01")},
+			granularity: GranularityYear,
+			from:        "2025-01-01",
+			to:          "2026-01-01",
+		},
+		{
+			name:        "range",
+			period:      Period{From: date("2026-09-11"), To: date("2026-09-21")},
+			granularity: GranularityRange,
+			from:        "2026-09-01",
+			to:          "2026-09-11",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := tt.period.Previous(tt.granularity)
+			assert.Equal(t, date(tt.from), previous.From)
+			assert.Equal(t, date(tt.to), previous.To)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Package webhook signs and schedules the events delivered to the endpoints
+// users register, following the Standard Webhooks specification that Svix,
+// and thus Clerk, implement.
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	svix "github.com/svix/svix-webhooks/go"
+)
+
+// Event types
+const (
+	ExpenseCreated  = "expense.created"
+	ExpenseUpdated  = "expense.updated"
+	ExpenseDeleted  = "expense.deleted"
+	ImportCompleted = "import.completed"
+)
+
+// EventTypes lists the event types endpoints can subscribe to.
+var EventTypes = []string{ExpenseCreated, ExpenseUpdated, ExpenseDeleted, ImportCompleted}
+
+// Headers of a delivery
+const (
+	IDHeader        = "webhook-id"
+	TimestampHeader = "webhook-timestamp"
+	SignatureHeader = "webhook-signature"
+)
+
+// Prefix of the secrets, as expected by the Standard Webhooks libraries
+const secretPrefix = "whsec_"
+
+// Number of attempts after which a delivery is given up
+const MaxAttempts = 10
+
+// Delay before the first retry, doubled on every retry after it
+const BaseDelay = time.Minute
+
+// Maximum length of the response bodies logged
+const MaxResponseBody = 1024
+
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// Envelope is the payload of a delivery.
+type Envelope struct {
+	Type      string          `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// ValidFilter reports whether a filter is an event type, or a wildcard such as
+// "expense.*" matching a group of them.
+func ValidFilter(filter string) bool {
+	for _, eventType := range EventTypes {
+		if matches(filter, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribed reports whether an endpoint filtering on the given event types
+// receives an event. Endpoints without filters receive every event.
+func Subscribed(filters []string, eventType string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, filter := range filters {
+		if matches(filter, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(filter, eventType string) bool {
+	if group, ok := strings.CutSuffix(filter, ".*"); ok {
+		return strings.HasPrefix(eventType, group+".")
+	}
+	return filter == eventType
+}
+
+// NewSecret returns a random signing secret.
+func NewSecret() (string, error) {
+	key := make([]byte, 24)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return secretPrefix + base64.StdEncoding.EncodeToString(key), nil
+}
+
+// Sign returns the headers identifying and signing a delivery.
+func Sign(secret, messageID string, timestamp time.Time, payload []byte) (http.Header, error) {
+	wh, err := svix.NewWebhook(secret)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := wh.Sign(messageID, timestamp, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := http.Header{}
+	headers.Set(IDHeader, messageID)
+	headers.Set(TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	headers.Set(SignatureHeader, signature)
+	return headers, nil
+}
+
+// NextAttempt returns when to retry a delivery after its attempts failed, and
+// false once it should be given up.
+func NextAttempt(attempts int, now time.Time) (time.Time, bool) {
+	if attempts >= MaxAttempts {
+		return time.Time{}, false
+	}
+	return now.Add(BaseDelay << (attempts - 1)), true
+}
+
+// Successful reports whether an endpoint accepted a delivery.
+func Successful(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
+
+// PublicAddress reports whether an IP address can be reached from the
+// internet, so that endpoints cannot reach into the network of the server.
+func PublicAddress(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
+
+// DialControl is a net.Dialer Control rejecting the connections to addresses
+// that are not public, once the host of an endpoint is resolved.
+func DialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !PublicAddress(ip) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
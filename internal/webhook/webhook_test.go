@@ -0,0 +1,141 @@
+package webhook
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	svix "github.com/svix/svix-webhooks/go"
+)
+
+func TestValidFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{"expense.created", true},
+		{"import.completed", true},
+		{"expense.*", true},
+		{"import.*", true},
+		{"budget.*", false},
+		{"expense.archived", false},
+		{"expense", false},
+		{"*", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			assert.Equal(t, tt.want, ValidFilter(tt.filter))
+		})
+	}
+}
+
+func TestSubscribed(t *testing.T) {
+	tests := []struct {
+		name      string
+		filters   []string
+		eventType string
+		want      bool
+	}{
+		{"no filters", nil, ExpenseDeleted, true},
+		{"exact match", []string{ExpenseCreated, ImportCompleted}, ImportCompleted, true},
+		{"wildcard", []string{"expense.*"}, ExpenseUpdated, true},
+		{"other type", []string{ExpenseCreated}, ExpenseDeleted, false},
+		{"other group", []string{"import.*"}, ExpenseCreated, false},
+		{"group prefix only", []string{"exp.*"}, ExpenseCreated, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Subscribed(tt.filters, tt.eventType))
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, "whsec_"))
+
+	payload := []byte(`{"type":"expense.created","data":{}}`)
+	headers, err := Sign(secret, "msg_1", time.Now(), payload)
+	require.NoError(t, err)
+	assert.Equal(t, "msg_1", headers.Get(IDHeader))
+	assert.True(t, strings.HasPrefix(headers.Get(SignatureHeader), "v1,"))
+
+	wh, err := svix.NewWebhook(secret)
+	require.NoError(t, err)
+	assert.NoError(t, wh.Verify(payload, headers))
+	assert.Error(t, wh.Verify([]byte(`{"type":"expense.deleted","data":{}}`), headers))
+
+	other, err := NewSecret()
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+}
+
+func TestNextAttempt(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+		ok       bool
+	}{
+		{1, time.Minute, true},
+		{2, 2 * time.Minute, true},
+		{3, 4 * time.Minute, true},
+		{MaxAttempts - 1, BaseDelay << (MaxAttempts - 2), true},
+		{MaxAttempts, 0, false},
+	}
+
+	for _, tt := range tests {
+		next, ok := NextAttempt(tt.attempts, now)
+		assert.Equal(t, tt.ok, ok, "attempts %d", tt.attempts)
+		if tt.ok {
+			assert.Equal(t, now.Add(tt.want), next, "attempts %d", tt.attempts)
+		}
+	}
+}
+
+func TestSuccessful(t *testing.T) {
+	assert.True(t, Successful(200))
+	assert.True(t, Successful(204))
+	assert.False(t, Successful(301))
+	assert.False(t, Successful(410))
+	assert.False(t, Successful(500))
+}
+
+func TestPublicAddress(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1::", true},
+		{"127.0.0.1", false},
+		{"10.0.0.8", false},
+		{"172.16.4.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.want, PublicAddress(net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestDialControl(t *testing.T) {
+	assert.NoError(t, DialControl("tcp4", "93.184.216.34:443", nil))
+	assert.ErrorIs(t, DialControl("tcp4", "127.0.0.1:8080", nil), ErrPrivateAddress)
+	assert.ErrorIs(t, DialControl("tcp6", "[::1]:443", nil), ErrPrivateAddress)
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"net/http"
 	"os"
 	"strconv"
@@ -9,8 +12,10 @@ import (
 	"github.com/igorschechtel/clearflow-backend/internal/api/handlers"
 	"github.com/igorschechtel/clearflow-backend/internal/config"
 	"github.com/igorschechtel/clearflow-backend/internal/database"
+	"github.com/igorschechtel/clearflow-backend/internal/jobs"
 	"github.com/igorschechtel/clearflow-backend/internal/repositories"
 	"github.com/igorschechtel/clearflow-backend/internal/services"
+	"github.com/igorschechtel/clearflow-backend/internal/storage"
 	"github.com/go-playground/validator/v10"
 	"github.com/sirupsen/logrus"
 )
@@ -46,15 +51,73 @@ func main() {
 		logrus.WithError(err).Fatal("Failed to run database migrations")
 	}
 
+	// Blob storage
+	blobStore, err := newBlobStore(cfg.Storage)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to set up blob storage")
+	}
+	urlSecret := cfg.Storage.URLSecret
+	if urlSecret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			logrus.WithError(err).Fatal("Failed to generate attachment URL secret")
+		}
+		urlSecret = hex.EncodeToString(secret)
+		logrus.Warn("ATTACHMENT_URL_SECRET is not set, attachment links will not survive restarts")
+	}
+	urlSigner := storage.NewURLSigner(urlSecret)
+
 	// Repositories
 	userRepo := repositories.NewUserRepository(db)
 	expenseRepo := repositories.NewExpenseRepository(db)
 	categoryRepo := repositories.NewCategoryRepository(db)
+	reportRepo := repositories.NewReportRepository(db)
+	anomalyRepo := repositories.NewAnomalyRepository(db)
+	insightRepo := repositories.NewInsightRepository(db)
+	accountRepo := repositories.NewAccountRepository(db)
+	tagRepo := repositories.NewTagRepository(db)
+	workspaceRepo := repositories.NewWorkspaceRepository(db)
+	contactRepo := repositories.NewContactRepository(db)
+	settlementRepo := repositories.NewSettlementRepository(db)
+	attachmentRepo := repositories.NewAttachmentRepository(db)
+	merchantRepo := repositories.NewMerchantRepository(db)
+	archiveRepo := repositories.NewArchiveRepository(db)
+	accountDeletionRepo := repositories.NewAccountDeletionRepository(db)
+	auditRepo := repositories.NewAuditRepository(db)
+	idempotencyRepo := repositories.NewIdempotencyRepository(db)
+	syncRepo := repositories.NewSyncRepository(db)
+	webhookRepo := repositories.NewWebhookRepository(db)
 
 	// Services
-	userService := services.NewUserService(userRepo)
-	expenseService := services.NewExpenseService(expenseRepo, categoryRepo, userService)
-	categoryService := services.NewCategoryService(categoryRepo, userService)
+	publicIDService := services.NewPublicIDService(expenseRepo, categoryRepo)
+	auditService := services.NewAuditService(auditRepo, userRepo)
+	userService := services.NewUserService(userRepo, auditService)
+	workspaceService := services.NewWorkspaceService(workspaceRepo, userService)
+	webhookService := services.NewWebhookService(webhookRepo, expenseRepo, categoryRepo, tagRepo, userService, cfg.Webhooks.Timeout, cfg.Webhooks.DeliveryRetention, cfg.Webhooks.AllowPrivateNetworks)
+	anomalyService := services.NewAnomalyService(anomalyRepo, expenseRepo, userService)
+	expenseService := services.NewExpenseService(expenseRepo, categoryRepo, accountRepo, tagRepo, contactRepo, merchantRepo, userService, workspaceService, anomalyService, auditService, webhookService)
+	categoryService := services.NewCategoryService(categoryRepo, userService, workspaceService)
+	accountService := services.NewAccountService(accountRepo, userService)
+	tagService := services.NewTagService(tagRepo, userService)
+	contactService := services.NewContactService(contactRepo, userService)
+	merchantService := services.NewMerchantService(merchantRepo, expenseRepo, userService)
+	settlementService := services.NewSettlementService(settlementRepo, contactRepo, expenseRepo, userService)
+	attachmentService := services.NewAttachmentService(
+		attachmentRepo, expenseRepo, blobStore, urlSigner, userService, workspaceService,
+		cfg.Storage.URLExpiry, cfg.Storage.PublicURL, cfg.Storage.MaxAttachmentBytes,
+	)
+	reportService := services.NewReportService(reportRepo, userService, workspaceService)
+	forecastService := services.NewForecastService(expenseRepo, categoryRepo, userService)
+	insightService := services.NewInsightService(insightRepo, expenseRepo, categoryRepo, userRepo, userService)
+	importService := services.NewImportService(expenseRepo, expenseService, userService, auditService, webhookService)
+	archiveService := services.NewArchiveService(archiveRepo, blobStore, userService, auditService)
+	accountDeletionService := services.NewAccountDeletionService(accountDeletionRepo, blobStore, userService, auditService, cfg.Account.DeletionGracePeriod)
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, userService, cfg.Idempotency.KeyTTL)
+	trashService := services.NewTrashService(expenseRepo, categoryRepo, blobStore, userService, workspaceService, auditService, cfg.Trash.Retention)
+	syncService := services.NewSyncService(
+		syncRepo, expenseRepo, tagRepo, userService, workspaceService,
+		expenseService, categoryService, tagService, publicIDService, cfg.Sync.TombstoneRetention,
+	)
 
 	// Logger
 	logger := logrus.StandardLogger()
@@ -62,11 +125,42 @@ func main() {
 	// Handlers
 	handlers := &api.Handlers{
 		User:         handlers.NewUserHandler(userService, v),
-		Expense:      handlers.NewExpenseHandler(expenseService, v),
-		Category:     handlers.NewCategoryHandler(categoryService, v),
-		ClerkWebhook: handlers.NewClerkWebhookHandler(userService, cfg.Clerk.WebhookSecret, logger),
+		Deletion:     handlers.NewAccountDeletionHandler(accountDeletionService),
+		Expense:      handlers.NewExpenseHandler(expenseService, publicIDService, v),
+		Income:       handlers.NewIncomeHandler(expenseService, publicIDService, v),
+		Transfer:     handlers.NewTransferHandler(expenseService, publicIDService, v),
+		Category:     handlers.NewCategoryHandler(categoryService, publicIDService, v),
+		Account:      handlers.NewAccountHandler(accountService, v),
+		Tag:          handlers.NewTagHandler(tagService, v),
+		Merchant:     handlers.NewMerchantHandler(merchantService, v),
+		Workspace:    handlers.NewWorkspaceHandler(workspaceService, v),
+		Contact:      handlers.NewContactHandler(contactService, v),
+		Settlement:   handlers.NewSettlementHandler(settlementService, v),
+		Attachment:   handlers.NewAttachmentHandler(attachmentService, publicIDService, cfg.Storage.MaxAttachmentBytes),
+		Report:       handlers.NewReportHandler(reportService, publicIDService, v),
+		Anomaly:      handlers.NewAnomalyHandler(anomalyService, publicIDService, v),
+		Insight:      handlers.NewInsightHandler(insightService, publicIDService, v),
+		Forecast:     handlers.NewForecastHandler(forecastService, publicIDService, v),
+		Import:       handlers.NewImportHandler(importService, publicIDService, v),
+		Archive:      handlers.NewArchiveHandler(archiveService),
+		Trash:        handlers.NewTrashHandler(trashService, publicIDService, v),
+		Audit:        handlers.NewAuditHandler(auditService, v),
+		Sync:         handlers.NewSyncHandler(syncService, publicIDService, v),
+		Webhook:      handlers.NewWebhookHandler(webhookService, v),
+		ClerkWebhook: handlers.NewClerkWebhookHandler(userService, accountDeletionService, cfg.Clerk.WebhookSecret, logger),
 	}
-	router := api.SetupRouter(cfg, handlers, db)
+	router := api.SetupRouter(cfg, handlers, idempotencyService, db)
+
+	// Background jobs
+	ctx := context.Background()
+	go jobs.Every(ctx, "refresh_insights", cfg.Jobs.InsightsInterval, insightService.RefreshAll)
+	go jobs.Every(ctx, "assign_merchants", cfg.Jobs.MerchantsInterval, merchantService.AssignMissing)
+	go jobs.Every(ctx, "purge_deleted_accounts", cfg.Jobs.AccountPurgeInterval, accountDeletionService.PurgeDue)
+	go jobs.Every(ctx, "purge_trash", cfg.Jobs.TrashPurgeInterval, trashService.PurgeExpired)
+	go jobs.Every(ctx, "purge_idempotency_keys", cfg.Jobs.IdempotencyPurgeInterval, idempotencyService.PurgeExpired)
+	go jobs.Every(ctx, "purge_sync_tombstones", cfg.Jobs.TombstonePurgeInterval, syncService.PurgeTombstones)
+	go jobs.Every(ctx, "deliver_webhooks", cfg.Jobs.WebhookDeliveryInterval, webhookService.DeliverDue)
+	go jobs.Every(ctx, "purge_webhook_deliveries", cfg.Jobs.WebhookPurgeInterval, webhookService.PurgeDeliveries)
 
 	// Start server
 	addr := ":" + strconv.Itoa(cfg.Server.Port)
@@ -75,3 +169,17 @@ func main() {
 		logrus.WithError(err).Fatal("Server failed to start")
 	}
 }
+
+// newBlobStore builds the blob store selected by the configuration.
+func newBlobStore(cfg config.StorageConfig) (storage.BlobStore, error) {
+	if cfg.Backend == "s3" {
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:        cfg.S3.Endpoint,
+			Bucket:          cfg.S3.Bucket,
+			Region:          cfg.S3.Region,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+		})
+	}
+	return storage.NewLocalStore(cfg.LocalDir)
+}